@@ -159,7 +159,7 @@ func setupYorcServer(workingDirectoryPath string) error {
 			return err
 		}
 
-		request, err := client.NewRequest("PUT", "/hosts_pool",
+		request, err := client.NewRequest("PUT", "/hosts_pools/default",
 			bytes.NewBuffer(bArray))
 		if err != nil {
 			return err
@@ -195,7 +195,7 @@ func waitForYorcServerUP(timeout time.Duration) error {
 		return err
 	}
 
-	request, err := client.NewRequest("PUT", "/hosts_pool", nil)
+	request, err := client.NewRequest("PUT", "/hosts_pools/default", nil)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,125 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/deployments"
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/helper/tabutil"
+	"github.com/ystia/yorc/rest"
+)
+
+const timeFormat = "2006-01-02 15:04:05 MST"
+
+var checksCmd = &cobra.Command{
+	Use:     "checks <DeploymentId>",
+	Short:   "List monitoring checks of a given deployment or perform commands on them",
+	Long:    "List monitoring checks of a given deployment instances with their status, last check date and last error or perform commands on them",
+	Aliases: []string{"check"},
+}
+
+func init() {
+	var status string
+	var history bool
+	checksCmd.Flags().StringVarP(&status, "status", "s", "", "Display only checks having this status (PASSING or CRITICAL)")
+	checksCmd.Flags().BoolVarP(&history, "history", "", false, "Display the status changes history of each check")
+	checksCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.Errorf("Expecting a deployment id (got %d parameters)", len(args))
+		}
+		return listChecks(args[0], status, history)
+	}
+	deployments.DeploymentsCmd.AddCommand(checksCmd)
+}
+
+func listChecks(deploymentID, status string, history bool) error {
+	client, err := httputil.GetClient(deployments.ClientConfig)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+
+	requestURL := fmt.Sprintf("/deployments/%s/checks", deploymentID)
+	if status != "" {
+		requestURL += "?status=" + url.QueryEscape(status)
+	}
+	request, err := client.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, deploymentID, "deployment", http.StatusOK, http.StatusNoContent)
+	if response.StatusCode == http.StatusNoContent {
+		fmt.Println("No monitoring checks found for this deployment")
+		return nil
+	}
+
+	var collection rest.CheckReportsCollection
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	err = json.Unmarshal(body, &collection)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+
+	checksTable := tabutil.NewTable()
+	checksTable.AddHeaders("Node", "Instance", "Type", "Status", "Last Check", "Last Error")
+	for _, check := range collection.Checks {
+		checkStatus := check.Status.String()
+		if check.Paused {
+			checkStatus += " (paused)"
+		}
+		lastCheck := "never"
+		if !check.LastCheck.IsZero() {
+			lastCheck = check.LastCheck.Local().Format(timeFormat)
+		}
+		checksTable.AddRow(check.NodeName, check.Instance, check.Type.String(), checkStatus, lastCheck, check.LastError)
+	}
+	fmt.Println("Checks:")
+	fmt.Println(checksTable.Render())
+
+	if !history {
+		return nil
+	}
+	for _, check := range collection.Checks {
+		fmt.Printf("\nHistory of check for node %q instance %q:\n", check.NodeName, check.Instance)
+		if len(check.History) == 0 {
+			fmt.Println("No status change")
+			continue
+		}
+		historyTable := tabutil.NewTable()
+		historyTable.AddHeaders("Date", "Status", "Error")
+		for _, entry := range check.History {
+			historyTable.AddRow(entry.Date.Local().Format(timeFormat), entry.Status.String(), entry.Error)
+		}
+		fmt.Println(historyTable.Render())
+	}
+	return nil
+}
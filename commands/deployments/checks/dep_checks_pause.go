@@ -0,0 +1,67 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checks
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/deployments"
+	"github.com/ystia/yorc/commands/httputil"
+)
+
+func init() {
+	var pauseCmd = &cobra.Command{
+		Use:   "pause <DeploymentId> <NodeName> <InstanceName>",
+		Short: "Pause the monitoring check of a node instance (maintenance mode)",
+		Long:  "Pause the monitoring check of a node instance (maintenance mode). The check is not run anymore until it is resumed but it stays registered.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateCheckState(args, "pause")
+		},
+	}
+	var resumeCmd = &cobra.Command{
+		Use:   "resume <DeploymentId> <NodeName> <InstanceName>",
+		Short: "Resume a paused monitoring check of a node instance",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateCheckState(args, "resume")
+		},
+	}
+	checksCmd.AddCommand(pauseCmd)
+	checksCmd.AddCommand(resumeCmd)
+}
+
+func updateCheckState(args []string, action string) error {
+	if len(args) != 3 {
+		return errors.Errorf("Expecting a deployment id, a node name and an instance name (got %d parameters)", len(args))
+	}
+	client, err := httputil.GetClient(deployments.ClientConfig)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	request, err := client.NewRequest("PUT", fmt.Sprintf("/deployments/%s/checks/%s/%s/%s", args[0], args[1], args[2], action), nil)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, fmt.Sprintf("%s-%s", args[1], args[2]), "check", http.StatusOK)
+	return nil
+}
@@ -16,6 +16,7 @@ package deployments
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -28,12 +29,14 @@ import (
 	"github.com/ystia/yorc/commands/httputil"
 	"github.com/ystia/yorc/helper/ziputil"
 	"github.com/ystia/yorc/rest"
+	"github.com/ystia/yorc/tasks/workflow/builder"
 )
 
 func init() {
 	var shouldStreamLogs bool
 	var shouldStreamEvents bool
 	var deploymentID string
+	var dryRun bool
 	var deployCmd = &cobra.Command{
 		Use:   "deploy <csar_path>",
 		Short: "Deploy an application",
@@ -57,6 +60,21 @@ func init() {
 			if err != nil {
 				return err
 			}
+			if dryRun {
+				csarZip, err := readCSAR(absPath, fileInfo)
+				if err != nil {
+					httputil.ErrExit(err)
+				}
+				plan, err := PlanCSAR(csarZip, client, deploymentID)
+				if err != nil {
+					httputil.ErrExit(err)
+				}
+				PrintPlan(plan)
+				if plan.HasIssues() {
+					httputil.ErrExit("the deployment plan reports issues")
+				}
+				return nil
+			}
 			var location = ""
 			if !fileInfo.IsDir() {
 				file, err := os.Open(absPath)
@@ -107,6 +125,7 @@ func init() {
 	}
 	deployCmd.PersistentFlags().BoolVarP(&shouldStreamLogs, "stream-logs", "l", false, "Stream logs after deploying the CSAR. In this mode logs can't be filtered, to use this feature see the \"log\" command.")
 	deployCmd.PersistentFlags().BoolVarP(&shouldStreamEvents, "stream-events", "e", false, "Stream events after deploying the CSAR.")
+	deployCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "", false, "Do not deploy the CSAR but display the execution plan of its install workflow and the issues that would prevent it to succeed.")
 	// Do not impose a max id length as it doesn't have a concrete impact for now
 	//deployCmd.PersistentFlags().StringVarP(&deploymentID, "id", "", "", fmt.Sprintf("Specify a id for this deployment. This id should not already exists, should respect the following format: %q and should be less than %d characters long", rest.YorcDeploymentIDPattern, rest.YorcDeploymentIDMaxLength))
 	deployCmd.PersistentFlags().StringVarP(&deploymentID, "id", "", "", fmt.Sprintf("Specify a id for this deployment. This id should not already exists, should respect the following format: %q", rest.YorcDeploymentIDPattern))
@@ -141,3 +160,46 @@ func SubmitCSAR(csarZip []byte, client *httputil.YorcClient, deploymentID string
 	}
 	return "", errors.New("No \"Location\" header returned in Yorc response")
 }
+
+// readCSAR returns the content of a zip archive or zips a file or directory
+func readCSAR(absPath string, fileInfo os.FileInfo) ([]byte, error) {
+	if !fileInfo.IsDir() {
+		buff, err := ioutil.ReadFile(absPath)
+		if err != nil {
+			return nil, err
+		}
+		if http.DetectContentType(buff) == "application/zip" {
+			return buff, nil
+		}
+	}
+	return ziputil.ZipPath(absPath)
+}
+
+// PlanCSAR submits an archive in dry-run mode and returns the execution plan of its install workflow
+func PlanCSAR(csarZip []byte, client *httputil.YorcClient, deploymentID string) (*builder.Plan, error) {
+	var request *http.Request
+	var err error
+	if deploymentID != "" {
+		request, err = client.NewRequest(http.MethodPut, path.Join("/deployments", deploymentID)+"?dryRun=true", bytes.NewReader(csarZip))
+	} else {
+		request, err = client.NewRequest(http.MethodPost, "/deployments?dryRun=true", bytes.NewReader(csarZip))
+	}
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Content-Type", "application/zip")
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		// Try to get the reason
+		httputil.PrintErrors(response.Body)
+		return nil, errors.Errorf("Dry-run failed: Expecting HTTP Status code 200 got %d, reason %q", response.StatusCode, response.Status)
+	}
+	plan := new(builder.Plan)
+	err = json.NewDecoder(response.Body).Decode(plan)
+	return plan, errors.Wrap(err, "failed to decode execution plan")
+}
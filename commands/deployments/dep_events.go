@@ -96,6 +96,16 @@ func StreamsEvents(client *httputil.YorcClient, deploymentID string, colorize, f
 			fmt.Fprint(os.Stderr, "Failed to get latest events index from Yorc, events will appear from the beginning.")
 		}
 	}
+	if !stop {
+		pollPath := "/events"
+		if deploymentID != "" {
+			pollPath = "/deployments/" + deploymentID + "/events"
+		}
+		streamEntries(client, pollPath+"/stream", pollPath, deploymentID, lastIdx, func(event json.RawMessage) {
+			fmt.Printf("%s\n", formatEvent(event, colorize))
+		})
+		fmt.Fprintln(os.Stderr, "Yorc server does not support events streaming, falling back to polling.")
+	}
 	for {
 		if deploymentID != "" {
 			request, err = client.NewRequest("GET", fmt.Sprintf("/deployments/%s/events?index=%d", deploymentID, lastIdx), nil)
@@ -0,0 +1,82 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/ystia/yorc/commands/httputil"
+)
+
+func init() {
+	var outputFile string
+	var exportCmd = &cobra.Command{
+		Use:   "export <DeploymentId>",
+		Short: "Export a deployment into an archive",
+		Long: `Export a deployment into an archive that could be imported back using the import command.
+	The archive contains the original CSAR, the stored definitions, instances and attributes,
+	the tasks history as well as the deployment events and logs.
+	By default the archive is written into a <DeploymentId>.tar.gz file in the current directory.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.Errorf("Expecting a deployment id (got %d parameters)", len(args))
+			}
+			client, err := httputil.GetClient(ClientConfig)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			deploymentID := args[0]
+			if outputFile == "" {
+				outputFile = deploymentID + ".tar.gz"
+			}
+
+			request, err := client.NewRequest("GET", "/deployments/"+deploymentID+"/export", nil)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			request.Header.Add("Accept", "application/gzip")
+			response, err := client.Do(request)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			defer response.Body.Close()
+			httputil.HandleHTTPStatusCode(response, deploymentID, "deployment", http.StatusOK)
+
+			var w io.Writer = os.Stdout
+			if outputFile != "-" {
+				f, err := os.Create(outputFile)
+				if err != nil {
+					httputil.ErrExit(err)
+				}
+				defer f.Close()
+				w = f
+			}
+			if _, err = io.Copy(w, response.Body); err != nil {
+				httputil.ErrExit(errors.Wrapf(err, "failed to write deployment %q archive", deploymentID))
+			}
+			if outputFile != "-" {
+				fmt.Printf("Deployment %q exported into %q\n", deploymentID, outputFile)
+			}
+			return nil
+		},
+	}
+	exportCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "", "Path of the generated archive, use \"-\" to write it on the standard output. Defaults to <DeploymentId>.tar.gz")
+	DeploymentsCmd.AddCommand(exportCmd)
+}
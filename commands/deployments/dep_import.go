@@ -0,0 +1,69 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/ystia/yorc/commands/httputil"
+)
+
+func init() {
+	var importCmd = &cobra.Command{
+		Use:   "import <archive_path>",
+		Short: "Import a deployment from an archive",
+		Long: `Import a deployment from an archive generated by the export command.
+	The deployment keeps its original id and should not already exist.
+	Tasks that were not completed at export time are marked as canceled.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.Errorf("Expecting a path to an archive (got %d parameters)", len(args))
+			}
+			client, err := httputil.GetClient(ClientConfig)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			file, err := os.Open(args[0])
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			defer file.Close()
+
+			request, err := client.NewRequest(http.MethodPost, "/deployments/import", file)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			request.Header.Add("Content-Type", "application/gzip")
+			response, err := client.Do(request)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			defer response.Body.Close()
+			if response.StatusCode != http.StatusCreated {
+				// Try to get the reason
+				httputil.PrintErrors(response.Body)
+				httputil.ErrExit(errors.Errorf("Expecting HTTP Status code 201 got %d, reason %q", response.StatusCode, response.Status))
+			}
+			fmt.Printf("Deployment %q imported\n", path.Base(response.Header.Get("Location")))
+			return nil
+		},
+	}
+	DeploymentsCmd.AddCommand(importCmd)
+}
@@ -97,6 +97,20 @@ func StreamsLogs(client *httputil.YorcClient, deploymentID string, colorize, fro
 			fmt.Fprint(os.Stderr, "Failed to get latest log index from Yorc, logs will appear from the beginning.")
 		}
 	}
+	if !stop {
+		pollPath := "/logs"
+		if deploymentID != "" {
+			pollPath = "/deployments/" + deploymentID + "/logs"
+		}
+		streamEntries(client, pollPath+"/stream", pollPath, deploymentID, lastIdx, func(log json.RawMessage) {
+			if colorize {
+				fmt.Printf("%s\n", color.CyanString("%s", format(log)))
+			} else {
+				fmt.Printf("%s\n", format(log))
+			}
+		})
+		fmt.Fprintln(os.Stderr, "Yorc server does not support logs streaming, falling back to polling.")
+	}
 	var filtersParam string
 	for {
 		if deploymentID != "" {
@@ -0,0 +1,94 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ystia/yorc/helper/tabutil"
+	"github.com/ystia/yorc/tasks/workflow/builder"
+)
+
+// PrintPlan displays a workflow execution plan and its issues
+func PrintPlan(plan *builder.Plan) {
+	fmt.Printf("Execution plan of workflow %q:\n", plan.WorkflowName)
+	stepsTable := tabutil.NewTable()
+	stepsTable.AddHeaders("Level", "Step", "Target", "Activity", "Executor")
+	for _, step := range plan.Steps {
+		name := step.Name
+		if step.Async {
+			name += " (async)"
+		}
+		if step.IsOnFailurePath || step.IsOnCancelPath {
+			name += " (on failure/cancel)"
+		}
+		if len(step.Activities) == 0 {
+			stepsTable.AddRow(step.Level, name, step.Target, "", "")
+		}
+		for i, a := range step.Activities {
+			var executor string
+			switch {
+			case a.Skipped:
+				executor = "skipped (not implemented)"
+			case a.ImplementationArtifact != "":
+				executor = fmt.Sprintf("%s (%s)", a.ExecutorOrigin, a.ImplementationArtifact)
+			default:
+				executor = a.ExecutorOrigin
+			}
+			activity := fmt.Sprintf("%s: %s", a.Type, a.Value)
+			if i == 0 {
+				stepsTable.AddRow(step.Level, name, step.Target, activity, executor)
+			} else {
+				// Following activities of a step are displayed on their own row
+				stepsTable.AddRow("", "", "", activity, executor)
+			}
+		}
+	}
+	fmt.Println(stepsTable.Render())
+
+	printPlanIssues("Unresolved functions", plan.UnresolvedFunctions)
+	printPlanIssues("Missing implementations", plan.MissingImplementations)
+	if len(plan.MissingInfrastructureConfigs) > 0 {
+		fmt.Println("Missing infrastructures configurations:")
+		for _, infra := range plan.MissingInfrastructureConfigs {
+			fmt.Printf("  - %s\n", infra)
+		}
+	}
+	if !plan.HasIssues() {
+		fmt.Println("No issue detected.")
+	}
+}
+
+func printPlanIssues(title string, issues []builder.PlanIssue) {
+	if len(issues) == 0 {
+		return
+	}
+	fmt.Printf("%s:\n", title)
+	for _, issue := range issues {
+		var ctx []string
+		if issue.Step != "" {
+			ctx = append(ctx, fmt.Sprintf("step %q", issue.Step))
+		}
+		if issue.Node != "" {
+			ctx = append(ctx, fmt.Sprintf("node %q", issue.Node))
+		}
+		if len(ctx) > 0 {
+			fmt.Printf("  - [%s] %s\n", strings.Join(ctx, ", "), issue.Message)
+		} else {
+			fmt.Printf("  - %s\n", issue.Message)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ystia/yorc/commands/httputil"
+)
+
+// streamReconnectDelay is the delay before reconnecting to a stream after a connection loss
+const streamReconnectDelay = time.Second
+
+// streamEntries reads entries pushed by the server using Server-Sent Events on the given path and calls fn for each of them.
+//
+// Entries are streamed starting after lastIdx (from the beginning if lastIdx is 0), on connection loss it reconnects
+// and resumes from the last received event ID.
+// It only returns if the server does not support streaming, callers should then fall back to long polling on pollPath.
+func streamEntries(client *httputil.YorcClient, path, pollPath, deploymentID string, lastIdx uint64, fn func(entry json.RawMessage)) {
+	lastEventID := ""
+	if lastIdx != 0 {
+		lastEventID = strconv.FormatUint(lastIdx, 10)
+	}
+	connected := false
+	for {
+		request, err := client.NewRequest("GET", path, nil)
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+		request.Header.Add("Accept", "text/event-stream")
+		if lastEventID != "" {
+			request.Header.Add("Last-Event-ID", lastEventID)
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Connection to Yorc lost: %v, reconnecting...\n", err)
+			time.Sleep(streamReconnectDelay)
+			continue
+		}
+		if !connected && !isStreamingSupported(client, response, pollPath, deploymentID) {
+			response.Body.Close()
+			return
+		}
+		connected = true
+		if deploymentID != "" {
+			httputil.HandleHTTPStatusCode(response, deploymentID, "deployment", http.StatusOK)
+		} else {
+			httputil.HandleHTTPStatusCode(response, "", "events stream", http.StatusOK)
+		}
+		err = httputil.ReadServerSentEvents(response.Body, func(evt httputil.SSEvent) {
+			fn(json.RawMessage(evt.Data))
+			if evt.ID != "" {
+				lastEventID = evt.ID
+			}
+		})
+		response.Body.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Connection to Yorc lost: %v, reconnecting...\n", err)
+		}
+		time.Sleep(streamReconnectDelay)
+	}
+}
+
+// isStreamingSupported checks the response to a first stream request to detect servers without streaming endpoints
+func isStreamingSupported(client *httputil.YorcClient, response *http.Response, pollPath, deploymentID string) bool {
+	switch response.StatusCode {
+	case http.StatusMethodNotAllowed:
+		return false
+	case http.StatusNotFound:
+		if deploymentID == "" {
+			return false
+		}
+		// Either the deployment or the streaming endpoint does not exist
+		pollResponse, err := client.Head(pollPath)
+		if err != nil {
+			httputil.ErrExit(err)
+		}
+		pollResponse.Body.Close()
+		httputil.HandleHTTPStatusCode(pollResponse, deploymentID, "deployment", http.StatusOK)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,205 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/tabutil"
+)
+
+func init() {
+	var shouldStreamLogs bool
+	var shouldStreamEvents bool
+	var preview bool
+	var updateOperation string
+	var updateCmd = &cobra.Command{
+		Use:   "update <DeploymentId> <csar_path>",
+		Short: "Update a deployed application",
+		Long: `Update a deployed application using a modified version of its CSAR pointed by <csar_path>.
+	Added nodes are installed, removed nodes are uninstalled and an update operation is called on modified nodes.
+	<csar_path> is handled the same way than for the deploy command.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.Errorf("Expecting a deployment id and a path to a file or directory (got %d parameters)", len(args))
+			}
+			deploymentID := args[0]
+			client, err := httputil.GetClient(ClientConfig)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			absPath, err := filepath.Abs(args[1])
+			if err != nil {
+				return err
+			}
+			fileInfo, err := os.Stat(absPath)
+			if err != nil {
+				return err
+			}
+			csarZip, err := readCSAR(absPath, fileInfo)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			err = submitUpdate(client, deploymentID, csarZip)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			diff, err := getUpdatePreview(client, deploymentID)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			printTopologyDiff(diff)
+			if preview || diff.IsEmpty() {
+				// Discard the pending update
+				err = discardUpdate(client, deploymentID)
+				if err != nil {
+					httputil.ErrExit(err)
+				}
+				return nil
+			}
+
+			url := path.Join("/deployments", deploymentID, "update")
+			if updateOperation != "" {
+				url += "?operation=" + updateOperation
+			}
+			request, err := client.NewRequest(http.MethodPost, url, nil)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			response, err := client.Do(request)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			defer response.Body.Close()
+			httputil.HandleHTTPStatusCode(response, deploymentID, "deployment update", http.StatusCreated)
+			fmt.Printf("Update submitted. Deployment Id: %s\t(Update Task Id: %s)\n", deploymentID, path.Base(response.Header.Get("Location")))
+			if shouldStreamLogs && !shouldStreamEvents {
+				StreamsLogs(client, deploymentID, !NoColor, false, false)
+			} else if !shouldStreamLogs && shouldStreamEvents {
+				StreamsEvents(client, deploymentID, !NoColor, false, false)
+			} else if shouldStreamLogs && shouldStreamEvents {
+				return errors.Errorf("You can't provide stream-events and stream-logs flags at same time")
+			}
+			return nil
+		},
+	}
+	updateCmd.PersistentFlags().BoolVarP(&preview, "preview", "", false, "Only display the differences between the deployed application and the given CSAR without updating it.")
+	updateCmd.PersistentFlags().StringVarP(&updateOperation, "operation", "o", "", fmt.Sprintf("Operation called on modified nodes (default %q).", deployments.DefaultUpdateOperation))
+	updateCmd.PersistentFlags().BoolVarP(&shouldStreamLogs, "stream-logs", "l", false, "Stream logs after submitting the update. In this mode logs can't be filtered, to use this feature see the \"log\" command.")
+	updateCmd.PersistentFlags().BoolVarP(&shouldStreamEvents, "stream-events", "e", false, "Stream events after submitting the update.")
+	DeploymentsCmd.AddCommand(updateCmd)
+}
+
+func submitUpdate(client *httputil.YorcClient, deploymentID string, csarZip []byte) error {
+	request, err := client.NewRequest(http.MethodPut, path.Join("/deployments", deploymentID, "update"), bytes.NewReader(csarZip))
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Content-Type", "application/zip")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusCreated {
+		// Try to get the reason
+		httputil.PrintErrors(response.Body)
+		return errors.Errorf("PUT failed: Expecting HTTP Status code 201 got %d, reason %q", response.StatusCode, response.Status)
+	}
+	return nil
+}
+
+func getUpdatePreview(client *httputil.YorcClient, deploymentID string) (*deployments.TopologyDiff, error) {
+	request, err := client.NewRequest(http.MethodGet, path.Join("/deployments", deploymentID, "update", "preview"), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, deploymentID, "deployment update", http.StatusOK)
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	diff := new(deployments.TopologyDiff)
+	err = json.Unmarshal(body, diff)
+	return diff, errors.Wrap(err, "failed to decode the update preview")
+}
+
+func discardUpdate(client *httputil.YorcClient, deploymentID string) error {
+	request, err := client.NewRequest(http.MethodDelete, path.Join("/deployments", deploymentID, "update"), nil)
+	if err != nil {
+		return err
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, deploymentID, "deployment update", http.StatusOK)
+	return nil
+}
+
+func printTopologyDiff(diff *deployments.TopologyDiff) {
+	if diff.IsEmpty() {
+		fmt.Println("No change detected.")
+		return
+	}
+	diffTable := tabutil.NewTable()
+	diffTable.AddHeaders("Node", "Change", "Details")
+	for _, nodeName := range diff.AddedNodes {
+		diffTable.AddRow(nodeName, "added", "")
+	}
+	for _, nodeName := range diff.RemovedNodes {
+		diffTable.AddRow(nodeName, "removed", "")
+	}
+	for _, nodeDiff := range diff.ModifiedNodes {
+		var details []string
+		if nodeDiff.NewType != "" {
+			details = append(details, fmt.Sprintf("type: %s -> %s", nodeDiff.OldType, nodeDiff.NewType))
+		}
+		if len(nodeDiff.ChangedProperties) > 0 {
+			details = append(details, "properties: "+strings.Join(nodeDiff.ChangedProperties, ", "))
+		}
+		if len(nodeDiff.AddedRelationships) > 0 {
+			details = append(details, "added relationships: "+strings.Join(nodeDiff.AddedRelationships, ", "))
+		}
+		if len(nodeDiff.RemovedRelationships) > 0 {
+			details = append(details, "removed relationships: "+strings.Join(nodeDiff.RemovedRelationships, ", "))
+		}
+		if len(nodeDiff.OtherChanges) > 0 {
+			details = append(details, "other changes: "+strings.Join(nodeDiff.OtherChanges, ", "))
+		}
+		diffTable.AddRow(nodeDiff.Name, "modified", strings.Join(details, "; "))
+	}
+	fmt.Println(diffTable.Render())
+}
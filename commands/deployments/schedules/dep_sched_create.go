@@ -0,0 +1,94 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/deployments"
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/prov/scheduling"
+)
+
+func init() {
+	var workflowName string
+	var timezone string
+	var overlapPolicy string
+	var continueOnError bool
+	var paused bool
+	var createCmd = &cobra.Command{
+		Use:   "create <DeploymentId> <cron_expression>",
+		Short: "Schedule the execution of a workflow",
+		Long: `Schedule the execution of a workflow of a given deployment using a cron expression.
+	Cron expressions are composed of 5 fields: minute, hour, day of month, month and day of week (for instance "0 2 * * *"),
+	the @yearly, @monthly, @weekly, @daily and @hourly descriptors are also supported.
+	The overlap policy defines what happens when the schedule is triggered while its previous run is still in progress:
+	"skip" skips the new run, "queue" starts it as soon as the previous run ends and "cancel" cancels the previous run.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.Errorf("Expecting a deployment id and a cron expression (got %d parameters)", len(args))
+			}
+			if workflowName == "" {
+				return errors.New("Missing mandatory \"workflow-name\" flag")
+			}
+			policy, err := scheduling.ParseOverlapPolicy(overlapPolicy)
+			if err != nil {
+				return err
+			}
+			client, err := httputil.GetClient(deployments.ClientConfig)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			schedule := scheduling.Schedule{
+				WorkflowName:    workflowName,
+				Cron:            args[1],
+				Timezone:        timezone,
+				OverlapPolicy:   policy,
+				ContinueOnError: continueOnError,
+				Paused:          paused,
+			}
+			body, err := json.Marshal(schedule)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			request, err := client.NewRequest("POST", fmt.Sprintf("/deployments/%s/schedules", args[0]), bytes.NewBuffer(body))
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			request.Header.Add("Content-Type", "application/json")
+			response, err := client.Do(request)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			defer response.Body.Close()
+			httputil.HandleHTTPStatusCode(response, args[0], "deployment", http.StatusCreated)
+			fmt.Printf("Schedule created. Schedule Id: %s\n", path.Base(response.Header.Get("Location")))
+			return nil
+		},
+	}
+	createCmd.PersistentFlags().StringVarP(&workflowName, "workflow-name", "w", "", "The workflows name (mandatory)")
+	createCmd.PersistentFlags().StringVarP(&timezone, "timezone", "z", "", "Time zone used to evaluate the cron expression (for instance \"Europe/Paris\"). Defaults to UTC")
+	createCmd.PersistentFlags().StringVarP(&overlapPolicy, "overlap-policy", "o", scheduling.OverlapPolicySkip.String(), "Policy applied when the previous run is still in progress: skip, queue or cancel")
+	createCmd.PersistentFlags().BoolVarP(&continueOnError, "continue-on-error", "", false, "By default if an error occurs in a step of a workflow then other running steps are cancelled and the workflow is stopped. This flag allows to continue to the next steps even if an error occurs.")
+	createCmd.PersistentFlags().BoolVarP(&paused, "paused", "", false, "Create the schedule in paused state")
+	schedulesCmd.AddCommand(createCmd)
+}
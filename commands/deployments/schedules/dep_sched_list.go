@@ -0,0 +1,92 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/deployments"
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/helper/tabutil"
+	"github.com/ystia/yorc/rest"
+)
+
+func init() {
+	var listCmd = &cobra.Command{
+		Use:     "list <DeploymentId>",
+		Short:   "List workflows schedules of a given deployment",
+		Aliases: []string{"ls"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.Errorf("Expecting a deployment id (got %d parameters)", len(args))
+			}
+			client, err := httputil.GetClient(deployments.ClientConfig)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+
+			request, err := client.NewRequest("GET", fmt.Sprintf("/deployments/%s/schedules", args[0]), nil)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			request.Header.Add("Accept", "application/json")
+			response, err := client.Do(request)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			defer response.Body.Close()
+			httputil.HandleHTTPStatusCode(response, args[0], "deployment", http.StatusOK, http.StatusNoContent)
+			if response.StatusCode == http.StatusNoContent {
+				fmt.Println("No schedules defined for this deployment")
+				return nil
+			}
+
+			var collection rest.SchedulesCollection
+			body, err := ioutil.ReadAll(response.Body)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			err = json.Unmarshal(body, &collection)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+
+			schedTable := tabutil.NewTable()
+			schedTable.AddHeaders("Id", "Workflow", "Cron", "Timezone", "Overlap Policy", "Next Run")
+			for _, link := range collection.Schedules {
+				if link.Rel != rest.LinkRelSchedule {
+					continue
+				}
+				schedule := getSchedule(client, args[0], path.Base(link.Href))
+				timezone := schedule.Timezone
+				if timezone == "" {
+					timezone = "UTC"
+				}
+				schedTable.AddRow(schedule.ID, schedule.WorkflowName, schedule.Cron, timezone, schedule.OverlapPolicy.String(), formatNextRun(schedule))
+			}
+			fmt.Println("Schedules:")
+			fmt.Println(schedTable.Render())
+			return nil
+		},
+	}
+	schedulesCmd.AddCommand(listCmd)
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/deployments"
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/helper/tabutil"
+)
+
+func init() {
+	var showCmd = &cobra.Command{
+		Use:   "show <DeploymentId> <ScheduleId>",
+		Short: "Show a workflow schedule and its runs history",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.Errorf("Expecting a deployment id and a schedule id (got %d parameters)", len(args))
+			}
+			client, err := httputil.GetClient(deployments.ClientConfig)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			schedule := getSchedule(client, args[0], args[1])
+			timezone := schedule.Timezone
+			if timezone == "" {
+				timezone = "UTC"
+			}
+			fmt.Println("Schedule:", schedule.ID)
+			fmt.Println("Workflow:", schedule.WorkflowName)
+			fmt.Println("Cron:", schedule.Cron)
+			fmt.Println("Timezone:", timezone)
+			fmt.Println("Overlap Policy:", schedule.OverlapPolicy)
+			fmt.Println("Continue On Error:", schedule.ContinueOnError)
+			fmt.Println("Next Run:", formatNextRun(schedule))
+			if len(schedule.History) == 0 {
+				return nil
+			}
+			historyTable := tabutil.NewTable()
+			historyTable.AddHeaders("Date", "Status", "Task Id", "Message")
+			for _, run := range schedule.History {
+				historyTable.AddRow(run.Date.Local().Format("2006-01-02 15:04:05 MST"), run.Status, run.TaskID, run.Message)
+			}
+			fmt.Println("History:")
+			fmt.Println(historyTable.Render())
+			return nil
+		},
+	}
+	schedulesCmd.AddCommand(showCmd)
+}
@@ -0,0 +1,78 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/commands/deployments"
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/rest"
+)
+
+var schedulesCmd = &cobra.Command{
+	Use:     "schedules",
+	Short:   "Perform commands on workflows schedules",
+	Aliases: []string{"schedule", "sched"},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := cmd.Help()
+		if err != nil {
+			fmt.Print(err)
+		}
+	},
+}
+
+func init() {
+	deployments.DeploymentsCmd.AddCommand(schedulesCmd)
+}
+
+func getSchedule(client *httputil.YorcClient, deploymentID, scheduleID string) *rest.Schedule {
+	request, err := client.NewRequest("GET", fmt.Sprintf("/deployments/%s/schedules/%s", deploymentID, scheduleID), nil)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, scheduleID, "schedule", http.StatusOK)
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	schedule := new(rest.Schedule)
+	err = json.Unmarshal(body, schedule)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	return schedule
+}
+
+func formatNextRun(schedule *rest.Schedule) string {
+	if schedule.Paused {
+		return "paused"
+	}
+	if schedule.NextRun == nil {
+		return "never"
+	}
+	return schedule.NextRun.Local().Format("2006-01-02 15:04:05 MST")
+}
@@ -118,10 +118,10 @@ func displayStepTables(client *httputil.YorcClient, args []string) {
 	}
 	fmt.Println("Steps:")
 	tasksTable := tabutil.NewTable()
-	tasksTable.AddHeaders("Name", "Status")
+	tasksTable.AddHeaders("Name", "Status", "Reason")
 	errs := make([]error, 0)
 	for _, step := range steps {
-		tasksTable.AddRow(step.Name, getColoredTaskStepStatus(colorize, step.Status))
+		tasksTable.AddRow(step.Name, getColoredTaskStepStatus(colorize, step.Status), step.StatusReason)
 	}
 	fmt.Println(tasksTable.Render())
 	if len(errs) > 0 {
@@ -15,6 +15,7 @@
 package workflows
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"path"
@@ -24,12 +25,14 @@ import (
 
 	"github.com/ystia/yorc/commands/deployments"
 	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/tasks/workflow/builder"
 )
 
 func init() {
 	var shouldStreamLogs bool
 	var shouldStreamEvents bool
 	var continueOnError bool
+	var dryRun bool
 	var workflowName string
 	var wfExecCmd = &cobra.Command{
 		Use:     "execute <id>",
@@ -47,6 +50,10 @@ func init() {
 				return errors.New("Missing mandatory \"workflow-name\" parameter")
 			}
 			url := fmt.Sprintf("/deployments/%s/workflows/%s", args[0], workflowName)
+			if dryRun {
+				planWorkflow(client, url+"?dryRun=true", args[0]+"/"+workflowName)
+				return nil
+			}
 			if continueOnError {
 				url = url + "?continueOnError"
 			}
@@ -76,7 +83,32 @@ func init() {
 	}
 	wfExecCmd.PersistentFlags().StringVarP(&workflowName, "workflow-name", "w", "", "The workflows name")
 	wfExecCmd.PersistentFlags().BoolVarP(&continueOnError, "continue-on-error", "", false, "By default if an error occurs in a step of a workflow then other running steps are cancelled and the workflow is stopped. This flag allows to continue to the next steps even if an error occurs.")
+	wfExecCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "", false, "Do not execute the workflow but display its execution plan and the issues that would prevent it to succeed.")
 	wfExecCmd.PersistentFlags().BoolVarP(&shouldStreamLogs, "stream-logs", "l", false, "Stream logs after triggering a workflow. In this mode logs can't be filtered, to use this feature see the \"log\" command.")
 	wfExecCmd.PersistentFlags().BoolVarP(&shouldStreamEvents, "stream-events", "e", false, "Stream events after triggering a workflow.")
 	workflowsCmd.AddCommand(wfExecCmd)
 }
+
+func planWorkflow(client *httputil.YorcClient, url, ids string) {
+	request, err := client.NewRequest("POST", url, nil)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, ids, "deployment/workflow", http.StatusOK)
+	plan := new(builder.Plan)
+	err = json.NewDecoder(response.Body).Decode(plan)
+	if err != nil {
+		httputil.ErrExit(errors.Wrap(err, "failed to decode execution plan"))
+	}
+	deployments.PrintPlan(plan)
+	if plan.HasIssues() {
+		httputil.ErrExit("the workflow plan reports issues")
+	}
+}
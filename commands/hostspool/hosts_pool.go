@@ -42,6 +42,7 @@ const (
 func init() {
 	commands.RootCmd.AddCommand(hostsPoolCmd)
 	commands.ConfigureYorcClientCommand(hostsPoolCmd, hpViper, &cfgFile, &noColor)
+	hostsPoolCmd.PersistentFlags().StringVarP(&poolName, "pool", "", "default", "Name of the hosts pool")
 }
 
 var hpViper = viper.New()
@@ -49,6 +50,7 @@ var clientConfig config.Client
 
 var noColor bool
 var cfgFile string
+var poolName string
 
 var hostsPoolCmd = &cobra.Command{
 	Use:           "hostspool",
@@ -101,6 +103,8 @@ func getColoredHostStatus(colorize bool, status string) string {
 		return color.New(color.FgHiGreen, color.Bold).SprintFunc()(status)
 	case strings.ToLower(status) == "allocated":
 		return color.New(color.FgHiYellow, color.Bold).SprintFunc()(status)
+	case strings.ToLower(status) == "maintenance", strings.ToLower(status) == "draining":
+		return color.New(color.FgHiBlue, color.Bold).SprintFunc()(status)
 	default:
 		return color.New(color.FgHiRed, color.Bold).SprintFunc()(status)
 	}
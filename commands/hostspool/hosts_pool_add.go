@@ -78,7 +78,7 @@ func init() {
 				jsonParam = string(tmp)
 			}
 
-			request, err := client.NewRequest("PUT", "/hosts_pool/"+args[0], bytes.NewBuffer([]byte(jsonParam)))
+			request, err := client.NewRequest("PUT", "/hosts_pools/"+poolName+"/"+args[0], bytes.NewBuffer([]byte(jsonParam)))
 			if err != nil {
 				httputil.ErrExit(err)
 			}
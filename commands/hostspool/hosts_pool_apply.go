@@ -91,7 +91,7 @@ func init() {
 				httputil.ErrExit(err)
 			}
 
-			request, err := client.NewRequest("GET", "/hosts_pool", nil)
+			request, err := client.NewRequest("GET", "/hosts_pools/"+poolName, nil)
 			if err != nil {
 				httputil.ErrExit(err)
 			}
@@ -146,7 +146,7 @@ func init() {
 						// This is an update
 						//  Check if there is any change before registering the
 						// need to update
-						if host.Connection != newDef.Connection ||
+						if !reflect.DeepEqual(host.Connection, newDef.Connection) ||
 							!reflect.DeepEqual(host.Labels, newDef.Labels) {
 							update = true
 							hostsImpacted = append(hostsImpacted, host.Name)
@@ -226,7 +226,7 @@ func init() {
 			// Proceed to the change
 
 			bArray, err := json.Marshal(&hostsPoolRequest)
-			request, err = client.NewRequest("POST", "/hosts_pool",
+			request, err = client.NewRequest("POST", "/hosts_pools/"+poolName,
 				bytes.NewBuffer(bArray))
 			if err != nil {
 				httputil.ErrExit(err)
@@ -267,7 +267,7 @@ func init() {
 				"Name", "Connection", "Status", "Message")
 			for _, name := range hostsImpacted {
 
-				request, err := client.NewRequest("GET", "/hosts_pool/"+name, nil)
+				request, err := client.NewRequest("GET", "/hosts_pools/"+poolName+"/"+name, nil)
 				request.Header.Add("Accept", "application/json")
 				if err != nil {
 					httputil.ErrExit(err)
@@ -326,9 +326,22 @@ func toPrintableLabels(labels map[string]string) string {
 // Returns a printable value of a connection, including empty fields
 func toPrintableConnection(connection hostspool.Connection) string {
 
-	return "user: " + connection.User + ",password: " + connection.Password +
+	connStr := "user: " + connection.User + ",password: " + connection.Password +
 		",private key:" + connection.PrivateKey + ",host: " +
 		connection.Host + ",port: " + strconv.FormatUint(connection.Port, 10)
+	if connection.Bastion != nil {
+		connStr += ",bastion: " + toPrintableBastion(connection.Bastion)
+	}
+	return connStr
+}
+
+// Returns a printable value of a bastions chain as "user@host:port" elements separated by ">"
+func toPrintableBastion(bastion *hostspool.Connection) string {
+	b := bastion.User + "@" + bastion.Host + ":" + strconv.FormatUint(bastion.Port, 10)
+	if bastion.Bastion != nil {
+		b = toPrintableBastion(bastion.Bastion) + " > " + b
+	}
+	return b
 }
 
 // Add rows to a table, for both old and new values
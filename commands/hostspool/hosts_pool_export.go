@@ -39,7 +39,7 @@ func init() {
 			if err != nil {
 				httputil.ErrExit(err)
 			}
-			request, err := client.NewRequest("GET", "/hosts_pool", nil)
+			request, err := client.NewRequest("GET", "/hosts_pools/"+poolName, nil)
 			if err != nil {
 				httputil.ErrExit(err)
 			}
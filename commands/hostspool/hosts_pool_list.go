@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -39,7 +40,7 @@ func init() {
 			if err != nil {
 				httputil.ErrExit(err)
 			}
-			request, err := client.NewRequest("GET", "/hosts_pool", nil)
+			request, err := client.NewRequest("GET", "/hosts_pools/"+poolName, nil)
 			if err != nil {
 				httputil.ErrExit(err)
 			}
@@ -87,6 +88,16 @@ func init() {
 			}
 			fmt.Println("Host pools:")
 			fmt.Println(hostsTable.Render())
+			if len(hostsColl.PendingAllocations) > 0 {
+				pendingTable := tabutil.NewTable()
+				pendingTable.AddHeaders("Position", "Deployment", "Node", "Instance", "Priority", "Waiting Since", "Expiration")
+				for _, pa := range hostsColl.PendingAllocations {
+					pendingTable.AddRow(pa.Position, pa.DeploymentID, pa.NodeName, pa.Instance, pa.Priority,
+						pa.CreationDate.Local().Format(time.RFC3339), pa.ExpirationDate.Local().Format(time.RFC3339))
+				}
+				fmt.Println("Pending allocations:")
+				fmt.Println(pendingTable.Render())
+			}
 			return nil
 		},
 	}
@@ -0,0 +1,73 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+
+	"github.com/spf13/cobra"
+	"github.com/ystia/yorc/commands/httputil"
+	"github.com/ystia/yorc/rest"
+)
+
+func init() {
+	hpPoolsCmd := &cobra.Command{
+		Use:   "pools",
+		Short: "List hosts pools names",
+		Long:  `Lists the names of hosts pools managed by this Yorc cluster.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := httputil.GetClient(clientConfig)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			request, err := client.NewRequest("GET", "/hosts_pools", nil)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			request.Header.Add("Accept", "application/json")
+			response, err := client.Do(request)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			defer response.Body.Close()
+			httputil.HandleHTTPStatusCode(response, "", "hosts pools", http.StatusOK, http.StatusNoContent)
+			if response.StatusCode == http.StatusNoContent {
+				fmt.Println("No hosts pool defined")
+				return nil
+			}
+			var poolsColl rest.HostsPoolsCollection
+			body, err := ioutil.ReadAll(response.Body)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			err = json.Unmarshal(body, &poolsColl)
+			if err != nil {
+				httputil.ErrExit(err)
+			}
+			fmt.Println("Hosts pools:")
+			for _, poolLink := range poolsColl.Pools {
+				if poolLink.Rel == rest.LinkRelHostsPool {
+					fmt.Println(" - " + path.Base(poolLink.Href))
+				}
+			}
+			return nil
+		},
+	}
+	hostsPoolCmd.AddCommand(hpPoolsCmd)
+}
@@ -37,15 +37,36 @@ func init() {
 	var port uint64
 	var labelsAdd []string
 	var labelsRemove []string
+	var maintenance bool
+	var drain bool
+	var enable bool
+	var reason string
 
 	var updCmd = &cobra.Command{
 		Use:   "update <hostname>",
 		Short: "Update host pool",
-		Long:  `Update labels list or connection of a host of the hosts pool managed by this Yorc cluster.`,
+		Long: `Update labels list, connection or status of a host of the hosts pool managed by this Yorc cluster.
+A host could be put in maintenance, drained or re-enabled. A draining host keeps its allocations but refuses new ones,
+it is put in maintenance once its last allocation is released. A host in maintenance is not allocated until it is re-enabled.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				return errors.Errorf("Expecting a hostname (got %d parameters)", len(args))
 			}
+			var status *hostspool.HostStatus
+			nbStatusFlags := 0
+			for _, sf := range []struct {
+				set    bool
+				status hostspool.HostStatus
+			}{{maintenance, hostspool.HostStatusMaintenance}, {drain, hostspool.HostStatusDraining}, {enable, hostspool.HostStatusFree}} {
+				if sf.set {
+					nbStatusFlags++
+					s := sf.status
+					status = &s
+				}
+			}
+			if nbStatusFlags > 1 {
+				return errors.New("Only one of --maintenance, --drain or --enable flags could be specified")
+			}
 			client, err := httputil.GetClient(clientConfig)
 			if err != nil {
 				httputil.ErrExit(err)
@@ -70,6 +91,8 @@ func init() {
 				for _, l := range labelsRemove {
 					hostRequest.Labels = append(hostRequest.Labels, rest.MapEntry{Op: rest.MapEntryOperationRemove, Name: l})
 				}
+				hostRequest.Status = status
+				hostRequest.Reason = reason
 				tmp, err := json.Marshal(hostRequest)
 				if err != nil {
 					log.Panic(err)
@@ -78,7 +101,7 @@ func init() {
 				jsonParam = string(tmp)
 			}
 
-			request, err := client.NewRequest("PATCH", "/hosts_pool/"+args[0], bytes.NewBuffer([]byte(jsonParam)))
+			request, err := client.NewRequest("PATCH", "/hosts_pools/"+poolName+"/"+args[0], bytes.NewBuffer([]byte(jsonParam)))
 			if err != nil {
 				httputil.ErrExit(err)
 			}
@@ -102,6 +125,10 @@ func init() {
 	updCmd.Flags().StringVarP(&password, "password", "p", "", `At any time a host of the pool should have at least one of private key or password. To delete a registered private key use the "-" character.`)
 	updCmd.Flags().StringSliceVarP(&labelsAdd, "add-label", "", nil, "Add a label in form 'key=value' to the host. May be specified several time.")
 	updCmd.Flags().StringSliceVarP(&labelsRemove, "remove-label", "", nil, "Remove a label from the host. May be specified several time.")
+	updCmd.Flags().BoolVarP(&maintenance, "maintenance", "", false, "Put the host in maintenance. A host with allocations should be drained first.")
+	updCmd.Flags().BoolVarP(&drain, "drain", "", false, "Drain the host: existing allocations are kept but new ones are refused. The host is put in maintenance once its last allocation is released.")
+	updCmd.Flags().BoolVarP(&enable, "enable", "", false, "Re-enable a host in maintenance or draining.")
+	updCmd.Flags().StringVarP(&reason, "reason", "", "", "Reason of a status change, recorded on the host.")
 
 	hostsPoolCmd.AddCommand(updCmd)
 }
@@ -68,6 +68,35 @@ func (c *YorcClient) PostForm(path string, data url.Values) (*http.Response, err
 	return c.Client.PostForm(c.baseURL+path, data)
 }
 
+// tokenTransport adds an API token as bearer token to every requests
+type tokenTransport struct {
+	token string
+	next  http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	r.Header.Set("Authorization", "Bearer "+t.token)
+	return t.next.RoundTrip(r)
+}
+
+func withAPIToken(cc config.Client, client *http.Client) *http.Client {
+	if cc.APIToken == "" {
+		return client
+	}
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client.Transport = &tokenTransport{token: cc.APIToken, next: next}
+	return client
+}
+
 // GetClient returns a yorc HTTP Client
 func GetClient(cc config.Client) (*YorcClient, error) {
 	yorcAPI := cc.YorcAPI
@@ -113,13 +142,13 @@ func GetClient(cc config.Client) (*YorcClient, error) {
 		}
 		return &YorcClient{
 			baseURL: "https://" + yorcAPI,
-			Client:  &http.Client{Transport: tr},
+			Client:  withAPIToken(cc, &http.Client{Transport: tr}),
 		}, nil
 	}
 
 	return &YorcClient{
 		baseURL: "http://" + yorcAPI,
-		Client:  &http.Client{},
+		Client:  withAPIToken(cc, &http.Client{}),
 	}, nil
 
 }
@@ -0,0 +1,78 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputil
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// maxSSELineSize is the maximum size of a line in a text/event-stream, log entries may be large
+const maxSSELineSize = 10 * 1024 * 1024
+
+// SSEvent is an event received from a text/event-stream response (Server-Sent Events)
+type SSEvent struct {
+	ID   string
+	Type string
+	Data []byte
+}
+
+// ReadServerSentEvents reads a text/event-stream until the end of the stream and calls fn for each received event
+func ReadServerSentEvents(body io.Reader, fn func(SSEvent)) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELineSize)
+	var evt SSEvent
+	var data bytes.Buffer
+	hasData := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// Dispatch the event
+			if hasData {
+				evt.Data = append([]byte(nil), data.Bytes()...)
+				fn(evt)
+			}
+			evt = SSEvent{}
+			data.Reset()
+			hasData = false
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// Comment
+			continue
+		}
+		field := line
+		var value string
+		if i := strings.IndexRune(line, ':'); i >= 0 {
+			field = line[:i]
+			value = strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "id":
+			evt.ID = value
+		case "event":
+			evt.Type = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadServerSentEvents(t *testing.T) {
+	t.Parallel()
+	stream := "retry: 1000\n\n" +
+		": keep-alive\n\n" +
+		"event: log\ndata: {\"content\":\"first\"}\n\n" +
+		"id: 12\nevent: log\ndata: {\"content\":\"second\"}\n\n" +
+		"data: multi\ndata:line\n\n" +
+		"event: log\ndata: incomplete"
+
+	var evts []SSEvent
+	err := ReadServerSentEvents(strings.NewReader(stream), func(evt SSEvent) {
+		evts = append(evts, evt)
+	})
+	require.NoError(t, err)
+	require.Len(t, evts, 3)
+	assert.Equal(t, SSEvent{Type: "log", Data: []byte(`{"content":"first"}`)}, evts[0])
+	assert.Equal(t, SSEvent{ID: "12", Type: "log", Data: []byte(`{"content":"second"}`)}, evts[1])
+	assert.Equal(t, SSEvent{Data: []byte("multi\nline")}, evts[2])
+}
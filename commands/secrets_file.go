@@ -0,0 +1,98 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/vault/filestore"
+)
+
+func init() {
+	secretsFileCmd := &cobra.Command{
+		Use:   "secrets-file",
+		Short: "Manage encrypted secrets files",
+		Long:  "Manage encrypted secrets files used by the builtin \"file\" vault implementation",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	generateKeyCmd := &cobra.Command{
+		Use:   "generate-key",
+		Short: "Generate a key",
+		Long:  "Generate a random base64-encoded key suitable to encrypt a secrets file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := filestore.GenerateKey()
+			if err != nil {
+				return err
+			}
+			fmt.Println(base64.StdEncoding.EncodeToString(key))
+			return nil
+		},
+	}
+
+	var keyFile string
+	transform := func(fn func(key, data []byte) ([]byte, error)) func(cmd *cobra.Command, args []string) error {
+		return func(cmd *cobra.Command, args []string) error {
+			b, err := ioutil.ReadFile(keyFile)
+			if err != nil {
+				return errors.Wrap(err, "failed to read key file")
+			}
+			key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+			if err != nil {
+				return errors.Wrap(err, "key file does not contain a valid base64 encoded key")
+			}
+			data, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			result, err := fn(key, data)
+			if err != nil {
+				return err
+			}
+			return ioutil.WriteFile(args[1], result, 0600)
+		}
+	}
+
+	encryptCmd := &cobra.Command{
+		Use:   "encrypt <yaml secrets file> <encrypted file>",
+		Short: "Encrypt a secrets file",
+		Long:  "Encrypt a YAML secrets file using the given key",
+		Args:  cobra.ExactArgs(2),
+		RunE:  transform(filestore.Encrypt),
+	}
+	decryptCmd := &cobra.Command{
+		Use:   "decrypt <encrypted file> <yaml secrets file>",
+		Short: "Decrypt a secrets file",
+		Long:  "Decrypt an encrypted secrets file using the given key",
+		Args:  cobra.ExactArgs(2),
+		RunE:  transform(filestore.Decrypt),
+	}
+	for _, c := range []*cobra.Command{encryptCmd, decryptCmd} {
+		c.Flags().StringVarP(&keyFile, "key-file", "k", "", "Path to the file containing the base64-encoded key")
+		c.MarkFlagRequired("key-file")
+	}
+
+	secretsFileCmd.AddCommand(generateKeyCmd, encryptCmd, decryptCmd)
+	RootCmd.AddCommand(secretsFileCmd)
+}
@@ -263,6 +263,7 @@ func setConfig() {
 	serverCmd.PersistentFlags().Duration("wf_step_graceful_termination_timeout", config.DefaultWfStepGracefulTerminationTimeout, "Timeout to wait for a graceful termination of a workflow step during concurrent workflow step failure. After this delay the step is set on error.")
 	serverCmd.PersistentFlags().String("server_id", host, "The server ID used to identify the server node in a cluster.")
 	serverCmd.PersistentFlags().Bool("disable_ssh_agent", false, "Allow disabling ssh-agent use for SSH authentication on provisioned computes. Default is false. If true, compute credentials must provide a path to a private key file instead of key content.")
+	serverCmd.PersistentFlags().StringSlice("logs_secrets_patterns", nil, "Regular expressions of sensitive content to mask in deployments logs. If a regular expression has capturing groups only the matching groups are masked.")
 
 	// Flags definition for Yorc HTTP REST API
 	serverCmd.PersistentFlags().Int("http_port", config.DefaultHTTPPort, "Port number for the Yorc HTTP REST API. If omitted or set to '0' then the default port number is used, any positive integer will be used as it, and finally any negative value will let use a random port.")
@@ -320,6 +321,7 @@ func setConfig() {
 	viper.BindPFlag("wf_step_graceful_termination_timeout", serverCmd.PersistentFlags().Lookup("wf_step_graceful_termination_timeout"))
 	viper.BindPFlag("server_id", serverCmd.PersistentFlags().Lookup("server_id"))
 	viper.BindPFlag("disable_ssh_agent", serverCmd.PersistentFlags().Lookup("disable_ssh_agent"))
+	viper.BindPFlag("logs_secrets_patterns", serverCmd.PersistentFlags().Lookup("logs_secrets_patterns"))
 
 	//Bind Flags Yorc HTTP REST API
 	viper.BindPFlag("http_port", serverCmd.PersistentFlags().Lookup("http_port"))
@@ -357,6 +359,7 @@ func setConfig() {
 	viper.BindEnv("resources_prefix")
 	viper.BindEnv("server_id")
 	viper.BindEnv("disable_ssh_agent")
+	viper.BindEnv("logs_secrets_patterns")
 
 	//Bind Consul environment variables flags
 	for key := range consulConfiguration {
@@ -64,6 +64,36 @@ const DefaultWfStepGracefulTerminationTimeout = 2 * time.Minute
 // DefaultAnsibleJobMonInterval is the default monitoring interval for Jobs handled by Ansible
 const DefaultAnsibleJobMonInterval = 15 * time.Second
 
+// DefaultWebhooksMaxAttempts is the default maximum number of attempts to deliver an event to a webhook
+const DefaultWebhooksMaxAttempts = 5
+
+// DefaultWebhooksInitialBackoff is the default delay before retrying a failed webhook delivery, it is doubled on each attempt
+const DefaultWebhooksInitialBackoff = 1 * time.Second
+
+// DefaultWebhooksMaxBackoff is the default maximum delay between two attempts to deliver an event to a webhook
+const DefaultWebhooksMaxBackoff = 5 * time.Minute
+
+// DefaultWebhooksTimeout is the default timeout of an HTTP request delivering an event to a webhook
+const DefaultWebhooksTimeout = 10 * time.Second
+
+// DefaultWfStepRetryMaxAttempts is the default maximum number of attempts of a workflow step activity, 1 means no retry
+const DefaultWfStepRetryMaxAttempts = 1
+
+// DefaultWfStepRetryBackoff is the default backoff strategy between two attempts of a workflow step activity
+const DefaultWfStepRetryBackoff = "fixed"
+
+// DefaultWfStepRetryInitialDelay is the default delay before retrying a failed workflow step activity
+const DefaultWfStepRetryInitialDelay = 10 * time.Second
+
+// DefaultWfStepRetryMaxDelay is the default maximum delay between two attempts of a workflow step activity
+const DefaultWfStepRetryMaxDelay = 5 * time.Minute
+
+// DefaultMonitoringHealMinInterval is the default minimum delay between two automatic heals of a same node instance
+const DefaultMonitoringHealMinInterval = 10 * time.Minute
+
+// DefaultMonitoringHealMaxConcurrency is the default maximum number of concurrent automatic heals per deployment
+const DefaultMonitoringHealMaxConcurrency = 1
+
 // Configuration holds config information filled by Cobra and Viper (see commands package for more information)
 type Configuration struct {
 	Ansible                          Ansible               `yaml:"ansible,omitempty" mapstructure:"ansible"`
@@ -87,6 +117,13 @@ type Configuration struct {
 	ServerID                         string                `yaml:"server_id,omitempty" mapstructure:"server_id"`
 	Terraform                        Terraform             `yaml:"terraform,omitempty" mapstructure:"terraform"`
 	DisableSSHAgent                  bool                  `yaml:"disable_ssh_agent,omitempty" mapstructure:"disable_ssh_agent"`
+	HTTPAuth                         HTTPAuth              `yaml:"http_auth,omitempty" mapstructure:"http_auth"`
+	Webhooks                         Webhooks              `yaml:"webhooks,omitempty" mapstructure:"webhooks"`
+	WfStepRetry                      RetryPolicy           `yaml:"wf_step_retry,omitempty" mapstructure:"wf_step_retry"`
+	WfStepTimeouts                   WfStepTimeouts        `yaml:"wf_step_timeouts,omitempty" mapstructure:"wf_step_timeouts"`
+	LogsSecretsPatterns              []string              `yaml:"logs_secrets_patterns,omitempty" mapstructure:"logs_secrets_patterns"`
+	Monitoring                       Monitoring            `yaml:"monitoring,omitempty" mapstructure:"monitoring"`
+	TasksDispatcher                  TasksDispatcher       `yaml:"tasks_dispatcher,omitempty" mapstructure:"tasks_dispatcher"`
 }
 
 // DockerSandbox holds the configuration for a docker sandbox
@@ -150,6 +187,73 @@ type Telemetry struct {
 	DisableGoRuntimeMetrics bool   `yaml:"disable_go_runtime_metrics,omitempty" mapstructure:"disable_go_runtime_metrics"`
 }
 
+// HTTPAuth holds the configuration of the authentication and authorization of the HTTP REST API
+type HTTPAuth struct {
+	Enabled           bool              `yaml:"enabled,omitempty" mapstructure:"enabled"`
+	Tokens            []HTTPAuthToken   `yaml:"tokens,omitempty" mapstructure:"tokens"`
+	JWT               HTTPAuthJWT       `yaml:"jwt,omitempty" mapstructure:"jwt"`
+	CertificatesRoles map[string]string `yaml:"certificates_roles,omitempty" mapstructure:"certificates_roles"`
+}
+
+// HTTPAuthToken is a static API token granting a role to its bearer
+type HTTPAuthToken struct {
+	Name  string `yaml:"name,omitempty" mapstructure:"name"`
+	Token string `yaml:"token,omitempty" mapstructure:"token"`
+	Role  string `yaml:"role,omitempty" mapstructure:"role"`
+}
+
+// HTTPAuthJWT holds the configuration for JWT bearer tokens validation
+type HTTPAuthJWT struct {
+	KeySetFile string        `yaml:"key_set_file,omitempty" mapstructure:"key_set_file"`
+	Issuer     string        `yaml:"issuer,omitempty" mapstructure:"issuer"`
+	Audience   string        `yaml:"audience,omitempty" mapstructure:"audience"`
+	RoleClaim  string        `yaml:"role_claim,omitempty" mapstructure:"role_claim"`
+	Leeway     time.Duration `yaml:"leeway,omitempty" mapstructure:"leeway"`
+}
+
+// Webhooks holds the configuration of events deliveries to webhooks
+type Webhooks struct {
+	MaxAttempts    int           `yaml:"max_attempts,omitempty" mapstructure:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff,omitempty" mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff,omitempty" mapstructure:"max_backoff"`
+	Timeout        time.Duration `yaml:"timeout,omitempty" mapstructure:"timeout"`
+}
+
+// Monitoring holds the configuration of the reactions to monitoring checks failures
+type Monitoring struct {
+	HealMinInterval    time.Duration `yaml:"heal_min_interval,omitempty" mapstructure:"heal_min_interval"`
+	HealMaxConcurrency int           `yaml:"heal_max_concurrency,omitempty" mapstructure:"heal_max_concurrency"`
+}
+
+// TasksDispatcher holds the configuration of the dispatching of tasks executions to workers
+//
+// Concurrency limits are enforced across all Yorc servers and should be identical on each of them, a zero or negative limit means no limit.
+// Priorities are indexed by task type name (case insensitive), executions of tasks with the highest priority are dispatched first.
+type TasksDispatcher struct {
+	MaxConcurrentExecutionsPerDeployment     int            `yaml:"max_concurrent_executions_per_deployment,omitempty" mapstructure:"max_concurrent_executions_per_deployment"`
+	MaxConcurrentExecutionsPerInfrastructure map[string]int `yaml:"max_concurrent_executions_per_infrastructure,omitempty" mapstructure:"max_concurrent_executions_per_infrastructure"`
+	TaskTypesPriorities                      map[string]int `yaml:"task_types_priorities,omitempty" mapstructure:"task_types_priorities"`
+}
+
+// RetryPolicy holds the default retry policy applied to failed workflow steps activities
+//
+// It may be overridden per node template or type using metadata and per workflow step.
+type RetryPolicy struct {
+	MaxAttempts  int           `yaml:"max_attempts,omitempty" mapstructure:"max_attempts"`
+	Backoff      string        `yaml:"backoff,omitempty" mapstructure:"backoff"`
+	InitialDelay time.Duration `yaml:"initial_delay,omitempty" mapstructure:"initial_delay"`
+	MaxDelay     time.Duration `yaml:"max_delay,omitempty" mapstructure:"max_delay"`
+	RetryOn      []string      `yaml:"retry_on,omitempty" mapstructure:"retry_on"`
+}
+
+// WfStepTimeouts holds the default timeouts of workflow steps activities per activity type
+//
+// A zero value means no timeout. The timeout of a call operation activity may be overridden by the operation implementation.
+type WfStepTimeouts struct {
+	Delegate      time.Duration `yaml:"delegate,omitempty" mapstructure:"delegate"`
+	CallOperation time.Duration `yaml:"call_operation,omitempty" mapstructure:"call_operation"`
+}
+
 // Terraform configuration
 type Terraform struct {
 	PluginsDir                       string `yaml:"plugins_dir,omitempty" mapstructure:"plugins_dir"`
@@ -296,4 +400,5 @@ type Client struct {
 	CertFile      string `mapstructure:"cert_file"`
 	CAFile        string `mapstructure:"ca_file"`
 	CAPath        string `mapstructure:"ca_path"`
+	APIToken      string `mapstructure:"api_token"`
 }
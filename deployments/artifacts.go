@@ -24,6 +24,27 @@ import (
 	"github.com/ystia/yorc/helper/consulutil"
 )
 
+// artifactLocationLocalFile is the get_artifact function keyword letting the orchestrator choose the artifact location
+const artifactLocationLocalFile = "LOCAL_FILE"
+
+// An ArtifactReference describes an artifact referenced by a get_artifact function
+type ArtifactReference struct {
+	// Path of the artifact relative to the root of the deployment archive
+	Path string
+	// Location where the artifact should be made available on the operation host, empty if left to the executor
+	Location string
+	// Remove indicates that the artifact should be removed from Location once the operation is done
+	Remove bool
+}
+
+// resolvedPath returns the location of the artifact if any or its path in the deployment archive otherwise
+func (ar *ArtifactReference) resolvedPath() string {
+	if ar.Location != "" {
+		return ar.Location
+	}
+	return ar.Path
+}
+
 // GetArtifactsForType returns a map of artifact name / artifact file for the given type.
 //
 // The returned artifacts paths are relative to root of the deployment archive.
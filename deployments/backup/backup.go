@@ -0,0 +1,247 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backup allows to export a deployment into a self-contained archive and to import it back
+// into a Yorc cluster.
+//
+// An archive is a gzip-compressed tar file containing:
+//   - a manifest.json file describing the archive (it should be the first entry of the archive)
+//   - a kv.json file containing the deployment Consul KV pairs (definitions, instances, attributes, tasks history, events and logs)
+//   - the files/ directory containing the original CSAR (deployment.zip) and its extracted content (overlay)
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	manifestFileName = "manifest.json"
+	kvFileName       = "kv.json"
+	filesDirName     = "files"
+)
+
+// Manifest describes an exported deployment archive
+type Manifest struct {
+	DeploymentID  string    `json:"deployment_id"`
+	SchemaVersion string    `json:"schema_version"`
+	Date          time.Time `json:"date"`
+}
+
+type deploymentAlreadyExists struct {
+	deploymentID string
+}
+
+func (e deploymentAlreadyExists) Error() string {
+	return fmt.Sprintf("Deployment with id %q already exists", e.deploymentID)
+}
+
+// IsDeploymentAlreadyExistsError checks if an error is due to the fact that the imported deployment already exists
+func IsDeploymentAlreadyExistsError(err error) bool {
+	_, ok := errors.Cause(err).(deploymentAlreadyExists)
+	return ok
+}
+
+type invalidArchive struct {
+	msg string
+}
+
+func (e invalidArchive) Error() string {
+	return fmt.Sprintf("invalid deployment archive: %s", e.msg)
+}
+
+// IsInvalidArchiveError checks if an error is due to a malformed or incompatible deployment archive
+func IsInvalidArchiveError(err error) bool {
+	_, ok := errors.Cause(err).(invalidArchive)
+	return ok
+}
+
+// writeArchive writes a deployment archive containing the given manifest, KV pairs and the
+// content of the filesDir directory if it exists
+func writeArchive(w io.Writer, manifest Manifest, kvps api.KVPairs, filesDir string) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal archive manifest")
+	}
+	if err = writeTarEntry(tw, manifestFileName, b, manifest.Date); err != nil {
+		return err
+	}
+	if kvps == nil {
+		kvps = api.KVPairs{}
+	}
+	b, err = json.Marshal(kvps)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal deployment KV pairs")
+	}
+	if err = writeTarEntry(tw, kvFileName, b, manifest.Date); err != nil {
+		return err
+	}
+
+	if _, err = os.Stat(filesDir); err == nil {
+		err = filepath.Walk(filesDir, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(filesDir, p)
+			if err != nil || rel == "." {
+				return err
+			}
+			if !info.IsDir() && !info.Mode().IsRegular() {
+				// Skip symlinks and special files
+				return nil
+			}
+			hdr, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			hdr.Name = path.Join(filesDirName, filepath.ToSlash(rel))
+			if info.IsDir() {
+				hdr.Name += "/"
+			}
+			if err = tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(tw, f)
+			return err
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to archive deployment files from %q", filesDir)
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to archive deployment files from %q", filesDir)
+	}
+
+	if err = tw.Close(); err != nil {
+		return errors.Wrap(err, "failed to write deployment archive")
+	}
+	return errors.Wrap(gw.Close(), "failed to write deployment archive")
+}
+
+func writeTarEntry(tw *tar.Writer, name string, content []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %q archive entry", name)
+	}
+	_, err = tw.Write(content)
+	return errors.Wrapf(err, "failed to write %q archive entry", name)
+}
+
+// readArchive reads a deployment archive. The manifest is given to the checkManifest function
+// as soon as it is read in order to allow to stop processing an unexpected archive early.
+// Files are extracted into the filesDir directory.
+func readArchive(r io.Reader, filesDir string, checkManifest func(Manifest) error) (Manifest, api.KVPairs, error) {
+	var manifest Manifest
+	var kvps api.KVPairs
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return manifest, nil, errors.WithStack(invalidArchive{err.Error()})
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	var manifestRead, kvRead bool
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return manifest, nil, errors.WithStack(invalidArchive{err.Error()})
+		}
+		name := path.Clean(hdr.Name)
+		if !manifestRead && name != manifestFileName {
+			return manifest, nil, errors.WithStack(invalidArchive{"manifest should be the first archive entry"})
+		}
+		switch {
+		case name == manifestFileName:
+			if manifestRead {
+				return manifest, nil, errors.WithStack(invalidArchive{"duplicated manifest"})
+			}
+			if err = json.NewDecoder(tr).Decode(&manifest); err != nil {
+				return manifest, nil, errors.WithStack(invalidArchive{"failed to read manifest: " + err.Error()})
+			}
+			if manifest.DeploymentID == "" || manifest.SchemaVersion == "" {
+				return manifest, nil, errors.WithStack(invalidArchive{"manifest should contain the deployment id and the schema version"})
+			}
+			manifestRead = true
+			if err = checkManifest(manifest); err != nil {
+				return manifest, nil, err
+			}
+		case name == kvFileName:
+			if err = json.NewDecoder(tr).Decode(&kvps); err != nil {
+				return manifest, nil, errors.WithStack(invalidArchive{"failed to read KV pairs: " + err.Error()})
+			}
+			kvRead = true
+		case strings.HasPrefix(name, filesDirName+"/"):
+			rel := strings.TrimPrefix(name, filesDirName+"/")
+			if strings.HasPrefix(rel, "../") || rel == ".." || path.IsAbs(rel) {
+				return manifest, nil, errors.WithStack(invalidArchive{fmt.Sprintf("illegal file path %q", hdr.Name)})
+			}
+			if err = extractTarEntry(tr, hdr, filepath.Join(filesDir, filepath.FromSlash(rel))); err != nil {
+				return manifest, nil, err
+			}
+		default:
+			return manifest, nil, errors.WithStack(invalidArchive{fmt.Sprintf("unexpected archive entry %q", hdr.Name)})
+		}
+	}
+	if !manifestRead || !kvRead {
+		return manifest, nil, errors.WithStack(invalidArchive{"missing manifest or KV pairs"})
+	}
+	return manifest, kvps, nil
+}
+
+func extractTarEntry(tr *tar.Reader, hdr *tar.Header, dest string) error {
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return errors.Wrapf(os.MkdirAll(dest, 0775), "failed to extract %q", hdr.Name)
+	case tar.TypeReg, tar.TypeRegA:
+		if err := os.MkdirAll(filepath.Dir(dest), 0775); err != nil {
+			return errors.Wrapf(err, "failed to extract %q", hdr.Name)
+		}
+		f, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(hdr.Mode)&os.ModePerm)
+		if err != nil {
+			return errors.Wrapf(err, "failed to extract %q", hdr.Name)
+		}
+		defer f.Close()
+		_, err = io.Copy(f, tr)
+		return errors.Wrapf(err, "failed to extract %q", hdr.Name)
+	}
+	return errors.WithStack(invalidArchive{fmt.Sprintf("unsupported type for archive entry %q", hdr.Name)})
+}
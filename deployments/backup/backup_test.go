@@ -0,0 +1,235 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/tasks"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	srcDir, err := ioutil.TempDir("", "yorc-backup-src")
+	require.NoError(t, err)
+	defer os.RemoveAll(srcDir)
+	destDir, err := ioutil.TempDir("", "yorc-backup-dest")
+	require.NoError(t, err)
+	defer os.RemoveAll(destDir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(srcDir, "deployment.zip"), []byte("zip content"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "overlay", "scripts"), 0775))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(srcDir, "overlay", "scripts", "create.sh"), []byte("#!/bin/bash"), 0755))
+
+	manifest := Manifest{DeploymentID: "dep", SchemaVersion: consulutil.YorcSchemaVersion, Date: time.Now().UTC().Truncate(time.Second)}
+	kvps := api.KVPairs{
+		{Key: path.Join(consulutil.DeploymentKVPrefix, "dep", "status"), Value: []byte("DEPLOYED")},
+		{Key: path.Join(consulutil.EventsPrefix, "dep", "1"), Value: []byte("{}"), Flags: 2},
+	}
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeArchive(buf, manifest, kvps, srcDir))
+
+	var checked bool
+	readManifest, readKVPs, err := readArchive(buf, destDir, func(m Manifest) error {
+		checked = true
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, checked)
+	assert.Equal(t, manifest.DeploymentID, readManifest.DeploymentID)
+	assert.Equal(t, manifest.SchemaVersion, readManifest.SchemaVersion)
+	assert.True(t, manifest.Date.Equal(readManifest.Date))
+	assert.Equal(t, kvps, readKVPs)
+
+	b, err := ioutil.ReadFile(filepath.Join(destDir, "deployment.zip"))
+	require.NoError(t, err)
+	assert.Equal(t, "zip content", string(b))
+	b, err = ioutil.ReadFile(filepath.Join(destDir, "overlay", "scripts", "create.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/bash", string(b))
+}
+
+func TestReadArchiveErrors(t *testing.T) {
+	manifest := []byte(`{"deployment_id":"dep","schema_version":"1.0.0"}`)
+	type entry struct {
+		name    string
+		content []byte
+	}
+	tests := []struct {
+		name    string
+		entries []entry
+	}{
+		{"ManifestNotFirst", []entry{{kvFileName, []byte("[]")}, {manifestFileName, manifest}}},
+		{"MissingKV", []entry{{manifestFileName, manifest}}},
+		{"IncompleteManifest", []entry{{manifestFileName, []byte(`{"deployment_id":"dep"}`)}, {kvFileName, []byte("[]")}}},
+		{"UnexpectedEntry", []entry{{manifestFileName, manifest}, {kvFileName, []byte("[]")}, {"other.txt", []byte("")}}},
+		{"PathTraversal", []entry{{manifestFileName, manifest}, {kvFileName, []byte("[]")}, {"files/../../evil", []byte("")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destDir, err := ioutil.TempDir("", "yorc-backup-dest")
+			require.NoError(t, err)
+			defer os.RemoveAll(destDir)
+
+			buf := new(bytes.Buffer)
+			gw := gzip.NewWriter(buf)
+			tw := tar.NewWriter(gw)
+			for _, e := range tt.entries {
+				require.NoError(t, writeTarEntry(tw, e.name, e.content, time.Now()))
+			}
+			require.NoError(t, tw.Close())
+			require.NoError(t, gw.Close())
+
+			_, _, err = readArchive(buf, destDir, func(m Manifest) error { return nil })
+			assert.Error(t, err)
+			assert.True(t, IsInvalidArchiveError(err), "unexpected error type: %v", err)
+		})
+	}
+}
+
+func TestImportInvalidDeploymentID(t *testing.T) {
+	workingDir, err := ioutil.TempDir("", "yorc-backup-wd")
+	require.NoError(t, err)
+	defer os.RemoveAll(workingDir)
+	for _, id := range []string{"..", "a/b", "dep.dry-run", "dep.update", "dep id"} {
+		t.Run(id, func(t *testing.T) {
+			manifest := Manifest{DeploymentID: id, SchemaVersion: consulutil.YorcSchemaVersion, Date: time.Now()}
+			buf := new(bytes.Buffer)
+			require.NoError(t, writeArchive(buf, manifest, api.KVPairs{}, workingDir))
+			// The deployment id is checked before accessing Consul
+			_, err := Import(context.Background(), nil, workingDir, buf)
+			assert.True(t, IsInvalidArchiveError(err), "unexpected error type: %v", err)
+		})
+	}
+}
+
+func TestPrepareKVPairs(t *testing.T) {
+	running := strconv.Itoa(int(tasks.TaskStatusRUNNING))
+	done := strconv.Itoa(int(tasks.TaskStatusDONE))
+	canceled := strconv.Itoa(int(tasks.TaskStatusCANCELED))
+	kvps := api.KVPairs{
+		{Key: path.Join(consulutil.DeploymentKVPrefix, "dep", "status"), Value: []byte("DEPLOYMENT_IN_PROGRESS")},
+		{Key: path.Join(consulutil.DeploymentKVPrefix, "dep", "topology", "name"), Value: []byte("topo")},
+		{Key: path.Join(consulutil.TasksPrefix, "t1", "targetId"), Value: []byte("dep")},
+		{Key: path.Join(consulutil.TasksPrefix, "t1", "status"), Value: []byte(done)},
+		{Key: path.Join(consulutil.TasksPrefix, "t2", "targetId"), Value: []byte("dep")},
+		{Key: path.Join(consulutil.TasksPrefix, "t2", "status"), Value: []byte(running)},
+		{Key: path.Join(consulutil.WorkflowsPrefix, "t2", "step"), Value: []byte("initial")},
+	}
+	statusKVP, taskIDs, err := prepareKVPairs("dep", kvps)
+	require.NoError(t, err)
+	assert.Equal(t, kvps[0], statusKVP)
+	assert.Equal(t, []string{"t1", "t2"}, taskIDs)
+	assert.Equal(t, done, string(kvps[3].Value))
+	assert.Equal(t, canceled, string(kvps[5].Value))
+
+	_, _, err = prepareKVPairs("dep", append(kvps, &api.KVPair{Key: path.Join(consulutil.DeploymentKVPrefix, "other", "status")}))
+	assert.True(t, IsInvalidArchiveError(err), "unexpected error type: %v", err)
+	_, _, err = prepareKVPairs("dep", append(kvps, &api.KVPair{Key: path.Join(consulutil.TasksPrefix, "t3", "status")}))
+	assert.True(t, IsInvalidArchiveError(err), "unexpected error type: %v", err)
+	_, _, err = prepareKVPairs("dep", kvps[1:])
+	assert.True(t, IsInvalidArchiveError(err), "unexpected error type: %v", err)
+}
+
+func testExportImport(t *testing.T, srv *testutil.TestServer, kv *api.KV) {
+	deploymentID := "testExportImport"
+	otherDeploymentID := "testExportImportOther"
+	running := strconv.Itoa(int(tasks.TaskStatusRUNNING))
+	srv.PopulateKV(t, map[string][]byte{
+		path.Join(consulutil.DeploymentKVPrefix, deploymentID, "status"):                                         []byte("DEPLOYED"),
+		path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances", "Compute", "0", "state"): []byte("started"),
+		path.Join(consulutil.EventsPrefix, deploymentID, "1"):                                                    []byte(`{"type":"anEvent"}`),
+		path.Join(consulutil.LogsPrefix, deploymentID, "1"):                                                      []byte(`{"content":"a log"}`),
+		path.Join(consulutil.TasksPrefix, "tExport1", "targetId"):                                                []byte(deploymentID),
+		path.Join(consulutil.TasksPrefix, "tExport1", "status"):                                                  []byte(running),
+		path.Join(consulutil.WorkflowsPrefix, "tExport1", "Compute_install"):                                     []byte("initial"),
+		path.Join(consulutil.TasksPrefix, "tExport2", "targetId"):                                                []byte(otherDeploymentID),
+		path.Join(consulutil.TasksPrefix, "tExport2", "status"):                                                  []byte(running),
+	})
+
+	workingDir, err := ioutil.TempDir("", "yorc-backup-wd")
+	require.NoError(t, err)
+	defer os.RemoveAll(workingDir)
+	depDir := filepath.Join(workingDir, "deployments", deploymentID)
+	require.NoError(t, os.MkdirAll(filepath.Join(depDir, "overlay"), 0775))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(depDir, "deployment.zip"), []byte("zip content"), 0644))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, Export(context.Background(), kv, workingDir, deploymentID, buf))
+	archive := buf.Bytes()
+
+	_, err = Import(context.Background(), kv, workingDir, bytes.NewReader(archive))
+	assert.True(t, IsDeploymentAlreadyExistsError(err), "unexpected error type: %v", err)
+
+	for _, prefix := range append(deploymentPrefixes(deploymentID), taskPrefixes("tExport1")...) {
+		_, err = kv.DeleteTree(prefix, nil)
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.RemoveAll(depDir))
+
+	importedID, err := Import(context.Background(), kv, workingDir, bytes.NewReader(archive))
+	require.NoError(t, err)
+	assert.Equal(t, deploymentID, importedID)
+
+	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "status"), nil)
+	require.NoError(t, err)
+	require.NotNil(t, kvp)
+	assert.Equal(t, "DEPLOYED", string(kvp.Value))
+	kvp, _, err = kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology", "instances", "Compute", "0", "state"), nil)
+	require.NoError(t, err)
+	require.NotNil(t, kvp)
+	assert.Equal(t, "started", string(kvp.Value))
+	kvp, _, err = kv.Get(path.Join(consulutil.EventsPrefix, deploymentID, "1"), nil)
+	require.NoError(t, err)
+	require.NotNil(t, kvp)
+	kvp, _, err = kv.Get(path.Join(consulutil.WorkflowsPrefix, "tExport1", "Compute_install"), nil)
+	require.NoError(t, err)
+	require.NotNil(t, kvp)
+
+	status, err := tasks.GetTaskStatus(kv, "tExport1")
+	require.NoError(t, err)
+	assert.Equal(t, tasks.TaskStatusCANCELED, status)
+	status, err = tasks.GetTaskStatus(kv, "tExport2")
+	require.NoError(t, err)
+	assert.Equal(t, tasks.TaskStatusRUNNING, status)
+
+	b, err := ioutil.ReadFile(filepath.Join(depDir, "deployment.zip"))
+	require.NoError(t, err)
+	assert.Equal(t, "zip content", string(b))
+
+	// Existing tasks are never overwritten
+	for _, prefix := range deploymentPrefixes(deploymentID) {
+		_, err = kv.DeleteTree(prefix, nil)
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.RemoveAll(depDir))
+	_, err = Import(context.Background(), kv, workingDir, bytes.NewReader(archive))
+	assert.True(t, IsInvalidArchiveError(err), "unexpected error type: %v", err)
+}
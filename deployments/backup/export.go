@@ -0,0 +1,93 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"io"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/tasks"
+)
+
+// Export writes into w an archive of the given deployment.
+//
+// The archive contains the deployment original CSAR, its stored definitions, instances and attributes,
+// its tasks history as well as its events and logs.
+func Export(ctx context.Context, kv *api.KV, workingDir, deploymentID string, w io.Writer) error {
+	exists, err := deployments.DoesDeploymentExists(kv, deploymentID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.Errorf("deployment %q not found", deploymentID)
+	}
+
+	prefixes := deploymentPrefixes(deploymentID)
+	taskIDs, err := tasks.GetTasksIdsForTarget(kv, deploymentID)
+	if err != nil {
+		return err
+	}
+	for _, taskID := range taskIDs {
+		prefixes = append(prefixes, taskPrefixes(taskID)...)
+	}
+
+	kvps := make(api.KVPairs, 0)
+	for _, prefix := range prefixes {
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "failed to export deployment %q", deploymentID)
+		default:
+		}
+		list, _, err := kv.List(prefix, nil)
+		if err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+		for _, kvp := range list {
+			kvps = append(kvps, &api.KVPair{Key: kvp.Key, Flags: kvp.Flags, Value: kvp.Value})
+		}
+	}
+
+	manifest := Manifest{
+		DeploymentID:  deploymentID,
+		SchemaVersion: consulutil.YorcSchemaVersion,
+		Date:          time.Now(),
+	}
+	return writeArchive(w, manifest, kvps, filepath.Join(workingDir, "deployments", deploymentID))
+}
+
+// deploymentPrefixes returns the Consul KV prefixes of data related to a deployment
+func deploymentPrefixes(deploymentID string) []string {
+	return []string{
+		path.Join(consulutil.DeploymentKVPrefix, deploymentID) + "/",
+		path.Join(consulutil.EventsPrefix, deploymentID) + "/",
+		path.Join(consulutil.LogsPrefix, deploymentID) + "/",
+	}
+}
+
+// taskPrefixes returns the Consul KV prefixes of data related to a task
+func taskPrefixes(taskID string) []string {
+	return []string{
+		path.Join(consulutil.TasksPrefix, taskID) + "/",
+		path.Join(consulutil.WorkflowsPrefix, taskID) + "/",
+	}
+}
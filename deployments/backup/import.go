@@ -0,0 +1,194 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/server/upgradeschema"
+	"github.com/ystia/yorc/tasks"
+)
+
+var deploymentIDRegexp = regexp.MustCompile(deployments.IDPattern)
+
+// Import reads a deployment archive generated by Export and restores the deployment it contains.
+//
+// It returns the identifier of the imported deployment. If a deployment with the same identifier
+// already exists an error is returned, this error could be checked using IsDeploymentAlreadyExistsError.
+// Archives generated using a previous Consul schema version are upgraded to the current schema version.
+// Tasks that were not completed at export time are marked as canceled.
+func Import(ctx context.Context, kv *api.KV, workingDir string, r io.Reader) (string, error) {
+	currentVersion, err := semver.Make(consulutil.YorcSchemaVersion)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse current version of consul db schema")
+	}
+
+	deploymentsDir := filepath.Join(workingDir, "deployments")
+	if err = os.MkdirAll(deploymentsDir, 0775); err != nil {
+		return "", errors.Wrapf(err, "failed to create directory %q", deploymentsDir)
+	}
+	tmpDir, err := ioutil.TempDir(deploymentsDir, ".import-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temporary import directory")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest, kvps, err := readArchive(r, tmpDir, func(m Manifest) error {
+		v, err := semver.Make(m.SchemaVersion)
+		if err != nil {
+			return errors.WithStack(invalidArchive{fmt.Sprintf("invalid schema version %q", m.SchemaVersion)})
+		}
+		if v.GT(currentVersion) {
+			return errors.WithStack(invalidArchive{fmt.Sprintf("archive schema version %q is newer than the one supported by this version of Yorc (%s)", m.SchemaVersion, currentVersion)})
+		}
+		if !deploymentIDRegexp.MatchString(m.DeploymentID) || deployments.IsDryRunID(m.DeploymentID) || deployments.IsUpdateStagingID(m.DeploymentID) {
+			return errors.WithStack(invalidArchive{fmt.Sprintf("invalid deployment id %q, it should match %q", m.DeploymentID, deployments.IDPattern)})
+		}
+		exists, err := deployments.DoesDeploymentExists(kv, m.DeploymentID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errors.WithStack(deploymentAlreadyExists{m.DeploymentID})
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	deploymentID := manifest.DeploymentID
+
+	if err = upgradeschema.UpgradeKVPairs(manifest.SchemaVersion, kvps); err != nil {
+		return deploymentID, err
+	}
+	statusKVP, taskIDs, err := prepareKVPairs(deploymentID, kvps)
+	if err != nil {
+		return deploymentID, err
+	}
+	if err = checkTasksDoNotExist(kv, taskIDs); err != nil {
+		return deploymentID, err
+	}
+
+	depDir := filepath.Join(deploymentsDir, deploymentID)
+	// The deployment does not exist so any remaining directory is a leftover
+	if err = os.RemoveAll(depDir); err != nil {
+		return deploymentID, errors.Wrapf(err, "failed to remove directory %q", depDir)
+	}
+	if err = os.Rename(tmpDir, depDir); err != nil {
+		return deploymentID, errors.Wrapf(err, "failed to move imported files into %q", depDir)
+	}
+
+	_, errGrp, store := consulutil.WithContext(ctx)
+	for _, kvp := range kvps {
+		if kvp != statusKVP {
+			store.StoreConsulKeyWithFlags(kvp.Key, kvp.Value, kvp.Flags)
+		}
+	}
+	if err = errGrp.Wait(); err == nil {
+		// The deployment status is stored last as it marks the deployment as existing
+		if err = ctx.Err(); err == nil {
+			err = consulutil.StoreConsulKeyWithFlags(statusKVP.Key, statusKVP.Value, statusKVP.Flags)
+		}
+	}
+	if err != nil {
+		os.RemoveAll(depDir)
+		return deploymentID, errors.Wrapf(err, "failed to import deployment %q", deploymentID)
+	}
+	log.Printf("Deployment %q imported from an archive generated on %s", deploymentID, manifest.Date)
+	return deploymentID, nil
+}
+
+// prepareKVPairs checks that the given KV pairs only concern the given deployment and its tasks,
+// marks tasks that are not completed as canceled, and returns the deployment status KV pair and
+// the IDs of the deployment tasks.
+func prepareKVPairs(deploymentID string, kvps api.KVPairs) (*api.KVPair, []string, error) {
+	statusKey := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "status")
+	var statusKVP *api.KVPair
+	prefixes := deploymentPrefixes(deploymentID)
+	taskIDs := make([]string, 0)
+	for _, kvp := range kvps {
+		if kvp == nil {
+			return nil, nil, errors.WithStack(invalidArchive{"empty KV pair"})
+		}
+		if kvp.Key == statusKey {
+			statusKVP = kvp
+		}
+		if path.Dir(path.Dir(kvp.Key)) == consulutil.TasksPrefix && path.Base(kvp.Key) == "targetId" && string(kvp.Value) == deploymentID {
+			taskID := path.Base(path.Dir(kvp.Key))
+			taskIDs = append(taskIDs, taskID)
+			prefixes = append(prefixes, taskPrefixes(taskID)...)
+		}
+	}
+	if statusKVP == nil {
+		return nil, nil, errors.WithStack(invalidArchive{fmt.Sprintf("missing status for deployment %q", deploymentID)})
+	}
+
+	for _, kvp := range kvps {
+		if !hasAnyPrefix(kvp.Key, prefixes) || path.Clean(kvp.Key) != kvp.Key {
+			return nil, nil, errors.WithStack(invalidArchive{fmt.Sprintf("key %q is not related to deployment %q", kvp.Key, deploymentID)})
+		}
+		if strings.HasPrefix(kvp.Key, consulutil.TasksPrefix+"/") && path.Base(kvp.Key) == "status" && path.Dir(path.Dir(kvp.Key)) == consulutil.TasksPrefix {
+			status, err := strconv.Atoi(string(kvp.Value))
+			if err != nil {
+				return nil, nil, errors.WithStack(invalidArchive{fmt.Sprintf("invalid task status %q for key %q", string(kvp.Value), kvp.Key)})
+			}
+			if tasks.TaskStatus(status) == tasks.TaskStatusINITIAL || tasks.TaskStatus(status) == tasks.TaskStatusRUNNING {
+				kvp.Value = []byte(strconv.Itoa(int(tasks.TaskStatusCANCELED)))
+			}
+		}
+	}
+	return statusKVP, taskIDs, nil
+}
+
+// checkTasksDoNotExist ensures that imported tasks will not overwrite tasks of another deployment
+func checkTasksDoNotExist(kv *api.KV, taskIDs []string) error {
+	for _, taskID := range taskIDs {
+		for _, prefix := range taskPrefixes(taskID) {
+			keys, _, err := kv.Keys(prefix, "/", nil)
+			if err != nil {
+				return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+			}
+			if len(keys) > 0 {
+				return errors.WithStack(invalidArchive{fmt.Sprintf("task %q already exists", taskID)})
+			}
+		}
+	}
+	return nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
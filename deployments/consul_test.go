@@ -57,6 +57,9 @@ func TestRunConsulDeploymentsPackageTests(t *testing.T) {
 		t.Run("testIssueGetEmptyPropRel", func(t *testing.T) {
 			testIssueGetEmptyPropRel(t, kv)
 		})
+		t.Run("testTopologyUpdate", func(t *testing.T) {
+			testTopologyUpdate(t, kv)
+		})
 		t.Run("testRelationshipWorkflow", func(t *testing.T) {
 			testRelationshipWorkflow(t, kv)
 		})
@@ -96,6 +99,12 @@ func TestRunConsulDeploymentsPackageTests(t *testing.T) {
 		t.Run("TestOperationHost", func(t *testing.T) {
 			testOperationHost(t, kv)
 		})
+		t.Run("testOperationImplementationTimeout", func(t *testing.T) {
+			testOperationImplementationTimeout(t, kv)
+		})
+		t.Run("testPolicies", func(t *testing.T) {
+			testPolicies(t, kv)
+		})
 		t.Run("testIssueGetEmptyPropOnRelationship", func(t *testing.T) {
 			testIssueGetEmptyPropOnRelationship(t, kv)
 		})
@@ -25,6 +25,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/pkg/errors"
@@ -122,6 +123,7 @@ func storeTopology(ctx context.Context, topology tosca.Topology, deploymentID, t
 		storeOutputs(ctx, topology, topologyPrefix)
 		storeSubstitutionMappings(ctx, topology, topologyPrefix)
 		storeNodes(ctx, topology, topologyPrefix, importPath, rootDefPath)
+		storePolicies(ctx, topology, topologyPrefix)
 	} else {
 		// For imported templates, storing substitution mappings if any
 		// as they contain details on service to application/node type mapping
@@ -137,6 +139,7 @@ func storeTopology(ctx context.Context, topology tosca.Topology, deploymentID, t
 	}
 	storeCapabilityTypes(ctx, topology, topologyPrefix, importPath)
 	storeArtifactTypes(ctx, topology, topologyPrefix, importPath)
+	storePolicyTypes(ctx, topology, topologyPrefix, importPath)
 
 	// Detect potential cycles in inline workflows
 	if err := checkNestedWorkflows(topology); err != nil {
@@ -548,6 +551,12 @@ func storeInterfaces(consulStore consulutil.ConsulStore, interfaces map[string]t
 				}
 				consulStore.StoreConsulKeyAsString(operationPrefix+"/implementation/operation_host", strings.ToUpper(operationDef.Implementation.OperationHost))
 			}
+			if operationDef.Implementation.Timeout != "" {
+				if _, err := time.ParseDuration(operationDef.Implementation.Timeout); err != nil {
+					return errors.Wrapf(err, "invalid timeout %q for operation %q", operationDef.Implementation.Timeout, opName)
+				}
+				consulStore.StoreConsulKeyAsString(operationPrefix+"/implementation/timeout", operationDef.Implementation.Timeout)
+			}
 		}
 	}
 	return nil
@@ -730,6 +739,43 @@ func storeArtifactTypes(ctx context.Context, topology tosca.Topology, topologyPr
 	}
 }
 
+// storePolicyTypes stores topology policy types
+func storePolicyTypes(ctx context.Context, topology tosca.Topology, topologyPrefix, importPath string) {
+	consulStore := ctx.Value(consulStoreKey).(consulutil.ConsulStore)
+	typesPrefix := path.Join(topologyPrefix, "types")
+	for policyTypeName, policyType := range topology.PolicyTypes {
+		policyTypePrefix := path.Join(typesPrefix, policyTypeName)
+		storeCommonType(consulStore, policyType.Type, policyTypePrefix, importPath)
+		consulStore.StoreConsulKeyAsString(policyTypePrefix+"/name", policyTypeName)
+		consulStore.StoreConsulKeyAsString(policyTypePrefix+"/targets", strings.Join(policyType.Targets, ","))
+		propertiesPrefix := policyTypePrefix + "/properties"
+		for propName, propDefinition := range policyType.Properties {
+			propPrefix := propertiesPrefix + "/" + propName
+			storePropertyDefinition(ctx, propPrefix, propName, propDefinition)
+		}
+	}
+}
+
+// storePolicies stores topology template policies
+func storePolicies(ctx context.Context, topology tosca.Topology, topologyPrefix string) {
+	consulStore := ctx.Value(consulStoreKey).(consulutil.ConsulStore)
+	policiesPrefix := path.Join(topologyPrefix, "policies")
+	for _, policyMap := range topology.TopologyTemplate.Policies {
+		for policyName, policy := range policyMap {
+			policyPrefix := path.Join(policiesPrefix, policyName)
+			consulStore.StoreConsulKeyAsString(policyPrefix+"/name", policyName)
+			consulStore.StoreConsulKeyAsString(policyPrefix+"/type", policy.Type)
+			consulStore.StoreConsulKeyAsString(policyPrefix+"/description", policy.Description)
+			consulStore.StoreConsulKeyAsString(policyPrefix+"/targets", strings.Join(policy.Targets, ","))
+			storeStringMap(consulStore, policyPrefix+"/metadata", policy.Metadata)
+			propertiesPrefix := policyPrefix + "/properties"
+			for propName, propValue := range policy.Properties {
+				storeValueAssignment(consulStore, propertiesPrefix+"/"+url.QueryEscape(propName), propValue)
+			}
+		}
+	}
+}
+
 func storeWorkflowStep(consulStore consulutil.ConsulStore, deploymentID, workflowName, stepName string, step *tosca.Step) {
 	stepPrefix := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "workflows", url.QueryEscape(workflowName), "steps", url.QueryEscape(stepName))
 	if step.Target != "" {
@@ -772,6 +818,9 @@ func storeWorkflowStep(consulStore consulutil.ConsulStore, deploymentID, workflo
 		// store in consul a prefix for the next step to be executed on cancel ; this prefix is stepPrefix/on-cancel/onCancel_value
 		consulStore.StoreConsulKeyAsString(fmt.Sprintf("%s/on-cancel/%s", stepPrefix, url.QueryEscape(oc)), "")
 	}
+	if step.Retry != nil {
+		storeRetryPolicy(consulStore, stepPrefix+"/retry", step.Retry)
+	}
 }
 
 // storeWorkflow stores a workflow
@@ -1313,3 +1362,21 @@ func enhanceWorkflows(consulStore consulutil.ConsulStore, kv *api.KV, deployment
 	}
 	return nil
 }
+
+func storeRetryPolicy(consulStore consulutil.ConsulStore, retryPrefix string, retry *tosca.RetryPolicy) {
+	if retry.MaxAttempts != 0 {
+		consulStore.StoreConsulKeyAsString(retryPrefix+"/max_attempts", strconv.Itoa(retry.MaxAttempts))
+	}
+	if retry.Backoff != "" {
+		consulStore.StoreConsulKeyAsString(retryPrefix+"/backoff", strings.ToLower(retry.Backoff))
+	}
+	if retry.InitialDelay != "" {
+		consulStore.StoreConsulKeyAsString(retryPrefix+"/initial_delay", retry.InitialDelay)
+	}
+	if retry.MaxDelay != "" {
+		consulStore.StoreConsulKeyAsString(retryPrefix+"/max_delay", retry.MaxDelay)
+	}
+	if len(retry.RetryOn) > 0 {
+		consulStore.StoreConsulKeyAsString(retryPrefix+"/retry_on", strings.ToLower(strings.Join(retry.RetryOn, ",")))
+	}
+}
@@ -907,7 +907,16 @@ func testInlineWorkflow(t *testing.T, kv *api.KV) {
 	require.Nil(t, err)
 	require.Equal(t, len(wfInstall.Steps), 4)
 
-	step := wfInstall.Steps["Some_other_inline"]
+	step := wfInstall.Steps["Compute_start"]
+	require.NotNil(t, step.Retry)
+	require.Equal(t, 3, step.Retry.MaxAttempts)
+	require.Equal(t, "exponential", step.Retry.Backoff)
+	require.Equal(t, "5s", step.Retry.InitialDelay)
+	require.Equal(t, "", step.Retry.MaxDelay)
+	require.Equal(t, []string{"timeout", "delegate"}, step.Retry.RetryOn)
+	require.Nil(t, wfInstall.Steps["Compute_install"].Retry)
+
+	step = wfInstall.Steps["Some_other_inline"]
 	require.Equal(t, step.Target, "")
 	require.Equal(t, len(step.Activities), 1)
 	require.Equal(t, step.Activities[0].Inline, "my_custom_wf")
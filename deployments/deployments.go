@@ -27,6 +27,9 @@ import (
 	"github.com/ystia/yorc/helper/consulutil"
 )
 
+// IDPattern is the allowed pattern for deployments IDs
+const IDPattern = "^[-_0-9a-zA-Z]+$"
+
 type deploymentNotFound struct {
 	deploymentID string
 }
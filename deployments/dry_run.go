@@ -0,0 +1,31 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import "strings"
+
+// dryRunSuffix is appended to the temporary id under which a deployment submitted in dry-run mode is stored.
+// It contains a character not allowed in deployment ids to prevent any collision.
+const dryRunSuffix = ".dry-run"
+
+// DryRunID returns the temporary id under which a deployment submitted in dry-run mode is stored
+func DryRunID(id string) string {
+	return id + dryRunSuffix
+}
+
+// IsDryRunID checks if the given id is the one of a deployment submitted in dry-run mode
+func IsDryRunID(id string) bool {
+	return strings.HasSuffix(id, dryRunSuffix)
+}
@@ -16,6 +16,7 @@ package deployments
 
 import (
 	"path"
+	"strconv"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/pkg/errors"
@@ -40,3 +41,29 @@ func GetInputValue(kv *api.KV, deploymentID, inputName string, nestedKeys ...str
 
 	return result.RawString(), errors.Wrapf(err, "Failed to get input %q value", inputName)
 }
+
+// GetTopologyInputsNames returns the list of inputs for the deployment
+func GetTopologyInputsNames(kv *api.KV, deploymentID string) ([]string, error) {
+	inputPaths, _, err := kv.Keys(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "/topology/inputs")+"/", "/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	for i := range inputPaths {
+		inputPaths[i] = path.Base(inputPaths[i])
+	}
+	return inputPaths, nil
+}
+
+// IsTopologyInputRequired checks if a given input is required
+//
+// Inputs are required by default.
+func IsTopologyInputRequired(kv *api.KV, deploymentID, inputName string) (bool, error) {
+	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/inputs", inputName, "required"), nil)
+	if err != nil {
+		return false, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return true, nil
+	}
+	return strconv.ParseBool(string(kvp.Value))
+}
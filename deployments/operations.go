@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v2"
 
@@ -501,6 +502,8 @@ type OperationInputResult struct {
 	InstanceName string
 	Value        string
 	IsSecret     bool
+	// Artifact is set when the input is a get_artifact function, executors are responsible of making it available at Value
+	Artifact *ArtifactReference
 }
 
 // GetOperationInput retrieves the value of an input for a given operation
@@ -540,7 +543,7 @@ func GetOperationInput(kv *api.KV, deploymentID, nodeName string, operation prov
 				return nil, err
 			}
 			for _, ins := range instances {
-				results = append(results, OperationInputResult{ctxNodeName, ins, res.RawString(), res.IsSecret})
+				results = append(results, OperationInputResult{NodeName: ctxNodeName, InstanceName: ins, Value: res.RawString(), IsSecret: res.IsSecret})
 			}
 			return results, nil
 		}
@@ -583,12 +586,17 @@ func GetOperationInput(kv *api.KV, deploymentID, nodeName string, operation prov
 		}
 
 		for _, ins := range instances {
-			res, err = resolver(kv, deploymentID).context(withNodeName(nodeName), withInstanceName(ins), withRequirementIndex(operation.RelOp.RequirementIndex)).resolveFunction(f)
+			fr := resolver(kv, deploymentID).context(withNodeName(nodeName), withInstanceName(ins), withRequirementIndex(operation.RelOp.RequirementIndex))
+			res, err = fr.resolveFunction(f)
 			if err != nil {
 				return nil, err
 			}
 			if res != nil {
-				results = append(results, OperationInputResult{ctxNodeName, ins, res.RawString(), hasSecret || res.IsSecret})
+				artifact, err := fr.resolveArtifactReference(f)
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, OperationInputResult{NodeName: ctxNodeName, InstanceName: ins, Value: res.RawString(), IsSecret: hasSecret || res.IsSecret, Artifact: artifact})
 			} else {
 				ctx := events.NewContext(context.Background(), events.LogOptionalFields{events.NodeID: nodeName, events.InstanceID: ins})
 				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, deploymentID).Registerf("[WARNING] The function %q hasn't be resolved for deployment: %q, node: %q, instance: %q. No operation input variable will be set", f, deploymentID, nodeName, ins)
@@ -648,7 +656,7 @@ func GetOperationInputPropertyDefinitionDefault(kv *api.KV, deploymentID, nodeNa
 			return nil, err
 		}
 		for _, ins := range instances {
-			results = append(results, OperationInputResult{nodeName, ins, res.RawString(), res.IsSecret})
+			results = append(results, OperationInputResult{NodeName: nodeName, InstanceName: ins, Value: res.RawString(), IsSecret: res.IsSecret})
 		}
 		return results, nil
 	}
@@ -720,6 +728,23 @@ func GetOperationHostFromTypeOperationByName(kv *api.KV, deploymentID, typeName,
 	return string(kvp.Value), nil
 }
 
+// GetOperationImplementationTimeout returns the timeout declared in the implementation of an operation if any.
+//
+// A zero duration means that no timeout is declared. As for operation_host this function doesn't explore
+// the type hierarchy, nodeTemplateImpl and nodeTypeImpl should be the node template or type actually implementing the operation.
+func GetOperationImplementationTimeout(kv *api.KV, deploymentID, nodeTemplateImpl, nodeTypeImpl, operationName string) (time.Duration, error) {
+	operationPath := getOperationPath(deploymentID, nodeTemplateImpl, nodeTypeImpl, operationName)
+	kvp, _, err := kv.Get(path.Join(operationPath, "implementation/timeout"), nil)
+	if err != nil {
+		return 0, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(string(kvp.Value))
+	return timeout, errors.Wrapf(err, "invalid timeout for operation %q", operationName)
+}
+
 // IsOperationImplemented checks if a given operation is implemented either in the node template or in the node type hierarchy
 //
 // An implemented operation means that it has a non empty primary implementation or file for an implementation artifact
@@ -17,6 +17,7 @@ package deployments
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/stretchr/testify/assert"
@@ -145,3 +146,17 @@ func testOperationHost(t *testing.T, kv *api.KV) {
 		})
 	}
 }
+
+func testOperationImplementationTimeout(t *testing.T, kv *api.KV) {
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/operation_host.yaml")
+	require.NoError(t, err, "Failed to store test topology deployment definition")
+
+	timeout, err := GetOperationImplementationTimeout(kv, deploymentID, "", "yorc.tests.OperationHosts.nodes.OHNode", "standard.create")
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Minute, timeout)
+
+	timeout, err = GetOperationImplementationTimeout(kv, deploymentID, "", "yorc.tests.OperationHosts.nodes.OHNode", "standard.configure")
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), timeout)
+}
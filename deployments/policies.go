@@ -0,0 +1,197 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/collections"
+	"github.com/ystia/yorc/helper/consulutil"
+)
+
+// GetPolicies returns the names of the policies defined in a deployment
+func GetPolicies(kv *api.KV, deploymentID string) ([]string, error) {
+	names := make([]string, 0)
+	policiesPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/policies")
+	policies, _, err := kv.Keys(policiesPath+"/", "/", nil)
+	if err != nil {
+		return names, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	for _, policy := range policies {
+		names = append(names, path.Base(policy))
+	}
+	return names, nil
+}
+
+// DoesPolicyExist checks if a given policy exist in a deployment
+func DoesPolicyExist(kv *api.KV, deploymentID, policyName string) (bool, error) {
+	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/policies", policyName, "name"), nil)
+	if err != nil {
+		return false, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	return kvp != nil && len(kvp.Value) > 0, nil
+}
+
+// GetPolicyType returns the type of a given policy identified by its name
+func GetPolicyType(kv *api.KV, deploymentID, policyName string) (string, error) {
+	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/policies", policyName, "type"), nil)
+	if err != nil {
+		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return "", errors.Errorf("Missing mandatory parameter \"type\" for policy %q", policyName)
+	}
+	return string(kvp.Value), nil
+}
+
+// GetPolicyTargets returns the names of the nodes targeted by a given policy
+//
+// An empty list means that the policy applies to all nodes of the topology.
+func GetPolicyTargets(kv *api.KV, deploymentID, policyName string) ([]string, error) {
+	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/policies", policyName, "targets"), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return nil, nil
+	}
+	return strings.Split(string(kvp.Value), ","), nil
+}
+
+// GetPolicyPropertyValue returns the value of a policy property
+//
+// If the property is not set on the policy, its default value is looked up in the policy type hierarchy.
+// TOSCA functions are not supported in policies properties.
+func GetPolicyPropertyValue(kv *api.KV, deploymentID, policyName, propertyName string, nestedKeys ...string) (*TOSCAValue, error) {
+	policyType, err := GetPolicyType(kv, deploymentID, policyName)
+	if err != nil {
+		return nil, err
+	}
+	var propDataType string
+	hasProp, err := TypeHasProperty(kv, deploymentID, policyType, propertyName, true)
+	if err != nil {
+		return nil, err
+	}
+	if hasProp {
+		propDataType, err = GetTypePropertyDataType(kv, deploymentID, policyType, propertyName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	propPath := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/policies", policyName, "properties", propertyName)
+	value, isFunction, err := getValueAssignmentWithoutResolve(kv, deploymentID, propPath, propDataType, nestedKeys...)
+	if err == nil && value == nil {
+		// Not found look at policy type
+		value, isFunction, err = getTypeDefaultProperty(kv, deploymentID, policyType, propertyName, nestedKeys...)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get property %q for policy %q", propertyName, policyName)
+	}
+	if isFunction {
+		return nil, errors.Errorf("TOSCA functions are not supported in property %q of policy %q", propertyName, policyName)
+	}
+	return value, nil
+}
+
+// GetPoliciesForType returns the names of the policies of a deployment having the given type or a type derived from it
+func GetPoliciesForType(kv *api.KV, deploymentID, policyTypeName string) ([]string, error) {
+	policies, err := GetPolicies(kv, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	result := policies[:0]
+	for _, policy := range policies {
+		policyType, err := GetPolicyType(kv, deploymentID, policy)
+		if err != nil {
+			return nil, err
+		}
+		derived, err := IsTypeDerivedFrom(kv, deploymentID, policyType, policyTypeName)
+		if err != nil {
+			return nil, err
+		}
+		if derived {
+			result = append(result, policy)
+		}
+	}
+	return result, nil
+}
+
+// GetPoliciesForTypeAndNode returns the names of the policies of a deployment having the given type or
+// a type derived from it, and applying to the given node
+func GetPoliciesForTypeAndNode(kv *api.KV, deploymentID, policyTypeName, nodeName string) ([]string, error) {
+	policies, err := GetPoliciesForType(kv, deploymentID, policyTypeName)
+	if err != nil {
+		return nil, err
+	}
+	result := policies[:0]
+	for _, policy := range policies {
+		targets, err := GetPolicyTargets(kv, deploymentID, policy)
+		if err != nil {
+			return nil, err
+		}
+		if len(targets) == 0 || collections.ContainsString(targets, nodeName) {
+			result = append(result, policy)
+		}
+	}
+	return result, nil
+}
+
+// GetScalingPoliciesBoundsForNode returns the most restrictive min_instances and max_instances properties values
+// of the tosca.policies.Scaling policies applying to the given node.
+//
+// The found result is false if no such policy defines those bounds.
+func GetScalingPoliciesBoundsForNode(kv *api.KV, deploymentID, nodeName string) (minInstances, maxInstances uint32, found bool, err error) {
+	policies, err := GetPoliciesForTypeAndNode(kv, deploymentID, "tosca.policies.Scaling", nodeName)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	maxInstances = ^uint32(0)
+	for _, policy := range policies {
+		min, hasMin, err := getPolicyUintProperty(kv, deploymentID, policy, "min_instances")
+		if err != nil {
+			return 0, 0, false, err
+		}
+		if hasMin && min > minInstances {
+			minInstances = min
+		}
+		max, hasMax, err := getPolicyUintProperty(kv, deploymentID, policy, "max_instances")
+		if err != nil {
+			return 0, 0, false, err
+		}
+		if hasMax && max < maxInstances {
+			maxInstances = max
+		}
+		found = found || hasMin || hasMax
+	}
+	return minInstances, maxInstances, found, nil
+}
+
+func getPolicyUintProperty(kv *api.KV, deploymentID, policyName, propertyName string) (uint32, bool, error) {
+	value, err := GetPolicyPropertyValue(kv, deploymentID, policyName, propertyName)
+	if err != nil || value == nil || value.RawString() == "" {
+		return 0, false, err
+	}
+	v, err := strconv.ParseUint(value.RawString(), 10, 32)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "invalid value %q for property %q of policy %q", value.RawString(), propertyName, policyName)
+	}
+	return uint32(v), true, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/testutil"
+)
+
+func testPolicies(t *testing.T, kv *api.KV) {
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/policies.yaml")
+	require.NoError(t, err, "Failed to store test topology deployment definition")
+
+	policies, err := GetPolicies(kv, deploymentID)
+	require.NoError(t, err)
+	sort.Strings(policies)
+	require.Equal(t, []string{"AntiAffinity", "Custom", "Scaling1", "Scaling2"}, policies)
+
+	exist, err := DoesPolicyExist(kv, deploymentID, "AntiAffinity")
+	require.NoError(t, err)
+	require.True(t, exist)
+	exist, err = DoesPolicyExist(kv, deploymentID, "DoesNotExist")
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	policyType, err := GetPolicyType(kv, deploymentID, "AntiAffinity")
+	require.NoError(t, err)
+	require.Equal(t, "yorc.policies.Placement", policyType)
+
+	targets, err := GetPolicyTargets(kv, deploymentID, "AntiAffinity")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Compute1", "Compute2"}, targets)
+	targets, err = GetPolicyTargets(kv, deploymentID, "Custom")
+	require.NoError(t, err)
+	require.Len(t, targets, 0)
+
+	value, err := GetPolicyPropertyValue(kv, deploymentID, "AntiAffinity", "affinity")
+	require.NoError(t, err)
+	require.NotNil(t, value)
+	require.Equal(t, "anti-colocate", value.RawString())
+	// Default value from policy type
+	value, err = GetPolicyPropertyValue(kv, deploymentID, "Custom", "level")
+	require.NoError(t, err)
+	require.NotNil(t, value)
+	require.Equal(t, "low", value.RawString())
+
+	policies, err = GetPoliciesForType(kv, deploymentID, "tosca.policies.Placement")
+	require.NoError(t, err)
+	require.Equal(t, []string{"AntiAffinity"}, policies)
+
+	policies, err = GetPoliciesForTypeAndNode(kv, deploymentID, "tosca.policies.Placement", "Compute3")
+	require.NoError(t, err)
+	require.Len(t, policies, 0)
+	policies, err = GetPoliciesForTypeAndNode(kv, deploymentID, "tosca.policies.Root", "Compute3")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Custom"}, policies)
+
+	min, max, found, err := GetScalingPoliciesBoundsForNode(kv, deploymentID, "Compute1")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint32(2), min)
+	require.Equal(t, uint32(3), max)
+	_, _, found, err = GetScalingPoliciesBoundsForNode(kv, deploymentID, "Compute2")
+	require.NoError(t, err)
+	require.False(t, found)
+}
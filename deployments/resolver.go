@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/ystia/yorc/helper/collections"
 	"github.com/ystia/yorc/log"
@@ -89,36 +90,25 @@ func (fr *functionResolver) resolveFunction(fn *tosca.Function) (*TOSCAValue, er
 	if fn == nil {
 		return nil, errors.Errorf("Trying to resolve a nil function")
 	}
-	operands := make([]string, len(fn.Operands))
-	var hasSecret bool
-	for i, op := range fn.Operands {
-		if op.IsLiteral() {
-			var err error
-			s := op.String()
-			if isQuoted(s) {
-				s, err = strconv.Unquote(s)
-				if err != nil {
-					return nil, errors.Wrapf(err, "failed to unquote literal operand of function %v", fn)
-				}
-			}
-			operands[i] = s
-		} else {
-			subFn := op.(*tosca.Function)
-			r, err := fr.resolveFunction(subFn)
-			if err != nil {
-				return nil, err
-			}
-			if r != nil {
-				if r.IsSecret {
-					hasSecret = true
-				}
-				operands[i] = r.RawString()
-			}
-		}
+	operands, hasSecret, err := fr.resolveOperands(fn)
+	if err != nil {
+		return nil, err
 	}
 	switch fn.Operator {
 	case tosca.ConcatOperator:
 		return &TOSCAValue{Value: strings.Join(operands, ""), IsSecret: hasSecret}, nil
+	case tosca.TokenOperator:
+		res, err := fr.resolveToken(operands)
+		return &TOSCAValue{Value: res, IsSecret: hasSecret}, err
+	case tosca.GetNodesOfTypeOperator:
+		res, err := fr.resolveGetNodesOfType(operands)
+		return &TOSCAValue{Value: res}, err
+	case tosca.GetArtifactOperator:
+		res, err := fr.resolveGetArtifact(operands)
+		if err != nil {
+			return nil, err
+		}
+		return &TOSCAValue{Value: res.resolvedPath()}, nil
 	case tosca.GetInputOperator:
 		res, err := fr.resolveGetInput(operands)
 		return &TOSCAValue{Value: res}, err
@@ -144,6 +134,40 @@ func (fr *functionResolver) resolveFunction(fn *tosca.Function) (*TOSCAValue, er
 	return nil, errors.Errorf("Unsupported function %q", string(fn.Operator))
 }
 
+// resolveOperands resolves the operands of a function as strings, nested functions are resolved first
+//
+// The returned boolean indicates if one of the nested functions resolved to a secret.
+func (fr *functionResolver) resolveOperands(fn *tosca.Function) ([]string, bool, error) {
+	operands := make([]string, len(fn.Operands))
+	var hasSecret bool
+	for i, op := range fn.Operands {
+		if op.IsLiteral() {
+			var err error
+			s := op.String()
+			if isQuoted(s) {
+				s, err = strconv.Unquote(s)
+				if err != nil {
+					return nil, false, errors.Wrapf(err, "failed to unquote literal operand of function %v", fn)
+				}
+			}
+			operands[i] = s
+		} else {
+			subFn := op.(*tosca.Function)
+			r, err := fr.resolveFunction(subFn)
+			if err != nil {
+				return nil, false, err
+			}
+			if r != nil {
+				if r.IsSecret {
+					hasSecret = true
+				}
+				operands[i] = r.RawString()
+			}
+		}
+	}
+	return operands, hasSecret, nil
+}
+
 func (fr *functionResolver) resolveGetInput(operands []string) (string, error) {
 	if len(operands) < 1 {
 		return "", errors.Errorf("expecting at least one parameter for a get_input function")
@@ -367,5 +391,116 @@ func (fr *functionResolver) resolveGetSecret(operands []string) (string, error)
 	if err != nil {
 		return "", err
 	}
+	// Secrets may be used by any executor or plugin, they should not appear in the deployment logs
+	events.RegisterDeploymentSecrets(fr.deploymentID, secret.String())
 	return secret.String(), nil
 }
+
+func (fr *functionResolver) resolveToken(operands []string) (string, error) {
+	if len(operands) != 3 {
+		return "", errors.Errorf("expecting exactly three parameters for a token function")
+	}
+	index, err := strconv.Atoi(operands[2])
+	if err != nil || index < 0 {
+		return "", errors.Errorf("substring index %q of token function should be a positive integer", operands[2])
+	}
+	str, tokenChars := operands[0], operands[1]
+	if tokenChars == "" {
+		return "", errors.New("token function expects at least one token character")
+	}
+	// Consecutive tokens lead to empty substrings to keep indexes predictable
+	substrings := make([]string, 0)
+	start := 0
+	for i, r := range str {
+		if strings.ContainsRune(tokenChars, r) {
+			substrings = append(substrings, str[start:i])
+			start = i + utf8.RuneLen(r)
+		}
+	}
+	substrings = append(substrings, str[start:])
+	if index >= len(substrings) {
+		return "", errors.Errorf("substring index %d of token function is out of range, %q has %d substrings separated by %q", index, str, len(substrings), tokenChars)
+	}
+	return substrings[index], nil
+}
+
+func (fr *functionResolver) resolveGetNodesOfType(operands []string) ([]string, error) {
+	if len(operands) != 1 {
+		return nil, errors.Errorf("expecting exactly one parameter for a get_nodes_of_type function")
+	}
+	nodes, err := GetNodes(fr.kv, fr.deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0)
+	for _, node := range nodes {
+		derived, err := IsNodeDerivedFrom(fr.kv, fr.deploymentID, node, operands[0])
+		if err != nil {
+			return nil, err
+		}
+		if derived {
+			result = append(result, node)
+		}
+	}
+	return result, nil
+}
+
+// resolveArtifactReference returns the artifact reference of a get_artifact function or nil if fn is another function
+func (fr *functionResolver) resolveArtifactReference(fn *tosca.Function) (*ArtifactReference, error) {
+	if fn == nil || fn.Operator != tosca.GetArtifactOperator {
+		return nil, nil
+	}
+	operands, _, err := fr.resolveOperands(fn)
+	if err != nil {
+		return nil, err
+	}
+	return fr.resolveGetArtifact(operands)
+}
+
+func (fr *functionResolver) resolveGetArtifact(operands []string) (*ArtifactReference, error) {
+	funcString := fmt.Sprintf("get_artifact: [%s]", strings.Join(operands, ", "))
+	if len(operands) < 2 || len(operands) > 4 {
+		return nil, errors.Errorf("expecting between two and four parameters for a get_artifact function (%s)", funcString)
+	}
+	entity := operands[0]
+	var err error
+	var actualNode string
+	switch entity {
+	case funcKeywordSELF, funcKeywordSOURCE:
+		actualNode = fr.nodeName
+	case funcKeywordHOST:
+		actualNode, err = GetHostedOnNode(fr.kv, fr.deploymentID, fr.nodeName)
+	case funcKeywordTARGET, funcKeywordRTARGET:
+		if fr.requirementIndex == "" {
+			return nil, errors.Errorf(`Can't resolve %q %s keyword is supported only in the context of a relationship`, funcString, entity)
+		}
+		actualNode, err = GetTargetNodeForRequirement(fr.kv, fr.deploymentID, fr.nodeName, fr.requirementIndex)
+	default:
+		actualNode = entity
+	}
+	if err != nil {
+		return nil, err
+	}
+	if actualNode == "" {
+		return nil, errors.Errorf(`Can't resolve %q without a specified node name`, funcString)
+	}
+	artifacts, err := GetArtifactsForNode(fr.kv, fr.deploymentID, actualNode)
+	if err != nil {
+		return nil, err
+	}
+	artifactPath, ok := artifacts[operands[1]]
+	if !ok {
+		return nil, errors.Errorf("Can't resolve %q: artifact %q not found for node %q", funcString, operands[1], actualNode)
+	}
+	ref := &ArtifactReference{Path: artifactPath}
+	if len(operands) > 2 && operands[2] != artifactLocationLocalFile {
+		ref.Location = operands[2]
+	}
+	if len(operands) > 3 {
+		ref.Remove, err = strconv.ParseBool(operands[3])
+		if err != nil {
+			return nil, errors.Errorf("Can't resolve %q: remove parameter should be a boolean", funcString)
+		}
+	}
+	return ref, nil
+}
@@ -50,6 +50,9 @@ func testResolver(t *testing.T, kv *api.KV) {
 	t.Run("TestResolveSecret", func(t *testing.T) {
 		testResolveSecret(t, kv)
 	})
+	t.Run("deployments/resolver/testResolveFunctions", func(t *testing.T) {
+		testResolveFunctions(t, kv)
+	})
 
 }
 
@@ -180,6 +183,73 @@ func testResolveComplex(t *testing.T, kv *api.KV) {
 	}
 }
 
+func testResolveFunctions(t *testing.T, kv *api.KV) {
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/functions.yaml")
+	require.Nil(t, err, "Failed to parse testdata/functions.yaml definition: %+v", err)
+	_, grp, store := consulutil.WithContext(context.Background())
+	createNodeInstance(kv, store, deploymentID, "Soft", "0")
+	require.NoError(t, grp.Wait(), "Failed to create node instances")
+	r := resolver(kv, deploymentID)
+
+	resolverTests := []struct {
+		name             string
+		nodeName         string
+		functionAsString string
+		wantErr          bool
+		want             string
+	}{
+		{"ResolveToken", "Soft", `{token: [{get_property: [SELF, url]}, ":/", 3]}`, false, `yorc.io`},
+		{"ResolveTokenLast", "Soft", `{token: [{get_property: [SELF, url]}, ":/", 4]}`, false, `8080`},
+		{"ResolveTokenEmptySubstring", "Soft", `{token: [{get_property: [SELF, url]}, ":/", 1]}`, false, ``},
+		{"ResolveTokenOutOfRange", "Soft", `{token: [{get_property: [SELF, url]}, ":", 3]}`, true, ``},
+		{"ResolveTokenNegativeIndex", "Soft", `{token: ["a.b", ".", -1]}`, true, ``},
+		{"ResolveTokenMissingOperand", "Soft", `{token: ["a.b", "."]}`, true, ``},
+		{"ResolveGetNodesOfType", "Soft", `{get_nodes_of_type: tosca.nodes.Compute}`, false, `["Compute"]`},
+		{"ResolveGetNodesOfParentType", "Soft", `{get_nodes_of_type: tosca.nodes.Root}`, false, `["Compute","Soft"]`},
+		{"ResolveGetNodesOfUnusedType", "Soft", `{get_nodes_of_type: tosca.nodes.BlockStorage}`, false, `[]`},
+		{"ResolveGetArtifact", "Soft", `{get_artifact: [SELF, config]}`, false, `config/app.conf`},
+		{"ResolveGetArtifactOtherNode", "Compute", `{get_artifact: [Soft, config]}`, false, `config/app.conf`},
+		{"ResolveGetArtifactLocalFile", "Soft", `{get_artifact: [SELF, config, LOCAL_FILE]}`, false, `config/app.conf`},
+		{"ResolveGetArtifactWithLocation", "Soft", `{get_artifact: [SELF, config, /etc/app.conf, true]}`, false, `/etc/app.conf`},
+		{"ResolveGetArtifactInvalidRemove", "Soft", `{get_artifact: [SELF, config, /etc/app.conf, maybe]}`, true, ``},
+		{"ResolveGetArtifactMissing", "Soft", `{get_artifact: [HOST, config]}`, true, ``},
+	}
+	for _, tt := range resolverTests {
+		t.Run(tt.name, func(t *testing.T) {
+			va := generateToscaValueAssignmentFromString(t, tt.functionAsString)
+			require.Equal(t, tosca.ValueAssignmentFunction, va.Type)
+			got, err := r.context(withNodeName(tt.nodeName), withInstanceName("0")).resolveFunction(va.GetFunction())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			require.Equal(t, tt.want, got.RawString())
+		})
+	}
+
+	op := prov.Operation{Name: "standard.create", ImplementedInType: "yorc.tests.nodes.FuncSoft"}
+	inputs, err := GetOperationInput(kv, deploymentID, "Soft", op, "CONF")
+	require.NoError(t, err)
+	require.Len(t, inputs, 1)
+	require.Equal(t, "config/app.conf", inputs[0].Value)
+	require.Equal(t, &ArtifactReference{Path: "config/app.conf"}, inputs[0].Artifact)
+
+	inputs, err = GetOperationInput(kv, deploymentID, "Soft", op, "CONF_ETC")
+	require.NoError(t, err)
+	require.Len(t, inputs, 1)
+	require.Equal(t, "/etc/app.conf", inputs[0].Value)
+	require.Equal(t, &ArtifactReference{Path: "config/app.conf", Location: "/etc/app.conf", Remove: true}, inputs[0].Artifact)
+
+	inputs, err = GetOperationInput(kv, deploymentID, "Soft", op, "PORT")
+	require.NoError(t, err)
+	require.Len(t, inputs, 1)
+	require.Equal(t, "8080", inputs[0].Value)
+	require.Nil(t, inputs[0].Artifact)
+}
+
 type vaultClientMock struct {
 	id              string
 	result          string
@@ -26,7 +26,9 @@ import "encoding/json"
 // UNDEPLOYED,
 // DEPLOYMENT_FAILED,
 // UNDEPLOYMENT_FAILED,
-// SCALING_IN_PROGRESS
+// SCALING_IN_PROGRESS,
+// UPDATE_IN_PROGRESS,
+// UPDATE_FAILED
 // )
 type DeploymentStatus int
 
@@ -38,9 +38,13 @@ const (
 	UNDEPLOYMENT_FAILED
 	// SCALING_IN_PROGRESS is a DeploymentStatus of type SCALING_IN_PROGRESS
 	SCALING_IN_PROGRESS
+	// UPDATE_IN_PROGRESS is a DeploymentStatus of type UPDATE_IN_PROGRESS
+	UPDATE_IN_PROGRESS
+	// UPDATE_FAILED is a DeploymentStatus of type UPDATE_FAILED
+	UPDATE_FAILED
 )
 
-const _DeploymentStatusName = "INITIALDEPLOYMENT_IN_PROGRESSDEPLOYEDUNDEPLOYMENT_IN_PROGRESSUNDEPLOYEDDEPLOYMENT_FAILEDUNDEPLOYMENT_FAILEDSCALING_IN_PROGRESS"
+const _DeploymentStatusName = "INITIALDEPLOYMENT_IN_PROGRESSDEPLOYEDUNDEPLOYMENT_IN_PROGRESSUNDEPLOYEDDEPLOYMENT_FAILEDUNDEPLOYMENT_FAILEDSCALING_IN_PROGRESSUPDATE_IN_PROGRESSUPDATE_FAILED"
 
 var _DeploymentStatusMap = map[DeploymentStatus]string{
 	0: _DeploymentStatusName[0:7],
@@ -51,6 +55,8 @@ var _DeploymentStatusMap = map[DeploymentStatus]string{
 	5: _DeploymentStatusName[71:88],
 	6: _DeploymentStatusName[88:107],
 	7: _DeploymentStatusName[107:126],
+	8: _DeploymentStatusName[126:144],
+	9: _DeploymentStatusName[144:157],
 }
 
 // String implements the Stringer interface.
@@ -70,6 +76,8 @@ var _DeploymentStatusValue = map[string]DeploymentStatus{
 	_DeploymentStatusName[71:88]:   5,
 	_DeploymentStatusName[88:107]:  6,
 	_DeploymentStatusName[107:126]: 7,
+	_DeploymentStatusName[126:144]: 8,
+	_DeploymentStatusName[144:157]: 9,
 }
 
 // ParseDeploymentStatus attempts to convert a string to a DeploymentStatus
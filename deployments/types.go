@@ -274,6 +274,28 @@ func isTypePropOrAttrRequired(kv *api.KV, deploymentID, typeName, originalTypeNa
 	return !(strings.ToLower(string(kvp.Value)) == "false"), nil
 }
 
+// GetTypeMetadata returns the value of a given metadata key defined on a type
+//
+// If exploreParents is true and the key is not defined on the given type then parent types are explored.
+// The first returned value is false if the key was not found.
+func GetTypeMetadata(kv *api.KV, deploymentID, typeName, key string, exploreParents bool) (bool, string, error) {
+	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/types", typeName, "metadata", key), nil)
+	if err != nil {
+		return false, "", errors.Wrapf(err, "Can't get metadata for type %q", typeName)
+	}
+	if kvp != nil && len(kvp.Value) != 0 {
+		return true, string(kvp.Value), nil
+	}
+	if !exploreParents {
+		return false, "", nil
+	}
+	parentType, err := GetParentType(kv, deploymentID, typeName)
+	if err != nil || parentType == "" {
+		return false, "", err
+	}
+	return GetTypeMetadata(kv, deploymentID, parentType, key, true)
+}
+
 // GetTypeImportPath returns the import path relative to the root of a CSAR of a given TOSCA type.
 //
 // This is particulary useful for resolving artifacts and implementation
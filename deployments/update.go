@@ -0,0 +1,557 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/collections"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/tosca"
+)
+
+// DefaultUpdateOperation is the operation called on modified nodes when applying a deployment update
+const DefaultUpdateOperation = "standard.update"
+
+// UpdateWorkflowName is the name of the workflow generated to apply a deployment update
+const UpdateWorkflowName = "_yorc_update"
+
+// updateStagingSuffix is appended to a deployment id to store the pending update of this deployment.
+// It contains a character not allowed in deployment ids to prevent any collision.
+const updateStagingSuffix = ".update"
+
+// appliedDiffKey is the key, relative to the pending update, where the differences are stored when applying it
+const appliedDiffKey = "applied_diff"
+
+// TopologyDiff represents the differences between the topology of a deployment and its pending update
+type TopologyDiff struct {
+	AddedNodes    []string   `json:"added_nodes,omitempty"`
+	RemovedNodes  []string   `json:"removed_nodes,omitempty"`
+	ModifiedNodes []NodeDiff `json:"modified_nodes,omitempty"`
+}
+
+// NodeDiff represents the differences of a node template between a deployment and its pending update
+type NodeDiff struct {
+	Name string `json:"name"`
+	// OldType and NewType are only set if the node type changed
+	OldType string `json:"old_type,omitempty"`
+	NewType string `json:"new_type,omitempty"`
+	// ChangedProperties lists properties added, removed or with a different value
+	ChangedProperties []string `json:"changed_properties,omitempty"`
+	// AddedRelationships and RemovedRelationships are formatted as <requirement_name>:<target_node>
+	AddedRelationships   []string `json:"added_relationships,omitempty"`
+	RemovedRelationships []string `json:"removed_relationships,omitempty"`
+	// OtherChanges lists other sections of the node template that changed (capabilities, artifacts, interfaces...)
+	OtherChanges []string `json:"other_changes,omitempty"`
+}
+
+// IsEmpty returns true if there is no difference between the two topologies
+func (d *TopologyDiff) IsEmpty() bool {
+	return len(d.AddedNodes) == 0 && len(d.RemovedNodes) == 0 && len(d.ModifiedNodes) == 0
+}
+
+// ModifiedNodesNames returns the names of modified nodes
+func (d *TopologyDiff) ModifiedNodesNames() []string {
+	names := make([]string, len(d.ModifiedNodes))
+	for i := range d.ModifiedNodes {
+		names[i] = d.ModifiedNodes[i].Name
+	}
+	return names
+}
+
+// UpdateStagingID returns the id under which the pending update of a deployment is stored
+func UpdateStagingID(deploymentID string) string {
+	return deploymentID + updateStagingSuffix
+}
+
+// IsUpdateStagingID checks if the given id is the one of a pending deployment update
+func IsUpdateStagingID(id string) bool {
+	return strings.HasSuffix(id, updateStagingSuffix)
+}
+
+// HasPendingUpdate checks if an update was submitted for the given deployment
+func HasPendingUpdate(kv *api.KV, deploymentID string) (bool, error) {
+	return DoesDeploymentExists(kv, UpdateStagingID(deploymentID))
+}
+
+// DeletePendingUpdate removes the pending update of a deployment from the Consul store
+//
+// Events and logs published while storing the update under its staging id are removed too.
+func DeletePendingUpdate(kv *api.KV, deploymentID string) error {
+	stagingID := UpdateStagingID(deploymentID)
+	for _, prefix := range []string{consulutil.DeploymentKVPrefix, consulutil.EventsPrefix, consulutil.LogsPrefix} {
+		_, err := kv.DeleteTree(path.Join(prefix, stagingID)+"/", nil)
+		if err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+	}
+	return nil
+}
+
+// GetPendingUpdateDiff computes the differences between the topology of a deployment and its pending update
+//
+// If the pending update was already applied, even partially, the differences stored at that time are returned
+// as the topology of the deployment may already contain the update.
+func GetPendingUpdateDiff(kv *api.KV, deploymentID string) (*TopologyDiff, error) {
+	diff, err := getAppliedUpdateDiff(kv, deploymentID)
+	if err != nil || diff != nil {
+		return diff, err
+	}
+	return DiffTopologies(kv, deploymentID, UpdateStagingID(deploymentID))
+}
+
+// getAppliedUpdateDiff returns the differences stored when applying the pending update of a deployment
+// or nil if it was not applied yet
+func getAppliedUpdateDiff(kv *api.KV, deploymentID string) (*TopologyDiff, error) {
+	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, UpdateStagingID(deploymentID), appliedDiffKey), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil {
+		return nil, nil
+	}
+	diff := new(TopologyDiff)
+	err = json.Unmarshal(kvp.Value, diff)
+	return diff, errors.Wrapf(err, "failed to unmarshal applied update differences of deployment %q", deploymentID)
+}
+
+// DiffTopologies computes the differences between node templates of two stored deployments
+func DiffTopologies(kv *api.KV, currentID, updatedID string) (*TopologyDiff, error) {
+	currentNodes, err := GetNodes(kv, currentID)
+	if err != nil {
+		return nil, err
+	}
+	updatedNodes, err := GetNodes(kv, updatedID)
+	if err != nil {
+		return nil, err
+	}
+	diff := &TopologyDiff{}
+	for _, nodeName := range updatedNodes {
+		if !collections.ContainsString(currentNodes, nodeName) {
+			diff.AddedNodes = append(diff.AddedNodes, nodeName)
+		}
+	}
+	for _, nodeName := range currentNodes {
+		if !collections.ContainsString(updatedNodes, nodeName) {
+			diff.RemovedNodes = append(diff.RemovedNodes, nodeName)
+			continue
+		}
+		nodeDiff, err := diffNode(kv, currentID, updatedID, nodeName)
+		if err != nil {
+			return nil, err
+		}
+		if nodeDiff != nil {
+			diff.ModifiedNodes = append(diff.ModifiedNodes, *nodeDiff)
+		}
+	}
+	sort.Strings(diff.AddedNodes)
+	sort.Strings(diff.RemovedNodes)
+	sort.Slice(diff.ModifiedNodes, func(i, j int) bool { return diff.ModifiedNodes[i].Name < diff.ModifiedNodes[j].Name })
+	return diff, nil
+}
+
+// readNodeTree returns the values stored for a node template indexed by their path relative to the node
+func readNodeTree(kv *api.KV, deploymentID, nodeName string) (map[string]string, error) {
+	nodePrefix := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/nodes", nodeName) + "/"
+	kvps, _, err := kv.List(nodePrefix, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	tree := make(map[string]string, len(kvps))
+	for _, kvp := range kvps {
+		tree[strings.TrimPrefix(kvp.Key, nodePrefix)] = string(kvp.Value)
+	}
+	return tree, nil
+}
+
+// readNodeRelationships returns relationships of a node template formatted as <requirement_name>:<target_node>
+func readNodeRelationships(tree map[string]string) []string {
+	relationships := make([]string, 0)
+	for key, value := range tree {
+		parts := strings.Split(key, "/")
+		if len(parts) == 3 && parts[0] == "requirements" && parts[2] == "node" {
+			relationships = append(relationships, tree[path.Join(parts[0], parts[1], "name")]+":"+value)
+		}
+	}
+	return relationships
+}
+
+func diffNode(kv *api.KV, currentID, updatedID, nodeName string) (*NodeDiff, error) {
+	current, err := readNodeTree(kv, currentID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := readNodeTree(kv, updatedID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	nodeDiff := &NodeDiff{Name: nodeName}
+	changed := false
+	if current["type"] != updated["type"] {
+		nodeDiff.OldType = current["type"]
+		nodeDiff.NewType = updated["type"]
+		changed = true
+	}
+
+	changedKeys := make([]string, 0)
+	for key, value := range current {
+		if updatedValue, ok := updated[key]; !ok || updatedValue != value {
+			changedKeys = append(changedKeys, key)
+		}
+	}
+	for key := range updated {
+		if _, ok := current[key]; !ok {
+			changedKeys = append(changedKeys, key)
+		}
+	}
+	relationshipsChanged := false
+	for _, key := range changedKeys {
+		parts := strings.SplitN(key, "/", 3)
+		switch parts[0] {
+		case "type", "name":
+		case "properties":
+			if len(parts) < 2 {
+				continue
+			}
+			propName, err := url.QueryUnescape(parts[1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get back property name from Consul")
+			}
+			if !collections.ContainsString(nodeDiff.ChangedProperties, propName) {
+				nodeDiff.ChangedProperties = append(nodeDiff.ChangedProperties, propName)
+			}
+		case "requirements":
+			relationshipsChanged = true
+		default:
+			if !collections.ContainsString(nodeDiff.OtherChanges, parts[0]) {
+				nodeDiff.OtherChanges = append(nodeDiff.OtherChanges, parts[0])
+			}
+		}
+	}
+	if relationshipsChanged {
+		currentRels := readNodeRelationships(current)
+		updatedRels := readNodeRelationships(updated)
+		for _, rel := range updatedRels {
+			if !collections.ContainsString(currentRels, rel) {
+				nodeDiff.AddedRelationships = append(nodeDiff.AddedRelationships, rel)
+			}
+		}
+		for _, rel := range currentRels {
+			if !collections.ContainsString(updatedRels, rel) {
+				nodeDiff.RemovedRelationships = append(nodeDiff.RemovedRelationships, rel)
+			}
+		}
+		sort.Strings(nodeDiff.AddedRelationships)
+		sort.Strings(nodeDiff.RemovedRelationships)
+		if len(nodeDiff.AddedRelationships) == 0 && len(nodeDiff.RemovedRelationships) == 0 {
+			// Same relationships but declared differently (order, capability...)
+			nodeDiff.OtherChanges = append(nodeDiff.OtherChanges, "requirements")
+		}
+	}
+	sort.Strings(nodeDiff.ChangedProperties)
+	sort.Strings(nodeDiff.OtherChanges)
+	changed = changed || len(nodeDiff.ChangedProperties) > 0 || len(nodeDiff.AddedRelationships) > 0 ||
+		len(nodeDiff.RemovedRelationships) > 0 || len(nodeDiff.OtherChanges) > 0
+	if !changed {
+		return nil, nil
+	}
+	return nodeDiff, nil
+}
+
+// ApplyPendingUpdate merges the pending update of a deployment into its topology and generates the workflow
+// applying it (see UpdateWorkflowName).
+//
+// Definitions of removed nodes are kept until the update workflow ends, in order to be able to uninstall them,
+// they should then be removed using CleanupAppliedUpdate.
+// The generated workflow uninstalls removed nodes, then installs added nodes and finally calls the given
+// updateOperation on modified nodes. Nodes that do not implement this operation are simply skipped.
+//
+// The differences and the generated workflow are stored with the pending update before merging it, so that
+// applying it again, for instance if the merge or the registration of the update task failed, replays the
+// same update. The updateOperation is then ignored.
+func ApplyPendingUpdate(ctx context.Context, kv *api.KV, deploymentID, updateOperation string) (*TopologyDiff, error) {
+	stagingID := UpdateStagingID(deploymentID)
+	deploymentPrefix := path.Join(consulutil.DeploymentKVPrefix, deploymentID)
+	stagingPrefix := path.Join(consulutil.DeploymentKVPrefix, stagingID)
+	diff, err := getAppliedUpdateDiff(kv, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	if diff == nil {
+		diff, err = storeAppliedUpdate(ctx, kv, deploymentID, updateOperation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	currentTopoPrefix := path.Join(deploymentPrefix, "topology")
+	stagingTopoPrefix := path.Join(stagingPrefix, "topology")
+
+	// Sections fully replaced by the updated ones, others are overridden in order to keep definitions
+	// required by removed nodes or runtime data
+	toDelete := []string{"inputs", "outputs", "substitution_mappings", "metadata", "repositories"}
+	for _, nodeName := range diff.AddedNodes {
+		toDelete = append(toDelete, path.Join("nodes", nodeName))
+	}
+	for _, nodeName := range diff.ModifiedNodesNames() {
+		toDelete = append(toDelete, path.Join("nodes", nodeName))
+	}
+	for _, nodeDiff := range diff.ModifiedNodes {
+		if len(nodeDiff.AddedRelationships) > 0 || len(nodeDiff.RemovedRelationships) > 0 {
+			toDelete = append(toDelete, path.Join("relationship_instances", nodeDiff.Name))
+		}
+	}
+	for _, p := range toDelete {
+		_, err = kv.DeleteTree(path.Join(currentTopoPrefix, p)+"/", nil)
+		if err != nil {
+			return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+	}
+	_, err = kv.DeleteTree(path.Join(deploymentPrefix, "workflows")+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+
+	_, errGroup, consulStore := consulutil.WithContext(ctx)
+	kvps, _, err := kv.List(stagingTopoPrefix+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	for _, kvp := range kvps {
+		relKey := strings.TrimPrefix(kvp.Key, stagingTopoPrefix+"/")
+		if !isMergedTopologyKey(relKey, diff) {
+			continue
+		}
+		consulStore.StoreConsulKey(path.Join(currentTopoPrefix, relKey), kvp.Value)
+	}
+	kvps, _, err = kv.List(path.Join(stagingPrefix, "workflows")+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	// The update workflow is copied along with the updated ones
+	for _, kvp := range kvps {
+		consulStore.StoreConsulKey(path.Join(deploymentPrefix, strings.TrimPrefix(kvp.Key, stagingPrefix+"/")), kvp.Value)
+	}
+	return diff, errGroup.Wait()
+}
+
+// storeAppliedUpdate computes the differences between a deployment and its pending update and the workflow
+// applying them, and stores both with the pending update
+func storeAppliedUpdate(ctx context.Context, kv *api.KV, deploymentID, updateOperation string) (*TopologyDiff, error) {
+	stagingID := UpdateStagingID(deploymentID)
+	diff, err := DiffTopologies(kv, deploymentID, stagingID)
+	if err != nil {
+		return nil, err
+	}
+	uninstallWf, err := ReadWorkflow(kv, deploymentID, "uninstall")
+	if err != nil {
+		return nil, err
+	}
+	installWf, err := ReadWorkflow(kv, stagingID, "install")
+	if err != nil {
+		return nil, err
+	}
+	if updateOperation == "" {
+		updateOperation = DefaultUpdateOperation
+	}
+	updateWf := buildUpdateWorkflow(uninstallWf, installWf, diff, updateOperation)
+	b, err := json.Marshal(diff)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal update differences of deployment %q", deploymentID)
+	}
+
+	_, errGroup, consulStore := consulutil.WithContext(ctx)
+	storeWorkflow(consulStore, stagingID, UpdateWorkflowName, updateWf)
+	err = errGroup.Wait()
+	if err != nil {
+		return nil, err
+	}
+	// Stored last as it marks the update as applied
+	_, err = kv.Put(&api.KVPair{Key: path.Join(consulutil.DeploymentKVPrefix, stagingID, appliedDiffKey), Value: b}, nil)
+	return diff, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+// isMergedTopologyKey checks if a key of the updated topology should be merged into the deployment topology
+func isMergedTopologyKey(relKey string, diff *TopologyDiff) bool {
+	parts := strings.SplitN(relKey, "/", 3)
+	switch parts[0] {
+	case "instances":
+		// Only new nodes instances are created, existing ones keep their runtime attributes
+		return len(parts) > 1 && collections.ContainsString(diff.AddedNodes, parts[1])
+	case "relationship_instances":
+		if len(parts) < 2 {
+			return false
+		}
+		if collections.ContainsString(diff.AddedNodes, parts[1]) {
+			return true
+		}
+		for _, nodeDiff := range diff.ModifiedNodes {
+			if nodeDiff.Name == parts[1] {
+				return len(nodeDiff.AddedRelationships) > 0 || len(nodeDiff.RemovedRelationships) > 0
+			}
+		}
+		return false
+	case "nodes":
+		return len(parts) > 1 && (collections.ContainsString(diff.AddedNodes, parts[1]) ||
+			collections.ContainsString(diff.ModifiedNodesNames(), parts[1]))
+	}
+	return true
+}
+
+// CleanupAppliedUpdate removes definitions and instances of nodes removed by an update as well as the
+// update workflow and the pending update
+func CleanupAppliedUpdate(kv *api.KV, deploymentID string, removedNodes []string) error {
+	topoPrefix := path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology")
+	for _, nodeName := range removedNodes {
+		for _, p := range []string{"nodes", "instances", "relationship_instances"} {
+			_, err := kv.DeleteTree(path.Join(topoPrefix, p, nodeName)+"/", nil)
+			if err != nil {
+				return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+			}
+		}
+	}
+	_, err := kv.DeleteTree(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "workflows", url.QueryEscape(UpdateWorkflowName))+"/", nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	return DeletePendingUpdate(kv, deploymentID)
+}
+
+// buildUpdateWorkflow generates a workflow uninstalling removed nodes, then installing added nodes and
+// finally calling the update operation on modified nodes
+func buildUpdateWorkflow(uninstallWf, installWf tosca.Workflow, diff *TopologyDiff, updateOperation string) tosca.Workflow {
+	wf := tosca.Workflow{Steps: make(map[string]*tosca.Step)}
+	parts := []map[string]*tosca.Step{
+		extractSubWorkflow(uninstallWf, diff.RemovedNodes, "uninstall_"),
+		extractSubWorkflow(installWf, diff.AddedNodes, "install_"),
+	}
+	updateSteps := make(map[string]*tosca.Step)
+	for _, nodeName := range diff.ModifiedNodesNames() {
+		updateSteps["update_"+nodeName] = &tosca.Step{
+			Target:     nodeName,
+			Activities: []tosca.Activity{{CallOperation: updateOperation}},
+		}
+	}
+	parts = append(parts, updateSteps)
+
+	var previousLeaves []string
+	for _, part := range parts {
+		if len(part) == 0 {
+			continue
+		}
+		roots, leaves := subWorkflowBounds(part)
+		for _, leaf := range previousLeaves {
+			wf.Steps[leaf].OnSuccess = roots
+		}
+		for name, step := range part {
+			wf.Steps[name] = step
+		}
+		previousLeaves = leaves
+	}
+	return wf
+}
+
+// extractSubWorkflow returns the steps of a workflow targeting one of the given nodes, prefixed by the given prefix.
+//
+// Ordering between kept steps is preserved even if they were linked through steps that are not kept.
+func extractSubWorkflow(wf tosca.Workflow, nodes []string, prefix string) map[string]*tosca.Step {
+	steps := make(map[string]*tosca.Step)
+	keep := func(stepName string) bool {
+		step, ok := wf.Steps[stepName]
+		return ok && step.Target != "" && collections.ContainsString(nodes, step.Target)
+	}
+	for stepName, step := range wf.Steps {
+		if !keep(stepName) {
+			continue
+		}
+		next := make([]string, 0)
+		visited := make(map[string]bool)
+		var walk func(names []string)
+		walk = func(names []string) {
+			for _, n := range names {
+				if visited[n] {
+					continue
+				}
+				visited[n] = true
+				if keep(n) {
+					if !collections.ContainsString(next, prefix+n) {
+						next = append(next, prefix+n)
+					}
+					continue
+				}
+				if s, ok := wf.Steps[n]; ok {
+					walk(s.OnSuccess)
+				}
+			}
+		}
+		walk(step.OnSuccess)
+		sort.Strings(next)
+		newStep := &tosca.Step{
+			Target:             step.Target,
+			TargetRelationShip: step.TargetRelationShip,
+			OperationHost:      step.OperationHost,
+			Activities:         step.Activities,
+		}
+		if len(next) > 0 {
+			newStep.OnSuccess = next
+		}
+		steps[prefix+stepName] = newStep
+	}
+	return steps
+}
+
+// subWorkflowBounds returns the steps without predecessors and the steps without successors of a sub-workflow
+func subWorkflowBounds(steps map[string]*tosca.Step) ([]string, []string) {
+	hasPrevious := make(map[string]bool)
+	leaves := make([]string, 0)
+	for name, step := range steps {
+		for _, next := range step.OnSuccess {
+			hasPrevious[next] = true
+		}
+		if len(step.OnSuccess) == 0 {
+			leaves = append(leaves, name)
+		}
+	}
+	roots := make([]string, 0)
+	for name := range steps {
+		if !hasPrevious[name] {
+			roots = append(roots, name)
+		}
+	}
+	sort.Strings(roots)
+	sort.Strings(leaves)
+	return roots, leaves
+}
+
+// FormatRemovedNodes formats a list of removed nodes to be stored as task data
+func FormatRemovedNodes(nodes []string) string {
+	return strings.Join(nodes, ",")
+}
+
+// ParseRemovedNodes parses a list of removed nodes stored as task data
+func ParseRemovedNodes(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
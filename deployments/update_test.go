@@ -0,0 +1,159 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/testutil"
+	"github.com/ystia/yorc/tosca"
+)
+
+func TestBuildUpdateWorkflow(t *testing.T) {
+	t.Parallel()
+	uninstallWf := tosca.Workflow{Steps: map[string]*tosca.Step{
+		"Kept_stop":         {Target: "Kept", Activities: []tosca.Activity{{CallOperation: "Standard.stop"}}, OnSuccess: []string{"Removed_stop"}},
+		"Removed_stop":      {Target: "Removed", Activities: []tosca.Activity{{CallOperation: "Standard.stop"}}, OnSuccess: []string{"Other_stop"}},
+		"Other_stop":        {Target: "Other", Activities: []tosca.Activity{{CallOperation: "Standard.stop"}}, OnSuccess: []string{"Removed_delete"}},
+		"Removed_delete":    {Target: "Removed", Activities: []tosca.Activity{{CallOperation: "Standard.delete"}}, OnSuccess: []string{"Compute_uninstall"}},
+		"Compute_uninstall": {Target: "Compute", Activities: []tosca.Activity{{Delegate: "uninstall"}}},
+	}}
+	installWf := tosca.Workflow{Steps: map[string]*tosca.Step{
+		"Compute_install": {Target: "Compute", Activities: []tosca.Activity{{Delegate: "install"}}, OnSuccess: []string{"Added_create"}},
+		"Added_create":    {Target: "Added", Activities: []tosca.Activity{{CallOperation: "Standard.create"}}, OnSuccess: []string{"Added_start"}},
+		"Added_start":     {Target: "Added", Activities: []tosca.Activity{{CallOperation: "Standard.start"}}, OnSuccess: []string{"Kept_start"}},
+		"Kept_start":      {Target: "Kept", Activities: []tosca.Activity{{CallOperation: "Standard.start"}}},
+	}}
+	diff := &TopologyDiff{
+		AddedNodes:    []string{"Added"},
+		RemovedNodes:  []string{"Removed"},
+		ModifiedNodes: []NodeDiff{{Name: "Kept", ChangedProperties: []string{"component_version"}}},
+	}
+
+	wf := buildUpdateWorkflow(uninstallWf, installWf, diff, "custom.update")
+	require.Len(t, wf.Steps, 5)
+	require.Contains(t, wf.Steps, "uninstall_Removed_stop")
+	// Order is kept through steps of other nodes
+	assert.Equal(t, []string{"uninstall_Removed_delete"}, wf.Steps["uninstall_Removed_stop"].OnSuccess)
+	assert.Equal(t, []string{"install_Added_create"}, wf.Steps["uninstall_Removed_delete"].OnSuccess)
+	assert.Equal(t, []string{"install_Added_start"}, wf.Steps["install_Added_create"].OnSuccess)
+	assert.Equal(t, []string{"update_Kept"}, wf.Steps["install_Added_start"].OnSuccess)
+	require.Contains(t, wf.Steps, "update_Kept")
+	assert.Equal(t, "Kept", wf.Steps["update_Kept"].Target)
+	assert.Equal(t, []tosca.Activity{{CallOperation: "custom.update"}}, wf.Steps["update_Kept"].Activities)
+	assert.Len(t, wf.Steps["update_Kept"].OnSuccess, 0)
+
+	// Only modified nodes
+	wf = buildUpdateWorkflow(uninstallWf, installWf, &TopologyDiff{ModifiedNodes: diff.ModifiedNodes}, DefaultUpdateOperation)
+	require.Len(t, wf.Steps, 1)
+	assert.Contains(t, wf.Steps, "update_Kept")
+}
+
+func testTopologyUpdate(t *testing.T, kv *api.KV) {
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/update_current.yaml")
+	require.NoError(t, err, "Failed to store test topology deployment definition")
+
+	pending, err := HasPendingUpdate(kv, deploymentID)
+	require.NoError(t, err)
+	assert.False(t, pending)
+
+	err = StoreDeploymentDefinition(context.Background(), kv, UpdateStagingID(deploymentID), "testdata/update_new.yaml")
+	require.NoError(t, err, "Failed to store test topology update definition")
+	for _, prefix := range []string{consulutil.EventsPrefix, consulutil.LogsPrefix} {
+		_, err = kv.Put(&api.KVPair{Key: path.Join(prefix, UpdateStagingID(deploymentID), "0001"), Value: []byte("{}")}, nil)
+		require.NoError(t, err)
+	}
+	pending, err = HasPendingUpdate(kv, deploymentID)
+	require.NoError(t, err)
+	assert.True(t, pending)
+
+	diff, err := GetPendingUpdateDiff(kv, deploymentID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Added"}, diff.AddedNodes)
+	assert.Equal(t, []string{"Removed"}, diff.RemovedNodes)
+	require.Len(t, diff.ModifiedNodes, 1)
+	assert.Equal(t, "Kept", diff.ModifiedNodes[0].Name)
+	assert.Equal(t, []string{"component_version"}, diff.ModifiedNodes[0].ChangedProperties)
+	assert.Equal(t, []string{"dependency:Added"}, diff.ModifiedNodes[0].AddedRelationships)
+	assert.Len(t, diff.ModifiedNodes[0].RemovedRelationships, 0)
+	assert.Equal(t, "", diff.ModifiedNodes[0].NewType)
+
+	diff, err = ApplyPendingUpdate(context.Background(), kv, deploymentID, "")
+	require.NoError(t, err)
+
+	nodes, err := GetNodes(kv, deploymentID)
+	require.NoError(t, err)
+	// Removed node is kept until the update workflow ends
+	assert.Contains(t, nodes, "Removed")
+	assert.Contains(t, nodes, "Added")
+	value, err := GetNodePropertyValue(kv, deploymentID, "Kept", "component_version")
+	require.NoError(t, err)
+	require.NotNil(t, value)
+	assert.Equal(t, "2.0", value.RawString())
+	instances, err := GetNodeInstancesIds(kv, deploymentID, "Added")
+	require.NoError(t, err)
+	assert.Len(t, instances, 1)
+
+	wf, err := ReadWorkflow(kv, deploymentID, UpdateWorkflowName)
+	require.NoError(t, err)
+	assert.Contains(t, wf.Steps, "uninstall_Removed_stop")
+	assert.Contains(t, wf.Steps, "install_Added_create")
+	require.Contains(t, wf.Steps, "update_Kept")
+	assert.Equal(t, DefaultUpdateOperation, wf.Steps["update_Kept"].Activities[0].CallOperation)
+	install, err := ReadWorkflow(kv, deploymentID, "install")
+	require.NoError(t, err)
+	assert.Contains(t, install.Steps, "Added_create")
+	assert.NotContains(t, install.Steps, "Removed_start")
+
+	// Applying the update again replays it
+	appliedDiff, err := GetPendingUpdateDiff(kv, deploymentID)
+	require.NoError(t, err)
+	assert.Equal(t, diff, appliedDiff)
+	appliedDiff, err = ApplyPendingUpdate(context.Background(), kv, deploymentID, "custom.update")
+	require.NoError(t, err)
+	assert.Equal(t, diff, appliedDiff)
+	wf, err = ReadWorkflow(kv, deploymentID, UpdateWorkflowName)
+	require.NoError(t, err)
+	assert.Contains(t, wf.Steps, "uninstall_Removed_stop")
+	assert.Equal(t, DefaultUpdateOperation, wf.Steps["update_Kept"].Activities[0].CallOperation)
+
+	err = CleanupAppliedUpdate(kv, deploymentID, diff.RemovedNodes)
+	require.NoError(t, err)
+	nodes, err = GetNodes(kv, deploymentID)
+	require.NoError(t, err)
+	assert.NotContains(t, nodes, "Removed")
+	kvp, _, err := kv.Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/instances/Removed/0/id"), nil)
+	require.NoError(t, err)
+	assert.Nil(t, kvp)
+	workflows, err := GetWorkflows(kv, deploymentID)
+	require.NoError(t, err)
+	assert.NotContains(t, workflows, UpdateWorkflowName)
+	pending, err = HasPendingUpdate(kv, deploymentID)
+	require.NoError(t, err)
+	assert.False(t, pending)
+	for _, prefix := range []string{consulutil.EventsPrefix, consulutil.LogsPrefix} {
+		keys, _, err := kv.Keys(path.Join(prefix, UpdateStagingID(deploymentID))+"/", "", nil)
+		require.NoError(t, err)
+		assert.Len(t, keys, 0, "events and logs of the pending update should be removed")
+	}
+}
@@ -170,5 +170,36 @@ func readWfStep(kv *api.KV, stepKey string, stepName string, wfName string) (*to
 			step.OnFailure[i] = nextName
 		}
 	}
-	return step, nil
+	step.Retry, err = readWfStepRetryPolicy(kv, path.Join(stepKey, "retry"))
+	return step, err
+}
+
+func readWfStepRetryPolicy(kv *api.KV, retryKey string) (*tosca.RetryPolicy, error) {
+	kvps, _, err := kv.List(retryKey+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if len(kvps) == 0 {
+		return nil, nil
+	}
+	retry := &tosca.RetryPolicy{}
+	for _, kvp := range kvps {
+		value := string(kvp.Value)
+		switch path.Base(kvp.Key) {
+		case "max_attempts":
+			retry.MaxAttempts, err = strconv.Atoi(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid retry max_attempts value %q for step", value)
+			}
+		case "backoff":
+			retry.Backoff = value
+		case "initial_delay":
+			retry.InitialDelay = value
+		case "max_delay":
+			retry.MaxDelay = value
+		case "retry_on":
+			retry.RetryOn = strings.Split(value, ",")
+		}
+	}
+	return retry, nil
 }
@@ -54,9 +54,10 @@ func (b *bufferedConsulWriter) flush(logEntry LogEntry) error {
 	if len(b.buf) == 0 {
 		return nil
 	}
-	log.Debug(string(b.buf))
 	reg := regexp.MustCompile(`\x1B\[([0-9]{1,2}(;[0-9]{1,2})?)?[m|K]`)
 	out := reg.ReplaceAll(b.buf, []byte(""))
+	// Register masks secrets but we don't want them in debug logs either
+	log.Debug(string(maskSecrets(logEntry.deploymentID, logEntry.additionalInfo, b.buf)))
 	logEntry.Register(out)
 	b.buf = b.buf[:0]
 	return nil
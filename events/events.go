@@ -172,6 +172,9 @@ func PublishAndLogWorkflowStepStatusChange(ctx context.Context, kv *api.KV, depl
 	info[EOperationName] = wfStepInfo.OperationName
 	info[ETargetNodeID] = wfStepInfo.TargetNodeID
 	info[ETargetInstanceID] = wfStepInfo.TargetInstanceID
+	if wfStepInfo.Attempt > 0 {
+		info[EAttempt] = strconv.Itoa(wfStepInfo.Attempt)
+	}
 	e, err := newStatusChange(StatusChangeTypeWorkflowStep, info, deploymentID, strings.ToLower(status))
 	if err != nil {
 		return "", err
@@ -202,6 +205,9 @@ func PublishAndLogAlienTaskStatusChange(ctx context.Context, kv *api.KV, deploym
 	info[EOperationName] = wfStepInfo.OperationName
 	info[ETargetNodeID] = wfStepInfo.TargetNodeID
 	info[ETargetInstanceID] = wfStepInfo.TargetInstanceID
+	if wfStepInfo.Attempt > 0 {
+		info[EAttempt] = strconv.Itoa(wfStepInfo.Attempt)
+	}
 	e, err := newStatusChange(StatusChangeTypeAlienTask, info, deploymentID, strings.ToLower(status))
 	if err != nil {
 		return "", err
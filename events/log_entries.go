@@ -72,6 +72,9 @@ const (
 
 	// TaskExecutionID is the field type representing the task execution ID in log entry
 	TaskExecutionID
+
+	// Attempt is the field type representing the attempt number of a retried workflow step activity in log entry
+	Attempt
 )
 
 // String allows to stringify the field type enumeration in JSON standard
@@ -93,6 +96,8 @@ func (ft FieldType) String() string {
 		return "type"
 	case TaskExecutionID:
 		return "alienTaskId"
+	case Attempt:
+		return "attempt"
 	}
 	return ""
 }
@@ -153,7 +158,7 @@ func (e LogEntry) Register(content []byte) {
 	if e.deploymentID == "" {
 		log.Panic("The deploymentID parameter must be filled")
 	}
-	e.content = content
+	e.content = maskSecrets(e.deploymentID, e.additionalInfo, content)
 
 	// Get the timestamp
 	e.timestamp = time.Now()
@@ -0,0 +1,195 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// SecretMask is the replacement of secret values in log entries
+const SecretMask = "****"
+
+// minSecretLength is the minimal length of a secret value to be masked, shorter values would lead to mask too much content
+const minSecretLength = 4
+
+type secretsRegistry struct {
+	lock sync.RWMutex
+	// secrets reference counts by execution ID and value
+	secrets map[string]map[string]int
+	// deploymentSecrets are the secrets masked in all log entries of a deployment by deployment ID
+	deploymentSecrets map[string]map[string]struct{}
+	patterns          []*regexp.Regexp
+}
+
+var secrets = &secretsRegistry{secrets: make(map[string]map[string]int), deploymentSecrets: make(map[string]map[string]struct{})}
+
+// RegisterSecrets registers values that should be masked in log entries of the execution (see ExecutionID) of the given context.
+//
+// The returned function should be called to stop masking those values once they could no more appear in logs.
+// Secrets registered several times are masked until each registration is released.
+func RegisterSecrets(ctx context.Context, values ...string) func() {
+	lof, _ := FromContext(ctx)
+	execID, ok := lof[ExecutionID]
+	if !ok {
+		return func() {}
+	}
+	return secrets.register(fmt.Sprint(execID), values)
+}
+
+// RegisterDeploymentSecrets registers values that should be masked in all log entries of the given deployment.
+//
+// This is used for secrets resolved without an execution context, typically by get_secret functions,
+// they are masked until UnregisterDeploymentSecrets is called for this deployment.
+func RegisterDeploymentSecrets(deploymentID string, values ...string) {
+	secrets.lock.Lock()
+	defer secrets.lock.Unlock()
+	for _, v := range values {
+		if len(v) < minSecretLength {
+			continue
+		}
+		if secrets.deploymentSecrets[deploymentID] == nil {
+			secrets.deploymentSecrets[deploymentID] = make(map[string]struct{})
+		}
+		secrets.deploymentSecrets[deploymentID][v] = struct{}{}
+	}
+}
+
+// UnregisterDeploymentSecrets stops masking the values registered for a deployment using RegisterDeploymentSecrets
+func UnregisterDeploymentSecrets(deploymentID string) {
+	secrets.lock.Lock()
+	defer secrets.lock.Unlock()
+	delete(secrets.deploymentSecrets, deploymentID)
+}
+
+// SetSecretsPatterns sets regular expressions of sensitive content to mask in all log entries.
+//
+// If a regular expression has capturing groups only the matching groups are masked otherwise the whole match is masked.
+func SetSecretsPatterns(patterns []string) error {
+	regexps := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return errors.Wrapf(err, "invalid secrets pattern %q", p)
+		}
+		regexps = append(regexps, r)
+	}
+	secrets.lock.Lock()
+	defer secrets.lock.Unlock()
+	secrets.patterns = regexps
+	return nil
+}
+
+func (sr *secretsRegistry) register(execID string, values []string) func() {
+	registered := make([]string, 0, len(values))
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+	for _, v := range values {
+		if len(v) < minSecretLength {
+			continue
+		}
+		if sr.secrets[execID] == nil {
+			sr.secrets[execID] = make(map[string]int)
+		}
+		sr.secrets[execID][v]++
+		registered = append(registered, v)
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			sr.lock.Lock()
+			defer sr.lock.Unlock()
+			for _, v := range registered {
+				sr.secrets[execID][v]--
+				if sr.secrets[execID][v] <= 0 {
+					delete(sr.secrets[execID], v)
+				}
+			}
+			if len(sr.secrets[execID]) == 0 {
+				delete(sr.secrets, execID)
+			}
+		})
+	}
+}
+
+// mask replaces secrets of the given execution and deployment and content matching secrets patterns by SecretMask
+func (sr *secretsRegistry) mask(execID, deploymentID string, content []byte) []byte {
+	sr.lock.RLock()
+	defer sr.lock.RUnlock()
+	if len(sr.secrets[execID]) == 0 && len(sr.deploymentSecrets[deploymentID]) == 0 && len(sr.patterns) == 0 {
+		return content
+	}
+	values := make([]string, 0, len(sr.secrets[execID])+len(sr.deploymentSecrets[deploymentID]))
+	for v := range sr.secrets[execID] {
+		values = append(values, v)
+	}
+	for v := range sr.deploymentSecrets[deploymentID] {
+		if _, ok := sr.secrets[execID][v]; !ok {
+			values = append(values, v)
+		}
+	}
+	// Longest values first as a secret may contain another one
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+	s := string(content)
+	for _, v := range values {
+		s = strings.Replace(s, v, SecretMask, -1)
+	}
+	for _, r := range sr.patterns {
+		s = maskPattern(r, s)
+	}
+	return []byte(s)
+}
+
+func maskPattern(r *regexp.Regexp, s string) string {
+	if r.NumSubexp() == 0 {
+		return r.ReplaceAllLiteralString(s, SecretMask)
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range r.FindAllStringSubmatchIndex(s, -1) {
+		for g := 1; g <= r.NumSubexp(); g++ {
+			start, end := loc[2*g], loc[2*g+1]
+			if start < last || start < 0 {
+				// unmatched or nested group
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(SecretMask)
+			last = end
+		}
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// maskSecrets masks secrets in the given content for the given deployment and the execution referenced in the given log optional fields
+func maskSecrets(deploymentID string, fields LogOptionalFields, content []byte) []byte {
+	var execID string
+	if id, ok := fields[ExecutionID]; ok {
+		execID = fmt.Sprint(id)
+	}
+	return secrets.mask(execID, deploymentID, content)
+}
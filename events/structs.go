@@ -71,6 +71,8 @@ const (
 	ETaskExecutionID
 	// EWorkflowStepID is event information related to workflow step
 	EWorkflowStepID
+	// EAttempt is event information related to the attempt number of a retried workflow step activity
+	EAttempt
 )
 
 func (i InfoType) String() string {
@@ -105,6 +107,8 @@ func (i InfoType) String() string {
 		return "alienTaskId"
 	case EWorkflowStepID:
 		return "stepId"
+	case EAttempt:
+		return "attempt"
 	}
 	return ""
 }
@@ -127,6 +131,7 @@ type WorkflowStepInfo struct {
 	OperationName    string `json:"operation_name,omitempty"`
 	TargetNodeID     string `json:"target_node_id,omitempty"`
 	TargetInstanceID string `json:"target_instance_id,omitempty"`
+	Attempt          int    `json:"attempt,omitempty"`
 }
 
 // Create a KVPair corresponding to an event and put it to Consul under the event prefix,
@@ -0,0 +1,329 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+)
+
+// maxConcurrentDeliveries limits the number of events being delivered in parallel
+const maxConcurrentDeliveries = 20
+
+var defaultDispatcher *dispatcher
+
+type dispatcher struct {
+	cc                *api.Client
+	httpClient        *http.Client
+	chStopDispatching chan struct{}
+	chShutdown        chan struct{}
+	isActive          bool
+	isActiveLock      sync.Mutex
+	serviceKey        string
+	maxAttempts       int
+	initialBackoff    time.Duration
+	maxBackoff        time.Duration
+	deliveries        chan struct{}
+	// wg tracks in-flight deliveries, shutdownLock prevents new ones to be added once shutting down
+	wg           sync.WaitGroup
+	shutdownLock sync.Mutex
+}
+
+func newDispatcher(cfg config.Configuration, cc *api.Client) *dispatcher {
+	d := &dispatcher{
+		cc:             cc,
+		chShutdown:     make(chan struct{}),
+		serviceKey:     path.Join(consulutil.YorcServicePrefix, "/webhooks/leader"),
+		maxAttempts:    cfg.Webhooks.MaxAttempts,
+		initialBackoff: cfg.Webhooks.InitialBackoff,
+		maxBackoff:     cfg.Webhooks.MaxBackoff,
+		deliveries:     make(chan struct{}, maxConcurrentDeliveries),
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = config.DefaultWebhooksMaxAttempts
+	}
+	if d.initialBackoff <= 0 {
+		d.initialBackoff = config.DefaultWebhooksInitialBackoff
+	}
+	if d.maxBackoff <= 0 {
+		d.maxBackoff = config.DefaultWebhooksMaxBackoff
+	}
+	timeout := cfg.Webhooks.Timeout
+	if timeout <= 0 {
+		timeout = config.DefaultWebhooksTimeout
+	}
+	d.httpClient = &http.Client{Timeout: timeout}
+	return d
+}
+
+// Start allows to instantiate a default webhooks dispatcher delivering status change events to subscribed webhooks
+//
+// Only the leader Yorc server of the cluster delivers events.
+func Start(cfg config.Configuration, cc *api.Client) {
+	defaultDispatcher = newDispatcher(cfg, cc)
+	// Watch leader election for webhooks dispatcher
+	go consulutil.WatchLeaderElection(defaultDispatcher.cc, defaultDispatcher.serviceKey, defaultDispatcher.chShutdown, defaultDispatcher.startDispatching, defaultDispatcher.stopDispatching)
+}
+
+// Stop allows to stop delivering events to webhooks
+func Stop() {
+	defaultDispatcher.stopDispatching()
+
+	// Stop watch leader election and deliveries retries
+	defaultDispatcher.shutdownLock.Lock()
+	close(defaultDispatcher.chShutdown)
+	defaultDispatcher.shutdownLock.Unlock()
+	// Wait for in-flight deliveries
+	defaultDispatcher.wg.Wait()
+}
+
+func handleError(err error) {
+	err = errors.Wrap(err, "[WARN] Error during webhooks events dispatching")
+	log.Print(err)
+	log.Debugf("%+v", err)
+}
+
+func (d *dispatcher) stopDispatching() {
+	d.isActiveLock.Lock()
+	defer d.isActiveLock.Unlock()
+	if d.isActive {
+		log.Debugf("Webhooks dispatcher is about to be stopped")
+		close(d.chStopDispatching)
+		d.isActive = false
+	}
+}
+
+func (d *dispatcher) isStopped() bool {
+	select {
+	case <-d.chStopDispatching:
+		return true
+	case <-d.chShutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+func (d *dispatcher) startDispatching() {
+	d.isActiveLock.Lock()
+	if d.isActive {
+		d.isActiveLock.Unlock()
+		log.Println("Webhooks dispatcher is already running.")
+		return
+	}
+	d.isActive = true
+	d.chStopDispatching = make(chan struct{})
+	d.isActiveLock.Unlock()
+	log.Debugf("Webhooks dispatcher is now running.")
+
+	go func() {
+		waitIndex, err := d.getLastIndex()
+		if err != nil {
+			handleError(err)
+		}
+		for !d.isStopped() {
+			kvps, rMeta, err := d.cc.KV().List(consulutil.EventsPrefix+"/", &api.QueryOptions{WaitIndex: waitIndex, WaitTime: time.Minute})
+			if err != nil {
+				handleError(err)
+				time.Sleep(time.Second)
+				continue
+			}
+			if waitIndex == rMeta.LastIndex {
+				// long pool ended due to a timeout
+				continue
+			}
+			if waitIndex == 0 {
+				// First start: do not deliver past events
+				waitIndex = rMeta.LastIndex
+				continue
+			}
+			newEvents := make([]*api.KVPair, 0)
+			for _, kvp := range kvps {
+				if kvp.ModifyIndex > waitIndex {
+					newEvents = append(newEvents, kvp)
+				}
+			}
+			if len(newEvents) > 0 {
+				subscriptions, err := ListSubscriptions(d.cc.KV())
+				if err != nil {
+					handleError(err)
+					time.Sleep(time.Second)
+					continue
+				}
+				for _, kvp := range newEvents {
+					d.dispatchEvent(subscriptions, kvp.Value)
+				}
+			}
+			waitIndex = rMeta.LastIndex
+			if err = d.storeLastIndex(waitIndex); err != nil {
+				handleError(err)
+			}
+		}
+	}()
+}
+
+func (d *dispatcher) getLastIndex() (uint64, error) {
+	kvp, _, err := d.cc.KV().Get(lastIndexKey, nil)
+	if err != nil || kvp == nil {
+		return 0, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	idx, err := strconv.ParseUint(string(kvp.Value), 10, 64)
+	return idx, errors.Wrapf(err, "invalid webhooks last index %q", string(kvp.Value))
+}
+
+func (d *dispatcher) storeLastIndex(idx uint64) error {
+	_, err := d.cc.KV().Put(&api.KVPair{Key: lastIndexKey, Value: []byte(strconv.FormatUint(idx, 10))}, nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+func (d *dispatcher) dispatchEvent(subscriptions []*Subscription, event []byte) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(event, &data); err != nil {
+		handleError(errors.Wrapf(err, "failed to parse event %q", string(event)))
+		return
+	}
+	eventTypeName := fmt.Sprint(data[events.EType.String()])
+	eventType, err := events.ParseStatusChangeType(eventTypeName)
+	if err != nil {
+		handleError(err)
+		return
+	}
+	deploymentID := fmt.Sprint(data[events.EDeploymentID.String()])
+	for _, s := range subscriptions {
+		if !s.matches(eventType, deploymentID) {
+			continue
+		}
+		// Limit concurrent deliveries, this blocks events polling when webhooks are slow
+		d.deliveries <- struct{}{}
+		if !d.addDelivery() {
+			<-d.deliveries
+			return
+		}
+		go func(s *Subscription) {
+			defer func() {
+				<-d.deliveries
+				d.wg.Done()
+			}()
+			d.deliver(s, eventTypeName, event)
+		}(s)
+	}
+}
+
+// addDelivery registers a new in-flight delivery, it returns false if the dispatcher is shutting down
+func (d *dispatcher) addDelivery() bool {
+	d.shutdownLock.Lock()
+	defer d.shutdownLock.Unlock()
+	select {
+	case <-d.chShutdown:
+		return false
+	default:
+	}
+	d.wg.Add(1)
+	return true
+}
+
+// deliver posts an event to a webhook, retrying with an exponential backoff, and records a dead letter if all attempts failed
+func (d *dispatcher) deliver(s *Subscription, eventType string, event []byte) {
+	deliveryID := fmt.Sprint(uuid.NewV4())
+	firstAttempt := time.Now()
+	backoff := d.initialBackoff
+	var err error
+	attempt := 1
+	for ; attempt <= d.maxAttempts; attempt++ {
+		err = d.post(s, deliveryID, eventType, event)
+		if err == nil {
+			log.Debugf("Event delivered to webhook %q (delivery %q, attempt %d)", s.ID, deliveryID, attempt)
+			return
+		}
+		log.Debugf("Failed to deliver event to webhook %q (delivery %q, attempt %d/%d): %v", s.ID, deliveryID, attempt, d.maxAttempts, err)
+		if attempt == d.maxAttempts {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-d.chShutdown:
+			// Do not record a dead letter on shutdown, the event was not really rejected
+			return
+		}
+		backoff *= 2
+		if backoff > d.maxBackoff {
+			backoff = d.maxBackoff
+		}
+	}
+	log.Printf("[WARN] Failed to deliver event to webhook %q after %d attempts, recording a dead letter %q: %v", s.ID, attempt, deliveryID, err)
+	dlErr := storeDeadLetter(d.cc.KV(), &DeadLetter{
+		DeliveryID:     deliveryID,
+		SubscriptionID: s.ID,
+		Event:          json.RawMessage(event),
+		Attempts:       attempt,
+		LastError:      err.Error(),
+		FirstAttempt:   firstAttempt.Format(time.RFC3339Nano),
+		LastAttempt:    time.Now().Format(time.RFC3339Nano),
+	})
+	if dlErr != nil {
+		handleError(dlErr)
+	}
+}
+
+// Sign computes the signature of a payload as sent in the SignatureHeader
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *dispatcher) post(s *Subscription, deliveryID, eventType string, event []byte) error {
+	req, err := http.NewRequest("POST", s.URL, bytes.NewReader(event))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(SubscriptionHeader, s.ID)
+	req.Header.Set(EventTypeHeader, eventType)
+	if s.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(s.Secret, event))
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected HTTP status %q", resp.Status)
+	}
+	return nil
+}
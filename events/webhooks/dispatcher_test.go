@@ -0,0 +1,119 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+)
+
+func newTestDispatcher(cc *api.Client) *dispatcher {
+	return newDispatcher(config.Configuration{Webhooks: config.Webhooks{
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		Timeout:        time.Second,
+	}}, cc)
+}
+
+func testSubscriptionsStore(t *testing.T, cc *api.Client) {
+	kv := cc.KV()
+	s := &Subscription{ID: "testSubscriptionsStore", URL: "http://127.0.0.1/hook", EventTypes: []string{"deployment"}}
+	require.NoError(t, StoreSubscription(kv, s))
+
+	actual, err := GetSubscription(kv, s.ID)
+	require.NoError(t, err)
+	assert.Equal(t, s, actual)
+
+	subscriptions, err := ListSubscriptions(kv)
+	require.NoError(t, err)
+	assert.Contains(t, subscriptions, s)
+
+	require.Error(t, StoreSubscription(kv, &Subscription{ID: "invalid"}))
+
+	require.NoError(t, DeleteSubscription(kv, s.ID))
+	actual, err = GetSubscription(kv, s.ID)
+	require.NoError(t, err)
+	assert.Nil(t, actual)
+}
+
+func testDeliverSuccess(t *testing.T, cc *api.Client) {
+	var calls int32
+	event := []byte(`{"type":"Deployment","deploymentId":"dep","status":"deployed"}`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first attempt to check retries
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, event, body)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "testDeliverSuccess", r.Header.Get(SubscriptionHeader))
+		assert.Equal(t, "Deployment", r.Header.Get(EventTypeHeader))
+		assert.NotEmpty(t, r.Header.Get(DeliveryHeader))
+		assert.Equal(t, Sign("mysecret", event), r.Header.Get(SignatureHeader))
+	}))
+	defer ts.Close()
+
+	d := newTestDispatcher(cc)
+	s := &Subscription{ID: "testDeliverSuccess", URL: ts.URL, Secret: "mysecret"}
+	d.deliver(s, "Deployment", event)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	deadLetters, err := ListDeadLetters(cc.KV(), s.ID)
+	require.NoError(t, err)
+	assert.Len(t, deadLetters, 0)
+}
+
+func testDeliverDeadLetter(t *testing.T, cc *api.Client) {
+	var calls int32
+	event := []byte(`{"type":"Deployment","deploymentId":"dep","status":"deployed"}`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	d := newTestDispatcher(cc)
+	s := &Subscription{ID: "testDeliverDeadLetter", URL: ts.URL}
+	d.deliver(s, "Deployment", event)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	deadLetters, err := ListDeadLetters(cc.KV(), s.ID)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	dl := deadLetters[0]
+	assert.Equal(t, s.ID, dl.SubscriptionID)
+	assert.Equal(t, 3, dl.Attempts)
+	assert.Contains(t, dl.LastError, "500")
+	assert.JSONEq(t, string(event), string(dl.Event))
+
+	require.NoError(t, DeleteDeadLetter(cc.KV(), s.ID, dl.DeliveryID))
+	deadLetters, err = ListDeadLetters(cc.KV(), s.ID)
+	require.NoError(t, err)
+	assert.Len(t, deadLetters, 0)
+}
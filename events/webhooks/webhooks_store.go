@@ -0,0 +1,119 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"encoding/json"
+	"path"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/consulutil"
+)
+
+var subscriptionsPrefix = path.Join(consulutil.WebhooksKVPrefix, "subscriptions")
+var deadLettersPrefix = path.Join(consulutil.WebhooksKVPrefix, "dead_letters")
+var lastIndexKey = path.Join(consulutil.WebhooksKVPrefix, "last_index")
+
+// StoreSubscription validates and stores (creates or replaces) a subscription
+func StoreSubscription(kv *api.KV, s *Subscription) error {
+	if s.ID == "" {
+		return errors.New("missing webhook subscription id")
+	}
+	err := s.Validate()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal webhook subscription %q", s.ID)
+	}
+	_, err = kv.Put(&api.KVPair{Key: path.Join(subscriptionsPrefix, s.ID), Value: b}, nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+// GetSubscription returns a subscription or nil if it does not exist
+func GetSubscription(kv *api.KV, id string) (*Subscription, error) {
+	kvp, _, err := kv.Get(path.Join(subscriptionsPrefix, id), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil {
+		return nil, nil
+	}
+	s := new(Subscription)
+	err = json.Unmarshal(kvp.Value, s)
+	return s, errors.Wrapf(err, "failed to unmarshal webhook subscription %q", id)
+}
+
+// ListSubscriptions returns all the registered subscriptions
+func ListSubscriptions(kv *api.KV) ([]*Subscription, error) {
+	kvps, _, err := kv.List(subscriptionsPrefix+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	subscriptions := make([]*Subscription, 0, len(kvps))
+	for _, kvp := range kvps {
+		s := new(Subscription)
+		if err = json.Unmarshal(kvp.Value, s); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal webhook subscription %q", path.Base(kvp.Key))
+		}
+		subscriptions = append(subscriptions, s)
+	}
+	return subscriptions, nil
+}
+
+// DeleteSubscription deletes a subscription and its dead letters
+func DeleteSubscription(kv *api.KV, id string) error {
+	_, err := kv.Delete(path.Join(subscriptionsPrefix, id), nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	_, err = kv.DeleteTree(path.Join(deadLettersPrefix, id)+"/", nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+func storeDeadLetter(kv *api.KV, dl *DeadLetter) error {
+	b, err := json.Marshal(dl)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal webhook dead letter %q", dl.DeliveryID)
+	}
+	_, err = kv.Put(&api.KVPair{Key: path.Join(deadLettersPrefix, dl.SubscriptionID, dl.DeliveryID), Value: b}, nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+// ListDeadLetters returns the events that could not be delivered to a given subscription
+func ListDeadLetters(kv *api.KV, subscriptionID string) ([]*DeadLetter, error) {
+	kvps, _, err := kv.List(path.Join(deadLettersPrefix, subscriptionID)+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	deadLetters := make([]*DeadLetter, 0, len(kvps))
+	for _, kvp := range kvps {
+		dl := new(DeadLetter)
+		if err = json.Unmarshal(kvp.Value, dl); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal webhook dead letter %q", path.Base(kvp.Key))
+		}
+		deadLetters = append(deadLetters, dl)
+	}
+	return deadLetters, nil
+}
+
+// DeleteDeadLetter deletes a dead letter of a given subscription
+func DeleteDeadLetter(kv *api.KV, subscriptionID, deliveryID string) error {
+	_, err := kv.Delete(path.Join(deadLettersPrefix, subscriptionID, deliveryID), nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+)
+
+const (
+	// SignatureHeader is the HTTP header containing the HMAC-SHA256 signature of a delivered event
+	SignatureHeader = "X-Yorc-Signature"
+	// DeliveryHeader is the HTTP header containing the unique ID of a delivery
+	DeliveryHeader = "X-Yorc-Delivery"
+	// SubscriptionHeader is the HTTP header containing the ID of the subscription of a delivery
+	SubscriptionHeader = "X-Yorc-Webhook"
+	// EventTypeHeader is the HTTP header containing the type of the delivered event
+	EventTypeHeader = "X-Yorc-Event-Type"
+)
+
+// A Subscription registers a webhook URL to be notified of status change events.
+//
+// EventTypes and Deployments are optional filters, if empty events of any type and any deployment are delivered.
+type Subscription struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"event_types,omitempty"`
+	Deployments []string `json:"deployments,omitempty"`
+	// Secret is used to sign deliveries, it is never returned by the REST API
+	Secret string `json:"secret,omitempty"`
+}
+
+// Validate checks that a subscription is well-formed
+func (s *Subscription) Validate() error {
+	if s.URL == "" {
+		return errors.New("missing webhook url")
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid webhook url %q", s.URL)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("invalid webhook url %q, an absolute http or https url is expected", s.URL)
+	}
+	for _, t := range s.EventTypes {
+		if _, err := events.ParseStatusChangeType(t); err != nil {
+			return errors.Wrap(err, "invalid event type filter")
+		}
+	}
+	return nil
+}
+
+// matches checks if an event should be delivered to this subscription
+func (s *Subscription) matches(eventType events.StatusChangeType, deploymentID string) bool {
+	if deployments.IsDryRunID(deploymentID) || deployments.IsUpdateStagingID(deploymentID) {
+		// Temporary deployments are never notified
+		return false
+	}
+	if len(s.EventTypes) > 0 {
+		found := false
+		for _, t := range s.EventTypes {
+			if sct, err := events.ParseStatusChangeType(t); err == nil && sct == eventType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(s.Deployments) > 0 {
+		for _, d := range s.Deployments {
+			if d == deploymentID {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// A DeadLetter records an event that could not be delivered to a webhook after all retries
+type DeadLetter struct {
+	DeliveryID     string          `json:"delivery_id"`
+	SubscriptionID string          `json:"subscription_id"`
+	Event          json.RawMessage `json:"event"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"last_error"`
+	FirstAttempt   string          `json:"first_attempt"`
+	LastAttempt    string          `json:"last_attempt"`
+}
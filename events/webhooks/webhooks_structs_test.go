@@ -0,0 +1,80 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ystia/yorc/events"
+)
+
+func TestSubscriptionValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		subscription Subscription
+		wantErr      bool
+	}{
+		{"Valid", Subscription{URL: "https://example.com/hook"}, false},
+		{"ValidWithFilters", Subscription{URL: "http://example.com:8080/hook", EventTypes: []string{"deployment", "Workflow"}, Deployments: []string{"dep"}}, false},
+		{"MissingURL", Subscription{}, true},
+		{"RelativeURL", Subscription{URL: "/hook"}, true},
+		{"UnsupportedScheme", Subscription{URL: "ftp://example.com/hook"}, true},
+		{"InvalidEventType", Subscription{URL: "https://example.com/hook", EventTypes: []string{"unknown"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.subscription.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSubscriptionMatches(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		subscription Subscription
+		eventType    events.StatusChangeType
+		deploymentID string
+		want         bool
+	}{
+		{"NoFilters", Subscription{}, events.StatusChangeTypeInstance, "dep1", true},
+		{"MatchingType", Subscription{EventTypes: []string{"deployment"}}, events.StatusChangeTypeDeployment, "dep1", true},
+		{"NotMatchingType", Subscription{EventTypes: []string{"deployment"}}, events.StatusChangeTypeWorkflow, "dep1", false},
+		{"MatchingDeployment", Subscription{Deployments: []string{"dep0", "dep1"}}, events.StatusChangeTypeWorkflow, "dep1", true},
+		{"NotMatchingDeployment", Subscription{Deployments: []string{"dep0"}}, events.StatusChangeTypeWorkflow, "dep1", false},
+		{"MatchingBoth", Subscription{EventTypes: []string{"Workflow"}, Deployments: []string{"dep1"}}, events.StatusChangeTypeWorkflow, "dep1", true},
+		{"DryRunDeployment", Subscription{}, events.StatusChangeTypeDeployment, "dep1.dry-run", false},
+		{"PendingUpdate", Subscription{}, events.StatusChangeTypeDeployment, "dep1.update", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.subscription.matches(tt.eventType, tt.deploymentID))
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+	// Computed using: echo -n '{"a":"b"}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=5782eb1e80a9c0cc789474a5ab9faf38384866cac1c0151663b2ec9d0082743e", Sign("secret", []byte(`{"a":"b"}`)))
+}
@@ -49,3 +49,6 @@ const YorcServicePrefix = yorcPrefix + "/service"
 
 // SchedulingKVPrefix is the prefix in Consul KV store for scheduling
 const SchedulingKVPrefix string = yorcPrefix + "/scheduling"
+
+// WebhooksKVPrefix is the prefix in Consul KV store for webhooks subscriptions and deliveries
+const WebhooksKVPrefix string = yorcPrefix + "/webhooks"
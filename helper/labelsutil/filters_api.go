@@ -40,6 +40,22 @@ func MatchesAll(labels map[string]string, filters ...Filter) (bool, Warning) {
 }
 
 // CreateFilter creates a Filter from a given input string
+//
+// The returned Filter also implements fmt.Stringer, its String method returns the input string.
 func CreateFilter(filter string) (Filter, error) {
-	return internal.FilterFromString(filter)
+	f, err := internal.FilterFromString(filter)
+	if err != nil {
+		return nil, err
+	}
+	return &stringFilter{Filter: f, input: filter}, nil
+}
+
+// stringFilter keeps the input string of a filter so that it could be stored and created again
+type stringFilter struct {
+	*internal.Filter
+	input string
+}
+
+func (f *stringFilter) String() string {
+	return f.input
 }
@@ -16,6 +16,7 @@ package sshutil
 
 import (
 	"fmt"
+	"github.com/pkg/errors"
 	"golang.org/x/crypto/ssh"
 	"net"
 	"strconv"
@@ -50,9 +51,9 @@ func (p *pool) openSession(client *SSHClient) (*ssh.Session, error) {
 	}
 
 	addr := fmt.Sprintf("%s:%d", client.Host, client.Port)
-	k := getUserKey(addr, client.Config)
+	k := getUserKey(addr, client.Config, client.Bastion)
 	for {
-		c := p.getConn(k, addr, client.Config, deadline)
+		c := p.getConn(k, addr, client.Config, client.Bastion, deadline)
 		if c.err != nil {
 			p.removeConn(k, c)
 			return nil, c.err
@@ -87,7 +88,7 @@ func (c *conn) newSession(deadline time.Time) (*ssh.Session, error) {
 
 // getConn gets an ssh connection from the pool for key.
 // If none is available, it dials anew.
-func (p *pool) getConn(k, addr string, config *ssh.ClientConfig, deadline time.Time) *conn {
+func (p *pool) getConn(k, addr string, config *ssh.ClientConfig, bastion *BastionHost, deadline time.Time) *conn {
 	p.mu.Lock()
 	if p.tab == nil {
 		p.tab = make(map[string]*conn)
@@ -101,7 +102,7 @@ func (p *pool) getConn(k, addr string, config *ssh.ClientConfig, deadline time.T
 	c = &conn{ok: make(chan bool)}
 	p.tab[k] = c
 	p.mu.Unlock()
-	c.netC, c.c, c.err = p.dial("tcp", addr, config, deadline)
+	c.netC, c.c, c.err = p.dial("tcp", addr, config, bastion, deadline)
 	close(c.ok)
 	return c
 }
@@ -116,7 +117,10 @@ func (p *pool) removeConn(k string, c1 *conn) {
 	}
 }
 
-func (p *pool) dial(network, addr string, config *ssh.ClientConfig, deadline time.Time) (net.Conn, *ssh.Client, error) {
+func (p *pool) dial(network, addr string, config *ssh.ClientConfig, bastion *BastionHost, deadline time.Time) (net.Conn, *ssh.Client, error) {
+	if bastion != nil {
+		return p.dialThroughBastion(network, addr, config, bastion, deadline)
+	}
 	dialer := net.Dialer{Deadline: deadline}
 	dial := dialer.Dial
 	netC, err := dial(network, addr)
@@ -132,6 +136,41 @@ func (p *pool) dial(network, addr string, config *ssh.ClientConfig, deadline tim
 	return netC, sshC, nil
 }
 
-func getUserKey(addr string, config *ssh.ClientConfig) string {
-	return strconv.Quote(addr) + "-" + strconv.Quote(config.User)
+// dialThroughBastion connects to the given address using a connection tunneled through a bastion host,
+// connections to bastions are closed when the connection to the address is closed
+func (p *pool) dialThroughBastion(network, addr string, config *ssh.ClientConfig, bastion *BastionHost, deadline time.Time) (net.Conn, *ssh.Client, error) {
+	bastionConfig, err := bastion.getClientConfig(config.Timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	bastionAddr := fmt.Sprintf("%s:%d", bastion.Host, bastion.getPort())
+	_, bastionC, err := p.dial(network, bastionAddr, bastionConfig, bastion.Bastion, deadline)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to connect to bastion host %q", bastionAddr)
+	}
+	netC, err := bastionC.Dial(network, addr)
+	if err != nil {
+		bastionC.Close()
+		return nil, nil, errors.Wrapf(err, "failed to reach %q through bastion host %q", addr, bastionAddr)
+	}
+	conn, chans, reqs, err := ssh.NewClientConn(netC, addr, config)
+	if err != nil {
+		netC.Close()
+		bastionC.Close()
+		return nil, nil, err
+	}
+	sshC := ssh.NewClient(conn, chans, reqs)
+	go func() {
+		sshC.Wait()
+		bastionC.Close()
+	}()
+	return netC, sshC, nil
+}
+
+func getUserKey(addr string, config *ssh.ClientConfig, bastion *BastionHost) string {
+	k := strconv.Quote(addr) + "-" + strconv.Quote(config.User)
+	if bastion != nil {
+		k += "-" + strconv.Quote(bastion.String())
+	}
+	return k
 }
@@ -36,6 +36,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"time"
 )
 
 // Client is interface allowing running command
@@ -55,6 +56,73 @@ type SSHClient struct {
 	Config *ssh.ClientConfig
 	Host   string
 	Port   int
+	// Bastion is an optional jump host used to reach Host
+	Bastion *BastionHost
+}
+
+// BastionHost holds information needed to connect to a host used as a jump host to reach another one
+type BastionHost struct {
+	Host string
+	// Port defaults to 22 if set to 0
+	Port int
+	// User defaults to root if empty
+	User string
+	// One of Password or PrivateKey is required
+	Password string
+	// PrivateKey is either a path to a private key file or the content of this file
+	PrivateKey string
+	// Bastion allows to chain bastions, it is the jump host used to reach this one
+	Bastion *BastionHost
+}
+
+// String returns the bastion hosts chain as "user@host:port" elements separated by ","
+// starting from the first bastion to connect to
+func (b *BastionHost) String() string {
+	if b == nil {
+		return ""
+	}
+	s := fmt.Sprintf("%s@%s:%d", b.getUser(), b.Host, b.getPort())
+	if b.Bastion != nil {
+		s = b.Bastion.String() + "," + s
+	}
+	return s
+}
+
+func (b *BastionHost) getUser() string {
+	if b.User == "" {
+		return "root"
+	}
+	return b.User
+}
+
+func (b *BastionHost) getPort() int {
+	if b.Port == 0 {
+		return 22
+	}
+	return b.Port
+}
+
+// getClientConfig returns the SSH configuration used to connect to the bastion host
+func (b *BastionHost) getClientConfig(timeout time.Duration) (*ssh.ClientConfig, error) {
+	conf := &ssh.ClientConfig{
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		User:            b.getUser(),
+		Timeout:         timeout,
+	}
+	if b.PrivateKey != "" {
+		keyAuth, err := ReadPrivateKey(b.PrivateKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read private key of bastion host %q", b.Host)
+		}
+		conf.Auth = append(conf.Auth, keyAuth)
+	}
+	if b.Password != "" {
+		conf.Auth = append(conf.Auth, ssh.Password(b.Password))
+	}
+	if len(conf.Auth) == 0 {
+		return nil, errors.Errorf("one of password or private key is required to connect to bastion host %q", b.Host)
+	}
+	return conf, nil
 }
 
 // SSHAgent is an SSH agent
@@ -175,12 +243,14 @@ func ToPrivateKeyContent(pk string) ([]byte, error) {
 
 // CopyFile allows to copy a reader over SSH with defined remote path and specific permissions
 func (client *SSHClient) CopyFile(source io.Reader, remotePath, permissions string) error {
-	// Create a new SCP client
+	// Create a new SCP client using a pooled session, this allows to reach
+	// the remote host through bastions
 	scpHostPort := fmt.Sprintf("%s:%d", client.Host, client.Port)
 	scpClient := scp.NewClient(scpHostPort, client.Config)
 
 	// Connect to the remote server
-	err := scpClient.Connect()
+	var err error
+	scpClient.Session, err = client.newSession()
 	if err != nil {
 		return errors.Wrapf(err, "Couldn't establish a connection to the remote host:%q", scpHostPort)
 	}
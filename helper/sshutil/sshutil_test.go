@@ -73,3 +73,23 @@ func TestReadPrivateKey(t *testing.T) {
 	_, err := ReadPrivateKey("./testdata/test.pem")
 	require.NotNil(t, err)
 }
+
+func TestBastionHost(t *testing.T) {
+	var nilBastion *BastionHost
+	require.Equal(t, "", nilBastion.String())
+
+	bastion := &BastionHost{Host: "bastion1", Password: "pass", Bastion: &BastionHost{Host: "bastion2", Port: 2222, User: "admin", Password: "pass"}}
+	require.Equal(t, "admin@bastion2:2222,root@bastion1:22", bastion.String())
+
+	conf, err := bastion.getClientConfig(0)
+	require.Nil(t, err)
+	require.Equal(t, "root", conf.User)
+	require.Len(t, conf.Auth, 1)
+
+	_, err = (&BastionHost{Host: "bastion3"}).getClientConfig(0)
+	require.NotNil(t, err, "expected an error as there is neither password nor private key")
+
+	clientConfig := &ssh.ClientConfig{User: "user"}
+	require.NotEqual(t, getUserKey("host:22", clientConfig, nil), getUserKey("host:22", clientConfig, bastion),
+		"connections through bastions should not be shared with direct connections")
+}
@@ -18,6 +18,8 @@ import (
 	"github.com/ystia/yorc/commands"
 	_ "github.com/ystia/yorc/commands/bootstrap"
 	_ "github.com/ystia/yorc/commands/deployments"
+	_ "github.com/ystia/yorc/commands/deployments/checks"
+	_ "github.com/ystia/yorc/commands/deployments/schedules"
 	_ "github.com/ystia/yorc/commands/deployments/tasks"
 	_ "github.com/ystia/yorc/commands/deployments/workflows"
 	_ "github.com/ystia/yorc/commands/hostspool"
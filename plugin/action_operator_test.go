@@ -0,0 +1,207 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/prov"
+)
+
+type mockActionOperator struct {
+	execActionCalled     bool
+	ctx                  context.Context
+	conf                 config.Configuration
+	taskID, deploymentID string
+	action               *prov.Action
+	contextCancelled     bool
+	lof                  events.LogOptionalFields
+}
+
+func (m *mockActionOperator) ExecAction(ctx context.Context, conf config.Configuration, taskID, deploymentID string, action *prov.Action) (bool, error) {
+	m.execActionCalled = true
+	m.ctx = ctx
+	m.conf = conf
+	m.taskID = taskID
+	m.deploymentID = deploymentID
+	m.action = action
+	m.lof, _ = events.FromContext(ctx)
+
+	go func() {
+		<-m.ctx.Done()
+		m.contextCancelled = true
+	}()
+	if m.deploymentID == "TestCancel" {
+		<-m.ctx.Done()
+	}
+	if m.deploymentID == "TestFailure" {
+		return true, NewRPCError(errors.New("a failure occurred during plugin exec action"))
+	}
+	return action.Data["done"] == "true", nil
+}
+
+func TestActionOperatorExecAction(t *testing.T) {
+	t.Parallel()
+	mock := new(mockActionOperator)
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		ActionPluginName: &ActionOperatorPlugin{F: func() prov.ActionOperator {
+			return mock
+		}},
+	})
+	defer client.Close()
+
+	raw, err := client.Dispense(ActionPluginName)
+	require.Nil(t, err)
+
+	plugin := raw.(prov.ActionOperator)
+	action := &prov.Action{
+		ID:         "actionID",
+		ActionType: "test-action",
+		Data:       map[string]string{"done": "true"},
+		AsyncOperation: prov.AsyncOperation{
+			DeploymentID: "TestDepID",
+			TaskID:       "TestTaskID",
+			StepName:     "TestStep",
+			NodeName:     "TestNodeName",
+			Operation:    prov.Operation{Name: "myOps"},
+			WorkflowStepInfo: &events.WorkflowStepInfo{
+				WorkflowName: "install",
+				StepName:     "TestStep",
+			},
+		},
+	}
+	lof := events.LogOptionalFields{
+		events.WorkFlowID:    "testWF",
+		events.OperationName: "myTest",
+	}
+	ctx := events.NewContext(context.Background(), lof)
+	deregister, err := plugin.ExecAction(
+		ctx,
+		config.Configuration{Consul: config.Consul{Address: "test", Datacenter: "testdc"}},
+		"TestTaskID", "TestDepID", action)
+	require.Nil(t, err)
+	require.True(t, deregister)
+	require.True(t, mock.execActionCalled)
+	require.Equal(t, "test", mock.conf.Consul.Address)
+	require.Equal(t, "testdc", mock.conf.Consul.Datacenter)
+	require.Equal(t, "TestTaskID", mock.taskID)
+	require.Equal(t, "TestDepID", mock.deploymentID)
+	require.Equal(t, action, mock.action)
+	assert.Equal(t, lof, mock.lof)
+}
+
+func TestActionOperatorExecActionWithoutLogFields(t *testing.T) {
+	t.Parallel()
+	mock := new(mockActionOperator)
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		ActionPluginName: &ActionOperatorPlugin{F: func() prov.ActionOperator {
+			return mock
+		}},
+	})
+	defer client.Close()
+
+	raw, err := client.Dispense(ActionPluginName)
+	require.Nil(t, err)
+
+	plugin := raw.(prov.ActionOperator)
+	deregister, err := plugin.ExecAction(
+		context.Background(),
+		config.Configuration{},
+		"TestTaskID", "TestDepID", &prov.Action{ActionType: "test-action"})
+	require.Nil(t, err)
+	require.False(t, deregister)
+	require.True(t, mock.execActionCalled)
+}
+
+func TestActionOperatorExecActionWithFailure(t *testing.T) {
+	t.Parallel()
+	mock := new(mockActionOperator)
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		ActionPluginName: &ActionOperatorPlugin{F: func() prov.ActionOperator {
+			return mock
+		}},
+	})
+	defer client.Close()
+
+	raw, err := client.Dispense(ActionPluginName)
+	require.Nil(t, err)
+
+	plugin := raw.(prov.ActionOperator)
+	deregister, err := plugin.ExecAction(
+		context.Background(),
+		config.Configuration{},
+		"TestTaskID", "TestFailure", &prov.Action{ActionType: "test-action"})
+	require.Error(t, err, "An error was expected during executing plugin action")
+	require.EqualError(t, err, "a failure occurred during plugin exec action")
+	require.True(t, deregister)
+}
+
+func TestActionOperatorExecActionWithCancel(t *testing.T) {
+	t.Parallel()
+	mock := new(mockActionOperator)
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		ActionPluginName: &ActionOperatorPlugin{F: func() prov.ActionOperator {
+			return mock
+		}},
+	})
+	defer client.Close()
+
+	raw, err := client.Dispense(ActionPluginName)
+	require.Nil(t, err)
+
+	plugin := raw.(prov.ActionOperator)
+	ctx, cancelF := context.WithCancel(context.Background())
+	go func() {
+		_, err = plugin.ExecAction(
+			ctx,
+			config.Configuration{},
+			"TestTaskID", "TestCancel", &prov.Action{ActionType: "test-action"})
+		require.Nil(t, err)
+	}()
+	cancelF()
+	// Wait for cancellation signal to be dispatched
+	time.Sleep(50 * time.Millisecond)
+	require.True(t, mock.contextCancelled, "Context not cancelled")
+}
+
+func TestActionOperatorGetActionTypes(t *testing.T) {
+	mock := new(mockActionOperator)
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		ActionPluginName: &ActionOperatorPlugin{
+			F: func() prov.ActionOperator {
+				return mock
+			},
+			ActionTypes: []string{"my-job-monitoring", "test"}},
+	})
+	defer client.Close()
+	raw, err := client.Dispense(ActionPluginName)
+	require.Nil(t, err)
+	plugin := raw.(ActionOperator)
+
+	actionTypes, err := plugin.GetActionTypes()
+	require.Nil(t, err)
+	require.Len(t, actionTypes, 2)
+	require.Contains(t, actionTypes, "my-job-monitoring")
+	require.Contains(t, actionTypes, "test")
+}
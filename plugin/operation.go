@@ -70,7 +70,33 @@ type OperationExecutorClient struct {
 // ExecAsyncOperation is public for use by reflexion and should be considered as private to this package.
 // Please do not use it directly.
 func (c *OperationExecutorClient) ExecAsyncOperation(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName string, operation prov.Operation, stepName string) (*prov.Action, time.Duration, error) {
-	return nil, 0, errors.New("Asynchronous operation is not yet handled by this executor")
+	lof, ok := events.FromContext(ctx)
+	if !ok {
+		return nil, 0, errors.New("Missing contextual log optionnal fields")
+	}
+	id := c.Broker.NextId()
+	closeChan := make(chan struct{}, 0)
+	defer close(closeChan)
+	go clientMonitorContextCancellation(ctx, closeChan, id, c.Broker)
+
+	var resp OperationExecutorExecAsyncOperationResponse
+	args := &OperationExecutorExecAsyncOperationArgs{
+		OperationExecutorExecOperationArgs: OperationExecutorExecOperationArgs{
+			ChannelID:         id,
+			Conf:              conf,
+			TaskID:            taskID,
+			DeploymentID:      deploymentID,
+			NodeName:          nodeName,
+			Operation:         operation,
+			LogOptionalFields: lof,
+		},
+		StepName: stepName,
+	}
+	err := c.Client.Call("Plugin.ExecAsyncOperation", args, &resp)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Failed to call ExecAsyncOperation for plugin")
+	}
+	return resp.Action, resp.MonitoringTimeInterval, toError(resp.Error)
 }
 
 // ExecOperation is public for use by reflexion and should be considered as private to this package.
@@ -145,6 +171,40 @@ func (s *OperationExecutorServer) ExecOperation(args *OperationExecutorExecOpera
 	return nil
 }
 
+// OperationExecutorExecAsyncOperationArgs is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+type OperationExecutorExecAsyncOperationArgs struct {
+	OperationExecutorExecOperationArgs
+	StepName string
+}
+
+// OperationExecutorExecAsyncOperationResponse is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+type OperationExecutorExecAsyncOperationResponse struct {
+	Action                 *prov.Action
+	MonitoringTimeInterval time.Duration
+	Error                  *RPCError
+}
+
+// ExecAsyncOperation is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+func (s *OperationExecutorServer) ExecAsyncOperation(args *OperationExecutorExecAsyncOperationArgs, reply *OperationExecutorExecAsyncOperationResponse) error {
+
+	ctx, cancelFunc := context.WithCancel(events.NewContext(context.Background(), args.LogOptionalFields))
+	defer cancelFunc()
+
+	go s.Broker.AcceptAndServe(args.ChannelID, &RPCContextCanceller{CancelFunc: cancelFunc})
+	action, interval, err := s.OpExecutor.ExecAsyncOperation(ctx, args.Conf, args.TaskID, args.DeploymentID, args.NodeName, args.Operation, args.StepName)
+	var resp OperationExecutorExecAsyncOperationResponse
+	resp.Action = action
+	resp.MonitoringTimeInterval = interval
+	if err != nil {
+		resp.Error = NewRPCError(err)
+	}
+	*reply = resp
+	return nil
+}
+
 // GetSupportedArtifactTypes is public for use by reflexion and should be considered as private to this package.
 // Please do not use it directly.
 func (s *OperationExecutorServer) GetSupportedArtifactTypes(_ interface{}, reply *OperationExecutorGetTypesResponse) error {
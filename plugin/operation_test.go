@@ -34,12 +34,37 @@ type mockOperationExecutor struct {
 	conf                           config.Configuration
 	taskID, deploymentID, nodeName string
 	operation                      prov.Operation
+	stepName                       string
 	contextCancelled               bool
 	lof                            events.LogOptionalFields
 }
 
 func (m *mockOperationExecutor) ExecAsyncOperation(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName string, operation prov.Operation, stepName string) (*prov.Action, time.Duration, error) {
-	return nil, 0, errors.New("asynchronous operation is not yet handled by this executor")
+	m.execoperationCalled = true
+	m.ctx = ctx
+	m.conf = conf
+	m.taskID = taskID
+	m.deploymentID = deploymentID
+	m.nodeName = nodeName
+	m.operation = operation
+	m.stepName = stepName
+	m.lof, _ = events.FromContext(ctx)
+
+	if m.deploymentID == "TestFailure" {
+		return nil, 0, NewRPCError(errors.New("a failure occurred during plugin exec async operation"))
+	}
+	action := &prov.Action{
+		ActionType: "test-action",
+		Data:       map[string]string{"jobID": "1234"},
+		AsyncOperation: prov.AsyncOperation{
+			DeploymentID: deploymentID,
+			TaskID:       taskID,
+			StepName:     stepName,
+			NodeName:     nodeName,
+			Operation:    operation,
+		},
+	}
+	return action, 5 * time.Second, nil
 }
 
 func (m *mockOperationExecutor) ExecOperation(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName string, operation prov.Operation) error {
@@ -180,6 +205,75 @@ func TestOperationExecutorExecOperationWithCancel(t *testing.T) {
 	require.True(t, mock.contextCancelled, "Context not cancelled")
 }
 
+func TestOperationExecutorExecAsyncOperation(t *testing.T) {
+	t.Parallel()
+	mock := new(mockOperationExecutor)
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		OperationPluginName: &OperationPlugin{F: func() prov.OperationExecutor {
+			return mock
+		}},
+	})
+	defer client.Close()
+
+	raw, err := client.Dispense(OperationPluginName)
+	require.Nil(t, err)
+
+	plugin := raw.(prov.OperationExecutor)
+	op := prov.Operation{
+		Name:                   "myOps",
+		ImplementationArtifact: "tosca.artifacts.Implementation.Bash",
+	}
+	lof := events.LogOptionalFields{
+		events.WorkFlowID:    "testWF",
+		events.InterfaceName: "delegate",
+		events.OperationName: "myTest",
+	}
+	ctx := events.NewContext(context.Background(), lof)
+	action, interval, err := plugin.ExecAsyncOperation(
+		ctx,
+		config.Configuration{Consul: config.Consul{Address: "test", Datacenter: "testdc"}},
+		"TestTaskID", "TestDepID", "TestNodeName", op, "TestStepName")
+	require.Nil(t, err)
+	require.True(t, mock.execoperationCalled)
+	require.Equal(t, "test", mock.conf.Consul.Address)
+	require.Equal(t, "TestTaskID", mock.taskID)
+	require.Equal(t, "TestDepID", mock.deploymentID)
+	require.Equal(t, "TestNodeName", mock.nodeName)
+	require.Equal(t, "TestStepName", mock.stepName)
+	require.Equal(t, op, mock.operation)
+	assert.Equal(t, lof, mock.lof)
+
+	require.NotNil(t, action)
+	assert.Equal(t, 5*time.Second, interval)
+	assert.Equal(t, "test-action", action.ActionType)
+	assert.Equal(t, "1234", action.Data["jobID"])
+	assert.Equal(t, "TestStepName", action.AsyncOperation.StepName)
+	assert.Equal(t, op, action.AsyncOperation.Operation)
+}
+
+func TestOperationExecutorExecAsyncOperationWithFailure(t *testing.T) {
+	t.Parallel()
+	mock := new(mockOperationExecutor)
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		OperationPluginName: &OperationPlugin{F: func() prov.OperationExecutor {
+			return mock
+		}},
+	})
+	defer client.Close()
+
+	raw, err := client.Dispense(OperationPluginName)
+	require.Nil(t, err)
+
+	plugin := raw.(prov.OperationExecutor)
+	ctx := events.NewContext(context.Background(), events.LogOptionalFields{events.WorkFlowID: "testWF"})
+	_, _, err = plugin.ExecAsyncOperation(
+		ctx,
+		config.Configuration{Consul: config.Consul{Address: "test", Datacenter: "testdc"}},
+		"TestTaskID", "TestFailure", "TestNodeName", prov.Operation{}, "TestStepName")
+	require.Error(t, err, "An error was expected during executing plugin async operation")
+	require.EqualError(t, err, "a failure occurred during plugin exec async operation")
+}
+
 func TestOperationGetSupportedArtifactTypes(t *testing.T) {
 	mock := new(mockOperationExecutor)
 	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
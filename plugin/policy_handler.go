@@ -0,0 +1,152 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"net/rpc"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/prov"
+)
+
+// PolicyHandler is an extension of prov.PolicyHandler that expose its supported policy types
+type PolicyHandler interface {
+	prov.PolicyHandler
+	// Returns a list of supported policy types
+	GetPolicyTypes() ([]string, error)
+}
+
+// PolicyHandlerPlugin is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+type PolicyHandlerPlugin struct {
+	F           func() prov.PolicyHandler
+	PolicyTypes []string
+}
+
+// Server is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+func (p *PolicyHandlerPlugin) Server(b *plugin.MuxBroker) (interface{}, error) {
+	phs := &PolicyHandlerServer{Broker: b, PolicyTypes: p.PolicyTypes}
+	if p.F != nil {
+		phs.PolicyHandler = p.F()
+	} else if len(p.PolicyTypes) > 0 {
+		return nil, errors.New("If PolicyTypes is defined then you have to defined a PolicyHandlerFunc")
+	}
+
+	return phs, nil
+}
+
+// Client is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+func (p *PolicyHandlerPlugin) Client(b *plugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &PolicyHandlerClient{Broker: b, Client: c}, nil
+}
+
+// PolicyHandlerClient is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+type PolicyHandlerClient struct {
+	Broker *plugin.MuxBroker
+	Client *rpc.Client
+}
+
+// CheckPolicy is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+func (c *PolicyHandlerClient) CheckPolicy(ctx context.Context, conf config.Configuration, deploymentID, policyName string) error {
+	id := c.Broker.NextId()
+	closeChan := make(chan struct{}, 0)
+	defer close(closeChan)
+	go clientMonitorContextCancellation(ctx, closeChan, id, c.Broker)
+
+	var resp PolicyHandlerCheckPolicyResponse
+	args := &PolicyHandlerCheckPolicyArgs{
+		ChannelID:    id,
+		Conf:         conf,
+		DeploymentID: deploymentID,
+		PolicyName:   policyName,
+	}
+	err := c.Client.Call("Plugin.CheckPolicy", args, &resp)
+	if err != nil {
+		return errors.Wrap(err, "Failed to call CheckPolicy for plugin")
+	}
+	return toError(resp.Error)
+}
+
+// GetPolicyTypes is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+func (c *PolicyHandlerClient) GetPolicyTypes() ([]string, error) {
+	var resp PolicyHandlerGetPolicyTypesResponse
+	err := c.Client.Call("Plugin.GetPolicyTypes", new(interface{}), &resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get policy types for plugin")
+	}
+	return resp.PolicyTypes, toError(resp.Error)
+}
+
+// PolicyHandlerServer is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+type PolicyHandlerServer struct {
+	Broker        *plugin.MuxBroker
+	PolicyHandler prov.PolicyHandler
+	PolicyTypes   []string
+}
+
+// PolicyHandlerCheckPolicyArgs is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+type PolicyHandlerCheckPolicyArgs struct {
+	ChannelID    uint32
+	Conf         config.Configuration
+	DeploymentID string
+	PolicyName   string
+}
+
+// PolicyHandlerCheckPolicyResponse is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+type PolicyHandlerCheckPolicyResponse struct {
+	Error *RPCError
+}
+
+// CheckPolicy is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+func (s *PolicyHandlerServer) CheckPolicy(args *PolicyHandlerCheckPolicyArgs, reply *PolicyHandlerCheckPolicyResponse) error {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	go s.Broker.AcceptAndServe(args.ChannelID, &RPCContextCanceller{CancelFunc: cancelFunc})
+	err := s.PolicyHandler.CheckPolicy(ctx, args.Conf, args.DeploymentID, args.PolicyName)
+	var resp PolicyHandlerCheckPolicyResponse
+	if err != nil {
+		resp.Error = NewRPCError(err)
+	}
+	*reply = resp
+	return nil
+}
+
+// PolicyHandlerGetPolicyTypesResponse is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+type PolicyHandlerGetPolicyTypesResponse struct {
+	PolicyTypes []string
+	Error       *RPCError
+}
+
+// GetPolicyTypes is public for use by reflexion and should be considered as private to this package.
+// Please do not use it directly.
+func (s *PolicyHandlerServer) GetPolicyTypes(_ interface{}, reply *PolicyHandlerGetPolicyTypesResponse) error {
+	*reply = PolicyHandlerGetPolicyTypesResponse{PolicyTypes: s.PolicyTypes}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/prov"
+)
+
+type mockPolicyHandler struct {
+	checkPolicyCalled        bool
+	conf                     config.Configuration
+	deploymentID, policyName string
+}
+
+func (m *mockPolicyHandler) CheckPolicy(ctx context.Context, conf config.Configuration, deploymentID, policyName string) error {
+	m.checkPolicyCalled = true
+	m.conf = conf
+	m.deploymentID = deploymentID
+	m.policyName = policyName
+
+	if m.deploymentID == "TestFailure" {
+		return NewRPCError(errors.New("a failure occurred during plugin check policy"))
+	}
+	return nil
+}
+
+func TestPolicyHandlerCheckPolicy(t *testing.T) {
+	t.Parallel()
+	mock := new(mockPolicyHandler)
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		PolicyHandlerPluginName: &PolicyHandlerPlugin{F: func() prov.PolicyHandler {
+			return mock
+		}},
+	})
+	defer client.Close()
+
+	raw, err := client.Dispense(PolicyHandlerPluginName)
+	require.Nil(t, err)
+
+	plugin := raw.(prov.PolicyHandler)
+	err = plugin.CheckPolicy(
+		context.Background(),
+		config.Configuration{Consul: config.Consul{Address: "test", Datacenter: "testdc"}},
+		"TestDepID",
+		"my-policy")
+	require.Nil(t, err)
+	require.True(t, mock.checkPolicyCalled)
+	require.Equal(t, "test", mock.conf.Consul.Address)
+	require.Equal(t, "testdc", mock.conf.Consul.Datacenter)
+	require.Equal(t, "TestDepID", mock.deploymentID)
+	require.Equal(t, "my-policy", mock.policyName)
+}
+
+func TestPolicyHandlerCheckPolicyWithFailure(t *testing.T) {
+	t.Parallel()
+	mock := new(mockPolicyHandler)
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		PolicyHandlerPluginName: &PolicyHandlerPlugin{F: func() prov.PolicyHandler {
+			return mock
+		}},
+	})
+	defer client.Close()
+
+	raw, err := client.Dispense(PolicyHandlerPluginName)
+	require.Nil(t, err)
+
+	plugin := raw.(prov.PolicyHandler)
+	err = plugin.CheckPolicy(context.Background(), config.Configuration{}, "TestFailure", "my-policy")
+	require.Error(t, err, "An error was expected during checking plugin policy")
+	require.EqualError(t, err, "a failure occurred during plugin check policy")
+}
+
+func TestPolicyHandlerGetPolicyTypes(t *testing.T) {
+	t.Parallel()
+	mock := new(mockPolicyHandler)
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		PolicyHandlerPluginName: &PolicyHandlerPlugin{
+			F: func() prov.PolicyHandler {
+				return mock
+			},
+			PolicyTypes: []string{"my.policies.Custom", "test"}},
+	})
+	defer client.Close()
+	raw, err := client.Dispense(PolicyHandlerPluginName)
+	require.Nil(t, err)
+	plugin := raw.(PolicyHandler)
+
+	policyTypes, err := plugin.GetPolicyTypes()
+	require.Nil(t, err)
+	require.Len(t, policyTypes, 2)
+	require.Contains(t, policyTypes, "my.policies.Custom")
+	require.Contains(t, policyTypes, "test")
+}
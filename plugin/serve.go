@@ -39,6 +39,10 @@ const (
 	OperationPluginName = "operation"
 	// InfraUsageCollectorPluginName is the name of InfraUsageCollector Plugins it could be used as a lookup key in Client.Dispense
 	InfraUsageCollectorPluginName = "infraUsageCollector"
+	// ActionPluginName is the name of ActionOperator Plugins it could be used as a lookup key in Client.Dispense
+	ActionPluginName = "action"
+	// PolicyHandlerPluginName is the name of PolicyHandler Plugins it could be used as a lookup key in Client.Dispense
+	PolicyHandlerPluginName = "policyHandler"
 )
 
 // HandshakeConfig are used to just do a basic handshake between
@@ -60,6 +64,12 @@ type OperationFunc func() prov.OperationExecutor
 // InfraUsageCollectorFunc is a function that is called when creating a plugin server
 type InfraUsageCollectorFunc func() prov.InfraUsageCollector
 
+// ActionFunc is a function that is called when creating a plugin server
+type ActionFunc func() prov.ActionOperator
+
+// PolicyHandlerFunc is a function that is called when creating a plugin server
+type PolicyHandlerFunc func() prov.PolicyHandler
+
 // ServeOpts are the configurations to serve a plugin.
 type ServeOpts struct {
 	DelegateFunc                       DelegateFunc
@@ -69,6 +79,10 @@ type ServeOpts struct {
 	OperationSupportedArtifactTypes    []string
 	InfraUsageCollectorFunc            InfraUsageCollectorFunc
 	InfraUsageCollectorSupportedInfras []string
+	ActionFunc                         ActionFunc
+	ActionTypes                        []string
+	PolicyHandlerFunc                  PolicyHandlerFunc
+	PolicyTypes                        []string
 }
 
 // Serve serves a plugin. This function never returns and should be the final
@@ -94,6 +108,8 @@ func getPlugins(opts *ServeOpts) map[string]plugin.Plugin {
 		DefinitionsPluginName:         &DefinitionsPlugin{Definitions: opts.Definitions},
 		ConfigManagerPluginName:       &ConfigManagerPlugin{&defaultConfigManager{}},
 		InfraUsageCollectorPluginName: &InfraUsageCollectorPlugin{F: opts.InfraUsageCollectorFunc, SupportedInfras: opts.InfraUsageCollectorSupportedInfras},
+		ActionPluginName:              &ActionOperatorPlugin{F: opts.ActionFunc, ActionTypes: opts.ActionTypes},
+		PolicyHandlerPluginName:       &PolicyHandlerPlugin{F: opts.PolicyHandlerFunc, PolicyTypes: opts.PolicyTypes},
 	}
 }
 
@@ -50,4 +50,20 @@ func TestServeDefaultOpts(t *testing.T) {
 	require.Nil(t, err)
 	require.Len(t, defs, 0)
 
+	raw, err = client.Dispense(ActionPluginName)
+	require.Nil(t, err)
+
+	actionPlugin := raw.(ActionOperator)
+	actionTypes, err := actionPlugin.GetActionTypes()
+	require.Nil(t, err)
+	require.Len(t, actionTypes, 0)
+
+	raw, err = client.Dispense(PolicyHandlerPluginName)
+	require.Nil(t, err)
+
+	policyPlugin := raw.(PolicyHandler)
+	policyTypes, err := policyPlugin.GetPolicyTypes()
+	require.Nil(t, err)
+	require.Len(t, policyTypes, 0)
+
 }
@@ -35,7 +35,22 @@ func (d *myDelegateExecutor) ExecDelegate(ctx context.Context, cfg config.Config
 type myOperationExecutor struct{}
 
 func (d *myOperationExecutor) ExecAsyncOperation(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName string, operation prov.Operation, stepName string) (*prov.Action, time.Duration, error) {
-	return nil, 0, fmt.Errorf("asynchronous operations %v not yet supported by this sample", operation)
+	log.Printf("Hello from myOperationExecutor asynchronous operation %q", operation.Name)
+	// The returned action is scheduled and periodically executed by the action operator registered for its type
+	action := &prov.Action{
+		ActionType: "my-job-monitoring",
+		Data:       map[string]string{"jobID": fmt.Sprintf("%s-%s", nodeName, operation.Name)},
+	}
+	return action, 5 * time.Second, nil
+}
+
+type myActionOperator struct{}
+
+func (o *myActionOperator) ExecAction(ctx context.Context, cfg config.Configuration, taskID, deploymentID string, action *prov.Action) (bool, error) {
+	log.Printf("Hello from myActionOperator checking job %q", action.Data["jobID"])
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, deploymentID).RegisterAsString("Hello from myActionOperator")
+	// Returning true deregisters the action, the asynchronous operation is then considered as done
+	return true, nil
 }
 
 func (d *myOperationExecutor) ExecOperation(ctx context.Context, cfg config.Configuration, taskID, deploymentID, nodeName string, operation prov.Operation) error {
@@ -97,5 +112,9 @@ node_types:
 			return new(myOperationExecutor)
 		},
 		OperationSupportedArtifactTypes: []string{"yorc.artifacts.Implementation.MyImplementation"},
+		ActionFunc: func() prov.ActionOperator {
+			return new(myActionOperator)
+		},
+		ActionTypes: []string{"my-job-monitoring"},
 	})
 }
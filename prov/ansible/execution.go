@@ -110,6 +110,15 @@ type hostConnection struct {
 	instanceID string
 	privateKey string
 	password   string
+	// connectionType is empty for SSH connections or "docker" for hosts that are Docker containers
+	connectionType string
+	containerID    string
+	dockerHost     string
+	// dockerCertPath is the directory of the TLS files used to reach dockerHost, TLS is not used when empty
+	dockerCertPath  string
+	dockerTLSVerify bool
+	// bastion is an optional jump host used to reach the host over SSH
+	bastion *sshutil.BastionHost
 }
 
 type sshCredentials struct {
@@ -121,6 +130,7 @@ type sshCredentials struct {
 type execution interface {
 	resolveExecution() error
 	execute(ctx context.Context, retry bool) error
+	secrets() []string
 }
 
 type ansibleRunner interface {
@@ -152,6 +162,7 @@ type executionCommon struct {
 	NodePath                 string
 	NodeTypePath             string
 	Artifacts                map[string]string
+	ArtifactInputs           []*artifactInput
 	OverlayPath              string
 	Context                  map[string]string
 	CapabilitiesCtx          map[string]*deployments.TOSCAValue
@@ -170,6 +181,13 @@ type executionCommon struct {
 	vaultToken               string
 }
 
+// An artifactInput is an artifact referenced by a get_artifact operation input that should be uploaded on the remote host
+type artifactInput struct {
+	Src    string
+	Dest   string
+	Remove bool
+}
+
 // Handling a command standard output and standard error
 type outputHandler interface {
 	start(cmd *exec.Cmd) error
@@ -388,10 +406,71 @@ func (e *executionCommon) setHostConnection(kv *api.KV, host, instanceID, capTyp
 				return errors.Wrapf(err, "Failed to convert port value:%q to int", port)
 			}
 		}
+
+		conn.bastion, err = operations.GetInstanceBastionHost(e.kv, e.deploymentID, host, instanceID)
+		if err != nil {
+			return err
+		}
+
+		return e.setDockerHostConnection(host, instanceID, conn)
+	}
+	return nil
+}
+
+// setDockerHostConnection sets the connection to hosts that are Docker containers reached using docker exec
+func (e *executionCommon) setDockerHostConnection(host, instanceID string, conn *hostConnection) error {
+	connectionType, err := deployments.GetInstanceCapabilityAttributeValue(e.kv, e.deploymentID, host, instanceID, "endpoint", "connection_type")
+	if err != nil || connectionType == nil || connectionType.RawString() != "docker" {
+		return err
+	}
+	containerID, err := deployments.GetInstanceCapabilityAttributeValue(e.kv, e.deploymentID, host, instanceID, "endpoint", "container_id")
+	if err != nil {
+		return err
+	}
+	if containerID == nil || containerID.RawString() == "" {
+		return errors.Errorf("missing container_id endpoint attribute for docker connection to instance %q of node %q", instanceID, host)
+	}
+	conn.connectionType = connectionType.RawString()
+	conn.containerID = containerID.RawString()
+	dockerHost, err := deployments.GetInstanceCapabilityAttributeValue(e.kv, e.deploymentID, host, instanceID, "endpoint", "docker_host")
+	if err != nil {
+		return err
+	}
+	if dockerHost != nil {
+		conn.dockerHost = dockerHost.RawString()
+	}
+	dockerCertPath, err := deployments.GetInstanceCapabilityAttributeValue(e.kv, e.deploymentID, host, instanceID, "endpoint", "docker_cert_path")
+	if err != nil || dockerCertPath == nil || dockerCertPath.RawString() == "" {
+		return err
+	}
+	conn.dockerCertPath = dockerCertPath.RawString()
+	dockerTLSVerify, err := deployments.GetInstanceCapabilityAttributeValue(e.kv, e.deploymentID, host, instanceID, "endpoint", "docker_tls_verify")
+	if err != nil {
+		return err
+	}
+	if dockerTLSVerify != nil && dockerTLSVerify.RawString() != "" {
+		conn.dockerTLSVerify, err = strconv.ParseBool(dockerTLSVerify.RawString())
+		if err != nil {
+			return errors.Wrapf(err, "invalid docker_tls_verify endpoint attribute for instance %q of node %q", instanceID, host)
+		}
 	}
 	return nil
 }
 
+// dockerExtraArgs returns the docker command line options used to reach a remote Docker host
+func dockerExtraArgs(host *hostConnection) string {
+	args := "-H " + host.dockerHost
+	if host.dockerCertPath == "" {
+		return args
+	}
+	if host.dockerTLSVerify {
+		args += " --tlsverify --tlscacert " + filepath.Join(host.dockerCertPath, "ca.pem")
+	} else {
+		args += " --tls"
+	}
+	return args + fmt.Sprintf(" --tlscert %s --tlskey %s", filepath.Join(host.dockerCertPath, "cert.pem"), filepath.Join(host.dockerCertPath, "key.pem"))
+}
+
 func (e *executionCommon) resolveHostsOrchestratorLocal(nodeName string, instances []string) error {
 	e.hosts = make(map[string]*hostConnection, len(instances))
 	for i := range instances {
@@ -704,6 +783,84 @@ func (e *executionCommon) resolveInputs() error {
 	return err
 }
 
+// resolveArtifactInputs computes the location of artifacts referenced by get_artifact operation inputs
+//
+// When upload is false artifacts are referenced in the deployment overlay on the orchestrator host.
+// It should be called once OperationRemotePath is known.
+func (e *executionCommon) resolveArtifactInputs(upload bool) {
+	e.ArtifactInputs = make([]*artifactInput, 0)
+	known := make(map[string]*artifactInput)
+	for _, envInput := range e.EnvInputs {
+		if envInput.Artifact == nil {
+			continue
+		}
+		src := path.Join(e.OverlayPath, envInput.Artifact.Path)
+		if !upload {
+			envInput.Value = src
+			continue
+		}
+		dest := envInput.Artifact.Location
+		if dest == "" {
+			dest = path.Join("{{ ansible_env.HOME}}", e.OperationRemotePath, envInput.Artifact.Path)
+		}
+		envInput.Value = dest
+		if ai, ok := known[dest]; ok {
+			ai.Remove = ai.Remove || envInput.Artifact.Remove
+			continue
+		}
+		ai := &artifactInput{Src: src, Dest: dest, Remove: envInput.Artifact.Remove}
+		known[dest] = ai
+		e.ArtifactInputs = append(e.ArtifactInputs, ai)
+	}
+}
+
+// HaveArtifactInputsToRemove returns true if an artifact uploaded for a get_artifact input should be removed after the operation
+func (e *executionCommon) HaveArtifactInputsToRemove() bool {
+	for _, ai := range e.ArtifactInputs {
+		if ai.Remove {
+			return true
+		}
+	}
+	return false
+}
+
+// secrets returns the secret values used by this execution that should not appear in logs
+func (e *executionCommon) secrets() []string {
+	secrets := make([]string, 0)
+	for _, envInput := range e.EnvInputs {
+		if envInput.IsSecret {
+			secrets = append(secrets, envInput.Value)
+		}
+	}
+	for _, v := range e.CapabilitiesCtx {
+		if v != nil && v.IsSecret {
+			secrets = append(secrets, v.RawString())
+		}
+	}
+	for _, h := range e.hosts {
+		if h.password != "" {
+			secrets = append(secrets, h.password)
+		}
+		if h.privateKey != "" {
+			if _, err := ssh.ParsePrivateKey([]byte(h.privateKey)); err == nil {
+				secrets = append(secrets, h.privateKey)
+			}
+		}
+		for b := h.bastion; b != nil; b = b.Bastion {
+			if b.Password != "" {
+				secrets = append(secrets, b.Password)
+			}
+			if _, err := ssh.ParsePrivateKey([]byte(b.PrivateKey)); err == nil {
+				secrets = append(secrets, b.PrivateKey)
+			}
+		}
+	}
+	if e.vaultToken != "" {
+		secrets = append(secrets, e.vaultToken)
+	}
+	return secrets
+}
+
 func (e *executionCommon) resolveExecution() error {
 	log.Debugf("Preparing execution of operation %q on node %q for deployment %q", e.operation.Name, e.NodeName, e.deploymentID)
 	ovPath, err := filepath.Abs(filepath.Join(e.cfg.WorkingDirectory, "deployments", e.deploymentID, "overlay"))
@@ -812,9 +969,24 @@ func (e *executionCommon) generateHostConnection(ctx context.Context, buffer *by
 		if err != nil {
 			return err
 		}
+	} else if host.connectionType == "docker" {
+		buffer.WriteString(" ansible_connection=docker ansible_host=")
+		buffer.WriteString(host.containerID)
+		if host.dockerHost != "" {
+			buffer.WriteString(fmt.Sprintf(" ansible_docker_extra_args=\"%s\"", dockerExtraArgs(host)))
+		}
 	} else {
 		sshCredentials := e.getSSHCredentials(ctx, host, true)
-		buffer.WriteString(fmt.Sprintf(" ansible_ssh_user=%s ansible_ssh_common_args=\"-o ConnectionAttempts=20\"", sshCredentials.user))
+		sshCommonArgs := "-o ConnectionAttempts=20"
+		if host.bastion != nil {
+			proxyCommand, err := e.getBastionProxyCommand(ctx, host.bastion)
+			if err != nil {
+				return err
+			}
+			sshCommonArgs += fmt.Sprintf(" -o ProxyCommand='%s'", proxyCommand)
+		}
+		// Nested proxy commands of chained bastions contain double quotes that should be escaped in the inventory
+		buffer.WriteString(fmt.Sprintf(" ansible_ssh_user=%s ansible_ssh_common_args=\"%s\"", sshCredentials.user, inventoryValueEscaper.Replace(sshCommonArgs)))
 		// Set with priority private key against password
 		if e.cfg.DisableSSHAgent && sshCredentials.privateKey != "" {
 			// check privateKey's a valid path
@@ -840,6 +1012,49 @@ func (e *executionCommon) generateHostConnection(ctx context.Context, buffer *by
 	return nil
 }
 
+// inventoryValueEscaper escapes characters having a special meaning in a double-quoted inventory value
+var inventoryValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// shellDoubleQuoteEscaper escapes characters having a special meaning in a shell double-quoted string
+var shellDoubleQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+
+// getBastionProxyCommand returns the SSH command used by Ansible as a ProxyCommand to reach a host through a bastion.
+//
+// Previous bastions in the chain, if any, are reached using nested ProxyCommand options so that each of them
+// is reached with its own private key and without host key checks.
+// As this command is not interactive, bastions should be reached using private keys.
+func (e *executionCommon) getBastionProxyCommand(ctx context.Context, bastion *sshutil.BastionHost) (string, error) {
+	if bastion.PrivateKey == "" && bastion.Password != "" {
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, e.deploymentID).Registerf("Ansible provisioning: password authentication is not supported for bastion host %q, a private key should be used instead.", bastion.Host)
+	}
+	user := bastion.User
+	if user == "" {
+		user = "root"
+	}
+	port := bastion.Port
+	if port == 0 {
+		port = 22
+	}
+	proxyCommand := "ssh -W %h:%p -q -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null"
+	if e.cfg.DisableSSHAgent && bastion.PrivateKey != "" {
+		if is, err := pathutil.IsValidPath(bastion.PrivateKey); err != nil || !is {
+			return "", errors.Errorf("private key of bastion host %q is not a valid path", bastion.Host)
+		}
+		proxyCommand += " -i " + bastion.PrivateKey
+	}
+	proxyCommand += fmt.Sprintf(" -p %d", port)
+	if bastion.Bastion != nil {
+		jumpCommand, err := e.getBastionProxyCommand(ctx, bastion.Bastion)
+		if err != nil {
+			return "", err
+		}
+		// ssh expands the % tokens of its ProxyCommand, they are escaped to be expanded by the nested ssh command
+		jumpCommand = strings.Replace(jumpCommand, "%", "%%", -1)
+		proxyCommand += fmt.Sprintf(" -o ProxyCommand=\"%s\"", shellDoubleQuoteEscaper.Replace(jumpCommand))
+	}
+	return proxyCommand + fmt.Sprintf(" %s@%s", user, bastion.Host), nil
+}
+
 func (e *executionCommon) executeWithCurrentInstance(ctx context.Context, retry bool, currentInstance string) error {
 	// Create a cancel func here to remove docker sandboxes as soon as we exit this function
 	ctx, cancelFn := context.WithCancel(ctx)
@@ -1167,7 +1382,7 @@ func (e *executionCommon) executePlaybook(ctx context.Context, retry bool,
 func (e *executionCommon) configureSSHAgent(ctx context.Context) (*sshutil.SSHAgent, error) {
 	var addSSHAgent bool
 	for _, host := range e.hosts {
-		if host.privateKey != "" {
+		if host.privateKey != "" || hasBastionPrivateKey(host.bastion) {
 			addSSHAgent = true
 			break
 		}
@@ -1186,10 +1401,26 @@ func (e *executionCommon) configureSSHAgent(ctx context.Context) (*sshutil.SSHAg
 				return nil, err
 			}
 		}
+		for b := host.bastion; b != nil; b = b.Bastion {
+			if b.PrivateKey != "" {
+				if err = agent.AddKey(b.PrivateKey, 3600); err != nil {
+					return nil, err
+				}
+			}
+		}
 	}
 	return agent, nil
 }
 
+func hasBastionPrivateKey(bastion *sshutil.BastionHost) bool {
+	for b := bastion; b != nil; b = b.Bastion {
+		if b.PrivateKey != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func buildArchive(rootDir, artifactDir, tarPath string) error {
 
 	srcDir := filepath.Join(rootDir, artifactDir)
@@ -37,6 +37,8 @@ const uploadArtifactsPlaybook = `
   tasks:
 [[[ range $artName, $art := .Artifacts ]]]    [[[printf "- file: path=\"{{ ansible_env.HOME}}/%s/%s\" state=directory mode=0755" $.OperationRemotePath (path $art)]]]
     [[[printf "- copy: src=\"%s/%s\" dest=\"{{ ansible_env.HOME}}/%s/%s\"" $.OverlayPath $art $.OperationRemotePath (path $art)]]]
+[[[end]]][[[ range $artInput := .ArtifactInputs ]]]    [[[printf "- file: path=\"%s\" state=directory mode=0755" (path $artInput.Dest)]]]
+    [[[printf "- copy: src=\"%s\" dest=\"%s\"" $artInput.Src $artInput.Dest]]]
 [[[end]]]
 `
 
@@ -49,7 +51,15 @@ const ansiblePlaybook = `
   tasks:
     [[[printf "- file: path=\"{{ ansible_env.HOME}}/%s\" state=directory mode=0755" $.OperationRemotePath]]]
     [[[printf "- template: src=\"outputs.csv.j2\" dest=\"{{ ansible_env.HOME}}/%s/out.csv\"" $.OperationRemotePath]]]
-    [[[printf "- fetch: src=\"{{ ansible_env.HOME}}/%s/out.csv\" dest={{dest_folder}}/{{ansible_host}}-out.csv flat=yes" $.OperationRemotePath]]]
+    [[[printf "- fetch: src=\"{{ ansible_env.HOME}}/%s/out.csv\" dest={{dest_folder}}/{{inventory_hostname}}-out.csv flat=yes" $.OperationRemotePath]]]
+[[[end]]]
+[[[if .HaveArtifactInputsToRemove]]]
+- name: Cleanup artifacts
+  hosts: all
+  strategy: free
+  tasks:
+[[[ range $artInput := .ArtifactInputs ]]][[[if $artInput.Remove]]]    [[[printf "- file: path=\"%s\" state=absent" $artInput.Dest]]]
+[[[end]]][[[end]]]
 [[[end]]]
 [[[if not .KeepOperationRemotePath]]]
 - name: Cleanup temp directories
@@ -68,6 +78,7 @@ type executionAnsible struct {
 
 func (e *executionAnsible) runAnsible(ctx context.Context, retry bool, currentInstance, ansibleRecipePath string) error {
 	var err error
+	e.resolveArtifactInputs(!e.isAlienAnsible)
 	if !e.isAlienAnsible {
 		e.PlaybookPath, err = filepath.Abs(filepath.Join(e.OverlayPath, e.Primary))
 	} else {
@@ -176,7 +187,7 @@ func (e *executionAnsible) runAnsible(ctx context.Context, retry bool, currentIn
 	}
 	tmpl := template.New("execTemplate").Delims("[[[", "]]]").Funcs(funcMap)
 	var playbook string
-	if e.isAlienAnsible || len(e.Artifacts) == 0 {
+	if e.isAlienAnsible || (len(e.Artifacts) == 0 && len(e.ArtifactInputs) == 0) {
 		playbook = ansiblePlaybook
 	} else {
 		playbook = uploadArtifactsPlaybook + ansiblePlaybook
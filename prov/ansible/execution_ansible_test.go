@@ -33,6 +33,7 @@ func TestAnsibleTemplate(t *testing.T) {
 		OverlayPath:         "/some/local/path",
 		VarInputsNames:      []string{"INSTANCE", "PORT"},
 		OperationRemotePath: ".yorc/path/on/remote",
+		ArtifactInputs:      []*artifactInput{{Src: "/some/local/path/config/app.conf", Dest: "/etc/app.conf", Remove: true}},
 	}
 
 	e := &executionAnsible{
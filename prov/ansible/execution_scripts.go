@@ -146,6 +146,10 @@ const shellAnsiblePlaybook = `
     [[[printf "- copy: src=\"%s/%s\" dest=\"{{ ansible_env.HOME}}/%s/%s\"" $.OverlayPath $art $.OperationRemotePath (path $art)]]]
     [[[printf "  when: (not %s) or result.failed" $.ArchiveArtifacts]]]
     [[[end]]]
+    [[[ range $artInput := .ArtifactInputs -]]]
+    [[[printf "- file: path=\"%s\" state=directory mode=0755" (path $artInput.Dest)]]]
+    [[[printf "- copy: src=\"%s\" dest=\"%s\"" $artInput.Src $artInput.Dest]]]
+    [[[end]]]
     [[[printf "- shell: \"/bin/bash -l -c %s\"" (getWrappedCommand)]]]
       environment:
         [[[ range $key, $envInput := .EnvInputs -]]]
@@ -163,8 +167,11 @@ const shellAnsiblePlaybook = `
         [[[printf "%s: \" {{%s}}\"" $hostVarValue $hostVarValue]]]
         [[[end]]]
     [[[if .HaveOutput]]]
-    [[[printf "- fetch: src={{ ansible_env.HOME}}/%s/out.csv dest=%s/{{ansible_host}}-out.csv flat=yes" $.OperationRemotePath $.DestFolder]]]
+    [[[printf "- fetch: src={{ ansible_env.HOME}}/%s/out.csv dest=%s/{{inventory_hostname}}-out.csv flat=yes" $.OperationRemotePath $.DestFolder]]]
     [[[end]]]
+    [[[ range $artInput := .ArtifactInputs -]]][[[if $artInput.Remove]]]
+    [[[printf "- file: path=\"%s\" state=absent" $artInput.Dest]]]
+    [[[end]]][[[end]]]
     [[[if not .KeepOperationRemotePath ]]]
     - file: path="{{ ansible_env.HOME}}/[[[.OperationRemoteBaseDir]]]" state=absent
     [[[end]]]
@@ -206,6 +213,7 @@ func getExecutionScriptTemplateFnMap(e *executionCommon, ansibleRecipePath strin
 
 func (e *executionScript) runAnsible(ctx context.Context, retry bool, currentInstance, ansibleRecipePath string) error {
 	var err error
+	e.resolveArtifactInputs(true)
 	e.ScriptToRun, err = filepath.Abs(filepath.Join(e.OverlayPath, e.Primary))
 	if err != nil {
 		return errors.Wrap(err, "Failed to retrieve script absolute path")
@@ -18,9 +18,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"testing"
 	"text/template"
@@ -32,6 +35,7 @@ import (
 	"github.com/ystia/yorc/config"
 	"github.com/ystia/yorc/deployments"
 	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/helper/sshutil"
 	"github.com/ystia/yorc/prov"
 	"github.com/ystia/yorc/prov/operations"
 	yorc_testutil "github.com/ystia/yorc/testutil"
@@ -54,6 +58,7 @@ func TestTemplates(t *testing.T) {
 		OverlayPath:            "/some/local/path",
 		VarInputsNames:         []string{"INSTANCE", "PORT"},
 		OperationRemoteBaseDir: ".yorc/path/on/remote",
+		ArtifactInputs:         []*artifactInput{{Src: "/some/local/path/config/app.conf", Dest: "/etc/app.conf", Remove: true}},
 	}
 
 	e := &executionScript{
@@ -70,6 +75,57 @@ func TestTemplates(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestResolveArtifactInputs(t *testing.T) {
+	t.Parallel()
+	ec := &executionCommon{
+		OverlayPath:         "/some/local/path",
+		OperationRemotePath: ".yorc/path/on/remote",
+		EnvInputs: []*operations.EnvInput{
+			{Name: "CONF", InstanceName: "Welcome_0", Value: "config/app.conf", Artifact: &deployments.ArtifactReference{Path: "config/app.conf"}},
+			{Name: "CONF", InstanceName: "Welcome_1", Value: "config/app.conf", Artifact: &deployments.ArtifactReference{Path: "config/app.conf"}},
+			{Name: "BIN", Value: "/usr/local/bin/app", Artifact: &deployments.ArtifactReference{Path: "bin/app", Location: "/usr/local/bin/app", Remove: true}},
+			{Name: "PORT", Value: "8080"},
+		},
+	}
+
+	ec.resolveArtifactInputs(true)
+	require.Len(t, ec.ArtifactInputs, 2)
+	require.Equal(t, &artifactInput{Src: "/some/local/path/config/app.conf", Dest: "{{ ansible_env.HOME}}/.yorc/path/on/remote/config/app.conf"}, ec.ArtifactInputs[0])
+	require.Equal(t, &artifactInput{Src: "/some/local/path/bin/app", Dest: "/usr/local/bin/app", Remove: true}, ec.ArtifactInputs[1])
+	require.Equal(t, "{{ ansible_env.HOME}}/.yorc/path/on/remote/config/app.conf", ec.EnvInputs[0].Value)
+	require.Equal(t, "/usr/local/bin/app", ec.EnvInputs[2].Value)
+	require.Equal(t, "8080", ec.EnvInputs[3].Value)
+	require.True(t, ec.HaveArtifactInputsToRemove())
+
+	ec.resolveArtifactInputs(false)
+	require.Len(t, ec.ArtifactInputs, 0)
+	require.Equal(t, "/some/local/path/config/app.conf", ec.EnvInputs[1].Value)
+	require.Equal(t, "/some/local/path/bin/app", ec.EnvInputs[2].Value)
+	require.False(t, ec.HaveArtifactInputsToRemove())
+}
+
+func TestExecutionSecrets(t *testing.T) {
+	t.Parallel()
+	ec := &executionCommon{
+		EnvInputs: []*operations.EnvInput{
+			{Name: "PASSWORD", Value: "s3cr3t", IsSecret: true},
+			{Name: "PORT", Value: "8080"},
+		},
+		CapabilitiesCtx: map[string]*deployments.TOSCAValue{
+			"TARGET_CAPABILITY_PROPERTY_token": {Value: "captoken", IsSecret: true},
+			"TARGET_CAPABILITY_PROPERTY_port":  {Value: "443"},
+		},
+		hosts: map[string]*hostConnection{
+			"host1": {host: "10.0.0.1", password: "hostpass"},
+			"host2": {host: "10.0.0.2", privateKey: "~/.ssh/yorc.pem"},
+		},
+		vaultToken: "vaulttoken",
+	}
+	secrets := ec.secrets()
+	sort.Strings(secrets)
+	require.Equal(t, []string{"captoken", "hostpass", "s3cr3t", "vaulttoken"}, secrets)
+}
+
 func testExecution(t *testing.T, srv1 *testutil.TestServer, kv *api.KV) {
 	deploymentID := yorc_testutil.BuildDeploymentID(t)
 	err := deployments.StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/execTemplate.yml")
@@ -596,3 +652,86 @@ func testExecutionGenerateOnRelationshipTarget(t *testing.T, kv *api.KV, deploym
 	t.Log(writer.String())
 	compareStringsIgnoreWhitespace(t, expectedResult, writer.String())
 }
+
+func TestGenerateHostConnectionDocker(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		host *hostConnection
+		want string
+	}{
+		{"LocalDocker", &hostConnection{host: "172.17.0.2", connectionType: "docker", containerID: "0123456789ab"},
+			"172.17.0.2 ansible_connection=docker ansible_host=0123456789ab\n"},
+		{"RemoteDocker", &hostConnection{host: "172.17.0.2", connectionType: "docker", containerID: "0123456789ab", dockerHost: "tcp://10.0.0.1:2376"},
+			"172.17.0.2 ansible_connection=docker ansible_host=0123456789ab ansible_docker_extra_args=\"-H tcp://10.0.0.1:2376\"\n"},
+		{"RemoteDockerTLS", &hostConnection{host: "172.17.0.2", connectionType: "docker", containerID: "0123456789ab", dockerHost: "tcp://10.0.0.1:2376", dockerCertPath: "/etc/docker/certs"},
+			"172.17.0.2 ansible_connection=docker ansible_host=0123456789ab ansible_docker_extra_args=\"-H tcp://10.0.0.1:2376 --tls --tlscert /etc/docker/certs/cert.pem --tlskey /etc/docker/certs/key.pem\"\n"},
+		{"RemoteDockerTLSVerify", &hostConnection{host: "172.17.0.2", connectionType: "docker", containerID: "0123456789ab", dockerHost: "tcp://10.0.0.1:2376", dockerCertPath: "/etc/docker/certs", dockerTLSVerify: true},
+			"172.17.0.2 ansible_connection=docker ansible_host=0123456789ab ansible_docker_extra_args=\"-H tcp://10.0.0.1:2376 --tlsverify --tlscacert /etc/docker/certs/ca.pem --tlscert /etc/docker/certs/cert.pem --tlskey /etc/docker/certs/key.pem\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &executionCommon{cfg: GetConfig(), deploymentID: "d1"}
+			var buffer bytes.Buffer
+			err := e.generateHostConnection(context.Background(), &buffer, tt.host)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, buffer.String())
+		})
+	}
+}
+
+func TestGenerateHostConnectionBastion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		host *hostConnection
+		want string
+	}{
+		{"Bastion", &hostConnection{host: "10.0.0.2", user: "centos", privateKey: "~/.ssh/yorc.pem",
+			bastion: &sshutil.BastionHost{Host: "bastion.example.com", User: "cloud", PrivateKey: "~/.ssh/bastion.pem"}},
+			"10.0.0.2 ansible_ssh_user=centos ansible_ssh_common_args=\"-o ConnectionAttempts=20 -o ProxyCommand='ssh -W %h:%p -q -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -p 22 cloud@bastion.example.com'\"\n"},
+		{"ChainedBastions", &hostConnection{host: "10.0.0.2", user: "centos", privateKey: "~/.ssh/yorc.pem",
+			bastion: &sshutil.BastionHost{Host: "10.0.0.1", Port: 2222, PrivateKey: "~/.ssh/bastion.pem",
+				Bastion: &sshutil.BastionHost{Host: "bastion.example.com", User: "cloud", PrivateKey: "~/.ssh/bastion.pem"}}},
+			"10.0.0.2 ansible_ssh_user=centos ansible_ssh_common_args=\"-o ConnectionAttempts=20 -o ProxyCommand='ssh -W %h:%p -q -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -p 2222 -o ProxyCommand=\\\"ssh -W %%h:%%p -q -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -p 22 cloud@bastion.example.com\\\" root@10.0.0.1'\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &executionCommon{cfg: GetConfig(), deploymentID: "d1"}
+			var buffer bytes.Buffer
+			err := e.generateHostConnection(context.Background(), &buffer, tt.host)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, buffer.String())
+		})
+	}
+}
+
+func TestGetBastionProxyCommandWithKeys(t *testing.T) {
+	t.Parallel()
+	keysDir, err := ioutil.TempDir("", "yorc-bastion-keys")
+	require.NoError(t, err)
+	defer os.RemoveAll(keysDir)
+	keys := make([]string, 3)
+	for i := range keys {
+		keys[i] = filepath.Join(keysDir, fmt.Sprintf("bastion%d.pem", i))
+		require.NoError(t, ioutil.WriteFile(keys[i], []byte("key"), 0600))
+	}
+	bastion := &sshutil.BastionHost{Host: "10.0.0.2", PrivateKey: keys[0],
+		Bastion: &sshutil.BastionHost{Host: "10.0.0.1", PrivateKey: keys[1],
+			Bastion: &sshutil.BastionHost{Host: "bastion.example.com", User: "cloud", PrivateKey: keys[2]}}}
+
+	cfg := GetConfig()
+	cfg.DisableSSHAgent = true
+	e := &executionCommon{cfg: cfg, deploymentID: "d1"}
+	proxyCommand, err := e.getBastionProxyCommand(context.Background(), bastion)
+	require.NoError(t, err)
+	want := fmt.Sprintf(`ssh -W %%h:%%p -q -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -i %s -p 22 `+
+		`-o ProxyCommand="ssh -W %%%%h:%%%%p -q -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -i %s -p 22 `+
+		`-o ProxyCommand=\"ssh -W %%%%%%%%h:%%%%%%%%p -q -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -i %s -p 22 cloud@bastion.example.com\" root@10.0.0.1" root@10.0.0.2`,
+		keys[0], keys[1], keys[2])
+	require.Equal(t, want, proxyCommand)
+
+	bastion.Bastion.Bastion.PrivateKey = filepath.Join(keysDir, "missing.pem")
+	_, err = e.getBastionProxyCommand(context.Background(), bastion)
+	require.Error(t, err)
+}
@@ -88,6 +88,9 @@ func (e *defaultExecutor) ExecOperation(ctx context.Context, conf config.Configu
 		return err
 	}
 
+	// Secrets used by the execution should not appear in logs
+	defer events.RegisterSecrets(ctx, exec.secrets()...)()
+
 	instances, err := tasks.GetInstances(kv, taskID, deploymentID, nodeName)
 	if err != nil {
 		return err
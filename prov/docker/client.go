@@ -0,0 +1,59 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"github.com/docker/docker/api"
+	"github.com/docker/go-connections/tlsconfig"
+	"github.com/moby/moby/client"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+)
+
+// newClient creates a Docker client for the Docker host defined in the docker infrastructure configuration.
+//
+// Without configuration the client is created from the standard DOCKER_HOST, DOCKER_API_VERSION,
+// DOCKER_CERT_PATH and DOCKER_TLS_VERIFY environment variables.
+func newClient(cfg config.Configuration) (*client.Client, error) {
+	infraCfg := cfg.Infrastructures[infrastructureName]
+	if infraCfg == nil || infraCfg.GetString("host") == "" {
+		cli, err := client.NewEnvClient()
+		return cli, errors.Wrap(err, "failed to create docker client from environment")
+	}
+
+	var httpClient *http.Client
+	if certPath := infraCfg.GetString("cert_path"); certPath != "" {
+		tlsc, err := tlsconfig.Client(tlsconfig.Options{
+			CAFile:             filepath.Join(certPath, "ca.pem"),
+			CertFile:           filepath.Join(certPath, "cert.pem"),
+			KeyFile:            filepath.Join(certPath, "key.pem"),
+			InsecureSkipVerify: !infraCfg.GetBool("tls_verify"),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load docker client TLS configuration")
+		}
+		httpClient = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsc,
+			},
+		}
+	}
+	cli, err := client.NewClient(infraCfg.GetString("host"), infraCfg.GetStringOrDefault("api_version", api.DefaultVersion), httpClient, nil)
+	return cli, errors.Wrapf(err, "failed to create docker client for host %q", infraCfg.GetString("host"))
+}
@@ -0,0 +1,321 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/strslice"
+	"github.com/docker/go-connections/nat"
+	"github.com/hashicorp/consul/api"
+	"github.com/moby/moby/client"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+)
+
+const (
+	connectionTypeDocker = "docker"
+	connectionTypeSSH    = "ssh"
+)
+
+var invalidContainerNameChars = regexp.MustCompile("[^a-zA-Z0-9_.-]")
+
+// containerSpec holds the definition of the container provisioned for a yorc.nodes.docker.Compute instance
+type containerSpec struct {
+	name           string
+	image          string
+	command        []string
+	env            map[string]string
+	networks       []string
+	portMappings   []string
+	volumes        []string
+	privileged     bool
+	connectionType string
+	labels         map[string]string
+}
+
+// containerName returns a container name unique for a given node instance
+func containerName(deploymentID, nodeName, instance string) string {
+	return invalidContainerNameChars.ReplaceAllString(fmt.Sprintf("%s-%s-%s", deploymentID, nodeName, instance), "-")
+}
+
+func readContainerSpec(kv *api.KV, deploymentID, nodeName, instance string) (*containerSpec, error) {
+	spec := &containerSpec{
+		name: containerName(deploymentID, nodeName, instance),
+		labels: map[string]string{
+			"yorc.deployment_id": deploymentID,
+			"yorc.node":          nodeName,
+			"yorc.instance":      instance,
+		},
+	}
+	image, err := deployments.GetNodePropertyValue(kv, deploymentID, nodeName, "image")
+	if err != nil {
+		return nil, err
+	}
+	if image == nil || image.RawString() == "" {
+		return nil, errors.Errorf("missing mandatory property \"image\" for node %q", nodeName)
+	}
+	spec.image = image.RawString()
+
+	for prop, dest := range map[string]interface{}{
+		"command":       &spec.command,
+		"env":           &spec.env,
+		"networks":      &spec.networks,
+		"port_mappings": &spec.portMappings,
+		"volumes":       &spec.volumes,
+	} {
+		err = readComplexProperty(kv, deploymentID, nodeName, prop, dest)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	privileged, err := deployments.GetNodePropertyValue(kv, deploymentID, nodeName, "privileged")
+	if err != nil {
+		return nil, err
+	}
+	if privileged != nil && privileged.RawString() != "" {
+		spec.privileged, err = strconv.ParseBool(privileged.RawString())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse property \"privileged\" for node %q", nodeName)
+		}
+	}
+
+	spec.connectionType = connectionTypeDocker
+	connectionType, err := deployments.GetNodePropertyValue(kv, deploymentID, nodeName, "connection_type")
+	if err != nil {
+		return nil, err
+	}
+	if connectionType != nil && connectionType.RawString() != "" {
+		spec.connectionType = connectionType.RawString()
+	}
+	if spec.connectionType != connectionTypeDocker && spec.connectionType != connectionTypeSSH {
+		return nil, errors.Errorf("unsupported connection type %q for node %q, supported types are %q and %q", spec.connectionType, nodeName, connectionTypeDocker, connectionTypeSSH)
+	}
+	return spec, nil
+}
+
+func readComplexProperty(kv *api.KV, deploymentID, nodeName, propertyName string, dest interface{}) error {
+	p, err := deployments.GetNodePropertyValue(kv, deploymentID, nodeName, propertyName)
+	if err != nil || p == nil || p.RawString() == "" {
+		return err
+	}
+	err = json.Unmarshal([]byte(p.RawString()), dest)
+	return errors.Wrapf(err, "failed to parse property %q for node %q as json %q", propertyName, nodeName, p.RawString())
+}
+
+// dockerConfigs translates a container spec into the Docker API structures used to create the container
+func (s *containerSpec) dockerConfigs() (*container.Config, *container.HostConfig, *network.NetworkingConfig, error) {
+	exposedPorts, portBindings, err := nat.ParsePortSpecs(s.portMappings)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "invalid port mappings %v", s.portMappings)
+	}
+
+	env := make([]string, 0, len(s.env))
+	for k, v := range s.env {
+		env = append(env, k+"="+v)
+	}
+	// Keep a stable order to ease troubleshooting
+	sort.Strings(env)
+
+	cc := &container.Config{
+		Image:        s.image,
+		Env:          env,
+		ExposedPorts: exposedPorts,
+		Labels:       s.labels,
+	}
+	if len(s.command) > 0 {
+		cc.Cmd = strslice.StrSlice(s.command)
+	} else if s.connectionType == connectionTypeDocker {
+		// Most images exit immediately without a command, keep the container alive to run operations in it
+		cc.Cmd = strslice.StrSlice{"sleep", "infinity"}
+	}
+
+	hc := &container.HostConfig{
+		Binds:        s.volumes,
+		PortBindings: portBindings,
+		Privileged:   s.privileged,
+	}
+
+	var nc *network.NetworkingConfig
+	if len(s.networks) > 0 {
+		// Only one network could be given at creation time, others are connected before starting the container
+		hc.NetworkMode = container.NetworkMode(s.networks[0])
+		nc = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{s.networks[0]: {}},
+		}
+	}
+	return cc, hc, nc, nil
+}
+
+// createContainer pulls the image then creates and starts a container, it returns the started container details
+func createContainer(ctx context.Context, cli *client.Client, deploymentID string, spec *containerSpec) (types.ContainerJSON, error) {
+	cc, hc, nc, err := spec.dockerConfigs()
+	if err != nil {
+		return types.ContainerJSON{}, err
+	}
+
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, deploymentID).Registerf("Pulling docker image: %s", spec.image)
+	pullResp, err := cli.ImagePull(ctx, spec.image, types.ImagePullOptions{})
+	if err != nil {
+		return types.ContainerJSON{}, errors.Wrapf(err, "failed to pull docker image %q", spec.image)
+	}
+	// Reading the response waits for the pull completion
+	err = readImagePullResponse(pullResp)
+	pullResp.Close()
+	if err != nil {
+		return types.ContainerJSON{}, errors.Wrapf(err, "failed to pull docker image %q", spec.image)
+	}
+
+	err = removeStaleContainer(ctx, cli, deploymentID, spec)
+	if err != nil {
+		return types.ContainerJSON{}, err
+	}
+
+	createResp, err := cli.ContainerCreate(ctx, cc, hc, nc, spec.name)
+	if err != nil {
+		return types.ContainerJSON{}, errors.Wrapf(err, "failed to create docker container %q", spec.name)
+	}
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, deploymentID).Registerf("Docker container %q created with id %q", spec.name, createResp.ID)
+
+	if len(spec.networks) > 1 {
+		for _, net := range spec.networks[1:] {
+			err = cli.NetworkConnect(ctx, net, createResp.ID, &network.EndpointSettings{})
+			if err != nil {
+				removeContainer(ctx, cli, deploymentID, createResp.ID)
+				return types.ContainerJSON{}, errors.Wrapf(err, "failed to connect docker container %q to network %q", spec.name, net)
+			}
+		}
+	}
+
+	err = cli.ContainerStart(ctx, createResp.ID, types.ContainerStartOptions{})
+	if err != nil {
+		removeContainer(ctx, cli, deploymentID, createResp.ID)
+		return types.ContainerJSON{}, errors.Wrapf(err, "failed to start docker container %q", spec.name)
+	}
+
+	inspect, err := cli.ContainerInspect(ctx, createResp.ID)
+	return inspect, errors.Wrapf(err, "failed to inspect docker container %q", spec.name)
+}
+
+// pullMessage is the part of the JSON messages streamed by an image pull used to detect failures
+type pullMessage struct {
+	Error       string `json:"error"`
+	ErrorDetail *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+}
+
+// readImagePullResponse consumes the messages streamed by an image pull and returns the error reported by the Docker daemon if any
+func readImagePullResponse(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	for {
+		var msg pullMessage
+		err := dec.Decode(&msg)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read image pull progress")
+		}
+		if msg.ErrorDetail != nil && msg.ErrorDetail.Message != "" {
+			return errors.New(msg.ErrorDetail.Message)
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+	}
+}
+
+// removeStaleContainer removes a container named after the instance left by a previous attempt,
+// a container with this name not created for this instance is an error as it is not ours to remove
+func removeStaleContainer(ctx context.Context, cli *client.Client, deploymentID string, spec *containerSpec) error {
+	existing, err := cli.ContainerInspect(ctx, spec.name)
+	if client.IsErrNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to inspect docker container %q", spec.name)
+	}
+	if existing.Config == nil || !isOwnedBy(existing.Config.Labels, spec.labels) {
+		return errors.Errorf("a docker container named %q already exists and was not created for this instance", spec.name)
+	}
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, deploymentID).Registerf("Removing docker container %q left by a previous attempt", spec.name)
+	return removeContainer(ctx, cli, deploymentID, existing.ID)
+}
+
+// isOwnedBy checks that a container carries all the given labels
+func isOwnedBy(containerLabels, labels map[string]string) bool {
+	for k, v := range labels {
+		if containerLabels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// removeContainer stops and removes a container and its anonymous volumes, a missing container is not an error
+func removeContainer(ctx context.Context, cli *client.Client, deploymentID, containerID string) error {
+	timeout := 10 * time.Second
+	err := cli.ContainerStop(ctx, containerID, &timeout)
+	if err != nil && !client.IsErrNotFound(err) {
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, deploymentID).Registerf("Failed to stop docker container %q: %v", containerID, err)
+	}
+	err = cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+	if err != nil && !client.IsErrNotFound(err) {
+		return errors.Wrapf(err, "failed to remove docker container %q", containerID)
+	}
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, deploymentID).Registerf("Docker container %q removed", containerID)
+	return nil
+}
+
+// containerIPAddress returns the IP address of a container on its first network
+func containerIPAddress(c types.ContainerJSON, networks []string) string {
+	if c.NetworkSettings == nil {
+		return ""
+	}
+	if len(networks) > 0 {
+		if settings, ok := c.NetworkSettings.Networks[networks[0]]; ok && settings != nil {
+			return settings.IPAddress
+		}
+	}
+	if c.NetworkSettings.IPAddress != "" {
+		return c.NetworkSettings.IPAddress
+	}
+	// Fallback to any network in a predictable order
+	names := make([]string, 0, len(c.NetworkSettings.Networks))
+	for name := range c.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if settings := c.NetworkSettings.Networks[name]; settings != nil && settings.IPAddress != "" {
+			return settings.IPAddress
+		}
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/strslice"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "my-dep-Compute-0", containerName("my-dep", "Compute", "0"))
+	assert.Equal(t, "my-dep-Compute_1-12", containerName("my dep", "Compute_1", "12"))
+}
+
+func TestContainerSpecDockerConfigs(t *testing.T) {
+	t.Parallel()
+	spec := &containerSpec{
+		name:           "c1",
+		image:          "ubuntu:18.04",
+		env:            map[string]string{"B": "2", "A": "1"},
+		networks:       []string{"net1", "net2"},
+		portMappings:   []string{"8080:80/tcp"},
+		volumes:        []string{"/tmp:/data:ro"},
+		privileged:     true,
+		connectionType: connectionTypeDocker,
+	}
+	cc, hc, nc, err := spec.dockerConfigs()
+	require.NoError(t, err)
+	assert.Equal(t, "ubuntu:18.04", cc.Image)
+	assert.Equal(t, []string{"A=1", "B=2"}, cc.Env)
+	assert.Equal(t, strslice.StrSlice{"sleep", "infinity"}, cc.Cmd)
+	assert.Contains(t, cc.ExposedPorts, nat.Port("80/tcp"))
+	assert.Equal(t, []nat.PortBinding{{HostPort: "8080"}}, hc.PortBindings[nat.Port("80/tcp")])
+	assert.Equal(t, []string{"/tmp:/data:ro"}, hc.Binds)
+	assert.True(t, hc.Privileged)
+	assert.Equal(t, container.NetworkMode("net1"), hc.NetworkMode)
+	require.NotNil(t, nc)
+	assert.Len(t, nc.EndpointsConfig, 1)
+	assert.Contains(t, nc.EndpointsConfig, "net1")
+
+	// SSH containers keep the image command
+	spec = &containerSpec{image: "sshd", connectionType: connectionTypeSSH}
+	cc, _, nc, err = spec.dockerConfigs()
+	require.NoError(t, err)
+	assert.Len(t, cc.Cmd, 0)
+	assert.Nil(t, nc)
+
+	spec = &containerSpec{image: "sshd", command: []string{"/usr/sbin/sshd", "-D"}, connectionType: connectionTypeSSH}
+	cc, _, _, err = spec.dockerConfigs()
+	require.NoError(t, err)
+	assert.Equal(t, strslice.StrSlice{"/usr/sbin/sshd", "-D"}, cc.Cmd)
+
+	spec = &containerSpec{image: "sshd", portMappings: []string{"8080:80/nope"}}
+	_, _, _, err = spec.dockerConfigs()
+	assert.Error(t, err)
+}
+
+func TestContainerIPAddress(t *testing.T) {
+	t.Parallel()
+	c := types.ContainerJSON{NetworkSettings: &types.NetworkSettings{
+		DefaultNetworkSettings: types.DefaultNetworkSettings{IPAddress: "172.17.0.2"},
+		Networks: map[string]*network.EndpointSettings{
+			"net2": {IPAddress: "10.0.2.2"},
+			"net1": {IPAddress: "10.0.1.2"},
+		},
+	}}
+	assert.Equal(t, "10.0.2.2", containerIPAddress(c, []string{"net2", "net1"}))
+	assert.Equal(t, "172.17.0.2", containerIPAddress(c, nil))
+	c.NetworkSettings.IPAddress = ""
+	assert.Equal(t, "10.0.1.2", containerIPAddress(c, nil))
+	assert.Equal(t, "", containerIPAddress(types.ContainerJSON{}, nil))
+}
+
+func TestReadImagePullResponse(t *testing.T) {
+	t.Parallel()
+	err := readImagePullResponse(strings.NewReader(`{"status":"Pulling from library/ubuntu","id":"18.04"}
+{"status":"Pull complete","progressDetail":{},"id":"7413c47ba209"}
+{"status":"Status: Downloaded newer image for ubuntu:18.04"}
+`))
+	require.NoError(t, err)
+
+	err = readImagePullResponse(strings.NewReader(`{"status":"Pulling from library/ubuntu","id":"18.04"}
+{"errorDetail":{"message":"unauthorized: authentication required"},"error":"unauthorized: authentication required"}
+`))
+	require.Error(t, err)
+	assert.Equal(t, "unauthorized: authentication required", err.Error())
+
+	err = readImagePullResponse(strings.NewReader(`{"error":"manifest unknown"}`))
+	require.Error(t, err)
+	assert.Equal(t, "manifest unknown", err.Error())
+
+	err = readImagePullResponse(strings.NewReader(`not json`))
+	require.Error(t, err)
+}
+
+func TestIsOwnedBy(t *testing.T) {
+	t.Parallel()
+	labels := map[string]string{"yorc.deployment_id": "dep", "yorc.node": "Compute", "yorc.instance": "0"}
+	assert.True(t, isOwnedBy(map[string]string{"yorc.deployment_id": "dep", "yorc.node": "Compute", "yorc.instance": "0", "other": "x"}, labels))
+	assert.False(t, isOwnedBy(map[string]string{"yorc.deployment_id": "dep", "yorc.node": "Compute", "yorc.instance": "1"}, labels))
+	assert.False(t, isOwnedBy(nil, labels))
+}
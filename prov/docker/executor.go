@@ -0,0 +1,192 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/moby/moby/client"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/tasks"
+	"github.com/ystia/yorc/tosca"
+)
+
+type defaultExecutor struct {
+}
+
+// dockerHostConfig holds how the docker command line reaches the configured Docker host
+type dockerHostConfig struct {
+	host      string
+	certPath  string
+	tlsVerify bool
+}
+
+func (e *defaultExecutor) ExecDelegate(ctx context.Context, cfg config.Configuration, taskID, deploymentID, nodeName, delegateOperation string) error {
+	cc, err := cfg.GetConsulClient()
+	if err != nil {
+		return err
+	}
+	kv := cc.KV()
+
+	instances, err := tasks.GetInstances(kv, taskID, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+
+	cli, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	var dockerHost dockerHostConfig
+	if infraCfg := cfg.Infrastructures[infrastructureName]; infraCfg != nil {
+		dockerHost = dockerHostConfig{
+			host:      infraCfg.GetString("host"),
+			certPath:  infraCfg.GetString("cert_path"),
+			tlsVerify: infraCfg.GetBool("tls_verify"),
+		}
+	}
+
+	switch strings.ToLower(delegateOperation) {
+	case "install":
+		for _, instance := range instances {
+			instanceCtx := events.AddLogOptionalFields(ctx, events.LogOptionalFields{events.InstanceID: instance})
+			deployments.SetInstanceStateWithContextualLogs(instanceCtx, kv, deploymentID, nodeName, instance, tosca.NodeStateCreating)
+			err = e.installInstance(instanceCtx, kv, cli, dockerHost, deploymentID, nodeName, instance)
+			if err != nil {
+				return err
+			}
+			deployments.SetInstanceStateWithContextualLogs(instanceCtx, kv, deploymentID, nodeName, instance, tosca.NodeStateStarted)
+		}
+		return nil
+	case "uninstall":
+		for _, instance := range instances {
+			instanceCtx := events.AddLogOptionalFields(ctx, events.LogOptionalFields{events.InstanceID: instance})
+			deployments.SetInstanceStateWithContextualLogs(instanceCtx, kv, deploymentID, nodeName, instance, tosca.NodeStateDeleting)
+			err = e.uninstallInstance(instanceCtx, kv, cli, deploymentID, nodeName, instance)
+			if err != nil {
+				return err
+			}
+			deployments.SetInstanceStateWithContextualLogs(instanceCtx, kv, deploymentID, nodeName, instance, tosca.NodeStateDeleted)
+		}
+		return nil
+	}
+	return errors.Errorf("operation %q not supported", delegateOperation)
+}
+
+func (e *defaultExecutor) installInstance(ctx context.Context, kv *api.KV, cli *client.Client, dockerHost dockerHostConfig, deploymentID, nodeName, instance string) (err error) {
+	spec, err := readContainerSpec(kv, deploymentID, nodeName, instance)
+	if err != nil {
+		return err
+	}
+	c, err := createContainer(ctx, cli, deploymentID, spec)
+	if err != nil {
+		return err
+	}
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, deploymentID).Registerf("Docker container %q started", spec.name)
+	defer func() {
+		if err != nil {
+			// Do not leave behind a container that the instance attributes do not reference
+			if errRemove := removeContainer(ctx, cli, deploymentID, c.ID); errRemove != nil {
+				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, deploymentID).Registerf("Failed to remove docker container %q: %v", spec.name, errRemove)
+			}
+		}
+	}()
+
+	err = deployments.SetInstanceAttribute(deploymentID, nodeName, instance, "container_id", c.ID)
+	if err != nil {
+		return err
+	}
+	err = deployments.SetInstanceAttribute(deploymentID, nodeName, instance, "container_name", spec.name)
+	if err != nil {
+		return err
+	}
+
+	ipAddress := containerIPAddress(c, spec.networks)
+	if ipAddress == "" {
+		if spec.connectionType == connectionTypeSSH {
+			return errors.Errorf("no IP address found for docker container %q, it can't be reached using SSH", spec.name)
+		}
+		// Operations are executed using docker exec, the container name is enough to reach it
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, deploymentID).Registerf("No IP address found for docker container %q, using its name as address", spec.name)
+		ipAddress = spec.name
+	}
+	err = deployments.SetInstanceAttribute(deploymentID, nodeName, instance, "ip_address", ipAddress)
+	if err != nil {
+		return err
+	}
+	err = deployments.SetInstanceAttribute(deploymentID, nodeName, instance, "private_address", ipAddress)
+	if err != nil {
+		return err
+	}
+	err = deployments.SetInstanceCapabilityAttribute(deploymentID, nodeName, instance, "endpoint", "ip_address", ipAddress)
+	if err != nil {
+		return err
+	}
+
+	// Those endpoint attributes allow operations to be executed using docker exec rather than SSH
+	err = deployments.SetInstanceCapabilityAttribute(deploymentID, nodeName, instance, "endpoint", "connection_type", spec.connectionType)
+	if err != nil {
+		return err
+	}
+	if spec.connectionType == connectionTypeDocker {
+		err = deployments.SetInstanceCapabilityAttribute(deploymentID, nodeName, instance, "endpoint", "container_id", c.ID)
+		if err != nil {
+			return err
+		}
+		if dockerHost.host != "" {
+			err = setDockerHostAttributes(deploymentID, nodeName, instance, dockerHost)
+		}
+	}
+	return err
+}
+
+// setDockerHostAttributes stores the Docker host connection in endpoint attributes, the TLS ones are only set when TLS is configured
+func setDockerHostAttributes(deploymentID, nodeName, instance string, dockerHost dockerHostConfig) error {
+	err := deployments.SetInstanceCapabilityAttribute(deploymentID, nodeName, instance, "endpoint", "docker_host", dockerHost.host)
+	if err != nil || dockerHost.certPath == "" {
+		return err
+	}
+	err = deployments.SetInstanceCapabilityAttribute(deploymentID, nodeName, instance, "endpoint", "docker_cert_path", dockerHost.certPath)
+	if err != nil {
+		return err
+	}
+	return deployments.SetInstanceCapabilityAttribute(deploymentID, nodeName, instance, "endpoint", "docker_tls_verify", strconv.FormatBool(dockerHost.tlsVerify))
+}
+
+func (e *defaultExecutor) uninstallInstance(ctx context.Context, kv *api.KV, cli *client.Client, deploymentID, nodeName, instance string) error {
+	containerID := containerName(deploymentID, nodeName, instance)
+	id, err := deployments.GetInstanceAttributeValue(kv, deploymentID, nodeName, instance, "container_id")
+	if err != nil {
+		return err
+	}
+	if id != nil && id.RawString() != "" {
+		containerID = id.RawString()
+	}
+	err = removeContainer(ctx, cli, deploymentID, containerID)
+	if err != nil {
+		return err
+	}
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, deploymentID).Registerf("Docker container %q deleted", containerID)
+	return nil
+}
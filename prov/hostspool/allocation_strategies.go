@@ -0,0 +1,236 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+const (
+	// FirstFitStrategy selects the first host matching an allocation request
+	FirstFitStrategy = "first_fit"
+	// BestFitStrategy selects the host whose available resources best fit the resources requested by an allocation
+	BestFitStrategy = "best_fit"
+	// SpreadStrategy selects the least allocated host
+	SpreadStrategy = "spread"
+	// LabelsWeightedStrategy selects the host having the highest score computed from weighted labels
+	LabelsWeightedStrategy = "labels_weighted"
+)
+
+// DefaultAllocationStrategy is the allocation strategy used when none is specified
+const DefaultAllocationStrategy = FirstFitStrategy
+
+// resourcesLabels are the host resources labels tracked by Manager.UpdateResourcesLabels
+var resourcesLabels = []string{"host.num_cpus", "host.mem_size", "host.disk_size"}
+
+// An AllocationStrategy scores hosts candidates to an allocation.
+//
+// The host having the highest score is selected, hosts having the same score are
+// considered in the order they are listed in the pool.
+type AllocationStrategy interface {
+	// Name returns the name of the strategy as it is reported in logs
+	Name() string
+	// Score returns the score of the given host for the given allocation
+	Score(host Host, allocation *Allocation) (float64, error)
+}
+
+// An AllocationStrategyFactory creates an AllocationStrategy using the given options
+type AllocationStrategyFactory func(options map[string]interface{}) (AllocationStrategy, error)
+
+var allocationStrategiesLock sync.RWMutex
+var allocationStrategies = map[string]AllocationStrategyFactory{
+	FirstFitStrategy:       func(options map[string]interface{}) (AllocationStrategy, error) { return firstFit{}, nil },
+	BestFitStrategy:        func(options map[string]interface{}) (AllocationStrategy, error) { return bestFit{}, nil },
+	SpreadStrategy:         func(options map[string]interface{}) (AllocationStrategy, error) { return spread{}, nil },
+	LabelsWeightedStrategy: newLabelsWeighted,
+}
+
+// RegisterAllocationStrategy allows to register a custom allocation strategy under the given name.
+//
+// Names are case-insensitive. Registering a strategy using an existing name replaces the previous one.
+func RegisterAllocationStrategy(name string, factory AllocationStrategyFactory) {
+	allocationStrategiesLock.Lock()
+	defer allocationStrategiesLock.Unlock()
+	allocationStrategies[strings.ToLower(name)] = factory
+}
+
+// NewAllocationStrategy returns the allocation strategy registered under the given name.
+//
+// DefaultAllocationStrategy is used if name is empty.
+func NewAllocationStrategy(name string, options map[string]interface{}) (AllocationStrategy, error) {
+	if name == "" {
+		name = DefaultAllocationStrategy
+	}
+	allocationStrategiesLock.RLock()
+	factory, ok := allocationStrategies[strings.ToLower(name)]
+	allocationStrategiesLock.RUnlock()
+	if !ok {
+		return nil, errors.WithStack(badRequestError{fmt.Sprintf("unknown hosts pool allocation strategy %q", name)})
+	}
+	return factory(options)
+}
+
+// rankHosts sorts hosts by decreasing score according to the given strategy,
+// preserving the original order of hosts having the same score.
+//
+// Scores of hosts are returned in the sorted order.
+func rankHosts(strategy AllocationStrategy, hosts []Host, allocation *Allocation) ([]Host, []float64, error) {
+	scores := make(map[string]float64, len(hosts))
+	for _, h := range hosts {
+		s, err := strategy.Score(h, allocation)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to compute %q allocation strategy score of host %q", strategy.Name(), h.Name)
+		}
+		scores[h.Name] = s
+	}
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return scores[hosts[i].Name] > scores[hosts[j].Name]
+	})
+	res := make([]float64, len(hosts))
+	for i, h := range hosts {
+		res[i] = scores[h.Name]
+	}
+	return hosts, res, nil
+}
+
+// parseLabelValue parses a label value as a number, possibly followed by a bytes unit
+func parseLabelValue(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f, nil
+	}
+	b, err := humanize.ParseBytes(value)
+	if err != nil {
+		return 0, errors.Errorf("label value %q is not a number", value)
+	}
+	return float64(b), nil
+}
+
+type firstFit struct{}
+
+func (firstFit) Name() string {
+	return FirstFitStrategy
+}
+
+func (firstFit) Score(host Host, allocation *Allocation) (float64, error) {
+	return 0, nil
+}
+
+// bestFit favors hosts that will have the least remaining resources once the allocation done.
+//
+// For each tracked resource label defined on a host, the remaining ratio of this resource after
+// allocation is computed, the score is the opposite of the mean of those ratios. Hosts without
+// resources labels are considered as the less fitting ones.
+type bestFit struct{}
+
+func (bestFit) Name() string {
+	return BestFitStrategy
+}
+
+func (bestFit) Score(host Host, allocation *Allocation) (float64, error) {
+	var sum float64
+	var nb int
+	for _, label := range resourcesLabels {
+		availableStr, ok := host.Labels[label]
+		if !ok {
+			continue
+		}
+		available, err := parseLabelValue(availableStr)
+		if err != nil {
+			return 0, err
+		}
+		if available <= 0 {
+			continue
+		}
+		var requested float64
+		if requestedStr, ok := allocation.Resources[label]; ok {
+			requested, err = parseLabelValue(requestedStr)
+			if err != nil {
+				return 0, err
+			}
+		}
+		sum += (available - requested) / available
+		nb++
+	}
+	if nb == 0 {
+		// Available resources are unknown, consider that they will all remain
+		return -1, nil
+	}
+	return -sum / float64(nb), nil
+}
+
+// spread favors hosts having the least allocations
+type spread struct{}
+
+func (spread) Name() string {
+	return SpreadStrategy
+}
+
+func (spread) Score(host Host, allocation *Allocation) (float64, error) {
+	return -float64(len(host.Allocations)), nil
+}
+
+// labelsWeighted scores hosts by summing the numeric values of their labels multiplied by a weight.
+//
+// Labels that are not numbers count as 1 and labels not defined on a host count as 0.
+type labelsWeighted struct {
+	weights map[string]float64
+}
+
+func newLabelsWeighted(options map[string]interface{}) (AllocationStrategy, error) {
+	weights, err := cast.ToStringMapE(options["labels_weights"])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %q option for %q allocation strategy", "labels_weights", LabelsWeightedStrategy)
+	}
+	if len(weights) == 0 {
+		return nil, errors.WithStack(badRequestError{fmt.Sprintf("%q allocation strategy requires labels weights", LabelsWeightedStrategy)})
+	}
+	lw := labelsWeighted{weights: make(map[string]float64, len(weights))}
+	for label, weight := range weights {
+		lw.weights[label], err = cast.ToFloat64E(weight)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid weight for label %q", label)
+		}
+	}
+	return lw, nil
+}
+
+func (lw labelsWeighted) Name() string {
+	return LabelsWeightedStrategy
+}
+
+func (lw labelsWeighted) Score(host Host, allocation *Allocation) (float64, error) {
+	var score float64
+	for label, weight := range lw.weights {
+		value, ok := host.Labels[label]
+		if !ok {
+			continue
+		}
+		f, err := parseLabelValue(value)
+		if err != nil {
+			f = 1
+		}
+		score += weight * f
+	}
+	return score, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAllocationStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		options  map[string]interface{}
+		want     string
+		wantErr  bool
+	}{
+		{"DefaultStrategy", "", nil, FirstFitStrategy, false},
+		{"FirstFit", "first_fit", nil, FirstFitStrategy, false},
+		{"BestFitCaseInsensitive", "Best_Fit", nil, BestFitStrategy, false},
+		{"Spread", "spread", nil, SpreadStrategy, false},
+		{"LabelsWeighted", "labels_weighted", map[string]interface{}{"labels_weights": map[string]interface{}{"gpu": 2, "host.num_cpus": "0.5"}}, LabelsWeightedStrategy, false},
+		{"LabelsWeightedWithoutWeights", "labels_weighted", nil, "", true},
+		{"LabelsWeightedInvalidWeight", "labels_weighted", map[string]interface{}{"labels_weights": map[string]interface{}{"gpu": "high"}}, "", true},
+		{"UnknownStrategy", "random", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewAllocationStrategy(tt.strategy, tt.options)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, s.Name())
+		})
+	}
+}
+
+type testReverseStrategy struct{}
+
+func (testReverseStrategy) Name() string {
+	return "reverse"
+}
+
+func (testReverseStrategy) Score(host Host, allocation *Allocation) (float64, error) {
+	return float64(len(host.Name)), nil
+}
+
+func TestRegisterAllocationStrategy(t *testing.T) {
+	RegisterAllocationStrategy("Reverse", func(options map[string]interface{}) (AllocationStrategy, error) {
+		return testReverseStrategy{}, nil
+	})
+	defer func() {
+		allocationStrategiesLock.Lock()
+		delete(allocationStrategies, "reverse")
+		allocationStrategiesLock.Unlock()
+	}()
+	s, err := NewAllocationStrategy("reverse", nil)
+	require.NoError(t, err)
+	hosts, scores, err := rankHosts(s, []Host{{Name: "h"}, {Name: "hhh"}, {Name: "hh"}}, &Allocation{})
+	require.NoError(t, err)
+	require.Equal(t, []Host{{Name: "hhh"}, {Name: "hh"}, {Name: "h"}}, hosts)
+	require.Equal(t, []float64{3, 2, 1}, scores)
+	_, err = NewAllocationStrategy("REVERSE", nil)
+	require.NoError(t, err, "strategies names should be case-insensitive")
+}
+
+func TestRankHosts(t *testing.T) {
+	small := Host{Name: "small", Labels: map[string]string{"host.num_cpus": "2", "host.mem_size": "4 GB"}}
+	big := Host{Name: "big", Labels: map[string]string{"host.num_cpus": "32", "host.mem_size": "128 GB", "gpu": "true"}}
+	medium := Host{Name: "medium", Labels: map[string]string{"host.num_cpus": "8", "host.mem_size": "16 GB", "gpu": "true"},
+		Allocations: []Allocation{{ID: "a1"}}}
+	noLabels := Host{Name: "nolabels", Allocations: []Allocation{{ID: "a2"}, {ID: "a3"}}}
+
+	tests := []struct {
+		name       string
+		strategy   string
+		options    map[string]interface{}
+		allocation *Allocation
+		want       []string
+	}{
+		{"FirstFit", FirstFitStrategy, nil, &Allocation{}, []string{"big", "small", "medium", "nolabels"}},
+		{"BestFit", BestFitStrategy, nil, &Allocation{Resources: map[string]string{"host.num_cpus": "2", "host.mem_size": "2 GB"}}, []string{"small", "medium", "big", "nolabels"}},
+		{"BestFitNoResources", BestFitStrategy, nil, &Allocation{}, []string{"big", "small", "medium", "nolabels"}},
+		{"Spread", SpreadStrategy, nil, &Allocation{}, []string{"big", "small", "medium", "nolabels"}},
+		{"LabelsWeighted", LabelsWeightedStrategy, map[string]interface{}{"labels_weights": map[string]interface{}{"gpu": 100, "host.num_cpus": -1}}, &Allocation{}, []string{"medium", "big", "nolabels", "small"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewAllocationStrategy(tt.strategy, tt.options)
+			require.NoError(t, err)
+			hosts, scores, err := rankHosts(s, []Host{big, small, medium, noLabels}, tt.allocation)
+			require.NoError(t, err)
+			names := make([]string, len(hosts))
+			for i := range hosts {
+				names[i] = hosts[i].Name
+			}
+			require.Equal(t, tt.want, names)
+			require.Len(t, scores, len(hosts))
+		})
+	}
+}
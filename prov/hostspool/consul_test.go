@@ -80,4 +80,22 @@ func TestRunConsulHostsPoolPackageTests(t *testing.T) {
 	t.Run("testConsulManagerAddLabelsWithAllocation", func(t *testing.T) {
 		testConsulManagerAddLabelsWithAllocation(t, client)
 	})
+	t.Run("testConsulManagerAllocatePlacements", func(t *testing.T) {
+		testConsulManagerAllocatePlacements(t, client)
+	})
+	t.Run("testConsulManagerMultiplePools", func(t *testing.T) {
+		testConsulManagerMultiplePools(t, client)
+	})
+	t.Run("testConsulManagerAllocateStrategies", func(t *testing.T) {
+		testConsulManagerAllocateStrategies(t, client)
+	})
+	t.Run("testConsulManagerAllocateOrWait", func(t *testing.T) {
+		testConsulManagerAllocateOrWait(t, client)
+	})
+	t.Run("testConsulManagerListPendingAllocations", func(t *testing.T) {
+		testConsulManagerListPendingAllocations(t, client)
+	})
+	t.Run("testConsulManagerUpdateHostStatus", func(t *testing.T) {
+		testConsulManagerUpdateHostStatus(t, client)
+	})
 }
@@ -15,6 +15,8 @@
 package hostspool
 
 import (
+	"fmt"
+
 	"github.com/pkg/errors"
 )
 
@@ -67,3 +69,18 @@ func IsNoMatchingHostFoundError(err error) bool {
 	_, ok := errors.Cause(err).(noMatchingHostFoundError)
 	return ok
 }
+
+type hostConnectionError struct {
+	hostname string
+	err      error
+}
+
+func (e hostConnectionError) Error() string {
+	return fmt.Sprintf("failed to connect to host %q: %v", e.hostname, e.err)
+}
+
+// IsHostConnectionError checks if an error is due to a failed connection to a host
+func IsHostConnectionError(err error) bool {
+	_, ok := errors.Cause(err).(hostConnectionError)
+	return ok
+}
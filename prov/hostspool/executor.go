@@ -19,20 +19,25 @@ import (
 	"encoding/json"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
+	"github.com/spf13/cast"
 
 	"github.com/ystia/yorc/config"
 	"github.com/ystia/yorc/deployments"
 	"github.com/ystia/yorc/events"
 	"github.com/ystia/yorc/helper/labelsutil"
+	"github.com/ystia/yorc/prov/policies"
 	"github.com/ystia/yorc/tasks"
 	"github.com/ystia/yorc/tosca"
 )
 
+const infrastructureName = "hostspool"
+
 type defaultExecutor struct {
 }
 
@@ -82,6 +87,11 @@ func (e *defaultExecutor) ExecDelegate(ctx context.Context, cfg config.Configura
 func (e *defaultExecutor) hostsPoolCreate(originalCtx context.Context, cc *api.Client, cfg config.Configuration, taskID, deploymentID, nodeName string, allocatedResources map[string]string) error {
 	hpManager := NewManager(cc)
 
+	poolName, err := getPoolName(cc.KV(), deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+
 	jsonProp, err := deployments.GetNodePropertyValue(cc.KV(), deploymentID, nodeName, "filters")
 	if err != nil {
 		return err
@@ -115,6 +125,21 @@ func (e *defaultExecutor) hostsPoolCreate(originalCtx context.Context, cc *api.C
 		}
 	}
 
+	placements, err := getPlacements(cc.KV(), deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+
+	strategy, err := getAllocationStrategy(cfg, cc.KV(), deploymentID, nodeName, poolName)
+	if err != nil {
+		return err
+	}
+
+	maxWaitTime, priority, err := getAllocationWaitOptions(cfg, cc.KV(), deploymentID, nodeName, poolName)
+	if err != nil {
+		return err
+	}
+
 	instances, err := tasks.GetInstances(cc.KV(), taskID, deploymentID, nodeName)
 	if err != nil {
 		return err
@@ -122,8 +147,12 @@ func (e *defaultExecutor) hostsPoolCreate(originalCtx context.Context, cc *api.C
 	for _, instance := range instances {
 		ctx := events.AddLogOptionalFields(originalCtx, events.LogOptionalFields{events.InstanceID: instance})
 
-		allocation := &Allocation{NodeName: nodeName, Instance: instance, DeploymentID: deploymentID, Shareable: shareable, Resources: allocatedResources}
-		hostname, warnings, err := hpManager.Allocate(allocation, filters...)
+		allocation := &Allocation{NodeName: nodeName, Instance: instance, DeploymentID: deploymentID, Shareable: shareable, Resources: allocatedResources, Placements: placements, Strategy: strategy, Priority: priority}
+		onWait := func(position int) {
+			events.WithContextOptionalFields(ctx).
+				NewLogEntry(events.LogLevelINFO, deploymentID).Registerf("waiting for host (position %d) in hosts pool %q", position, poolName)
+		}
+		hostname, warnings, err := hpManager.AllocateOrWait(ctx, poolName, allocation, maxWaitTime, onWait, filters...)
 		for _, warn := range warnings {
 			events.WithContextOptionalFields(ctx).
 				NewLogEntry(events.LogLevelWARN, deploymentID).Registerf(`%v`, warn)
@@ -131,11 +160,17 @@ func (e *defaultExecutor) hostsPoolCreate(originalCtx context.Context, cc *api.C
 		if err != nil {
 			return err
 		}
+		events.WithContextOptionalFields(ctx).
+			NewLogEntry(events.LogLevelINFO, deploymentID).Registerf("host %q of pool %q allocated using %q allocation strategy (score: %g)", hostname, poolName, strategy.Name(), allocation.Score)
 		err = deployments.SetInstanceAttribute(deploymentID, nodeName, instance, "hostname", hostname)
 		if err != nil {
 			return err
 		}
-		host, err := hpManager.GetHost(hostname)
+		err = deployments.SetInstanceAttribute(deploymentID, nodeName, instance, "pool", poolName)
+		if err != nil {
+			return err
+		}
+		host, err := hpManager.GetHost(poolName, hostname)
 		if err != nil {
 			return err
 		}
@@ -150,6 +185,9 @@ func (e *defaultExecutor) hostsPoolCreate(originalCtx context.Context, cc *api.C
 		if host.Connection.PrivateKey != "" {
 			credentials["keys"] = []string{host.Connection.PrivateKey}
 		}
+		if host.Connection.Bastion != nil {
+			credentials["bastion"] = getBastionCredentials(host.Connection.Bastion)
+		}
 		err = deployments.SetInstanceCapabilityAttributeComplex(deploymentID, nodeName, instance, "endpoint", "credentials", credentials)
 		if err != nil {
 			return err
@@ -208,12 +246,134 @@ func (e *defaultExecutor) hostsPoolCreate(originalCtx context.Context, cc *api.C
 			}
 		}
 
-		return hpManager.UpdateResourcesLabels(hostname, allocatedResources, subtract, updateResourcesLabels)
+		return hpManager.UpdateResourcesLabels(poolName, hostname, allocatedResources, subtract, updateResourcesLabels)
 	}
 
 	return nil
 }
 
+// getPoolName returns the name of the hosts pool where to allocate hosts for the given node
+func getPoolName(kv *api.KV, deploymentID, nodeName string) (string, error) {
+	p, err := deployments.GetNodePropertyValue(kv, deploymentID, nodeName, "pool")
+	if err != nil {
+		return "", err
+	}
+	if p == nil || p.RawString() == "" {
+		return DefaultPoolName, nil
+	}
+	return p.RawString(), nil
+}
+
+// getPoolConfiguration returns the hostspool infrastructure configuration applying to the given pool.
+//
+// Options defined for this pool in the "pools" section of this configuration override global ones.
+func getPoolConfiguration(cfg config.Configuration, poolName string) config.DynamicMap {
+	poolCfg := make(config.DynamicMap)
+	hpConfig := cfg.Infrastructures[infrastructureName]
+	for _, k := range hpConfig.Keys() {
+		if k != "pools" {
+			poolCfg.Set(k, hpConfig.Get(k))
+		}
+	}
+	for p, poolConfig := range cast.ToStringMap(hpConfig.Get("pools")) {
+		// Configuration keys are not case sensitive
+		if !strings.EqualFold(p, poolName) {
+			continue
+		}
+		for k, v := range cast.ToStringMap(poolConfig) {
+			poolCfg.Set(k, v)
+		}
+	}
+	return poolCfg
+}
+
+// getAllocationStrategy returns the allocation strategy to use for the given node.
+//
+// The strategy is defined in the hostspool infrastructure configuration of the pool
+// and could be overridden by the node properties.
+func getAllocationStrategy(cfg config.Configuration, kv *api.KV, deploymentID, nodeName, poolName string) (AllocationStrategy, error) {
+	poolCfg := getPoolConfiguration(cfg, poolName)
+	name := poolCfg.GetString("allocation_strategy")
+	options := map[string]interface{}{"labels_weights": poolCfg.Get("labels_weights")}
+
+	p, err := deployments.GetNodePropertyValue(kv, deploymentID, nodeName, "allocation_strategy")
+	if err != nil {
+		return nil, err
+	}
+	if p != nil && p.RawString() != "" {
+		name = p.RawString()
+	}
+	p, err = deployments.GetNodePropertyValue(kv, deploymentID, nodeName, "labels_weights")
+	if err != nil {
+		return nil, err
+	}
+	if p != nil && p.RawString() != "" {
+		var weights map[string]interface{}
+		err = json.Unmarshal([]byte(p.RawString()), &weights)
+		if err != nil {
+			return nil, errors.Wrapf(err, `failed to parse property "labels_weights" for node %q as json %q`, nodeName, p.String())
+		}
+		options["labels_weights"] = weights
+	}
+	strategy, err := NewAllocationStrategy(name, options)
+	return strategy, errors.Wrapf(err, "failed to get allocation strategy for node %q", nodeName)
+}
+
+// getAllocationWaitOptions returns the maximum time to wait for a host to be available and the priority
+// of the allocation requests of the given node.
+//
+// The maximum wait time is defined in the hostspool infrastructure configuration of the pool and could be
+// overridden by the node properties.
+func getAllocationWaitOptions(cfg config.Configuration, kv *api.KV, deploymentID, nodeName, poolName string) (time.Duration, int, error) {
+	maxWaitTime := getPoolConfiguration(cfg, poolName).GetDuration("allocation_wait_timeout")
+	p, err := deployments.GetNodePropertyValue(kv, deploymentID, nodeName, "allocation_wait_timeout")
+	if err != nil {
+		return 0, 0, err
+	}
+	if p != nil && p.RawString() != "" {
+		maxWaitTime, err = time.ParseDuration(p.RawString())
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, `failed to parse property "allocation_wait_timeout" for node %q as a duration`, nodeName)
+		}
+	}
+	var priority int
+	p, err = deployments.GetNodePropertyValue(kv, deploymentID, nodeName, "allocation_priority")
+	if err != nil {
+		return 0, 0, err
+	}
+	if p != nil && p.RawString() != "" {
+		priority, err = strconv.Atoi(p.RawString())
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, `failed to parse property "allocation_priority" for node %q as an integer`, nodeName)
+		}
+	}
+	return maxWaitTime, priority, nil
+}
+
+// getPlacements returns the placement constraints defined by the placement policies applying to the given node
+func getPlacements(kv *api.KV, deploymentID, nodeName string) ([]Placement, error) {
+	placementPolicies, err := deployments.GetPoliciesForTypeAndNode(kv, deploymentID, "tosca.policies.Placement", nodeName)
+	if err != nil {
+		return nil, err
+	}
+	placements := make([]Placement, 0)
+	for _, policy := range placementPolicies {
+		affinity, err := policies.GetPlacementAffinity(kv, deploymentID, policy)
+		if err != nil {
+			return nil, err
+		}
+		if affinity == "" {
+			continue
+		}
+		targets, err := deployments.GetPolicyTargets(kv, deploymentID, policy)
+		if err != nil {
+			return nil, err
+		}
+		placements = append(placements, Placement{Affinity: affinity, NodeNames: targets})
+	}
+	return placements, nil
+}
+
 func (e *defaultExecutor) getAllocatedResourcesFromHostCapabilities(kv *api.KV, deploymentID, nodeName string) (map[string]string, error) {
 	res := make(map[string]string, 0)
 	p, err := deployments.GetCapabilityPropertyValue(kv, deploymentID, nodeName, "host", "num_cpus")
@@ -312,12 +472,21 @@ func (e *defaultExecutor) hostsPoolDelete(originalCtx context.Context, cc *api.C
 			events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, deploymentID).Registerf("instance %q of node %q does not have a registered hostname. This may be due to an error at creation time. Should be checked.", instance, nodeName)
 			continue
 		}
+		// Hosts allocated before the introduction of named pools belong to the default pool
+		poolName := DefaultPoolName
+		pool, err := deployments.GetInstanceAttributeValue(cc.KV(), deploymentID, nodeName, instance, "pool")
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+		if pool != nil && pool.RawString() != "" {
+			poolName = pool.RawString()
+		}
 		allocation := &Allocation{NodeName: nodeName, Instance: instance, DeploymentID: deploymentID}
-		err = hpManager.Release(hostname.RawString(), allocation)
+		err = hpManager.Release(poolName, hostname.RawString(), allocation)
 		if err != nil {
 			errs = multierror.Append(errs, err)
 		}
-		return hpManager.UpdateResourcesLabels(hostname.RawString(), allocatedResources, add, updateResourcesLabels)
+		return hpManager.UpdateResourcesLabels(poolName, hostname.RawString(), allocatedResources, add, updateResourcesLabels)
 
 	}
 	return errors.Wrap(errs, "errors encountered during hosts pool node release. Some hosts maybe not properly released.")
@@ -410,3 +579,24 @@ func isIECformat(value string) bool {
 	}
 	return false
 }
+
+// getBastionCredentials returns a bastion connection as a yorc.datatypes.BastionHost complex value
+func getBastionCredentials(bastion *Connection) map[string]interface{} {
+	bastionCreds := map[string]interface{}{"host": bastion.Host}
+	if bastion.Port != 0 {
+		bastionCreds["port"] = bastion.Port
+	}
+	if bastion.User != "" {
+		bastionCreds["user"] = bastion.User
+	}
+	if bastion.Password != "" {
+		bastionCreds["token"] = bastion.Password
+	}
+	if bastion.PrivateKey != "" {
+		bastionCreds["keys"] = []string{bastion.PrivateKey}
+	}
+	if bastion.Bastion != nil {
+		bastionCreds["bastion"] = getBastionCredentials(bastion.Bastion)
+	}
+	return bastionCreds
+}
@@ -18,6 +18,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/ystia/yorc/config"
 )
 
 func TestUpdateHostResourcesLabels(t *testing.T) {
@@ -59,3 +62,40 @@ func TestUpdateHostResourcesLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPoolConfiguration(t *testing.T) {
+	cfg := config.Configuration{Infrastructures: map[string]config.DynamicMap{
+		infrastructureName: config.DynamicMap{
+			"allocation_strategy":     "spread",
+			"allocation_wait_timeout": "10m",
+			"pools": map[string]interface{}{
+				"hpc": map[string]interface{}{
+					"allocation_strategy": "best_fit",
+				},
+				"gpu": map[interface{}]interface{}{
+					"allocation_strategy":     "labels_weighted",
+					"labels_weights":          map[string]interface{}{"gpu": 10},
+					"allocation_wait_timeout": "1h",
+				},
+			},
+		},
+	}}
+
+	poolCfg := getPoolConfiguration(cfg, "default")
+	require.Equal(t, "spread", poolCfg.GetString("allocation_strategy"))
+	require.Equal(t, 10*time.Minute, poolCfg.GetDuration("allocation_wait_timeout"))
+	require.False(t, poolCfg.IsSet("pools"))
+
+	poolCfg = getPoolConfiguration(cfg, "HPC")
+	require.Equal(t, "best_fit", poolCfg.GetString("allocation_strategy"))
+	require.Equal(t, 10*time.Minute, poolCfg.GetDuration("allocation_wait_timeout"))
+
+	poolCfg = getPoolConfiguration(cfg, "gpu")
+	require.Equal(t, "labels_weighted", poolCfg.GetString("allocation_strategy"))
+	require.Equal(t, time.Hour, poolCfg.GetDuration("allocation_wait_timeout"))
+	require.Equal(t, map[string]interface{}{"gpu": 10}, poolCfg.Get("labels_weights"))
+
+	poolCfg = getPoolConfiguration(config.Configuration{}, "default")
+	require.Equal(t, "", poolCfg.GetString("allocation_strategy"))
+	require.Equal(t, time.Duration(0), poolCfg.GetDuration("allocation_wait_timeout"))
+}
@@ -17,8 +17,10 @@ package hostspool
 import (
 	"github.com/hashicorp/consul/api"
 	"github.com/pkg/errors"
+	"github.com/ystia/yorc/helper/collections"
 	"github.com/ystia/yorc/helper/consulutil"
 	"github.com/ystia/yorc/helper/labelsutil"
+	"github.com/ystia/yorc/prov/policies"
 	"net/url"
 	"path"
 	"strconv"
@@ -26,26 +28,28 @@ import (
 	"time"
 )
 
-func (cm *consulManager) Allocate(allocation *Allocation, filters ...labelsutil.Filter) (string, []labelsutil.Warning, error) {
-	return cm.allocateWait(maxWaitTimeSeconds*time.Second, allocation, filters...)
+func (cm *consulManager) Allocate(poolName string, allocation *Allocation, filters ...labelsutil.Filter) (string, []labelsutil.Warning, error) {
+	return cm.allocateWait(poolName, maxWaitTimeSeconds*time.Second, allocation, filters...)
 }
-func (cm *consulManager) allocateWait(maxWaitTime time.Duration, allocation *Allocation, filters ...labelsutil.Filter) (string, []labelsutil.Warning, error) {
+
+func (cm *consulManager) allocateWait(poolName string, maxWaitTime time.Duration, allocation *Allocation, filters ...labelsutil.Filter) (string, []labelsutil.Warning, error) {
 	// Build allocationID
 	if err := allocation.buildID(); err != nil {
 		return "", nil, err
 	}
 
-	lockCh, cleanupFn, err := cm.lockKey("", "allocation", maxWaitTime)
+	lockCh, cleanupFn, err := cm.lockKey(poolName, "", "allocation", maxWaitTime)
 	if err != nil {
 		return "", nil, err
 	}
 	defer cleanupFn()
 
-	hosts, warnings, _, err := cm.List(filters...)
+	hosts, warnings, _, err := cm.List(poolName, filters...)
 	if err != nil {
 		return "", warnings, err
 	}
 	// Filters only free or allocated hosts in case of shareable allocation
+	// Hosts in maintenance or draining are skipped
 	var lastErr error
 	freeHosts := hosts[:0]
 	for _, h := range hosts {
@@ -54,27 +58,24 @@ func (cm *consulManager) allocateWait(maxWaitTime time.Duration, allocation *All
 			return "", warnings, errors.New("admin lock lost on hosts pool during host allocation")
 		default:
 		}
-		err := cm.checkConnection(h)
+		hs, err := cm.GetHostStatus(poolName, h)
 		if err != nil {
 			lastErr = err
 			continue
 		}
-		hs, err := cm.GetHostStatus(h)
+		if hs == HostStatusMaintenance || hs == HostStatusDraining {
+			continue
+		}
+		err = cm.checkConnection(poolName, h)
 		if err != nil {
 			lastErr = err
 		} else {
-			if hs == HostStatusFree {
-				freeHosts = append(freeHosts, h)
-			} else if hs == HostStatusAllocated && allocation.Shareable {
-				allocations, err := cm.GetAllocations(h)
-				if err != nil {
-					lastErr = err
-					continue
-				}
-				// Check the host allocation is not unshareable
-				if len(allocations) == 1 && !allocations[0].Shareable {
-					continue
-				}
+			available, err := cm.isHostAvailable(poolName, h, hs, allocation.Shareable)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if available {
 				freeHosts = append(freeHosts, h)
 			}
 		}
@@ -86,60 +87,176 @@ func (cm *consulManager) allocateWait(maxWaitTime time.Duration, allocation *All
 		}
 		return "", warnings, errors.WithStack(noMatchingHostFoundError{})
 	}
+	if allocation.Strategy == nil {
+		allocation.Strategy = firstFit{}
+	}
+	scores, err := cm.rankHosts(poolName, allocation, freeHosts)
+	if err != nil {
+		return "", warnings, err
+	}
+	candidates, err := cm.applyPlacements(poolName, allocation, freeHosts)
+	if err != nil {
+		return "", warnings, err
+	}
+	if len(candidates) == 0 {
+		return "", warnings, errors.WithStack(noMatchingHostFoundError{})
+	}
 	// Get the first host that match
-	hostname := freeHosts[0]
+	hostname := candidates[0]
+	allocation.Score = scores[hostname]
 	select {
 	case <-lockCh:
 		return "", warnings, errors.New("admin lock lost on hosts pool during host allocation")
 	default:
 	}
 
-	if err := cm.addAllocation(hostname, allocation); err != nil {
+	if err := cm.addAllocation(poolName, hostname, allocation); err != nil {
 		return "", warnings, errors.Wrapf(err, "failed to add allocation for hostname:%q", hostname)
 	}
 
-	return hostname, warnings, cm.setHostStatus(hostname, HostStatusAllocated)
+	return hostname, warnings, cm.setHostStatus(poolName, hostname, HostStatusAllocated)
+}
+
+// isHostAvailable checks if a host having the given status could be allocated, either because it is free
+// or because it is allocated and the allocation is shareable as well as the existing ones
+func (cm *consulManager) isHostAvailable(poolName, hostname string, status HostStatus, shareable bool) (bool, error) {
+	if status == HostStatusFree {
+		return true, nil
+	}
+	if status != HostStatusAllocated || !shareable {
+		return false, nil
+	}
+	allocations, err := cm.GetAllocations(poolName, hostname)
+	if err != nil {
+		return false, err
+	}
+	// Check the host allocation is not unshareable
+	return len(allocations) != 1 || allocations[0].Shareable, nil
+}
+
+// rankHosts sorts in place the given hosts according to the allocation strategy and returns their scores.
+//
+// The first-fit strategy keeps the hosts order and does not require to retrieve hosts details.
+func (cm *consulManager) rankHosts(poolName string, allocation *Allocation, hostnames []string) (map[string]float64, error) {
+	scores := make(map[string]float64, len(hostnames))
+	if _, ok := allocation.Strategy.(firstFit); ok {
+		return scores, nil
+	}
+	hosts := make([]Host, len(hostnames))
+	for i, h := range hostnames {
+		host, err := cm.GetHost(poolName, h)
+		if err != nil {
+			return nil, err
+		}
+		hosts[i] = host
+	}
+	hosts, hostsScores, err := rankHosts(allocation.Strategy, hosts, allocation)
+	if err != nil {
+		return nil, err
+	}
+	for i, h := range hosts {
+		hostnames[i] = h.Name
+		scores[h.Name] = hostsScores[i]
+	}
+	return scores, nil
+}
+
+// applyPlacements removes hosts that violate an anti-colocate placement of the given allocation
+// and moves first hosts that satisfy its colocate placements, preserving the original order otherwise
+func (cm *consulManager) applyPlacements(poolName string, allocation *Allocation, hosts []string) ([]string, error) {
+	if len(allocation.Placements) == 0 {
+		return hosts, nil
+	}
+	preferred := make([]string, 0)
+	others := make([]string, 0)
+	for _, h := range hosts {
+		allocations, err := cm.GetAllocations(poolName, h)
+		if err != nil {
+			return nil, err
+		}
+		excluded, colocated := false, false
+		for _, placement := range allocation.Placements {
+			if !placement.matchesAny(allocation.DeploymentID, allocations) {
+				continue
+			}
+			switch placement.Affinity {
+			case policies.AffinityAntiColocate:
+				excluded = true
+			case policies.AffinityColocate:
+				colocated = true
+			}
+		}
+		switch {
+		case excluded:
+			// Host violates an anti-colocate placement
+		case colocated:
+			preferred = append(preferred, h)
+		default:
+			others = append(others, h)
+		}
+	}
+	return append(preferred, others...), nil
+}
+
+// matchesAny checks if one of the given allocations concerns a node of the given deployment targeted by this placement
+func (p Placement) matchesAny(deploymentID string, allocations []Allocation) bool {
+	for _, alloc := range allocations {
+		if alloc.DeploymentID != deploymentID {
+			continue
+		}
+		if len(p.NodeNames) == 0 || collections.ContainsString(p.NodeNames, alloc.NodeName) {
+			return true
+		}
+	}
+	return false
 }
-func (cm *consulManager) Release(hostname string, allocation *Allocation) error {
-	return cm.releaseWait(hostname, allocation, maxWaitTimeSeconds*time.Second)
+
+func (cm *consulManager) Release(poolName, hostname string, allocation *Allocation) error {
+	return cm.releaseWait(poolName, hostname, allocation, maxWaitTimeSeconds*time.Second)
 }
 
-func (cm *consulManager) releaseWait(hostname string, allocation *Allocation, maxWaitTime time.Duration) error {
+func (cm *consulManager) releaseWait(poolName, hostname string, allocation *Allocation, maxWaitTime time.Duration) error {
 	// Build allocationID
 	if err := allocation.buildID(); err != nil {
 		return err
 	}
-	_, cleanupFn, err := cm.lockKey(hostname, "release", maxWaitTime)
+	_, cleanupFn, err := cm.lockKey(poolName, hostname, "release", maxWaitTime)
 	if err != nil {
 		return err
 	}
 	defer cleanupFn()
 
-	if err := cm.removeAllocation(hostname, allocation); err != nil {
+	if err := cm.removeAllocation(poolName, hostname, allocation); err != nil {
 		return errors.Wrapf(err, "failed to remove allocation with ID:%q and hostname:%q", allocation.ID, hostname)
 	}
 
-	host, err := cm.GetHost(hostname)
+	host, err := cm.GetHost(poolName, hostname)
 	if err != nil {
 		return err
 	}
 	// Set the host status to free only for host with no allocations
+	// A draining host is put in maintenance once its last allocation is released
 	if len(host.Allocations) == 0 {
-		if err = cm.setHostStatus(hostname, HostStatusFree); err != nil {
+		if host.Status == HostStatusDraining {
+			err = cm.setHostStatusWithMessage(poolName, hostname, HostStatusMaintenance, host.Message)
+		} else {
+			err = cm.setHostStatus(poolName, hostname, HostStatusFree)
+		}
+		if err != nil {
 			return err
 		}
 	}
-	err = cm.checkConnection(hostname)
+	err = cm.checkConnection(poolName, hostname)
 	if err != nil {
-		cm.backupHostStatus(hostname)
-		cm.setHostStatusWithMessage(hostname, HostStatusError, "failed to connect to host")
+		cm.backupHostStatus(poolName, hostname)
+		cm.setHostStatusWithMessage(poolName, hostname, HostStatusError, "failed to connect to host")
 	}
 	return nil
 }
 
-func getAddAllocationsOperation(hostname string, allocations []Allocation) (api.KVTxnOps, error) {
+func getAddAllocationsOperation(poolName, hostname string, allocations []Allocation) (api.KVTxnOps, error) {
 	allocsOps := api.KVTxnOps{}
-	hostKVPrefix := path.Join(consulutil.HostsPoolPrefix, hostname)
+	hostKVPrefix := path.Join(consulutil.HostsPoolPrefix, poolName, hostname)
 	if allocations != nil {
 		for _, alloc := range allocations {
 			allocKVPrefix := path.Join(hostKVPrefix, "allocations", alloc.ID)
@@ -189,10 +306,10 @@ func getAddAllocationsOperation(hostname string, allocations []Allocation) (api.
 	return allocsOps, nil
 }
 
-func (cm *consulManager) addAllocation(hostname string, allocation *Allocation) error {
+func (cm *consulManager) addAllocation(poolName, hostname string, allocation *Allocation) error {
 	var allocOps api.KVTxnOps
 	var err error
-	if allocOps, err = getAddAllocationsOperation(hostname, []Allocation{*allocation}); err != nil {
+	if allocOps, err = getAddAllocationsOperation(poolName, hostname, []Allocation{*allocation}); err != nil {
 		return errors.Wrapf(err, "failed to add allocation to host:%q", hostname)
 	}
 
@@ -211,8 +328,8 @@ func (cm *consulManager) addAllocation(hostname string, allocation *Allocation)
 	return nil
 }
 
-func (cm *consulManager) removeAllocation(hostname string, allocation *Allocation) error {
-	_, err := cm.cc.KV().DeleteTree(path.Join(consulutil.HostsPoolPrefix, hostname, "allocations", allocation.ID), nil)
+func (cm *consulManager) removeAllocation(poolName, hostname string, allocation *Allocation) error {
+	_, err := cm.cc.KV().DeleteTree(path.Join(consulutil.HostsPoolPrefix, poolName, hostname, "allocations", allocation.ID), nil)
 	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 }
 
@@ -225,12 +342,12 @@ func exist(allocations []Allocation, ID string) bool {
 	return false
 }
 
-func (cm *consulManager) GetAllocations(hostname string) ([]Allocation, error) {
+func (cm *consulManager) GetAllocations(poolName, hostname string) ([]Allocation, error) {
 	allocations := make([]Allocation, 0)
 	if hostname == "" {
 		return nil, errors.WithStack(badRequestError{`"hostname" missing`})
 	}
-	keys, _, err := cm.cc.KV().Keys(path.Join(consulutil.HostsPoolPrefix, hostname, "allocations")+"/", "/", nil)
+	keys, _, err := cm.cc.KV().Keys(path.Join(consulutil.HostsPoolPrefix, poolName, hostname, "allocations")+"/", "/", nil)
 	if err != nil {
 		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
@@ -15,6 +15,8 @@
 package hostspool
 
 import (
+	"encoding/json"
+
 	"github.com/hashicorp/consul/api"
 	"github.com/pkg/errors"
 	"github.com/ystia/yorc/config"
@@ -26,22 +28,22 @@ import (
 	"time"
 )
 
-func (cm *consulManager) UpdateConnection(hostname string, conn Connection) error {
-	return cm.updateConnectionWait(hostname, conn, maxWaitTimeSeconds*time.Second)
+func (cm *consulManager) UpdateConnection(poolName, hostname string, conn Connection) error {
+	return cm.updateConnectionWait(poolName, hostname, conn, maxWaitTimeSeconds*time.Second)
 }
-func (cm *consulManager) updateConnectionWait(hostname string, conn Connection, maxWaitTime time.Duration) error {
+func (cm *consulManager) updateConnectionWait(poolName, hostname string, conn Connection, maxWaitTime time.Duration) error {
 	if hostname == "" {
 		return errors.WithStack(badRequestError{`"hostname" missing`})
 	}
 
 	// check if host exists
-	status, err := cm.GetHostStatus(hostname)
+	status, err := cm.GetHostStatus(poolName, hostname)
 	if err != nil {
 		return err
 	}
 
 	ops := make(api.KVTxnOps, 0)
-	hostKVPrefix := path.Join(consulutil.HostsPoolPrefix, hostname)
+	hostKVPrefix := path.Join(consulutil.HostsPoolPrefix, poolName, hostname)
 	if conn.User != "" {
 		ops = append(ops, &api.KVTxnOp{
 			Verb:  api.KVSet,
@@ -63,9 +65,28 @@ func (cm *consulManager) updateConnectionWait(hostname string, conn Connection,
 			Value: []byte(conn.Host),
 		})
 	}
+	if conn.Bastion != nil {
+		var bastionOp *api.KVTxnOp
+		if conn.Bastion.Host == "-" {
+			// Removing the bastion
+			bastionOp = &api.KVTxnOp{
+				Verb: api.KVDelete,
+				Key:  path.Join(hostKVPrefix, "connection", "bastion"),
+			}
+		} else {
+			if err = conn.checkBastion(); err != nil {
+				return err
+			}
+			bastionOp, err = getBastionOperation(hostKVPrefix, conn.Bastion)
+			if err != nil {
+				return err
+			}
+		}
+		ops = append(ops, bastionOp)
+	}
 	if conn.PrivateKey != "" {
 		if conn.PrivateKey == "-" {
-			ok, err := cm.DoesHostHasConnectionPassword(hostname)
+			ok, err := cm.DoesHostHasConnectionPassword(poolName, hostname)
 			if err != nil {
 				return err
 			}
@@ -82,7 +103,7 @@ func (cm *consulManager) updateConnectionWait(hostname string, conn Connection,
 	}
 	if conn.Password != "" {
 		if conn.Password == "-" {
-			ok, err := cm.DoesHostHasConnectionPrivateKey(hostname)
+			ok, err := cm.DoesHostHasConnectionPrivateKey(poolName, hostname)
 			if err != nil {
 				return err
 			}
@@ -98,7 +119,7 @@ func (cm *consulManager) updateConnectionWait(hostname string, conn Connection,
 		})
 	}
 
-	_, cleanupFn, err := cm.lockKey(hostname, "update", maxWaitTime)
+	_, cleanupFn, err := cm.lockKey(poolName, hostname, "update", maxWaitTime)
 	if err != nil {
 		return err
 	}
@@ -117,43 +138,43 @@ func (cm *consulManager) updateConnectionWait(hostname string, conn Connection,
 		return errors.Errorf("Failed to update host %q connection: %s", hostname, strings.Join(errs, ", "))
 	}
 
-	err = cm.checkConnection(hostname)
+	err = cm.checkConnection(poolName, hostname)
 	if err != nil {
 		if status != HostStatusError {
-			cm.backupHostStatus(hostname)
-			cm.setHostStatusWithMessage(hostname, HostStatusError, "failed to connect to host")
+			cm.backupHostStatus(poolName, hostname)
+			cm.setHostStatusWithMessage(poolName, hostname, HostStatusError, "failed to connect to host")
 		}
 		return err
 	}
 	if status == HostStatusError {
-		cm.restoreHostStatus(hostname)
+		cm.restoreHostStatus(poolName, hostname)
 	}
 	return nil
 }
 
-func (cm *consulManager) DoesHostHasConnectionPrivateKey(hostname string) (bool, error) {
-	c, err := cm.GetHostConnection(hostname)
+func (cm *consulManager) DoesHostHasConnectionPrivateKey(poolName, hostname string) (bool, error) {
+	c, err := cm.GetHostConnection(poolName, hostname)
 	if err != nil {
 		return false, err
 	}
 	return c.PrivateKey != "", nil
 }
 
-func (cm *consulManager) DoesHostHasConnectionPassword(hostname string) (bool, error) {
-	c, err := cm.GetHostConnection(hostname)
+func (cm *consulManager) DoesHostHasConnectionPassword(poolName, hostname string) (bool, error) {
+	c, err := cm.GetHostConnection(poolName, hostname)
 	if err != nil {
 		return false, err
 	}
 	return c.Password != "", nil
 }
 
-func (cm *consulManager) GetHostConnection(hostname string) (Connection, error) {
+func (cm *consulManager) GetHostConnection(poolName, hostname string) (Connection, error) {
 	conn := Connection{}
 	if hostname == "" {
 		return conn, errors.WithStack(badRequestError{`"hostname" missing`})
 	}
 	kv := cm.cc.KV()
-	connKVPrefix := path.Join(consulutil.HostsPoolPrefix, hostname, "connection")
+	connKVPrefix := path.Join(consulutil.HostsPoolPrefix, poolName, hostname, "connection")
 
 	kvp, _, err := kv.Get(path.Join(connKVPrefix, "host"), nil)
 	if err != nil {
@@ -193,55 +214,98 @@ func (cm *consulManager) GetHostConnection(hostname string) (Connection, error)
 			return conn, errors.Wrapf(err, "failed to retrieve connection port for host %q", hostname)
 		}
 	}
+	kvp, _, err = kv.Get(path.Join(connKVPrefix, "bastion"), nil)
+	if err != nil {
+		return conn, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp != nil && len(kvp.Value) > 0 {
+		conn.Bastion = new(Connection)
+		err = json.Unmarshal(kvp.Value, conn.Bastion)
+		if err != nil {
+			return conn, errors.Wrapf(err, "failed to retrieve bastion connection for host %q", hostname)
+		}
+	}
 
 	return conn, nil
 }
 
+// getBastionOperation returns the operation storing a bastion connection of a host.
+//
+// As bastions could be chained, the bastion connection is stored as a single JSON value
+func getBastionOperation(hostKVPrefix string, bastion *Connection) (*api.KVTxnOp, error) {
+	b, err := json.Marshal(bastion)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal bastion connection")
+	}
+	return &api.KVTxnOp{
+		Verb:  api.KVSet,
+		Key:   path.Join(hostKVPrefix, "connection", "bastion"),
+		Value: b,
+	}, nil
+}
+
 func resolveTemplatesInConnection(conn *Connection) {
 	conn.User = config.DefaultConfigTemplateResolver.ResolveValueWithTemplates("Connection.User", conn.User).(string)
 	conn.Password = config.DefaultConfigTemplateResolver.ResolveValueWithTemplates("Connection.Password", conn.Password).(string)
 	conn.PrivateKey = config.DefaultConfigTemplateResolver.ResolveValueWithTemplates("Connection.PrivateKey", conn.PrivateKey).(string)
 	conn.Host = config.DefaultConfigTemplateResolver.ResolveValueWithTemplates("Connection.Host", conn.Host).(string)
+	if conn.Bastion != nil {
+		resolveTemplatesInConnection(conn.Bastion)
+	}
 }
 
 // Check if we can log into an host given a connection
-func (cm *consulManager) checkConnection(hostname string) error {
+func (cm *consulManager) checkConnection(poolName, hostname string) error {
 
-	conn, err := cm.GetHostConnection(hostname)
+	status, err := cm.GetHostStatus(poolName, hostname)
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to host %q", hostname)
+	}
+	if status == HostStatusMaintenance {
+		// Hosts in maintenance are not checked until they are re-enabled
+		return nil
+	}
+	conn, err := cm.GetHostConnection(poolName, hostname)
 	if err != nil {
 		return errors.Wrapf(err, "failed to connect to host %q", hostname)
 	}
 	resolveTemplatesInConnection(&conn)
 	conf, err := getSSHConfig(conn)
 	if err != nil {
-		return errors.Wrapf(err, "failed to connect to host %q", hostname)
+		return errors.WithStack(hostConnectionError{hostname: hostname, err: err})
+	}
+	if err = conn.checkBastion(); err != nil {
+		return errors.WithStack(hostConnectionError{hostname: hostname, err: err})
 	}
 
 	client := cm.getSSHClient(conf, conn)
 	_, err = client.RunCommand(`echo "Connected!"`)
-	return errors.Wrapf(err, "failed to connect to host %q", hostname)
+	if err != nil {
+		return errors.WithStack(hostConnectionError{hostname: hostname, err: err})
+	}
+	return nil
 }
 
 // Go routine checking a Host connection and updating the Host status
-func (cm *consulManager) updateConnectionStatus(name string, waitGroup *sync.WaitGroup) {
+func (cm *consulManager) updateConnectionStatus(poolName, name string, waitGroup *sync.WaitGroup) {
 	defer waitGroup.Done()
-	status, err := cm.GetHostStatus(name)
+	status, err := cm.GetHostStatus(poolName, name)
 	if err != nil {
 		// No such host anymore
 		return
 	}
 
-	err = cm.checkConnection(name)
+	err = cm.checkConnection(poolName, name)
 	if err != nil {
 		if status != HostStatusError {
-			cm.backupHostStatus(name)
-			cm.setHostStatusWithMessage(name, HostStatusError, "failed to connect to host")
+			cm.backupHostStatus(poolName, name)
+			cm.setHostStatusWithMessage(poolName, name, HostStatusError, "failed to connect to host")
 		}
 		return
 	}
 	// Connection is up now. If it was previously down, restoring the status as
 	// it was before the failure (free, allocated)
 	if status == HostStatusError {
-		cm.restoreHostStatus(name)
+		cm.restoreHostStatus(poolName, name)
 	}
 }
@@ -0,0 +1,288 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/helper/labelsutil"
+	"github.com/ystia/yorc/log"
+)
+
+// pendingAllocationsPollInterval is the max time to wait for a change in a hosts pool
+// before checking again the pending allocations queue
+const pendingAllocationsPollInterval = 10 * time.Second
+
+// pendingAllocationsSessionTTL is the TTL of the Consul sessions holding pending allocations
+const pendingAllocationsSessionTTL = 30 * time.Second
+
+// getQueueKey returns the key prefix of the pending allocations queue of a hosts pool.
+//
+// As the lock key, it is not under HostsPoolPrefix so that queue changes will not update
+// the last index of the Hosts Pool list
+func getQueueKey(poolName string) string {
+	return path.Join(consulutil.YorcManagementPrefix, "hosts_pool", poolName, "queue")
+}
+
+// AllocateOrWait allocates a host as Allocate does but, if no host matches, the allocation request
+// is queued and waits up to maxWaitTime for a host to be released.
+//
+// Pending allocations are ordered by decreasing priority and then by creation date. A request is served
+// only if none of the requests ahead of it in the queue could be satisfied by the currently available hosts,
+// so that a request waiting for specific hosts does not block requests that could use other ones.
+// onWait, if not nil, is called each time the position of the request in the queue changes.
+// Cancelling the given context removes the request from the queue.
+func (cm *consulManager) AllocateOrWait(ctx context.Context, poolName string, allocation *Allocation, maxWaitTime time.Duration, onWait func(position int), filters ...labelsutil.Filter) (string, []labelsutil.Warning, error) {
+	if maxWaitTime <= 0 {
+		return cm.Allocate(poolName, allocation, filters...)
+	}
+	if err := checkPoolName(poolName); err != nil {
+		return "", nil, err
+	}
+	if err := allocation.buildID(); err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	pa := PendingAllocation{
+		ID:             allocation.ID,
+		DeploymentID:   allocation.DeploymentID,
+		NodeName:       allocation.NodeName,
+		Instance:       allocation.Instance,
+		Priority:       allocation.Priority,
+		Shareable:      allocation.Shareable,
+		CreationDate:   now,
+		ExpirationDate: now.Add(maxWaitTime),
+	}
+	for _, f := range filters {
+		// Filters that can't be stored are ignored, the request is then considered as less selective than it is
+		if s, ok := f.(fmt.Stringer); ok {
+			pa.Filters = append(pa.Filters, s.String())
+		}
+	}
+	var queued bool
+	var sessionCleanupFn func()
+	defer func() {
+		if queued {
+			if err := cm.removePendingAllocation(poolName, pa.ID); err != nil {
+				log.Printf("Failed to remove pending allocation %q from hosts pool %q queue: %v", pa.ID, poolName, err)
+			}
+		}
+		if sessionCleanupFn != nil {
+			sessionCleanupFn()
+		}
+	}()
+
+	var lastIndex uint64
+	var lastPosition int
+	for {
+		pending, err := cm.ListPendingAllocations(poolName)
+		if err != nil {
+			return "", nil, err
+		}
+		position := getPendingAllocationPosition(pending, pa.ID)
+		ahead := pending
+		if position > 0 {
+			ahead = pending[:position-1]
+		}
+		// New requests should not bypass pending ones that could be served
+		blocked, err := cm.isAnyPendingAllocationServable(poolName, ahead)
+		if err != nil {
+			return "", nil, err
+		}
+		if !blocked {
+			hostname, warnings, err := cm.allocateWait(poolName, maxWaitTimeSeconds*time.Second, allocation, filters...)
+			// Hosts that can't be reached for now may come back before the end of the wait
+			if err == nil || !(IsNoMatchingHostFoundError(err) || IsHostConnectionError(err)) {
+				return hostname, warnings, err
+			}
+		}
+		if !queued {
+			var sessionID string
+			sessionID, sessionCleanupFn, err = cm.createPendingAllocationSession(poolName)
+			if err != nil {
+				return "", nil, err
+			}
+			err = cm.addPendingAllocation(poolName, sessionID, pa)
+			if err != nil {
+				return "", nil, err
+			}
+			queued = true
+			continue
+		}
+		remaining := time.Until(pa.ExpirationDate)
+		if position == 0 || remaining <= 0 {
+			return "", nil, errors.Wrapf(noMatchingHostFoundError{}, "no host available after waiting %v", maxWaitTime)
+		}
+		if position != lastPosition {
+			lastPosition = position
+			if onWait != nil {
+				onWait(position)
+			}
+		}
+
+		waitTime := pendingAllocationsPollInterval
+		if remaining < waitTime {
+			waitTime = remaining
+		}
+		q := &api.QueryOptions{WaitIndex: lastIndex, WaitTime: waitTime}
+		_, meta, err := cm.cc.KV().Keys(path.Join(consulutil.HostsPoolPrefix, poolName)+"/", "/", q.WithContext(ctx))
+		select {
+		case <-ctx.Done():
+			return "", nil, errors.Wrap(ctx.Err(), "allocation request cancelled while waiting for a host")
+		default:
+		}
+		if err != nil {
+			return "", nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+		lastIndex = meta.LastIndex
+	}
+}
+
+// isAnyPendingAllocationServable checks if one of the given pending allocations could be satisfied by a host
+// currently available in the pool.
+//
+// This is an estimation as placement constraints and hosts connections are not checked.
+func (cm *consulManager) isAnyPendingAllocationServable(poolName string, pending []PendingAllocation) (bool, error) {
+	for _, pa := range pending {
+		filters := make([]labelsutil.Filter, len(pa.Filters))
+		for i := range pa.Filters {
+			var err error
+			filters[i], err = labelsutil.CreateFilter(pa.Filters[i])
+			if err != nil {
+				return false, errors.Wrapf(err, "invalid filter for pending allocation %q", pa.ID)
+			}
+		}
+		hosts, _, _, err := cm.List(poolName, filters...)
+		if err != nil {
+			return false, err
+		}
+		for _, h := range hosts {
+			hs, err := cm.GetHostStatus(poolName, h)
+			if err != nil {
+				return false, err
+			}
+			available, err := cm.isHostAvailable(poolName, h, hs, pa.Shareable)
+			if err != nil || available {
+				return available, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// ListPendingAllocations returns the allocation requests waiting for a host of the given pool in the order they will be served.
+//
+// Expired requests are removed.
+func (cm *consulManager) ListPendingAllocations(poolName string) ([]PendingAllocation, error) {
+	if err := checkPoolName(poolName); err != nil {
+		return nil, err
+	}
+	kvps, _, err := cm.cc.KV().List(getQueueKey(poolName)+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	now := time.Now()
+	pending := make([]PendingAllocation, 0, len(kvps))
+	for _, kvp := range kvps {
+		var pa PendingAllocation
+		err = json.Unmarshal(kvp.Value, &pa)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal pending allocation %q", kvp.Key)
+		}
+		if now.After(pa.ExpirationDate) {
+			err = cm.removePendingAllocation(poolName, pa.ID)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		pending = append(pending, pa)
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].Priority != pending[j].Priority {
+			return pending[i].Priority > pending[j].Priority
+		}
+		return pending[i].CreationDate.Before(pending[j].CreationDate)
+	})
+	for i := range pending {
+		pending[i].Position = i + 1
+	}
+	return pending, nil
+}
+
+func getPendingAllocationPosition(pending []PendingAllocation, id string) int {
+	for _, pa := range pending {
+		if pa.ID == id {
+			return pa.Position
+		}
+	}
+	return 0
+}
+
+// createPendingAllocationSession creates a Consul session used to hold a pending allocation and renews it until
+// the returned cleanup function is called.
+//
+// The session is configured to delete the pending allocation when it is invalidated, so requests of a Yorc server
+// that stopped are removed from the queue once the session TTL expires.
+func (cm *consulManager) createPendingAllocationSession(poolName string) (string, func(), error) {
+	sessionID, _, err := cm.cc.Session().Create(&api.SessionEntry{
+		Name:     fmt.Sprintf("hosts pool %q pending allocation", poolName),
+		TTL:      pendingAllocationsSessionTTL.String(),
+		Behavior: api.SessionBehaviorDelete,
+		// Consul Issue : LockDelay = 0 is not allowed by API. https://github.com/hashicorp/consul/issues/1077
+		LockDelay: 1 * time.Nanosecond,
+	}, nil)
+	if err != nil {
+		return "", nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	doneCh := make(chan struct{})
+	go func() {
+		// Destroys the session when doneCh is closed
+		err := cm.cc.Session().RenewPeriodic(pendingAllocationsSessionTTL.String(), sessionID, nil, doneCh)
+		if err != nil {
+			log.Printf("Failed to renew session of hosts pool %q pending allocation: %v", poolName, err)
+		}
+	}()
+	return sessionID, func() { close(doneCh) }, nil
+}
+
+func (cm *consulManager) addPendingAllocation(poolName, sessionID string, pa PendingAllocation) error {
+	b, err := json.Marshal(pa)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal pending allocation %q", pa.ID)
+	}
+	acquired, _, err := cm.cc.KV().Acquire(&api.KVPair{Key: path.Join(getQueueKey(poolName), pa.ID), Value: b, Session: sessionID}, nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if !acquired {
+		return errors.Errorf("allocation request %q is already pending in hosts pool %q", pa.ID, poolName)
+	}
+	return nil
+}
+
+func (cm *consulManager) removePendingAllocation(poolName, id string) error {
+	_, err := cm.cc.KV().Delete(path.Join(getQueueKey(poolName), id), nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
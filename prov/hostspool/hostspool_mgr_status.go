@@ -0,0 +1,120 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+)
+
+func (cm *consulManager) UpdateHostStatus(poolName, hostname string, status HostStatus, reason string) error {
+	return cm.updateHostStatusWait(poolName, hostname, status, reason, maxWaitTimeSeconds*time.Second)
+}
+
+// updateHostStatusWait allows an administrator to put a host in maintenance, to drain it or to re-enable it.
+//
+// A draining host keeps its allocations but refuses new ones, it is put in maintenance
+// once its last allocation is released. A host in maintenance is neither allocated nor
+// checked until it is re-enabled by setting its status to free. If the connection to a
+// re-enabled host fails, the host is put in error and an error is returned.
+func (cm *consulManager) updateHostStatusWait(poolName, hostname string, status HostStatus, reason string, maxWaitTime time.Duration) error {
+	if hostname == "" {
+		return errors.WithStack(badRequestError{`"hostname" missing`})
+	}
+	switch status {
+	case HostStatusFree, HostStatusMaintenance, HostStatusDraining:
+	default:
+		return errors.WithStack(badRequestError{fmt.Sprintf("status %q can't be set on host %q, expecting one of %q, %q or %q",
+			status, hostname, HostStatusFree, HostStatusMaintenance, HostStatusDraining)})
+	}
+
+	// Taking the pool lock to prevent concurrent allocations on this host
+	_, cleanupFn, err := cm.lockKey(poolName, hostname, "status update", maxWaitTime)
+	if err != nil {
+		return err
+	}
+	defer cleanupFn()
+
+	currentStatus, err := cm.GetHostStatus(poolName, hostname)
+	if err != nil {
+		return err
+	}
+	allocations, err := cm.GetAllocations(poolName, hostname)
+	if err != nil {
+		return err
+	}
+
+	newStatus := status
+	switch status {
+	case HostStatusFree:
+		switch currentStatus {
+		case HostStatusMaintenance, HostStatusDraining:
+		case HostStatusError:
+			return errors.WithStack(badRequestError{fmt.Sprintf("can't re-enable host %q with status %q", hostname, currentStatus)})
+		default:
+			// Host already enabled
+			return nil
+		}
+		if len(allocations) > 0 {
+			newStatus = HostStatusAllocated
+		}
+	case HostStatusMaintenance:
+		if len(allocations) > 0 {
+			return errors.WithStack(badRequestError{fmt.Sprintf("can't put host %q in maintenance as it has %d allocation(s), it should be drained first", hostname, len(allocations))})
+		}
+	case HostStatusDraining:
+		if len(allocations) == 0 {
+			// Nothing to drain
+			newStatus = HostStatusMaintenance
+		}
+	}
+
+	log.Printf("Hosts pool %q: changing status of host %q from %q to %q (reason: %q)", poolName, hostname, currentStatus, newStatus, reason)
+	// A status backup may exist for a host in error, it should not be restored anymore
+	hostPath := path.Join(consulutil.HostsPoolPrefix, poolName, hostname)
+	kv := cm.cc.KV()
+	for _, key := range []string{".statusBackup", ".messageBackup"} {
+		if _, err = kv.Delete(path.Join(hostPath, key), nil); err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+	}
+	err = cm.setHostStatusWithMessage(poolName, hostname, newStatus, reason)
+	if err != nil {
+		return err
+	}
+
+	if status == HostStatusFree {
+		// Re-enabled host connection was not checked while in maintenance
+		connErr := cm.checkConnection(poolName, hostname)
+		if connErr != nil {
+			err = cm.backupHostStatus(poolName, hostname)
+			if err != nil {
+				return err
+			}
+			err = cm.setHostStatusWithMessage(poolName, hostname, HostStatusError, "failed to connect to host")
+			if err != nil {
+				return err
+			}
+			return errors.Wrapf(connErr, "host %q re-enabled but its status is now %q", hostname, HostStatusError)
+		}
+	}
+	return nil
+}
@@ -15,6 +15,7 @@
 package hostspool
 
 import (
+	"context"
 	"fmt"
 	"path"
 	"strconv"
@@ -29,6 +30,7 @@ import (
 	"github.com/ystia/yorc/helper/consulutil"
 	"github.com/ystia/yorc/helper/labelsutil"
 	"github.com/ystia/yorc/helper/sshutil"
+	"github.com/ystia/yorc/prov/policies"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -60,6 +62,9 @@ WSPb8a8DvmtjA0/IyWLtRhiKDPo0ultXa3DHINOC2+sZ5qJpsycNaqV4ObcmCJCc
 h5pSY3nqKgmTiTW5EGnhLxUnEmS0MMvVT59ldx2pZhzgDyxYWO09
 -----END RSA PRIVATE KEY-----`
 
+// testPoolName is the name of the hosts pool used by tests
+const testPoolName = "test_pool"
+
 type mockSSHClient struct {
 	config *ssh.ClientConfig
 }
@@ -87,12 +92,12 @@ func cleanupHostsPool(t *testing.T, cc *api.Client) {
 func testConsulManagerAddLabels(t *testing.T, cc *api.Client) {
 	cleanupHostsPool(t, cc)
 	cm := NewManagerWithSSHFactory(cc, mockSSHClientFactory)
-	err := cm.Add("host_labels_update", Connection{PrivateKey: dummySSHkey}, map[string]string{
+	err := cm.Add(testPoolName, "host_labels_update", Connection{PrivateKey: dummySSHkey}, map[string]string{
 		"label1":         "val1",
 		"label/&special": "val/&special",
 	})
 	require.NoError(t, err)
-	err = cm.Add("host_no_labels_update", Connection{PrivateKey: dummySSHkey}, map[string]string{
+	err = cm.Add(testPoolName, "host_no_labels_update", Connection{PrivateKey: dummySSHkey}, map[string]string{
 		"label1": "val1",
 	})
 	require.NoError(t, err)
@@ -136,14 +141,14 @@ func testConsulManagerAddLabels(t *testing.T, cc *api.Client) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var err error
-			if err = cm.AddLabels(tt.args.hostname, tt.args.labels); (err != nil) != tt.wantErr {
+			if err = cm.AddLabels(testPoolName, tt.args.hostname, tt.args.labels); (err != nil) != tt.wantErr {
 				t.Fatalf("consulManager.AddLabels() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err != nil && tt.errorCheck != nil {
 				assert.True(t, tt.errorCheck(err), "consulManager.AddLabels() unexpected error %T", err)
 			}
 			for k, v := range tt.checks {
-				kvp, _, err := cc.KV().Get(path.Join(consulutil.HostsPoolPrefix, tt.args.hostname, k), nil)
+				kvp, _, err := cc.KV().Get(path.Join(consulutil.HostsPoolPrefix, testPoolName, tt.args.hostname, k), nil)
 				if err != nil {
 					t.Fatalf("consulManager.AddLabels() consul comm error during result check (you should retry) %v", err)
 				}
@@ -159,20 +164,20 @@ func testConsulManagerAddLabels(t *testing.T, cc *api.Client) {
 			}
 		})
 	}
-	labels, _, err := cc.KV().Keys(path.Join(consulutil.HostsPoolPrefix, "host_no_labels_update", "labels")+"/", "/", nil)
+	labels, _, err := cc.KV().Keys(path.Join(consulutil.HostsPoolPrefix, testPoolName, "host_no_labels_update", "labels")+"/", "/", nil)
 	require.NoError(t, err)
 	assert.Len(t, labels, 1, `Expecting only one label for host "host_no_labels_update", something updated those labels`)
 }
 func testConsulManagerRemoveLabels(t *testing.T, cc *api.Client) {
 	cleanupHostsPool(t, cc)
 	cm := NewManagerWithSSHFactory(cc, mockSSHClientFactory)
-	err := cm.Add("host_labels_remove", Connection{PrivateKey: dummySSHkey}, map[string]string{
+	err := cm.Add(testPoolName, "host_labels_remove", Connection{PrivateKey: dummySSHkey}, map[string]string{
 		"label1":         "val1",
 		"label/&special": "val/&special",
 		"labelSurvivor":  "still here!",
 	})
 	require.NoError(t, err)
-	err = cm.Add("host_no_labels_remove", Connection{PrivateKey: dummySSHkey}, map[string]string{
+	err = cm.Add(testPoolName, "host_no_labels_remove", Connection{PrivateKey: dummySSHkey}, map[string]string{
 		"label1": "val1",
 	})
 	require.NoError(t, err)
@@ -205,14 +210,14 @@ func testConsulManagerRemoveLabels(t *testing.T, cc *api.Client) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var err error
-			if err = cm.RemoveLabels(tt.args.hostname, tt.args.labels); (err != nil) != tt.wantErr {
+			if err = cm.RemoveLabels(testPoolName, tt.args.hostname, tt.args.labels); (err != nil) != tt.wantErr {
 				t.Fatalf("consulManager.RemoveLabels() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err != nil && tt.errorCheck != nil {
 				assert.True(t, tt.errorCheck(err), "consulManager.AddLabels() unexpected error %T", err)
 			}
 			for k, v := range tt.checks {
-				kvp, _, err := cc.KV().Get(path.Join(consulutil.HostsPoolPrefix, tt.args.hostname, k), nil)
+				kvp, _, err := cc.KV().Get(path.Join(consulutil.HostsPoolPrefix, testPoolName, tt.args.hostname, k), nil)
 				if err != nil {
 					t.Fatalf("consulManager.RemoveLabels() consul comm error during result check (you should retry) %v", err)
 				}
@@ -228,7 +233,7 @@ func testConsulManagerRemoveLabels(t *testing.T, cc *api.Client) {
 			}
 		})
 	}
-	labels, _, err := cc.KV().Keys(path.Join(consulutil.HostsPoolPrefix, "host_no_labels_remove", "labels")+"/", "/", nil)
+	labels, _, err := cc.KV().Keys(path.Join(consulutil.HostsPoolPrefix, testPoolName, "host_no_labels_remove", "labels")+"/", "/", nil)
 	require.NoError(t, err)
 	assert.Len(t, labels, 1, `Expecting only one label for host "host_no_labels_remove", something updated those labels`)
 }
@@ -278,14 +283,14 @@ func testConsulManagerAdd(t *testing.T, cc *api.Client) {
 		t.Run(tt.name, func(t *testing.T) {
 			cm := NewManagerWithSSHFactory(cc, mockSSHClientFactory)
 			var err error
-			if err = cm.Add(tt.args.hostname, tt.args.conn, tt.args.labels); (err != nil) != tt.wantErr {
+			if err = cm.Add(testPoolName, tt.args.hostname, tt.args.conn, tt.args.labels); (err != nil) != tt.wantErr {
 				t.Fatalf("consulManager.Add() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err != nil && tt.errorCheck != nil {
 				assert.True(t, tt.errorCheck(err), "consulManager.AddLabels() unexpected error %T", err)
 			}
 			for k, v := range tt.checks {
-				kvp, _, err := cc.KV().Get(path.Join(consulutil.HostsPoolPrefix, tt.args.hostname, k), nil)
+				kvp, _, err := cc.KV().Get(path.Join(consulutil.HostsPoolPrefix, testPoolName, tt.args.hostname, k), nil)
 				if err != nil {
 					t.Fatalf("consulManager.Add() consul comm error during result check (you should retry) %v", err)
 				}
@@ -307,7 +312,7 @@ func testConsulManagerUpdateConnection(t *testing.T, cc *api.Client) {
 	cleanupHostsPool(t, cc)
 	cm := NewManagerWithSSHFactory(cc, mockSSHClientFactory)
 	originalConn := Connection{User: "u1", Password: "test", Host: "h1", Port: 24, PrivateKey: dummySSHkey}
-	cm.Add("hostUpdateConn1", originalConn, nil)
+	cm.Add(testPoolName, "hostUpdateConn1", originalConn, nil)
 	type args struct {
 		hostname string
 		conn     Connection
@@ -368,14 +373,14 @@ func testConsulManagerUpdateConnection(t *testing.T, cc *api.Client) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var err error
-			if err = cm.UpdateConnection(tt.args.hostname, tt.args.conn); (err != nil) != tt.wantErr {
+			if err = cm.UpdateConnection(testPoolName, tt.args.hostname, tt.args.conn); (err != nil) != tt.wantErr {
 				t.Fatalf("consulManager.Add() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err != nil && tt.errorCheck != nil {
 				assert.True(t, tt.errorCheck(err), "consulManager.AddLabels() unexpected error %T", err)
 			}
 			for k, v := range tt.checks {
-				kvp, _, err := cc.KV().Get(path.Join(consulutil.HostsPoolPrefix, tt.args.hostname, k), nil)
+				kvp, _, err := cc.KV().Get(path.Join(consulutil.HostsPoolPrefix, testPoolName, tt.args.hostname, k), nil)
 				if err != nil {
 					t.Fatalf("consulManager.Add() consul comm error during result check (you should retry) %v", err)
 				}
@@ -396,9 +401,9 @@ func testConsulManagerUpdateConnection(t *testing.T, cc *api.Client) {
 func testConsulManagerRemove(t *testing.T, cc *api.Client) {
 	cleanupHostsPool(t, cc)
 	cm := NewManagerWithSSHFactory(cc, mockSSHClientFactory)
-	cm.Add("host1", Connection{PrivateKey: dummySSHkey}, nil)
-	cm.Add("host2", Connection{PrivateKey: dummySSHkey}, nil)
-	_, err := cc.KV().Put(&api.KVPair{Key: path.Join(consulutil.HostsPoolPrefix, "host2", "status"), Value: []byte(HostStatusAllocated.String())}, nil)
+	cm.Add(testPoolName, "host1", Connection{PrivateKey: dummySSHkey}, nil)
+	cm.Add(testPoolName, "host2", Connection{PrivateKey: dummySSHkey}, nil)
+	_, err := cc.KV().Put(&api.KVPair{Key: path.Join(consulutil.HostsPoolPrefix, testPoolName, "host2", "status"), Value: []byte(HostStatusAllocated.String())}, nil)
 	require.NoError(t, err)
 	type args struct {
 		hostname string
@@ -416,7 +421,7 @@ func testConsulManagerRemove(t *testing.T, cc *api.Client) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var err error
-			if err := cm.Remove(tt.args.hostname); (err != nil) != tt.wantErr {
+			if err := cm.Remove(testPoolName, tt.args.hostname); (err != nil) != tt.wantErr {
 				t.Errorf("consulManager.Remove() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err != nil && tt.errorCheck != nil {
@@ -429,24 +434,24 @@ func testConsulManagerRemove(t *testing.T, cc *api.Client) {
 func testConsulManagerList(t *testing.T, cc *api.Client) {
 	cleanupHostsPool(t, cc)
 	cm := &consulManager{cc, mockSSHClientFactory}
-	err := cm.Add("list_host1", Connection{PrivateKey: dummySSHkey}, nil)
+	err := cm.Add(testPoolName, "list_host1", Connection{PrivateKey: dummySSHkey}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cm.Add("list_host2", Connection{PrivateKey: dummySSHkey}, nil)
+	err = cm.Add(testPoolName, "list_host2", Connection{PrivateKey: dummySSHkey}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cm.Add("list_host3", Connection{PrivateKey: dummySSHkey}, nil)
+	err = cm.Add(testPoolName, "list_host3", Connection{PrivateKey: dummySSHkey}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cm.Add("list_host4", Connection{PrivateKey: dummySSHkey}, nil)
+	err = cm.Add(testPoolName, "list_host4", Connection{PrivateKey: dummySSHkey}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	hosts, warnings, checkpoint, err := cm.List()
+	hosts, warnings, checkpoint, err := cm.List(testPoolName)
 	require.NoError(t, err)
 	assert.Len(t, warnings, 0)
 	assert.Len(t, hosts, 4)
@@ -456,11 +461,11 @@ func testConsulManagerList(t *testing.T, cc *api.Client) {
 	assert.Contains(t, hosts, "list_host4")
 
 	// Check checkpoint increase
-	err = cm.Add("list_host5", Connection{PrivateKey: dummySSHkey}, nil)
+	err = cm.Add(testPoolName, "list_host5", Connection{PrivateKey: dummySSHkey}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	hosts, warnings, checkpoint2, err := cm.List()
+	hosts, warnings, checkpoint2, err := cm.List(testPoolName)
 	require.NoError(t, err)
 	assert.Len(t, warnings, 0)
 	assert.Len(t, hosts, 5)
@@ -483,10 +488,10 @@ func testConsulManagerGetHost(t *testing.T, cc *api.Client) {
 		Port:       26,
 		PrivateKey: dummySSHkey,
 	}
-	err := cm.Add("get_host1", connection, labelList)
+	err := cm.Add(testPoolName, "get_host1", connection, labelList)
 	require.NoError(t, err)
 
-	host, err := cm.GetHost("get_host1")
+	host, err := cm.GetHost(testPoolName, "get_host1")
 	require.NoError(t, err)
 	assert.Equal(t, connection, host.Connection)
 	assert.Equal(t, labelList, host.Labels)
@@ -498,27 +503,27 @@ func testConsulManagerGetHost(t *testing.T, cc *api.Client) {
 func testConsulManagerConcurrency(t *testing.T, cc *api.Client) {
 	cleanupHostsPool(t, cc)
 	cm := &consulManager{cc, mockSSHClientFactory}
-	err := cm.Add("concurrent_host1", Connection{PrivateKey: dummySSHkey}, nil)
+	err := cm.Add(testPoolName, "concurrent_host1", Connection{PrivateKey: dummySSHkey}, nil)
 	require.NoError(t, err)
-	l, err := cc.LockKey(kvLockKey)
+	l, err := cc.LockKey(getLockKey(testPoolName))
 	require.NoError(t, err)
 	_, err = l.Lock(nil)
 	require.NoError(t, err)
 	defer l.Unlock()
 
-	err = cm.addWait("concurrent_host2", Connection{PrivateKey: dummySSHkey}, nil, 500*time.Millisecond)
+	err = cm.addWait(testPoolName, "concurrent_host2", Connection{PrivateKey: dummySSHkey}, nil, 500*time.Millisecond)
 	assert.Error(t, err, "Expecting concurrency lock for addWait()")
-	err = cm.removeWait("concurrent_host1", 500*time.Millisecond)
+	err = cm.removeWait(testPoolName, "concurrent_host1", 500*time.Millisecond)
 	assert.Error(t, err, "Expecting concurrency lock for removeWait()")
-	err = cm.addLabelsWait("concurrent_host1", map[string]string{"t1": "v1"}, 500*time.Millisecond)
+	err = cm.addLabelsWait(testPoolName, "concurrent_host1", map[string]string{"t1": "v1"}, 500*time.Millisecond)
 	assert.Error(t, err, "Expecting concurrency lock for addLabelsWait()")
-	err = cm.removeLabelsWait("concurrent_host1", []string{"t1"}, 500*time.Millisecond)
+	err = cm.removeLabelsWait(testPoolName, "concurrent_host1", []string{"t1"}, 500*time.Millisecond)
 	assert.Error(t, err, "Expecting concurrency lock for removeLabelsWait()")
-	err = cm.updateConnectionWait("concurrent_host1", Connection{}, 500*time.Millisecond)
+	err = cm.updateConnectionWait(testPoolName, "concurrent_host1", Connection{}, 500*time.Millisecond)
 	assert.Error(t, err, "Expecting concurrency lock for removeLabelsWait()")
-	_, _, err = cm.allocateWait(500*time.Millisecond, &Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "test", Shareable: false})
+	_, _, err = cm.allocateWait(testPoolName, 500*time.Millisecond, &Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "test", Shareable: false})
 	assert.Error(t, err, "Expecting concurrency lock for allocateWait()")
-	err = cm.releaseWait("concurrent_host1", &Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "test", Shareable: false}, 500*time.Millisecond)
+	err = cm.releaseWait(testPoolName, "concurrent_host1", &Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "test", Shareable: false}, 500*time.Millisecond)
 	assert.Error(t, err, "Expecting concurrency lock for releaseWait()")
 }
 
@@ -574,11 +579,11 @@ func testConsulManagerApply(t *testing.T, cc *api.Client) {
 
 	// Apply this definition
 	var checkpoint uint64
-	err := cm.Apply(hostpool, &checkpoint)
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
 	require.NoError(t, err, "Unexpected failure applying host pool configuration")
 	assert.NotEqual(t, uint64(0), checkpoint, "Expected checkpoint to be > 0 after apply")
 	// Check the pool now
-	hosts, warnings, newCkpt, err := cm.List()
+	hosts, warnings, newCkpt, err := cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 	assert.Len(t, warnings, 0)
 	assert.Len(t, hosts, 3)
@@ -589,7 +594,7 @@ func testConsulManagerApply(t *testing.T, cc *api.Client) {
 		suffix := strconv.Itoa(i)
 		hostname := "host" + suffix
 		assert.Contains(t, hosts, hostname)
-		host, err := cm.GetHost(hostname)
+		host, err := cm.GetHost(testPoolName, hostname)
 		require.NoError(t, err, "Could not get host %s", hostname)
 		assert.Equal(t, hostpool[i].Connection, host.Connection,
 			"Unexpected connection value for host %s", hostname)
@@ -602,10 +607,10 @@ func testConsulManagerApply(t *testing.T, cc *api.Client) {
 	filter, err := labelsutil.CreateFilter(
 		fmt.Sprintf("%s=%s", filterLabel, hostpool[1].Labels[filterLabel]))
 	require.NoError(t, err, "Unexpected error creating a filter")
-	allocatedName, warnings, err := cm.Allocate(&Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "test", Shareable: false}, filter)
+	allocatedName, warnings, err := cm.Allocate(testPoolName, &Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "test", Shareable: false}, filter)
 	assert.Equal(t, hostpool[1].Name, allocatedName,
 		"Unexpected host allocated")
-	allocatedHost, err := cm.GetHost(allocatedName)
+	allocatedHost, err := cm.GetHost(testPoolName, allocatedName)
 	require.NoError(t, err, "Unexpected error getting allocated host")
 	require.NotNil(t, allocatedHost)
 	require.Equal(t, 1, len(allocatedHost.Allocations))
@@ -655,19 +660,19 @@ func testConsulManagerApply(t *testing.T, cc *api.Client) {
 		})
 	}
 
-	_, _, checkpoint, err = cm.List()
+	_, _, checkpoint, err = cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 
 	// Apply this new definition
 	oldcheckpoint := checkpoint
-	err = cm.Apply(hostpool, &checkpoint)
+	err = cm.Apply(testPoolName, hostpool, &checkpoint)
 	require.NoError(t, err,
 		"Unexpected failure applying new host pool configuration")
 
 	assert.NotEqual(t, oldcheckpoint, checkpoint, "Expected a checkpoint change")
 
 	// Check the pool now
-	hosts, warnings, ckpt2, err := cm.List()
+	hosts, warnings, ckpt2, err := cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 	assert.Len(t, warnings, 0)
 	assert.Len(t, hosts, 4)
@@ -683,7 +688,7 @@ func testConsulManagerApply(t *testing.T, cc *api.Client) {
 
 		hostname := "host" + suffix
 		assert.Contains(t, hosts, hostname)
-		host, err := cm.GetHost(hostname)
+		host, err := cm.GetHost(testPoolName, hostname)
 		require.NoError(t, err, "Could not get host %s", hostname)
 		assert.Equal(t, hostpool[i].Connection, host.Connection,
 			"Unexpected connection value for host %s", hostname)
@@ -692,7 +697,7 @@ func testConsulManagerApply(t *testing.T, cc *api.Client) {
 	}
 
 	// Check the allocated status of host1 didn't change
-	allocatedHost, err = cm.GetHost(allocatedName)
+	allocatedHost, err = cm.GetHost(testPoolName, allocatedName)
 	require.NoError(t, err, "Unexpected error getting allocated host")
 	assert.Equal(t, HostStatusAllocated, allocatedHost.Status,
 		"Unexpected status for an allocated host after Pool redefinition")
@@ -707,11 +712,11 @@ func testConsulManagerApplyWithAllocation(t *testing.T, cc *api.Client) {
 
 	// Apply this definition
 	var checkpoint uint64
-	err := cm.Apply(hostpool, &checkpoint)
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
 	require.NoError(t, err, "Unexpected failure applying host pool configuration")
 	assert.NotEqual(t, uint64(0), checkpoint, "Expected checkpoint to be > 0 after apply")
 	// Check the pool now
-	hosts, warnings, newCkpt, err := cm.List()
+	hosts, warnings, newCkpt, err := cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 	assert.Len(t, warnings, 0)
 	assert.Len(t, hosts, 1)
@@ -720,7 +725,7 @@ func testConsulManagerApplyWithAllocation(t *testing.T, cc *api.Client) {
 
 	hostname := "host0"
 	assert.Contains(t, hosts, hostname)
-	host, err := cm.GetHost(hostname)
+	host, err := cm.GetHost(testPoolName, hostname)
 	require.NoError(t, err, "Could not get host %s", hostname)
 	assert.Equal(t, hostpool[0].Connection, host.Connection,
 		"Unexpected connection value for host %s", hostname)
@@ -736,13 +741,13 @@ func testConsulManagerApplyWithAllocation(t *testing.T, cc *api.Client) {
 	}
 
 	AllocResources := map[string]string{"host.num_cpus": "2", "host.mem_size": "2 GB", "host.disk_size": "10 GB"}
-	allocatedName, warnings, err := cm.Allocate(&Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "test", Shareable: true, Resources: AllocResources})
+	allocatedName, warnings, err := cm.Allocate(testPoolName, &Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "test", Shareable: true, Resources: AllocResources})
 	assert.Equal(t, hostpool[0].Name, allocatedName,
 		"Unexpected host allocated")
 
-	err = cm.UpdateResourcesLabels(hostname, AllocResources, subtract, updateResourcesLabels)
+	err = cm.UpdateResourcesLabels(testPoolName, hostname, AllocResources, subtract, updateResourcesLabels)
 	require.NoError(t, err, "Unexpected error updating labels")
-	allocatedHost, err := cm.GetHost(allocatedName)
+	allocatedHost, err := cm.GetHost(testPoolName, allocatedName)
 	require.NoError(t, err, "Unexpected error getting allocated host")
 	require.NotNil(t, allocatedHost)
 	require.Equal(t, 1, len(allocatedHost.Allocations))
@@ -760,13 +765,13 @@ func testConsulManagerApplyWithAllocation(t *testing.T, cc *api.Client) {
 	}
 
 	AllocResources = map[string]string{"host.num_cpus": "2", "host.mem_size": "2 GB", "host.disk_size": "10 GB"}
-	allocatedName, warnings, err = cm.Allocate(&Allocation{NodeName: "node_test2", Instance: "instance_test2", DeploymentID: "test2", Shareable: true, Resources: AllocResources})
+	allocatedName, warnings, err = cm.Allocate(testPoolName, &Allocation{NodeName: "node_test2", Instance: "instance_test2", DeploymentID: "test2", Shareable: true, Resources: AllocResources})
 	assert.Equal(t, hostpool[0].Name, allocatedName,
 		"Unexpected host allocated")
 
-	err = cm.UpdateResourcesLabels(hostname, AllocResources, subtract, updateResourcesLabels)
+	err = cm.UpdateResourcesLabels(testPoolName, hostname, AllocResources, subtract, updateResourcesLabels)
 	require.NoError(t, err, "Unexpected error updating labels")
-	allocatedHost, err = cm.GetHost(allocatedName)
+	allocatedHost, err = cm.GetHost(testPoolName, allocatedName)
 	require.NoError(t, err, "Unexpected error getting allocated host")
 	require.NotNil(t, allocatedHost)
 	require.Equal(t, 2, len(allocatedHost.Allocations))
@@ -782,12 +787,12 @@ func testConsulManagerApplyWithAllocation(t *testing.T, cc *api.Client) {
 		"host.disk_size": "60 GB",
 	}
 
-	_, _, checkpoint, err = cm.List()
+	_, _, checkpoint, err = cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 
 	// Apply this new definition
 	oldcheckpoint := checkpoint
-	err = cm.Apply(hostpool, &checkpoint)
+	err = cm.Apply(testPoolName, hostpool, &checkpoint)
 	require.NoError(t, err,
 		"Unexpected failure applying new host pool configuration")
 
@@ -800,7 +805,7 @@ func testConsulManagerApplyWithAllocation(t *testing.T, cc *api.Client) {
 		"host.disk_size": "40 GB",
 	}
 
-	allocatedHost, err = cm.GetHost(allocatedName)
+	allocatedHost, err = cm.GetHost(testPoolName, allocatedName)
 	require.NoError(t, err, "Unexpected error getting allocated host")
 	require.NotNil(t, allocatedHost)
 	assert.Equal(t, expectedLabels, allocatedHost.Labels, "labels have not been updated after apply")
@@ -815,7 +820,7 @@ func testConsulManagerApplyErrorNoName(t *testing.T, cc *api.Client) {
 
 	// Apply this definition
 	var checkpoint uint64
-	err := cm.Apply(hostpool, &checkpoint)
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
 	require.NoError(t, err, "Unexpected failure applying host pool configuration")
 	assert.NotEqual(t, uint64(0), checkpoint, "Expected checkpoint to be > 0 after apply")
 
@@ -824,14 +829,14 @@ func testConsulManagerApplyErrorNoName(t *testing.T, cc *api.Client) {
 	hostpool[0].Name = "newName"
 	hostpool = append(hostpool, hostpool[0])
 	hostpool[len(hostpool)-1].Name = ""
-	_, _, ckpt1, err := cm.List()
+	_, _, ckpt1, err := cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool before test")
 	ckpt := ckpt1
-	err = cm.Apply(hostpool, &ckpt)
+	err = cm.Apply(testPoolName, hostpool, &ckpt)
 	assert.Error(t, err, "Expected an error adding a host with no name")
 
 	// Check the new definition wasn't applied after this error
-	hosts, warnings, ckpt2, err := cm.List()
+	hosts, warnings, ckpt2, err := cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 	assert.Len(t, warnings, 0)
 	assert.Len(t, hosts, 3)
@@ -851,22 +856,22 @@ func testConsulManagerApplyErrorDuplicateName(t *testing.T, cc *api.Client) {
 
 	// Apply this definition
 	var checkpoint uint64
-	err := cm.Apply(hostpool, &checkpoint)
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
 	require.NoError(t, err, "Unexpected failure applying host pool configuration")
 	assert.NotEqual(t, uint64(0), checkpoint, "Expected checkpoint to be > 0 after apply")
 
 	// Error case: duplicate names
 	oldName := hostpool[len(hostpool)-1].Name
 	hostpool[len(hostpool)-1].Name = hostpool[0].Name
-	_, _, ckpt1, err := cm.List()
+	_, _, ckpt1, err := cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool before test")
 	ckpt := ckpt1
-	err = cm.Apply(hostpool, &ckpt)
+	err = cm.Apply(testPoolName, hostpool, &ckpt)
 	assert.Error(t, err,
 		"Expected an error applying a hosts pool with duplicate names")
 
 	// Check the new definition wasn't applied after this error
-	hosts, warnings, ckpt2, err := cm.List()
+	hosts, warnings, ckpt2, err := cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 	assert.Len(t, warnings, 0)
 	assert.Len(t, hosts, 3)
@@ -884,7 +889,7 @@ func testConsulManagerApplyErrorDeleteAllocatedHost(t *testing.T, cc *api.Client
 	var hostpool = createHosts(3)
 
 	var checkpoint uint64
-	err := cm.Apply(hostpool, &checkpoint)
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
 	require.NoError(t, err, "Unexpected failure applying host pool configuration")
 	assert.NotEqual(t, uint64(0), checkpoint, "Expected checkpoint to be > 0 after apply")
 
@@ -893,10 +898,10 @@ func testConsulManagerApplyErrorDeleteAllocatedHost(t *testing.T, cc *api.Client
 	filter, err := labelsutil.CreateFilter(
 		fmt.Sprintf("%s=%s", filterLabel, hostpool[1].Labels[filterLabel]))
 	require.NoError(t, err, "Unexpected error creating a filter")
-	allocatedName, warnings, err := cm.Allocate(&Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "test", Shareable: false}, filter)
+	allocatedName, warnings, err := cm.Allocate(testPoolName, &Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "test", Shareable: false}, filter)
 	assert.Equal(t, hostpool[1].Name, allocatedName,
 		"Unexpected host allocated")
-	allocatedHost, err := cm.GetHost(allocatedName)
+	allocatedHost, err := cm.GetHost(testPoolName, allocatedName)
 	require.NoError(t, err, "Unexpected error getting allocated host")
 	require.NotNil(t, allocatedHost)
 	require.Equal(t, 1, len(allocatedHost.Allocations))
@@ -904,15 +909,15 @@ func testConsulManagerApplyErrorDeleteAllocatedHost(t *testing.T, cc *api.Client
 	require.Equal(t, "test", allocatedHost.Allocations[0].DeploymentID)
 	require.Equal(t, "node_test", allocatedHost.Allocations[0].NodeName)
 
-	hosts1, _, checkpoint, err := cm.List()
+	hosts1, _, checkpoint, err := cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting the hosts pool")
 
 	// Error case : attempt to delete an allocated host
 	hostpool1 := hostpool[:1]
 	var ckpt uint64
-	err = cm.Apply(hostpool1, &ckpt)
+	err = cm.Apply(testPoolName, hostpool1, &ckpt)
 	assert.Error(t, err, "Expected an error deleting an allocated host")
-	hosts2, warnings, ckpt2, err := cm.List()
+	hosts2, warnings, ckpt2, err := cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 	assert.Len(t, warnings, 0)
 	assert.Len(t, hosts2, 3)
@@ -928,14 +933,14 @@ func testConsulManagerApplyErrorOutdatedCheckpoint(t *testing.T, cc *api.Client)
 	var hostpool = createHosts(3)
 
 	var checkpoint uint64
-	err := cm.Apply(hostpool, &checkpoint)
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
 	require.NoError(t, err, "Unexpected failure applying host pool configuration")
 	assert.NotEqual(t, uint64(0), checkpoint, "Expected checkpoint to be > 0 after apply")
 
 	// Error case : outdated checkpoint
 	hostpool[0].Labels["label1"] = "newValues"
 	oldcheckpoint := checkpoint - 1
-	err = cm.Apply(hostpool, &oldcheckpoint)
+	err = cm.Apply(testPoolName, hostpool, &oldcheckpoint)
 	assert.Error(t, err, "Expected an error doing an apply with outdated checkpoint")
 }
 
@@ -947,12 +952,12 @@ func testConsulManagerApplyBadConnection(t *testing.T, cc *api.Client) {
 	var hostpool = createHosts(3)
 
 	var checkpoint uint64
-	err := cm.Apply(hostpool, &checkpoint)
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
 	require.NoError(t, err, "Unexpected failure applying host pool configuration")
 	assert.NotEqual(t, uint64(0), checkpoint, "Expected checkpoint to be > 0 after apply")
 
 	// Check an apply with one host having bad connection settings
-	_, _, checkpoint, err = cm.List()
+	_, _, checkpoint, err = cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 	newHost := Host{
 		Name: "testhost",
@@ -964,33 +969,33 @@ func testConsulManagerApplyBadConnection(t *testing.T, cc *api.Client) {
 	}
 
 	hostpool = append(hostpool, newHost)
-	err = cm.Apply(hostpool, &checkpoint)
+	err = cm.Apply(testPoolName, hostpool, &checkpoint)
 	assert.NoError(t, err, "Expected no error apply a configuration containing a host with bad credentials")
 
 	// Check host status
-	hostInPool, err := cm.GetHost(newHost.Name)
+	hostInPool, err := cm.GetHost(testPoolName, newHost.Name)
 	require.NoError(t, err, "Unexpected error attempting to get host %s", newHost.Name)
 	assert.Equal(t, HostStatusError.String(), hostInPool.Status.String(),
 		"Expected a status error for host with bad credentials")
 
 	// Check a backup status exists
-	backupStatus, err := cm.getStatus(newHost.Name, true)
+	backupStatus, err := cm.getStatus(testPoolName, newHost.Name, true)
 	assert.NoError(t, err, "Expected to have a backup status")
 	assert.Equal(t, backupStatus.String(), HostStatusFree.String(), "Unexpected backup status")
 
 	// Fixing bad credentials
 	hostpool[len(hostpool)-1].Connection.User = "test"
-	err = cm.Apply(hostpool, &checkpoint)
+	err = cm.Apply(testPoolName, hostpool, &checkpoint)
 	assert.NoError(t, err,
 		"Expected no error apply a configuration with host credentials fixed ")
 
 	// Verify there is no connection failure anymore
-	err = cm.checkConnection(newHost.Name)
+	err = cm.checkConnection(testPoolName, newHost.Name)
 	require.NoError(t, err,
 		"Unexpected connection error after credentials fix")
 
 	// Check host status change
-	hostInPool, err = cm.GetHost(newHost.Name)
+	hostInPool, err = cm.GetHost(testPoolName, newHost.Name)
 	require.NoError(t, err,
 		"Unexpected error attempting to get host %s after apply", newHost.Name)
 	assert.Equal(t, HostStatusFree.String(), hostInPool.Status.String(),
@@ -1008,11 +1013,11 @@ func testConsulManagerAllocateShareableCompute(t *testing.T, cc *api.Client) {
 
 	// Apply this definition
 	var checkpoint uint64
-	err := cm.Apply(hostpool, &checkpoint)
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
 	require.NoError(t, err, "Unexpected failure applying host pool configuration")
 	assert.NotEqual(t, uint64(0), checkpoint, "Expected checkpoint to be > 0 after apply")
 	// Check the pool now
-	hosts, warnings, newCkpt, err := cm.List()
+	hosts, warnings, newCkpt, err := cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 	assert.Len(t, warnings, 0)
 	assert.Len(t, hosts, 1)
@@ -1025,10 +1030,10 @@ func testConsulManagerAllocateShareableCompute(t *testing.T, cc *api.Client) {
 	filter, err := labelsutil.CreateFilter(
 		fmt.Sprintf("%s=%s", filterLabel, hostpool[0].Labels[filterLabel]))
 	require.NoError(t, err, "Unexpected error creating a filter")
-	allocatedName, warnings, err := cm.Allocate(alloc1, filter)
+	allocatedName, warnings, err := cm.Allocate(testPoolName, alloc1, filter)
 	assert.Equal(t, hostpool[0].Name, allocatedName,
 		"Unexpected host allocated")
-	allocatedHost, err := cm.GetHost(allocatedName)
+	allocatedHost, err := cm.GetHost(testPoolName, allocatedName)
 	require.NoError(t, err, "Unexpected error getting allocated host")
 	require.NotNil(t, allocatedHost)
 	require.Equal(t, 1, len(allocatedHost.Allocations))
@@ -1044,10 +1049,10 @@ func testConsulManagerAllocateShareableCompute(t *testing.T, cc *api.Client) {
 	filter, err = labelsutil.CreateFilter(
 		fmt.Sprintf("%s=%s", filterLabel, hostpool[0].Labels[filterLabel]))
 	require.NoError(t, err, "Unexpected error creating a filter")
-	allocatedName, warnings, err = cm.Allocate(alloc2, filter)
+	allocatedName, warnings, err = cm.Allocate(testPoolName, alloc2, filter)
 	assert.Equal(t, hostpool[0].Name, allocatedName,
 		"Unexpected host allocated")
-	allocatedHost, err = cm.GetHost(allocatedName)
+	allocatedHost, err = cm.GetHost(testPoolName, allocatedName)
 	require.NoError(t, err, "Unexpected error getting allocated host")
 	require.NotNil(t, allocatedHost)
 	require.Equal(t, 2, len(allocatedHost.Allocations))
@@ -1058,18 +1063,18 @@ func testConsulManagerAllocateShareableCompute(t *testing.T, cc *api.Client) {
 	require.Equal(t, resources, allocatedHost.Allocations[1].Resources)
 
 	// Release 2nd allocation
-	err = cm.Release(hostpool[0].Name, alloc2)
+	err = cm.Release(testPoolName, hostpool[0].Name, alloc2)
 	require.NoError(t, err, "Unexpected error releasing host allocation1")
-	allocatedHost, err = cm.GetHost(allocatedName)
+	allocatedHost, err = cm.GetHost(testPoolName, allocatedName)
 	require.NoError(t, err, "Unexpected error getting allocated host")
 	require.NotNil(t, allocatedHost)
 	require.Equal(t, 1, len(allocatedHost.Allocations))
 	require.Equal(t, HostStatusAllocated, allocatedHost.Status)
 
 	// Release 1st allocation
-	err = cm.Release(hostpool[0].Name, alloc1)
+	err = cm.Release(testPoolName, hostpool[0].Name, alloc1)
 	require.NoError(t, err, "Unexpected error releasing host allocation2")
-	allocatedHost, err = cm.GetHost(allocatedName)
+	allocatedHost, err = cm.GetHost(testPoolName, allocatedName)
 	require.NoError(t, err, "Unexpected error getting allocated host")
 	require.NotNil(t, allocatedHost)
 	require.Equal(t, 0, len(allocatedHost.Allocations))
@@ -1086,10 +1091,10 @@ func testConsulManagerAllocateConcurrency(t *testing.T, cc *api.Client) {
 	// Configure a Hosts Pool
 	var hostpool = createHosts(numberOfHosts)
 	var checkpoint uint64
-	err := cm.Apply(hostpool, &checkpoint)
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
 	require.NoError(t, err, "Unexpected failure applying host pool configuration")
 	// Check the pool now
-	hosts, warnings, _, err := cm.List()
+	hosts, warnings, _, err := cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 	assert.Len(t, warnings, 0)
 	assert.Len(t, hosts, numberOfHosts)
@@ -1115,11 +1120,11 @@ func testConsulManagerAllocateConcurrency(t *testing.T, cc *api.Client) {
 	close(errors)
 
 	// Check all hosts are allocated
-	hosts, _, _, err = cm.List()
+	hosts, _, _, err = cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool after allocation")
 
 	for _, hostname := range hosts {
-		host, err := cm.GetHost(hostname)
+		host, err := cm.GetHost(testPoolName, hostname)
 		require.NoError(t, err, "Failed to get host %s", hostname)
 		assert.Equal(t, HostStatusAllocated.String(), host.Status.String(),
 			"Unexpected status for host %s", hostname)
@@ -1136,7 +1141,7 @@ func routineAllocate(
 
 	defer waitGroup.Done()
 	fmt.Println("Attempting to allocate a host in routine", id)
-	hostname, _, err := cm.Allocate(&Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "dep_test", Shareable: false})
+	hostname, _, err := cm.Allocate(testPoolName, &Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "dep_test", Shareable: false})
 	if err != nil {
 		fmt.Println("Failed to allocate a host in routine", id)
 		errors <- err
@@ -1156,11 +1161,11 @@ func testConsulManagerAddLabelsWithAllocation(t *testing.T, cc *api.Client) {
 
 	// Apply this definition
 	var checkpoint uint64
-	err := cm.Apply(hostpool, &checkpoint)
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
 	require.NoError(t, err, "Unexpected failure applying host pool configuration")
 	assert.NotEqual(t, uint64(0), checkpoint, "Expected checkpoint to be > 0 after apply")
 	// Check the pool now
-	hosts, warnings, newCkpt, err := cm.List()
+	hosts, warnings, newCkpt, err := cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 	assert.Len(t, warnings, 0)
 	assert.Len(t, hosts, 1)
@@ -1169,7 +1174,7 @@ func testConsulManagerAddLabelsWithAllocation(t *testing.T, cc *api.Client) {
 
 	hostname := "host0"
 	assert.Contains(t, hosts, hostname)
-	host, err := cm.GetHost(hostname)
+	host, err := cm.GetHost(testPoolName, hostname)
 	require.NoError(t, err, "Could not get host %s", hostname)
 	assert.Equal(t, hostpool[0].Connection, host.Connection,
 		"Unexpected connection value for host %s", hostname)
@@ -1185,13 +1190,13 @@ func testConsulManagerAddLabelsWithAllocation(t *testing.T, cc *api.Client) {
 	}
 
 	AllocResources := map[string]string{"host.num_cpus": "2", "host.mem_size": "2 GB", "host.disk_size": "10 GB"}
-	allocatedName, warnings, err := cm.Allocate(&Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "test", Shareable: false, Resources: AllocResources})
+	allocatedName, warnings, err := cm.Allocate(testPoolName, &Allocation{NodeName: "node_test", Instance: "instance_test", DeploymentID: "test", Shareable: false, Resources: AllocResources})
 	assert.Equal(t, hostpool[0].Name, allocatedName,
 		"Unexpected host allocated")
 
-	err = cm.UpdateResourcesLabels(hostname, AllocResources, subtract, updateResourcesLabels)
+	err = cm.UpdateResourcesLabels(testPoolName, hostname, AllocResources, subtract, updateResourcesLabels)
 	require.NoError(t, err, "Unexpected error updating labels")
-	allocatedHost, err := cm.GetHost(allocatedName)
+	allocatedHost, err := cm.GetHost(testPoolName, allocatedName)
 	require.NoError(t, err, "Unexpected error getting allocated host")
 	require.NotNil(t, allocatedHost)
 	require.Equal(t, 1, len(allocatedHost.Allocations))
@@ -1207,11 +1212,11 @@ func testConsulManagerAddLabelsWithAllocation(t *testing.T, cc *api.Client) {
 		"host.disk_size": "60 GB",
 	}
 
-	_, _, checkpoint, err = cm.List()
+	_, _, checkpoint, err = cm.List(testPoolName)
 	require.NoError(t, err, "Unexpected error getting list of hosts in pool")
 
 	// Add new labels
-	err = cm.AddLabels(hostname, newLabels)
+	err = cm.AddLabels(testPoolName, hostname, newLabels)
 	require.NoError(t, err,
 		"Unexpected failure adding new labels")
 
@@ -1222,7 +1227,7 @@ func testConsulManagerAddLabelsWithAllocation(t *testing.T, cc *api.Client) {
 		"host.disk_size": "50 GB",
 	}
 
-	allocatedHost, err = cm.GetHost(allocatedName)
+	allocatedHost, err = cm.GetHost(testPoolName, allocatedName)
 	require.NoError(t, err, "Unexpected error getting allocated host")
 	require.NotNil(t, allocatedHost)
 	require.Equal(t, 1, len(allocatedHost.Allocations))
@@ -1231,3 +1236,350 @@ func testConsulManagerAddLabelsWithAllocation(t *testing.T, cc *api.Client) {
 	require.Equal(t, "node_test", allocatedHost.Allocations[0].NodeName)
 	assert.Equal(t, expectedLabels, allocatedHost.Labels, "labels have not been updated after apply")
 }
+
+func testConsulManagerAllocatePlacements(t *testing.T, cc *api.Client) {
+	cleanupHostsPool(t, cc)
+	cm := &consulManager{cc, mockSSHClientFactory}
+	hostpool := createHosts(2)
+	var checkpoint uint64
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
+	require.NoError(t, err, "Unexpected failure applying host pool configuration")
+
+	antiColocate := []Placement{{Affinity: policies.AffinityAntiColocate, NodeNames: []string{"node_anti"}}}
+	allocatedName, _, err := cm.Allocate(testPoolName, &Allocation{NodeName: "node_anti", Instance: "0", DeploymentID: "test", Shareable: true, Placements: antiColocate})
+	require.NoError(t, err)
+	require.Equal(t, "host0", allocatedName)
+	allocatedName, _, err = cm.Allocate(testPoolName, &Allocation{NodeName: "node_anti", Instance: "1", DeploymentID: "test", Shareable: true, Placements: antiColocate})
+	require.NoError(t, err)
+	require.Equal(t, "host1", allocatedName, "anti-colocate placement should prevent allocating the same host")
+	_, _, err = cm.Allocate(testPoolName, &Allocation{NodeName: "node_anti", Instance: "2", DeploymentID: "test", Shareable: true, Placements: antiColocate})
+	require.Error(t, err, "anti-colocate placement should prevent allocating an already used host")
+
+	// Allocations of other deployments are not considered
+	allocatedName, _, err = cm.Allocate(testPoolName, &Allocation{NodeName: "node_anti", Instance: "0", DeploymentID: "other", Shareable: true, Placements: antiColocate})
+	require.NoError(t, err)
+	require.Equal(t, "host0", allocatedName)
+
+	filter, err := labelsutil.CreateFilter(fmt.Sprintf("label2=%s", hostpool[1].Labels["label2"]))
+	require.NoError(t, err, "Unexpected error creating a filter")
+	allocatedName, _, err = cm.Allocate(testPoolName, &Allocation{NodeName: "node_co1", Instance: "0", DeploymentID: "test2", Shareable: true}, filter)
+	require.NoError(t, err)
+	require.Equal(t, "host1", allocatedName)
+	colocate := []Placement{{Affinity: policies.AffinityColocate, NodeNames: []string{"node_co1"}}}
+	allocatedName, _, err = cm.Allocate(testPoolName, &Allocation{NodeName: "node_co2", Instance: "0", DeploymentID: "test2", Shareable: true, Placements: colocate})
+	require.NoError(t, err)
+	require.Equal(t, "host1", allocatedName, "colocate placement should prefer hosts already allocated to targets")
+}
+
+func testConsulManagerMultiplePools(t *testing.T, cc *api.Client) {
+	cleanupHostsPool(t, cc)
+	cm := &consulManager{cc, mockSSHClientFactory}
+
+	pools, err := cm.ListPools()
+	require.NoError(t, err)
+	assert.Len(t, pools, 0)
+
+	// A same hostname may be used in several pools
+	err = cm.Add("site1", "host1", Connection{PrivateKey: dummySSHkey}, map[string]string{"site": "1"})
+	require.NoError(t, err)
+	err = cm.Apply("site2", []Host{
+		{Name: "host1", Connection: Connection{PrivateKey: dummySSHkey}, Labels: map[string]string{"site": "2"}},
+		{Name: "host2", Connection: Connection{PrivateKey: dummySSHkey}, Labels: map[string]string{"site": "2"}},
+	}, nil)
+	require.NoError(t, err)
+
+	pools, err = cm.ListPools()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"site1", "site2"}, pools)
+
+	hosts, _, _, err := cm.List("site1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"host1"}, hosts)
+	hosts, _, _, err = cm.List("site2")
+	require.NoError(t, err)
+	assert.Len(t, hosts, 2)
+
+	host, err := cm.GetHost("site2", "host1")
+	require.NoError(t, err)
+	assert.Equal(t, "2", host.Labels["site"])
+
+	// Allocations are made in the requested pool only
+	alloc := &Allocation{NodeName: "Compute", Instance: "0", DeploymentID: "dep"}
+	filter, err := labelsutil.CreateFilter("site=2")
+	require.NoError(t, err)
+	_, _, err = cm.Allocate("site1", alloc, filter)
+	require.Error(t, err)
+	assert.True(t, IsNoMatchingHostFoundError(err))
+	hostname, _, err := cm.Allocate("site2", alloc, filter)
+	require.NoError(t, err)
+	host, err = cm.GetHost("site2", hostname)
+	require.NoError(t, err)
+	assert.Equal(t, HostStatusAllocated, host.Status)
+	host, err = cm.GetHost("site1", "host1")
+	require.NoError(t, err)
+	assert.Equal(t, HostStatusFree, host.Status)
+
+	err = cm.Release("site2", hostname, alloc)
+	require.NoError(t, err)
+	err = cm.Remove("site1", "host1")
+	require.NoError(t, err)
+	_, err = cm.GetHost("site1", "host1")
+	assert.True(t, IsHostNotFoundError(err))
+	_, err = cm.GetHost("site2", "host1")
+	require.NoError(t, err)
+
+	// Invalid pool names
+	for _, poolName := range []string{"", "a/b"} {
+		_, _, _, err = cm.List(poolName)
+		assert.True(t, IsBadRequestError(err), "pool name %q", poolName)
+		err = cm.Add(poolName, "host1", Connection{PrivateKey: dummySSHkey}, nil)
+		assert.True(t, IsBadRequestError(err), "pool name %q", poolName)
+	}
+}
+
+func testConsulManagerAllocateStrategies(t *testing.T, cc *api.Client) {
+	cleanupHostsPool(t, cc)
+	cm := &consulManager{cc, mockSSHClientFactory}
+	hostpool := createHosts(3)
+	hostpool[0].Labels["host.num_cpus"] = "32"
+	hostpool[1].Labels["host.num_cpus"] = "4"
+	hostpool[2].Labels["host.num_cpus"] = "16"
+	var checkpoint uint64
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
+	require.NoError(t, err, "Unexpected failure applying host pool configuration")
+
+	resources := map[string]string{"host.num_cpus": "2"}
+	bestFit, err := NewAllocationStrategy(BestFitStrategy, nil)
+	require.NoError(t, err)
+	allocation := &Allocation{NodeName: "node_best", Instance: "0", DeploymentID: "test", Shareable: true, Resources: resources, Strategy: bestFit}
+	allocatedName, _, err := cm.Allocate(testPoolName, allocation)
+	require.NoError(t, err)
+	require.Equal(t, "host1", allocatedName, "best-fit strategy should select the smallest host")
+	require.Equal(t, -0.5, allocation.Score)
+
+	spread, err := NewAllocationStrategy(SpreadStrategy, nil)
+	require.NoError(t, err)
+	allocatedName, _, err = cm.Allocate(testPoolName, &Allocation{NodeName: "node_spread", Instance: "0", DeploymentID: "test", Shareable: true, Strategy: spread})
+	require.NoError(t, err)
+	require.Equal(t, "host0", allocatedName, "spread strategy should select the first host without allocations")
+	allocatedName, _, err = cm.Allocate(testPoolName, &Allocation{NodeName: "node_spread", Instance: "1", DeploymentID: "test", Shareable: true, Strategy: spread})
+	require.NoError(t, err)
+	require.Equal(t, "host2", allocatedName, "spread strategy should select the least allocated host")
+
+	weighted, err := NewAllocationStrategy(LabelsWeightedStrategy, map[string]interface{}{"labels_weights": map[string]interface{}{"host.num_cpus": 1}})
+	require.NoError(t, err)
+	allocation = &Allocation{NodeName: "node_weighted", Instance: "0", DeploymentID: "test", Shareable: true, Strategy: weighted}
+	allocatedName, _, err = cm.Allocate(testPoolName, allocation)
+	require.NoError(t, err)
+	require.Equal(t, "host0", allocatedName, "labels weighted strategy should select the host with the most cpus")
+	require.Equal(t, float64(32), allocation.Score)
+}
+
+func testConsulManagerAllocateOrWait(t *testing.T, cc *api.Client) {
+	cleanupHostsPool(t, cc)
+	cm := &consulManager{cc, mockSSHClientFactory}
+	hostpool := createHosts(1)
+	var checkpoint uint64
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
+	require.NoError(t, err, "Unexpected failure applying host pool configuration")
+
+	first := &Allocation{NodeName: "node_first", Instance: "0", DeploymentID: "test"}
+	allocatedName, _, err := cm.AllocateOrWait(context.Background(), testPoolName, first, time.Minute, nil)
+	require.NoError(t, err)
+	require.Equal(t, "host0", allocatedName)
+
+	// Without waiting allocation fails immediately
+	_, _, err = cm.AllocateOrWait(context.Background(), testPoolName, &Allocation{NodeName: "node_nowait", Instance: "0", DeploymentID: "test"}, 0, nil)
+	require.True(t, IsNoMatchingHostFoundError(err), "unexpected error %v", err)
+
+	// Waiting until timeout
+	_, _, err = cm.AllocateOrWait(context.Background(), testPoolName, &Allocation{NodeName: "node_timeout", Instance: "0", DeploymentID: "test"}, time.Second, nil)
+	require.True(t, IsNoMatchingHostFoundError(err), "unexpected error %v", err)
+	pending, err := cm.ListPendingAllocations(testPoolName)
+	require.NoError(t, err)
+	require.Len(t, pending, 0, "expired request should be removed from queue")
+
+	// Cancelling a pending request
+	ctx, cancel := context.WithCancel(context.Background())
+	positions := make(chan int, 10)
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := cm.AllocateOrWait(ctx, testPoolName, &Allocation{NodeName: "node_cancel", Instance: "0", DeploymentID: "test"}, time.Minute, func(position int) { positions <- position })
+		errCh <- err
+	}()
+	require.Equal(t, 1, <-positions)
+	cancel()
+	require.Error(t, <-errCh)
+	pending, err = cm.ListPendingAllocations(testPoolName)
+	require.NoError(t, err)
+	require.Len(t, pending, 0, "cancelled request should be removed from queue")
+
+	// Served when a host is released
+	hostnames := make(chan string, 1)
+	go func() {
+		hostname, _, err := cm.AllocateOrWait(context.Background(), testPoolName, &Allocation{NodeName: "node_wait", Instance: "0", DeploymentID: "test"}, time.Minute, func(position int) { positions <- position })
+		errCh <- err
+		hostnames <- hostname
+	}()
+	require.Equal(t, 1, <-positions)
+	pending, err = cm.ListPendingAllocations(testPoolName)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, "node_wait", pending[0].NodeName)
+	err = cm.Release(testPoolName, "host0", first)
+	require.NoError(t, err)
+	require.NoError(t, <-errCh)
+	require.Equal(t, "host0", <-hostnames)
+	pending, err = cm.ListPendingAllocations(testPoolName)
+	require.NoError(t, err)
+	require.Len(t, pending, 0)
+
+	// Non-waiting allocations are not blocked by pending ones
+	err = cm.Release(testPoolName, "host0", &Allocation{NodeName: "node_wait", Instance: "0", DeploymentID: "test"})
+	require.NoError(t, err)
+	sessionID, sessionCleanupFn, err := cm.createPendingAllocationSession(testPoolName)
+	require.NoError(t, err)
+	defer sessionCleanupFn()
+	now := time.Now()
+	err = cm.addPendingAllocation(testPoolName, sessionID, PendingAllocation{ID: "pending", CreationDate: now, ExpirationDate: now.Add(time.Minute)})
+	require.NoError(t, err)
+	third := &Allocation{NodeName: "node_third", Instance: "0", DeploymentID: "test"}
+	allocatedName, _, err = cm.Allocate(testPoolName, third)
+	require.NoError(t, err)
+	require.Equal(t, "host0", allocatedName)
+	err = cm.Release(testPoolName, "host0", third)
+	require.NoError(t, err)
+
+	// Waiting allocations do not bypass pending ones that could be served
+	_, _, err = cm.AllocateOrWait(context.Background(), testPoolName, &Allocation{NodeName: "node_bypass", Instance: "0", DeploymentID: "test"}, time.Second, nil)
+	require.True(t, IsNoMatchingHostFoundError(err), "unexpected error %v", err)
+
+	// But they do bypass pending ones waiting for other hosts
+	err = cm.removePendingAllocation(testPoolName, "pending")
+	require.NoError(t, err)
+	err = cm.addPendingAllocation(testPoolName, sessionID, PendingAllocation{ID: "pending_other", Filters: []string{"label1=other"}, CreationDate: now, ExpirationDate: now.Add(time.Minute)})
+	require.NoError(t, err)
+	fourth := &Allocation{NodeName: "node_fourth", Instance: "0", DeploymentID: "test"}
+	allocatedName, _, err = cm.AllocateOrWait(context.Background(), testPoolName, fourth, time.Second, nil)
+	require.NoError(t, err)
+	require.Equal(t, "host0", allocatedName)
+	err = cm.Release(testPoolName, "host0", fourth)
+	require.NoError(t, err)
+
+	// Pending allocations are removed with their session
+	_, err = cc.Session().Destroy(sessionID, nil)
+	require.NoError(t, err)
+	pending, err = cm.ListPendingAllocations(testPoolName)
+	require.NoError(t, err)
+	require.Len(t, pending, 0, "pending allocation should be removed with its session")
+
+	// Unreachable hosts do not abort the wait
+	hostpool[0].Connection.User = "fail"
+	err = cm.UpdateConnection(testPoolName, "host0", hostpool[0].Connection)
+	require.True(t, IsHostConnectionError(err), "unexpected error %v", err)
+	_, _, err = cm.AllocateOrWait(context.Background(), testPoolName, &Allocation{NodeName: "node_unreachable", Instance: "0", DeploymentID: "test"}, time.Second, nil)
+	require.True(t, IsNoMatchingHostFoundError(err), "unexpected error %v", err)
+	_, _, err = cm.Allocate(testPoolName, &Allocation{NodeName: "node_unreachable", Instance: "0", DeploymentID: "test"})
+	require.True(t, IsHostConnectionError(err), "unexpected error %v", err)
+}
+
+func testConsulManagerListPendingAllocations(t *testing.T, cc *api.Client) {
+	cleanupHostsPool(t, cc)
+	cm := &consulManager{cc, mockSSHClientFactory}
+	sessionID, sessionCleanupFn, err := cm.createPendingAllocationSession(testPoolName)
+	require.NoError(t, err)
+	defer sessionCleanupFn()
+	now := time.Now()
+	for _, pa := range []PendingAllocation{
+		{ID: "low_old", Priority: 0, CreationDate: now.Add(-2 * time.Minute), ExpirationDate: now.Add(time.Hour)},
+		{ID: "low_new", Priority: 0, CreationDate: now.Add(-time.Minute), ExpirationDate: now.Add(time.Hour)},
+		{ID: "high", Priority: 10, CreationDate: now, ExpirationDate: now.Add(time.Hour)},
+		{ID: "expired", Priority: 20, CreationDate: now.Add(-2 * time.Hour), ExpirationDate: now.Add(-time.Hour)},
+	} {
+		require.NoError(t, cm.addPendingAllocation(testPoolName, sessionID, pa))
+	}
+	pending, err := cm.ListPendingAllocations(testPoolName)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+	for i, id := range []string{"high", "low_old", "low_new"} {
+		assert.Equal(t, id, pending[i].ID)
+		assert.Equal(t, i+1, pending[i].Position)
+	}
+	kvp, _, err := cc.KV().Get(path.Join(getQueueKey(testPoolName), "expired"), nil)
+	require.NoError(t, err)
+	require.Nil(t, kvp, "expired pending allocation should be removed")
+	for _, pa := range pending {
+		require.NoError(t, cm.removePendingAllocation(testPoolName, pa.ID))
+	}
+}
+
+func testConsulManagerUpdateHostStatus(t *testing.T, cc *api.Client) {
+	cleanupHostsPool(t, cc)
+	cm := &consulManager{cc, mockSSHClientFactory}
+	hostpool := createHosts(2)
+	var checkpoint uint64
+	err := cm.Apply(testPoolName, hostpool, &checkpoint)
+	require.NoError(t, err, "Unexpected failure applying host pool configuration")
+
+	err = cm.UpdateHostStatus(testPoolName, "host0", HostStatusAllocated, "")
+	require.True(t, IsBadRequestError(err), "only free, maintenance and draining statuses could be set, got %v", err)
+
+	// Host in maintenance is not allocated
+	err = cm.UpdateHostStatus(testPoolName, "host0", HostStatusMaintenance, "disk replacement")
+	require.NoError(t, err)
+	host, err := cm.GetHost(testPoolName, "host0")
+	require.NoError(t, err)
+	require.Equal(t, HostStatusMaintenance, host.Status)
+	require.Equal(t, "disk replacement", host.Message)
+
+	allocation := &Allocation{NodeName: "node1", Instance: "0", DeploymentID: "test"}
+	allocatedName, _, err := cm.Allocate(testPoolName, allocation)
+	require.NoError(t, err)
+	require.Equal(t, "host1", allocatedName, "host in maintenance should not be allocated")
+
+	// Allocated host should be drained before maintenance
+	err = cm.UpdateHostStatus(testPoolName, "host1", HostStatusMaintenance, "")
+	require.True(t, IsBadRequestError(err), "unexpected error %v", err)
+	err = cm.UpdateHostStatus(testPoolName, "host1", HostStatusDraining, "kernel upgrade")
+	require.NoError(t, err)
+	host, err = cm.GetHost(testPoolName, "host1")
+	require.NoError(t, err)
+	require.Equal(t, HostStatusDraining, host.Status)
+	require.Len(t, host.Allocations, 1, "draining host should keep its allocations")
+
+	_, _, err = cm.Allocate(testPoolName, &Allocation{NodeName: "node2", Instance: "0", DeploymentID: "test", Shareable: true})
+	require.True(t, IsNoMatchingHostFoundError(err), "draining and maintenance hosts should not be allocated, got %v", err)
+
+	// Releasing last allocation of a draining host puts it in maintenance
+	err = cm.Release(testPoolName, "host1", allocation)
+	require.NoError(t, err)
+	host, err = cm.GetHost(testPoolName, "host1")
+	require.NoError(t, err)
+	require.Equal(t, HostStatusMaintenance, host.Status)
+	require.Equal(t, "kernel upgrade", host.Message)
+
+	// Re-enabling hosts
+	err = cm.UpdateHostStatus(testPoolName, "host0", HostStatusFree, "")
+	require.NoError(t, err)
+	host, err = cm.GetHost(testPoolName, "host0")
+	require.NoError(t, err)
+	require.Equal(t, HostStatusFree, host.Status)
+	require.Equal(t, "", host.Message)
+	allocatedName, _, err = cm.Allocate(testPoolName, &Allocation{NodeName: "node3", Instance: "0", DeploymentID: "test"})
+	require.NoError(t, err)
+	require.Equal(t, "host0", allocatedName)
+
+	// Re-enabling an unreachable host fails and puts it in error
+	hostpool[1].Connection.User = "fail"
+	err = cm.UpdateConnection(testPoolName, "host1", hostpool[1].Connection)
+	require.NoError(t, err)
+	err = cm.UpdateHostStatus(testPoolName, "host1", HostStatusFree, "")
+	require.True(t, IsHostConnectionError(err), "unexpected error %v", err)
+	host, err = cm.GetHost(testPoolName, "host1")
+	require.NoError(t, err)
+	require.Equal(t, HostStatusError, host.Status)
+
+	// Host in error could be removed
+	err = cm.Remove(testPoolName, "host1")
+	require.NoError(t, err)
+}
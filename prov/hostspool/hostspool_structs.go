@@ -24,13 +24,17 @@ import (
 
 	"fmt"
 	"github.com/pkg/errors"
+	"github.com/ystia/yorc/helper/sshutil"
 	"net/url"
+	"time"
 )
 
 // HostStatus x ENUM(
 // free,
 // allocated,
-// error
+// error,
+// maintenance,
+// draining
 // )
 type HostStatus int
 
@@ -68,6 +72,8 @@ type Connection struct {
 	Host string `json:"host,omitempty" yaml:"host,omitempty"`
 	// The Port to connect to. Defaults to 22 if set to 0.
 	Port uint64 `json:"port,omitempty" yaml:"port,omitempty"`
+	// The Bastion is an optional jump host used to reach the host. It could itself be reached through another bastion.
+	Bastion *Connection `json:"bastion,omitempty" yaml:"bastion,omitempty"`
 }
 
 // String allows to stringify a connection
@@ -80,7 +86,39 @@ func (conn Connection) String() string {
 		key = "private key: " + conn.PrivateKey + ", "
 	}
 
-	return "user: " + conn.User + ", " + pass + key + "host: " + conn.Host + ", " + "port: " + strconv.FormatUint(conn.Port, 10)
+	connStr := "user: " + conn.User + ", " + pass + key + "host: " + conn.Host + ", " + "port: " + strconv.FormatUint(conn.Port, 10)
+	if conn.Bastion != nil {
+		connStr += ", bastion: " + conn.Bastion.Host
+	}
+	return connStr
+}
+
+// checkBastion checks that a bastion connection, if any, and the bastions it relies on are valid
+func (conn Connection) checkBastion() error {
+	for b := conn.Bastion; b != nil; b = b.Bastion {
+		if b.Host == "" {
+			return errors.WithStack(badRequestError{`"host" is required for a bastion connection`})
+		}
+		if b.Password == "" && b.PrivateKey == "" {
+			return errors.WithStack(badRequestError{fmt.Sprintf(`at least "password" or "private_key" is required for bastion %q connection`, b.Host)})
+		}
+	}
+	return nil
+}
+
+// getBastionHost converts a bastion connection into a sshutil.BastionHost
+func getBastionHost(bastion *Connection) *sshutil.BastionHost {
+	if bastion == nil {
+		return nil
+	}
+	return &sshutil.BastionHost{
+		Host:       bastion.Host,
+		Port:       int(bastion.Port),
+		User:       bastion.User,
+		Password:   bastion.Password,
+		PrivateKey: bastion.PrivateKey,
+		Bastion:    getBastionHost(bastion.Bastion),
+	}
 }
 
 // An Host holds information on an Host as it is known by the hostspool
@@ -101,6 +139,40 @@ type Allocation struct {
 	DeploymentID string            `json:"deployment_id"`
 	Shareable    bool              `json:"shareable"`
 	Resources    map[string]string `json:"resource_labels,omitempty"`
+	// Placements constraints are only considered at allocation time and are not stored
+	Placements []Placement `json:"-"`
+	// Strategy used to select a host, it is only considered at allocation time and is not stored.
+	// DefaultAllocationStrategy is used if not set.
+	Strategy AllocationStrategy `json:"-"`
+	// Score of the selected host according to the allocation strategy, it is set at allocation time and is not stored
+	Score float64 `json:"-"`
+	// Priority of the allocation request when waiting for a host, higher priorities are served first
+	Priority int `json:"-"`
+}
+
+// A PendingAllocation is an allocation request waiting for a host of a pool to be available
+type PendingAllocation struct {
+	ID           string `json:"id"`
+	DeploymentID string `json:"deployment_id"`
+	NodeName     string `json:"node_name"`
+	Instance     string `json:"instance"`
+	Priority     int    `json:"priority"`
+	Shareable    bool   `json:"shareable"`
+	// Filters are the labels filters of the request, they are used to check if the request could be satisfied by available hosts
+	Filters        []string  `json:"filters,omitempty"`
+	CreationDate   time.Time `json:"creation_date"`
+	ExpirationDate time.Time `json:"expiration_date"`
+	// Position of the request in the queue starting at 1, it is computed when listing pending allocations
+	Position int `json:"position"`
+}
+
+// A Placement describes a placement constraint of an allocation relatively to the
+// allocations of other nodes of the same deployment
+type Placement struct {
+	// Affinity is either policies.AffinityColocate or policies.AffinityAntiColocate
+	Affinity string
+	// NodeNames are the names of the nodes whose allocations are considered, an empty list means all nodes
+	NodeNames []string
 }
 
 func (alloc *Allocation) String() string {
@@ -29,14 +29,20 @@ const (
 	HostStatusAllocated
 	// HostStatusError is a HostStatus of type Error
 	HostStatusError
+	// HostStatusMaintenance is a HostStatus of type Maintenance
+	HostStatusMaintenance
+	// HostStatusDraining is a HostStatus of type Draining
+	HostStatusDraining
 )
 
-const _HostStatusName = "freeallocatederror"
+const _HostStatusName = "freeallocatederrormaintenancedraining"
 
 var _HostStatusMap = map[HostStatus]string{
 	0: _HostStatusName[0:4],
 	1: _HostStatusName[4:13],
 	2: _HostStatusName[13:18],
+	3: _HostStatusName[18:29],
+	4: _HostStatusName[29:37],
 }
 
 // String implements the Stringer interface.
@@ -54,6 +60,10 @@ var _HostStatusValue = map[string]HostStatus{
 	strings.ToLower(_HostStatusName[4:13]):  1,
 	_HostStatusName[13:18]:                  2,
 	strings.ToLower(_HostStatusName[13:18]): 2,
+	_HostStatusName[18:29]:                  3,
+	strings.ToLower(_HostStatusName[18:29]): 3,
+	_HostStatusName[29:37]:                  4,
+	strings.ToLower(_HostStatusName[29:37]): 4,
 }
 
 // ParseHostStatus attempts to convert a string to a HostStatus
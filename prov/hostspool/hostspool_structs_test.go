@@ -34,6 +34,8 @@ func TestHostStatusJSONMarshalling(t *testing.T) {
 		{"TestUnknownHostStatus", args{HostStatus(-1)}, false, `"HostStatus(-1)"`},
 		{"TestHostStatusFree", args{HostStatusFree}, false, `"free"`},
 		{"TestHostStatusAllocated", args{HostStatusAllocated}, false, `"allocated"`},
+		{"TestHostStatusMaintenance", args{HostStatusMaintenance}, false, `"maintenance"`},
+		{"TestHostStatusDraining", args{HostStatusDraining}, false, `"draining"`},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -64,6 +66,8 @@ func TestHostStatusJSONUnmarshalling(t *testing.T) {
 		{"TestUnmarshalHostStatusFree", args{`"free"`}, false, HostStatusFree},
 		{"TestUnmarshalHostStatusAlloc", args{`"allocated"`}, false, HostStatusAllocated},
 		{"TestUnmarshalHostStatusAllocNoCase", args{`"alLoCatEd"`}, false, HostStatusAllocated},
+		{"TestUnmarshalHostStatusMaintenance", args{`"maintenance"`}, false, HostStatusMaintenance},
+		{"TestUnmarshalHostStatusDraining", args{`"Draining"`}, false, HostStatusDraining},
 		{"TestUnmarshalHostStatusNotString", args{`10`}, true, HostStatus(0)},
 		{"TestUnmarshalInvalidHostStatus", args{`"HostStatusFree"`}, true, HostStatus(0)},
 	}
@@ -80,3 +84,45 @@ func TestHostStatusJSONUnmarshalling(t *testing.T) {
 		})
 	}
 }
+
+func TestConnectionCheckBastion(t *testing.T) {
+	tests := []struct {
+		name    string
+		conn    Connection
+		wantErr bool
+	}{
+		{"NoBastion", Connection{Host: "h1", Password: "pass"}, false},
+		{"BastionWithKey", Connection{Host: "h1", Bastion: &Connection{Host: "b1", PrivateKey: "~/.ssh/id_rsa"}}, false},
+		{"BastionWithoutHost", Connection{Host: "h1", Bastion: &Connection{Password: "pass"}}, true},
+		{"BastionWithoutCredentials", Connection{Host: "h1", Bastion: &Connection{Host: "b1"}}, true},
+		{"ChainedBastionWithoutCredentials", Connection{Host: "h1", Bastion: &Connection{Host: "b1", Password: "pass", Bastion: &Connection{Host: "b2"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.conn.checkBastion()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Connection.checkBastion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !IsBadRequestError(err) {
+				t.Errorf("Connection.checkBastion() error = %v, expecting a bad request error", err)
+			}
+		})
+	}
+}
+
+func TestGetBastionHost(t *testing.T) {
+	if b := getBastionHost(nil); b != nil {
+		t.Fatalf("getBastionHost(nil) = %v, expected nil", b)
+	}
+	bastion := &Connection{Host: "b2", User: "centos", Port: 2222, PrivateKey: "key", Bastion: &Connection{Host: "b1", Password: "pass"}}
+	b := getBastionHost(bastion)
+	if b == nil || b.Host != "b2" || b.User != "centos" || b.Port != 2222 || b.PrivateKey != "key" {
+		t.Fatalf("getBastionHost() = %+v, unexpected bastion host", b)
+	}
+	if b.Bastion == nil || b.Bastion.Host != "b1" || b.Bastion.Password != "pass" || b.Bastion.Bastion != nil {
+		t.Errorf("getBastionHost() chained bastion = %+v, unexpected bastion host", b.Bastion)
+	}
+	if b.String() != "root@b1:22,centos@b2:2222" {
+		t.Errorf("getBastionHost().String() = %q, unexpected value", b.String())
+	}
+}
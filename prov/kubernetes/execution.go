@@ -44,6 +44,10 @@ import (
 
 const deploymentResourceType string = "yorc.nodes.kubernetes.api.types.DeploymentResource"
 const serviceResourceType string = "yorc.nodes.kubernetes.api.types.ServiceResource"
+const statefulSetResourceType string = "yorc.nodes.kubernetes.api.types.StatefulSetResource"
+const configMapResourceType string = "yorc.nodes.kubernetes.api.types.ConfigMapResource"
+const secretResourceType string = "yorc.nodes.kubernetes.api.types.SecretResource"
+const persistentVolumeClaimResourceType string = "yorc.nodes.kubernetes.api.types.PersistentVolumeClaimResource"
 
 type k8sResourceOperation int
 
@@ -164,6 +168,14 @@ func (e *execution) manageKubernetesResource(ctx context.Context, clientset kube
 		return e.manageDeploymentResource(ctx, clientset, generator, op, rSpec.RawString())
 	case serviceResourceType:
 		return e.manageServiceResource(ctx, clientset, generator, op, rSpec.RawString())
+	case statefulSetResourceType:
+		return e.manageStatefulSetResource(ctx, clientset, op, rSpec.RawString())
+	case configMapResourceType:
+		return e.manageConfigMapResource(ctx, clientset, op, rSpec.RawString())
+	case secretResourceType:
+		return e.manageSecretResource(ctx, clientset, op, rSpec.RawString())
+	case persistentVolumeClaimResourceType:
+		return e.managePersistentVolumeClaimResource(ctx, clientset, op, rSpec.RawString())
 	default:
 		return errors.Errorf("Unsupported k8s resource type %q", e.nodeType)
 	}
@@ -283,13 +295,13 @@ func (e *execution) manageDeploymentResource(ctx context.Context, clientset kube
 		if !namespaceProvided {
 			// Check if other deployments exist in the namespace
 			// In that case nothing to do
-			nbDeployments, err := deploymentsInNamespace(clientset, namespaceName)
+			nbDeployments, err := workloadsInNamespace(clientset, namespaceName)
 			if err != nil {
 				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("Cannot delete %s k8s Namespace", namespaceName)
 				return err
 			}
 			if nbDeployments > 0 {
-				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("Do not delete %s namespace as %d deployments exist", namespaceName, nbDeployments)
+				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("Do not delete %s namespace as %d deployments or statefulsets exist", namespaceName, nbDeployments)
 			} else {
 				err = deleteNamespace(namespaceName, clientset)
 				if err != nil {
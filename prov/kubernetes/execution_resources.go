@@ -0,0 +1,119 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ystia/yorc/events"
+)
+
+// simpleResource describes how to create and delete a k8s resource having no status to wait for
+type simpleResource struct {
+	kind       string
+	objectMeta *metav1.ObjectMeta
+	create     func(namespace string) (metav1.Object, error)
+	delete     func(namespace, name string) error
+}
+
+func (e *execution) manageConfigMapResource(ctx context.Context, clientset kubernetes.Interface, operationType k8sResourceOperation, rSpec string) error {
+	var configMapRepr apiv1.ConfigMap
+	return e.manageSimpleResource(ctx, clientset, operationType, rSpec, &configMapRepr, simpleResource{
+		kind:       "ConfigMap",
+		objectMeta: &configMapRepr.ObjectMeta,
+		create: func(namespace string) (metav1.Object, error) {
+			return clientset.CoreV1().ConfigMaps(namespace).Create(&configMapRepr)
+		},
+		delete: func(namespace, name string) error {
+			return clientset.CoreV1().ConfigMaps(namespace).Delete(name, nil)
+		},
+	})
+}
+
+func (e *execution) manageSecretResource(ctx context.Context, clientset kubernetes.Interface, operationType k8sResourceOperation, rSpec string) error {
+	var secretRepr apiv1.Secret
+	return e.manageSimpleResource(ctx, clientset, operationType, rSpec, &secretRepr, simpleResource{
+		kind:       "Secret",
+		objectMeta: &secretRepr.ObjectMeta,
+		create: func(namespace string) (metav1.Object, error) {
+			return clientset.CoreV1().Secrets(namespace).Create(&secretRepr)
+		},
+		delete: func(namespace, name string) error {
+			return clientset.CoreV1().Secrets(namespace).Delete(name, nil)
+		},
+	})
+}
+
+func (e *execution) managePersistentVolumeClaimResource(ctx context.Context, clientset kubernetes.Interface, operationType k8sResourceOperation, rSpec string) error {
+	var pvcRepr apiv1.PersistentVolumeClaim
+	return e.manageSimpleResource(ctx, clientset, operationType, rSpec, &pvcRepr, simpleResource{
+		kind:       "PersistentVolumeClaim",
+		objectMeta: &pvcRepr.ObjectMeta,
+		create: func(namespace string) (metav1.Object, error) {
+			// Claims may stay pending until a pod uses them (depending on the storage class binding mode) so do not wait for them to be bound
+			return clientset.CoreV1().PersistentVolumeClaims(namespace).Create(&pvcRepr)
+		},
+		delete: func(namespace, name string) error {
+			return clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(name, nil)
+		},
+	})
+}
+
+func (e *execution) manageSimpleResource(ctx context.Context, clientset kubernetes.Interface, operationType k8sResourceOperation, rSpec string, resourceRepr interface{}, resource simpleResource) error {
+	if rSpec == "" {
+		return errors.Errorf("Missing mandatory resource_spec property for node %s", e.nodeName)
+	}
+
+	// Unmarshal JSON to k8s data structs
+	if err := json.Unmarshal([]byte(rSpec), resourceRepr); err != nil {
+		return errors.Errorf("The resource-spec JSON unmarshaling failed: %s", err)
+	}
+
+	// Get the namespace if provided. Otherwise, the namespace is generated using the default yorc policy
+	namespace, namespaceProvided := getNamespace(e.deploymentID, *resource.objectMeta)
+
+	switch operationType {
+	case k8sCreateOperation:
+		if !namespaceProvided {
+			err := createNamespaceIfMissing(e.deploymentID, namespace, clientset)
+			if err != nil {
+				return err
+			}
+		}
+		obj, err := resource.create(namespace)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to create %s", resource.kind)
+		}
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, e.deploymentID).Registerf("k8s %s %s created in namespace %s", resource.kind, obj.GetName(), namespace)
+	case k8sDeleteOperation:
+		name := resource.objectMeta.Name
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, e.deploymentID).Registerf("Delete k8s %s %s", resource.kind, name)
+		err := resource.delete(namespace, name)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "Failed to delete %s", resource.kind)
+		}
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("k8s %s %s deleted", resource.kind, name)
+	default:
+		return errors.Errorf("Unsupported operation on k8s resource")
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/tasks"
+)
+
+const defaultStatefulSetTimeout = 5 * time.Minute
+
+// statefulSetTimeout returns how long to wait for a StatefulSet to be ready or deleted
+func (e *execution) statefulSetTimeout() time.Duration {
+	timeout := e.cfg.Infrastructures["kubernetes"].GetDuration("statefulset_timeout")
+	if timeout <= 0 {
+		return defaultStatefulSetTimeout
+	}
+	return timeout
+}
+
+func (e *execution) manageStatefulSetResource(ctx context.Context, clientset kubernetes.Interface, operationType k8sResourceOperation, rSpec string) (err error) {
+	if rSpec == "" {
+		return errors.Errorf("Missing mandatory resource_spec property for node %s", e.nodeName)
+	}
+
+	// Unmarshal JSON to k8s data structs
+	var statefulSetRepr appsv1beta1.StatefulSet
+	if err = json.Unmarshal([]byte(rSpec), &statefulSetRepr); err != nil {
+		return errors.Errorf("The resource-spec JSON unmarshaling failed: %s", err)
+	}
+
+	// Get the namespace if provided. Otherwise, the namespace is generated using the default yorc policy
+	namespaceName, namespaceProvided := getNamespace(e.deploymentID, statefulSetRepr.ObjectMeta)
+
+	switch operationType {
+	case k8sCreateOperation:
+		if !namespaceProvided {
+			err = createNamespaceIfMissing(e.deploymentID, namespaceName, clientset)
+			if err != nil {
+				return err
+			}
+			events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("k8s Namespace %s created", namespaceName)
+		}
+		// Update resource_spec with actual reference to used services, if necessary
+		rSpec, err = e.replaceServiceIPInDeploymentSpec(ctx, clientset, namespaceName, rSpec)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal([]byte(rSpec), &statefulSetRepr); err != nil {
+			return errors.Errorf("The resource-spec JSON unmarshaling failed: %s", err)
+		}
+		// Create StatefulSet k8s resource
+		statefulSet, err := clientset.AppsV1beta1().StatefulSets(namespaceName).Create(&statefulSetRepr)
+		if err != nil {
+			return err
+		}
+
+		streamDeploymentLogs(ctx, e.deploymentID, clientset, statefulSet)
+
+		err = waitForStatefulSetCompletion(ctx, e.deploymentID, clientset, statefulSet, e.statefulSetTimeout())
+		if err != nil {
+			return err
+		}
+		err = deployments.SetAttributeForAllInstances(e.kv, e.deploymentID, e.nodeName, "replicas", fmt.Sprint(statefulSetReplicas(statefulSet)))
+		if err != nil {
+			return err
+		}
+		err = e.publishStatefulSetPodsIPs(ctx, clientset, statefulSet)
+		if err != nil {
+			return err
+		}
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, e.deploymentID).Registerf("k8s StatefulSet %s created in namespace %s", statefulSet.Name, namespaceName)
+
+	case k8sDeleteOperation:
+		// Delete StatefulSet k8s resource
+		statefulSetName := statefulSetRepr.Name
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, e.deploymentID).Registerf("Delete k8s StatefulSet %s", statefulSetName)
+
+		statefulSet, err := clientset.AppsV1beta1().StatefulSets(namespaceName).Get(statefulSetName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		streamDeploymentLogs(ctx, e.deploymentID, clientset, statefulSet)
+
+		deletePolicy := metav1.DeletePropagationForeground
+		var gracePeriod int64 = 5
+		if err = clientset.AppsV1beta1().StatefulSets(namespaceName).Delete(statefulSetName, &metav1.DeleteOptions{
+			GracePeriodSeconds: &gracePeriod, PropagationPolicy: &deletePolicy}); err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, e.statefulSetTimeout())
+		defer cancel()
+		err = waitForStatefulSetDeletion(ctx, clientset, statefulSet)
+		if err != nil {
+			return err
+		}
+
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("k8s StatefulSet %s deleted", statefulSetName)
+
+		// Delete namespace if it was not provided and no other workload remains in it
+		if !namespaceProvided {
+			nbWorkloads, err := workloadsInNamespace(clientset, namespaceName)
+			if err != nil {
+				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("Cannot delete %s k8s Namespace", namespaceName)
+				return err
+			}
+			if nbWorkloads > 0 {
+				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("Do not delete %s namespace as %d deployments or statefulsets exist", namespaceName, nbWorkloads)
+			} else {
+				err = deleteNamespace(namespaceName, clientset)
+				if err != nil {
+					events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("Cannot delete %s k8s Namespace", namespaceName)
+					return err
+				}
+				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("k8s Namespace %s deleted", namespaceName)
+			}
+		}
+	case k8sScaleOperation:
+		expectedInstances, err := tasks.GetTaskInput(e.kv, e.taskID, "EXPECTED_INSTANCES")
+		if err != nil {
+			return err
+		}
+		r, err := strconv.ParseInt(expectedInstances, 10, 32)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse EXPECTED_INSTANCES: %q parameter as integer", expectedInstances)
+		}
+		replicas := int32(r)
+
+		// Update the current StatefulSet rather than the resource spec to keep the resource version
+		statefulSet, err := clientset.AppsV1beta1().StatefulSets(namespaceName).Get(statefulSetRepr.Name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to retrieve kubernetes statefulset for scaling")
+		}
+		statefulSet.Spec.Replicas = &replicas
+		statefulSet, err = clientset.AppsV1beta1().StatefulSets(namespaceName).Update(statefulSet)
+		if err != nil {
+			return errors.Wrap(err, "failed to update kubernetes statefulset for scaling")
+		}
+		streamDeploymentLogs(ctx, e.deploymentID, clientset, statefulSet)
+
+		err = waitForStatefulSetCompletion(ctx, e.deploymentID, clientset, statefulSet, e.statefulSetTimeout())
+		if err != nil {
+			return err
+		}
+		err = deployments.SetAttributeForAllInstances(e.kv, e.deploymentID, e.nodeName, "replicas", expectedInstances)
+		if err != nil {
+			return err
+		}
+		err = e.publishStatefulSetPodsIPs(ctx, clientset, statefulSet)
+		if err != nil {
+			return err
+		}
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, e.deploymentID).Registerf("k8s StatefulSet %s scaled to %s instances in namespace %s", statefulSet.Name, expectedInstances, namespaceName)
+	default:
+		return errors.Errorf("Unsupported operation on k8s resource")
+	}
+
+	return nil
+}
+
+// publishStatefulSetPodsIPs sets the pod_name and pod_ip attributes of each node instance
+// and the list of all pods IPs as the pod_ips attribute of all instances.
+//
+// StatefulSet pods have a stable identity "<statefulset name>-<ordinal>", the ordinal is matched against the node instance ID.
+func (e *execution) publishStatefulSetPodsIPs(ctx context.Context, clientset kubernetes.Interface, statefulSet *appsv1beta1.StatefulSet) error {
+	selector, err := metav1.LabelSelectorAsSelector(statefulSet.Spec.Selector)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get pods selector of k8s StatefulSet %s", statefulSet.Name)
+	}
+	pods, err := clientset.CoreV1().Pods(statefulSet.Namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list pods of k8s StatefulSet %s", statefulSet.Name)
+	}
+	podsIPs := make(map[string]string, len(pods.Items))
+	for _, pod := range pods.Items {
+		podsIPs[pod.Name] = pod.Status.PodIP
+	}
+
+	instances, err := deployments.GetNodeInstancesIds(e.kv, e.deploymentID, e.nodeName)
+	if err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		podName := statefulSetPodName(statefulSet.Name, instance)
+		podIP, ok := podsIPs[podName]
+		if !ok {
+			events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, e.deploymentID).Registerf("No pod %s found for instance %s of k8s StatefulSet %s", podName, instance, statefulSet.Name)
+			continue
+		}
+		err = deployments.SetInstanceAttribute(e.deploymentID, e.nodeName, instance, "pod_name", podName)
+		if err != nil {
+			return err
+		}
+		err = deployments.SetInstanceAttribute(e.deploymentID, e.nodeName, instance, "pod_ip", podIP)
+		if err != nil {
+			return err
+		}
+	}
+
+	return deployments.SetAttributeComplexForAllInstances(e.kv, e.deploymentID, e.nodeName, "pod_ips", sortedPodsIPs(statefulSet.Name, podsIPs))
+}
+
+// statefulSetPodName returns the name of the pod of a StatefulSet matching a given node instance
+func statefulSetPodName(statefulSetName, instanceID string) string {
+	return statefulSetName + "-" + instanceID
+}
+
+// sortedPodsIPs returns the IPs of the StatefulSet pods ordered by pod ordinal
+func sortedPodsIPs(statefulSetName string, podsIPs map[string]string) []string {
+	podsNames := make([]string, 0, len(podsIPs))
+	for name := range podsIPs {
+		podsNames = append(podsNames, name)
+	}
+	ordinal := func(podName string) int {
+		o, err := strconv.Atoi(strings.TrimPrefix(podName, statefulSetName+"-"))
+		if err != nil {
+			return -1
+		}
+		return o
+	}
+	sort.Slice(podsNames, func(i, j int) bool {
+		return ordinal(podsNames[i]) < ordinal(podsNames[j])
+	})
+	ips := make([]string, len(podsNames))
+	for i, name := range podsNames {
+		ips[i] = podsIPs[name]
+	}
+	return ips
+}
+
+func statefulSetReplicas(statefulSet *appsv1beta1.StatefulSet) int32 {
+	if statefulSet.Spec.Replicas == nil {
+		// Kubernetes default
+		return 1
+	}
+	return *statefulSet.Spec.Replicas
+}
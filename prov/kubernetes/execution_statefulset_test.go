@@ -0,0 +1,57 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ystia/yorc/config"
+)
+
+func Test_sortedPodsIPs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		podsIPs map[string]string
+		want    []string
+	}{
+		{"Empty", map[string]string{}, []string{}},
+		{"Single", map[string]string{"web-0": "10.0.0.1"}, []string{"10.0.0.1"}},
+		{"OrderedByOrdinal", map[string]string{"web-10": "10.0.0.11", "web-2": "10.0.0.3", "web-0": "10.0.0.1", "web-1": "10.0.0.2"}, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.11"}},
+		{"WithGap", map[string]string{"web-3": "10.0.0.4", "web-0": "10.0.0.1"}, []string{"10.0.0.1", "10.0.0.4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sortedPodsIPs("web", tt.podsIPs))
+		})
+	}
+}
+
+func Test_statefulSetPodName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "web-0", statefulSetPodName("web", "0"))
+	assert.Equal(t, "my-db-12", statefulSetPodName("my-db", "12"))
+}
+
+func Test_statefulSetTimeout(t *testing.T) {
+	t.Parallel()
+	e := &execution{cfg: config.Configuration{}}
+	assert.Equal(t, defaultStatefulSetTimeout, e.statefulSetTimeout())
+	e.cfg.Infrastructures = map[string]config.DynamicMap{"kubernetes": {"statefulset_timeout": "20m"}}
+	assert.Equal(t, 20*time.Minute, e.statefulSetTimeout())
+}
@@ -174,6 +174,24 @@ func (k8s *k8sGenerator) generateVolume(deploymentID, volumeNodeName string) (v1
 			volumeSource.EmptyDir = &emptyDirVolumeSource
 			volume.VolumeSource = volumeSource
 			err = nil
+		case "persistentVolumeClaim":
+			volumeSource.PersistentVolumeClaim = &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: k8s.getVolumeStringProperty(deploymentID, volumeNodeName, "claim_name"),
+			}
+			volume.VolumeSource = volumeSource
+			err = nil
+		case "configMap":
+			volumeSource.ConfigMap = &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: k8s.getVolumeStringProperty(deploymentID, volumeNodeName, "config_map_name")},
+			}
+			volume.VolumeSource = volumeSource
+			err = nil
+		case "secret":
+			volumeSource.Secret = &v1.SecretVolumeSource{
+				SecretName: k8s.getVolumeStringProperty(deploymentID, volumeNodeName, "secret_name"),
+			}
+			volume.VolumeSource = volumeSource
+			err = nil
 		default:
 			err = errors.Errorf("Unsupported volume type %q", vtype)
 		}
@@ -181,6 +199,15 @@ func (k8s *k8sGenerator) generateVolume(deploymentID, volumeNodeName string) (v1
 	return volume, err
 }
 
+// Returns a volume node string property or an empty string if it is not set
+func (k8s *k8sGenerator) getVolumeStringProperty(deploymentID, volumeNodeName, propertyName string) string {
+	val, _ := deployments.GetNodePropertyValue(k8s.kv, deploymentID, volumeNodeName, propertyName)
+	if val == nil {
+		return ""
+	}
+	return val.RawString()
+}
+
 // Generate an emptyDir Kubernetes Volume
 func (k8s *k8sGenerator) generateEmptyDirVolumeSource(deploymentID, volumeNodeName string) v1.EmptyDirVolumeSource {
 	//TODO is this a good idea to ignore returned error?
@@ -22,6 +22,7 @@ import (
 
 	"github.com/hashicorp/consul/api"
 	"github.com/pkg/errors"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
@@ -80,9 +81,51 @@ func waitForDeploymentCompletion(ctx context.Context, deploymentID string, clien
 	}, ctx.Done())
 }
 
-func streamDeploymentLogs(ctx context.Context, deploymentID string, clientset kubernetes.Interface, deployment *v1beta1.Deployment) {
+func waitForStatefulSetDeletion(ctx context.Context, clientset kubernetes.Interface, statefulSet *appsv1beta1.StatefulSet) error {
+	return wait.PollUntil(2*time.Second, func() (bool, error) {
+		_, err := clientset.AppsV1beta1().StatefulSets(statefulSet.Namespace).Get(statefulSet.Name, metav1.GetOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		}
+		return false, nil
+	}, ctx.Done())
+}
+
+// waitForStatefulSetCompletion waits for all replicas of a StatefulSet to be ready
+//
+// StatefulSets do not report failure conditions, so this gives up after the given timeout.
+func waitForStatefulSetCompletion(ctx context.Context, deploymentID string, clientset kubernetes.Interface, statefulSet *appsv1beta1.StatefulSet, timeout time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var ready int32 = -1
+	err := wait.PollUntil(2*time.Second, func() (bool, error) {
+		statefulSet, err := clientset.AppsV1beta1().StatefulSets(statefulSet.Namespace).Get(statefulSet.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		var replicas int32 = 1
+		if statefulSet.Spec.Replicas != nil {
+			replicas = *statefulSet.Spec.Replicas
+		}
+		if ready != statefulSet.Status.ReadyReplicas {
+			ready = statefulSet.Status.ReadyReplicas
+			events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, deploymentID).Registerf("k8s StatefulSet %s: %d ready replicas of %d", statefulSet.Name, ready, replicas)
+		}
+		observed := statefulSet.Status.ObservedGeneration != nil && *statefulSet.Status.ObservedGeneration >= statefulSet.Generation
+		return observed && statefulSet.Status.ReadyReplicas == replicas, nil
+	}, waitCtx.Done())
+	if err == wait.ErrWaitTimeout && ctx.Err() == nil {
+		return errors.Errorf("k8s StatefulSet %s: replicas not ready after %s", statefulSet.Name, timeout)
+	}
+	return err
+}
+
+func streamDeploymentLogs(ctx context.Context, deploymentID string, clientset kubernetes.Interface, deployment metav1.Object) {
 	go func() {
-		watcher, err := clientset.CoreV1().Events(deployment.Namespace).Watch(metav1.ListOptions{})
+		watcher, err := clientset.CoreV1().Events(deployment.GetNamespace()).Watch(metav1.ListOptions{})
 		if err != nil {
 			events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, deploymentID).Registerf("Failed to monitor Kubernetes deployment events: %v", err)
 			return
@@ -107,7 +150,7 @@ func streamDeploymentLogs(ctx context.Context, deploymentID string, clientset ku
 					return
 				}
 				if event, ok := e.Object.(*corev1.Event); ok {
-					if ok, err := isChildOf(clientset, deployment.UID, referenceFromObjectReference(event.InvolvedObject)); err == nil && ok {
+					if ok, err := isChildOf(clientset, deployment.GetUID(), referenceFromObjectReference(event.InvolvedObject)); err == nil && ok {
 						switch e.Type {
 						case watch.Added, watch.Modified:
 							level := events.LogLevelDEBUG
@@ -144,6 +187,8 @@ func isChildOf(clientset kubernetes.Interface, parent types.UID, ref reference)
 		om, err = clientset.ExtensionsV1beta1().Deployments(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
 	case "job":
 		om, err = clientset.BatchV1().Jobs(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
+	case "statefulset":
+		om, err = clientset.AppsV1beta1().StatefulSets(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
 	default:
 		return false, nil
 	}
@@ -179,6 +224,24 @@ func deploymentsInNamespace(clientset kubernetes.Interface, namespace string) (i
 	return nbDeployments, nil
 }
 
+func statefulSetsInNamespace(clientset kubernetes.Interface, namespace string) (int, error) {
+	statefulSetsList, err := clientset.AppsV1beta1().StatefulSets(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return len(statefulSetsList.Items), nil
+}
+
+// workloadsInNamespace returns the number of Deployments and StatefulSets in a namespace
+func workloadsInNamespace(clientset kubernetes.Interface, namespace string) (int, error) {
+	nbDeployments, err := deploymentsInNamespace(clientset, namespace)
+	if err != nil {
+		return 0, err
+	}
+	nbStatefulSets, err := statefulSetsInNamespace(clientset, namespace)
+	return nbDeployments + nbStatefulSets, err
+}
+
 type reference struct {
 	Kind      string
 	UID       types.UID
@@ -15,11 +15,19 @@
 package monitoring
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
+	"net/http"
 	"path"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -27,10 +35,21 @@ import (
 	"github.com/ystia/yorc/deployments"
 	"github.com/ystia/yorc/events"
 	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/helper/stringutil"
 	"github.com/ystia/yorc/log"
 	"github.com/ystia/yorc/tosca"
 )
 
+// maxCheckedBodySize is the maximum size of an HTTP response body read to be matched against a regular expression
+const maxCheckedBodySize = 1024 * 1024
+
+// lastCheckDateStoreInterval is the minimum duration between two updates of the last check date stored in Consul
+// when the check status does not change
+const lastCheckDateStoreInterval = time.Minute
+
+// maxCommandOutputInError is the maximum size of a command output reported in a check error
+const maxCommandOutputInError = 256
+
 // NewCheck allows to instantiate a Check
 func NewCheck(deploymentID, nodeName, instance string) *Check {
 	return &Check{ID: buildID(deploymentID, nodeName, instance), Report: CheckReport{DeploymentID: deploymentID, NodeName: nodeName, Instance: instance}}
@@ -45,7 +64,7 @@ func NewCheckFromID(checkID string) (*Check, error) {
 	return &Check{ID: checkID, Report: CheckReport{DeploymentID: tab[0], NodeName: tab[1], Instance: tab[2]}}, nil
 }
 
-// Start allows to start running a TCP check
+// Start allows to start running a check
 func (c *Check) Start() {
 	c.stopLock.Lock()
 	defer c.stopLock.Unlock()
@@ -59,6 +78,21 @@ func (c *Check) Start() {
 
 	// timeout is defined arbitrary as half interval to avoid overlap
 	c.timeout = c.TimeInterval / 2
+	if c.Type == CheckTypeHttp {
+		c.httpClient = &http.Client{
+			Timeout: c.timeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: c.TLSSkipVerify},
+			},
+		}
+		if c.BodyRegex != "" {
+			var err error
+			c.bodyRegexp, err = regexp.Compile(c.BodyRegex)
+			if err != nil {
+				log.Printf("[WARN] Invalid body regular expression for check ID:%q, it will be ignored: %v", c.ID, err)
+			}
+		}
+	}
 	// instantiate channel to close the check ticker
 	c.chStop = make(chan struct{})
 
@@ -66,7 +100,7 @@ func (c *Check) Start() {
 	go c.run()
 }
 
-// Stop allows to stop a TCP check
+// Stop allows to stop a check
 func (c *Check) Stop() {
 	c.stopLock.Lock()
 	defer c.stopLock.Unlock()
@@ -93,14 +127,125 @@ func (c *Check) run() {
 }
 
 func (c *Check) check() {
+	var err error
+	switch c.Type {
+	case CheckTypeHttp:
+		err = c.checkHTTP()
+	case CheckTypeCommand:
+		err = c.checkCommand()
+	default:
+		err = c.checkTCP()
+	}
+	previousStatus := c.Report.Status
+	c.Report.LastCheck = time.Now()
+	if err != nil {
+		log.Debugf("[WARN] %s check (id:%q) failed: %v", c.Type, c.ID, err)
+		c.updateStatus(CheckStatusCRITICAL, err.Error())
+		c.handleCriticalResult()
+	} else {
+		c.consecutiveCriticals = 0
+		c.updateStatus(CheckStatusPASSING, "")
+	}
+	if c.shouldStoreLastCheckDate(c.Report.Status != previousStatus) {
+		if errStore := storeLastCheckDate(defaultMonManager.cc.KV(), c.ID, c.Report.LastCheck); errStore != nil {
+			log.Debugf("[WARN] Failed to store last check date for check ID:%q due to error:%+v", c.ID, errStore)
+			return
+		}
+		c.lastCheckStored = c.Report.LastCheck
+	}
+}
+
+// shouldStoreLastCheckDate checks if the last check date kept in memory should be stored in Consul,
+// it is stored on status changes or at most every lastCheckDateStoreInterval to not write on each run of frequent checks
+func (c *Check) shouldStoreLastCheckDate(statusChanged bool) bool {
+	return statusChanged || c.Report.LastCheck.Sub(c.lastCheckStored) >= lastCheckDateStoreInterval
+}
+
+func (c *Check) checkTCP() error {
 	conn, err := net.DialTimeout("tcp", c.TCPAddress, c.timeout)
 	if err != nil {
-		log.Debugf("[WARN] TCP check (id:%q) connection failed for address:%s", c.ID, c.TCPAddress)
-		c.updateStatus(CheckStatusCRITICAL)
-		return
+		return err
 	}
 	conn.Close()
-	c.updateStatus(CheckStatusPASSING)
+	return nil
+}
+
+func (c *Check) checkHTTP() error {
+	resp, err := c.httpClient.Get(c.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if !matchStatusCode(resp.StatusCode, c.ExpectedStatus) {
+		return errors.Errorf("unexpected HTTP status %q", resp.Status)
+	}
+	if c.bodyRegexp == nil {
+		return nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxCheckedBodySize))
+	if err != nil {
+		return errors.Wrap(err, "failed to read HTTP response body")
+	}
+	if !c.bodyRegexp.Match(body) {
+		return errors.Errorf("HTTP response body does not match %q", c.BodyRegex)
+	}
+	return nil
+}
+
+// matchStatusCode checks if a status code is part of the expected ones,
+// expected status may be codes (ex: 200) or classes (ex: 2xx). Any 2xx status is expected if no one is specified.
+func matchStatusCode(code int, expected []string) bool {
+	if len(expected) == 0 {
+		expected = []string{"2xx"}
+	}
+	codeStr := strconv.Itoa(code)
+	for _, e := range expected {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == codeStr || (len(e) == 3 && strings.HasSuffix(e, "xx") && e[0] == codeStr[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Check) checkCommand() error {
+	if c.sshClient == nil {
+		client, err := defaultMonManager.getSSHClient(c.Report.DeploymentID, c.Report.NodeName, c.Report.Instance, c.TCPAddress, c.timeout)
+		if err != nil {
+			return err
+		}
+		c.sshClient = client
+	}
+
+	session, err := c.sshClient.GetSessionWrapper()
+	if err != nil {
+		return errors.Wrap(err, "command failed")
+	}
+	// Outputs are read while the command runs to not block it, only their beginning is kept for errors
+	var stdout, stderr bytes.Buffer
+	var wg sync.WaitGroup
+	readOutput := func(r io.Reader, buf *bytes.Buffer) {
+		defer wg.Done()
+		io.CopyN(buf, r, maxCommandOutputInError)
+		io.Copy(ioutil.Discard, r)
+	}
+	wg.Add(2)
+	go readOutput(session.Stdout, &stdout)
+	go readOutput(session.Stderr, &stderr)
+
+	// The session is killed and closed on timeout
+	ctx, cancel := context.WithTimeout(c.ctx, c.timeout)
+	defer cancel()
+	err = session.RunCommand(ctx, c.Command)
+	wg.Wait()
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("command timed out after %s", c.timeout)
+	}
+	if err != nil {
+		output := strings.TrimSpace(stdout.String() + "\n" + stderr.String())
+		return errors.Errorf("command failed: %v: %s", err, stringutil.Truncate(output, maxCommandOutputInError))
+	}
+	return nil
 }
 
 func (c *Check) exist() bool {
@@ -116,18 +261,33 @@ func (c *Check) exist() bool {
 	return true
 }
 
-func (c *Check) updateStatus(status CheckStatus) {
-	if c.Report.Status != status {
-		// Be sure check isn't currently being removed before check has been stopped
-		if !c.exist() {
-			return
+func (c *Check) updateStatus(status CheckStatus, lastError string) {
+	if c.Report.Status == status && c.Report.LastError == lastError {
+		return
+	}
+	// Be sure check isn't currently being removed before check has been stopped
+	if !c.exist() {
+		return
+	}
+	reportPath := path.Join(consulutil.MonitoringKVPrefix, "reports", c.ID)
+	if c.Report.LastError != lastError {
+		err := consulutil.StoreConsulKeyAsString(path.Join(reportPath, "last_error"), lastError)
+		if err != nil {
+			log.Printf("[WARN] %s check updating last error failed for check ID:%q due to error:%+v", c.Type, c.ID, err)
 		}
+		c.Report.LastError = lastError
+	}
+	if c.Report.Status != status {
 		log.Debugf("Update check status from %q to %q", c.Report.Status.String(), status.String())
-		err := consulutil.StoreConsulKeyAsString(path.Join(consulutil.MonitoringKVPrefix, "reports", c.ID, "status"), status.String())
+		err := consulutil.StoreConsulKeyAsString(path.Join(reportPath, "status"), status.String())
 		if err != nil {
-			log.Printf("[WARN] TCP check updating status failed for check ID:%q due to error:%+v", c.ID, err)
+			log.Printf("[WARN] %s check updating status failed for check ID:%q due to error:%+v", c.Type, c.ID, err)
 		}
 		c.Report.Status = status
+		err = storeCheckHistoryEntry(defaultMonManager.cc.KV(), c.ID, CheckHistoryEntry{Date: time.Now(), Status: status, Error: lastError})
+		if err != nil {
+			log.Printf("[WARN] %s check updating history failed for check ID:%q due to error:%+v", c.Type, c.ID, err)
+		}
 		c.notify()
 	}
 }
@@ -142,7 +302,7 @@ func (c *Check) notify() {
 	} else if c.Report.Status == CheckStatusCRITICAL {
 		// Node in ERROR
 		nodeState = tosca.NodeStateError
-		events.WithContextOptionalFields(c.ctx).NewLogEntry(events.LogLevelERROR, c.Report.DeploymentID).Registerf("Monitoring Check returned a connection failure for node (%s-%s)", c.Report.NodeName, c.Report.Instance)
+		events.WithContextOptionalFields(c.ctx).NewLogEntry(events.LogLevelERROR, c.Report.DeploymentID).Registerf("Monitoring %s Check returned a failure for node (%s-%s): %s", c.Type, c.Report.NodeName, c.Report.Instance, c.Report.LastError)
 	}
 
 	// Update the node state
@@ -0,0 +1,159 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchStatusCode(t *testing.T) {
+	tests := []struct {
+		code     int
+		expected []string
+		want     bool
+	}{
+		{200, nil, true},
+		{204, nil, true},
+		{301, nil, false},
+		{301, []string{"200", "3xx"}, true},
+		{404, []string{"200", "3xx"}, false},
+		{404, []string{" 404 "}, true},
+		{500, []string{"5XX"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d-%v", tt.code, tt.expected), func(t *testing.T) {
+			require.Equal(t, tt.want, matchStatusCode(tt.code, tt.expected))
+		})
+	}
+}
+
+func TestShouldStoreLastCheckDate(t *testing.T) {
+	now := time.Now()
+	c := &Check{Report: CheckReport{LastCheck: now}}
+	require.True(t, c.shouldStoreLastCheckDate(false), "never stored")
+	c.lastCheckStored = now.Add(-lastCheckDateStoreInterval / 2)
+	require.False(t, c.shouldStoreLastCheckDate(false))
+	require.True(t, c.shouldStoreLastCheckDate(true), "status changed")
+	c.lastCheckStored = now.Add(-lastCheckDateStoreInterval)
+	require.True(t, c.shouldStoreLastCheckDate(false))
+}
+
+func TestCheckHTTP(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			fmt.Fprint(w, `{"status": "UP"}`)
+		case "/down":
+			fmt.Fprint(w, `{"status": "DOWN"}`)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		expected   []string
+		bodyRegex  string
+		wantErrMsg string
+	}{
+		{"Passing", "/health", nil, "", ""},
+		{"PassingWithBody", "/health", nil, `"status":\s*"UP"`, ""},
+		{"UnexpectedBody", "/down", nil, `"status":\s*"UP"`, "HTTP response body does not match"},
+		{"UnexpectedStatus", "/other", nil, "", "unexpected HTTP status \"503 Service Unavailable\""},
+		{"ExpectedStatus", "/other", []string{"503"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Check{Type: CheckTypeHttp, URL: ts.URL + tt.path, ExpectedStatus: tt.expected, BodyRegex: tt.bodyRegex}
+			c.httpClient = &http.Client{Timeout: 5 * time.Second}
+			if tt.bodyRegex != "" {
+				c.bodyRegexp = regexp.MustCompile(tt.bodyRegex)
+			}
+			err := c.checkHTTP()
+			if tt.wantErrMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErrMsg)
+		})
+	}
+}
+
+func TestReadCheckDefinition(t *testing.T) {
+	check := NewCheck("dep", "Compute", "0")
+	kvps := api.KVPairs{
+		&api.KVPair{Key: "checks/dep:Compute:0/type", Value: []byte("http")},
+		&api.KVPair{Key: "checks/dep:Compute:0/interval", Value: []byte("5s")},
+		&api.KVPair{Key: "checks/dep:Compute:0/address", Value: []byte("1.2.3.4:8080")},
+		&api.KVPair{Key: "checks/dep:Compute:0/url", Value: []byte("https://1.2.3.4:8080/health")},
+		&api.KVPair{Key: "checks/dep:Compute:0/expected_status", Value: []byte("200,3xx")},
+		&api.KVPair{Key: "checks/dep:Compute:0/body_regex", Value: []byte("UP")},
+		&api.KVPair{Key: "checks/dep:Compute:0/tls_skip_verify", Value: []byte("true")},
+	}
+	require.NoError(t, readCheckDefinition(check, kvps))
+	require.Equal(t, CheckTypeHttp, check.Type)
+	require.Equal(t, CheckTypeHttp, check.Report.Type)
+	require.Equal(t, 5*time.Second, check.TimeInterval)
+	require.Equal(t, "1.2.3.4:8080", check.TCPAddress)
+	require.Equal(t, "https://1.2.3.4:8080/health", check.URL)
+	require.Equal(t, []string{"200", "3xx"}, check.ExpectedStatus)
+	require.Equal(t, "UP", check.BodyRegex)
+	require.True(t, check.TLSSkipVerify)
+
+	// Checks registered by previous versions have no type
+	check = NewCheck("dep", "Compute", "0")
+	require.NoError(t, readCheckDefinition(check, api.KVPairs{&api.KVPair{Key: "checks/dep:Compute:0/address", Value: []byte("1.2.3.4:22")}}))
+	require.Equal(t, CheckTypeTcp, check.Type)
+
+	err := readCheckDefinition(check, api.KVPairs{&api.KVPair{Key: "checks/dep:Compute:0/type", Value: []byte("udp")}})
+	require.Error(t, err)
+}
+
+func testIsMonitoringRequiredWithCheckTypes(t *testing.T, client *api.Client) {
+	t.Parallel()
+	tests := []struct {
+		node    string
+		want    CheckDefinition
+		wantErr bool
+	}{
+		{"Compute1", CheckDefinition{Type: CheckTypeHttp, TimeInterval: 5 * time.Second, Port: 443, HTTPScheme: "https", HTTPPath: "/health", ExpectedStatus: []string{"200", "3xx"}, BodyRegex: "UP", TLSSkipVerify: true}, false},
+		{"Compute2", CheckDefinition{Type: CheckTypeCommand, TimeInterval: 5 * time.Second, Port: 2222, Command: "systemctl is-active myapp"}, false},
+		{"Compute3", CheckDefinition{}, true},
+		{"Compute4", CheckDefinition{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.node, func(t *testing.T) {
+			is, checkDef, err := defaultMonManager.isMonitoringRequired("monitoring7", tt.node)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, is)
+			require.Equal(t, tt.want, checkDef)
+		})
+	}
+}
@@ -72,6 +72,36 @@ func TestRunConsulMonitoringPackageTests(t *testing.T) {
 		consulutil.DeploymentKVPrefix + "/monitoring5/topology/nodes/Compute1/metadata/monitoring_time_interval": []byte("1"),
 		consulutil.DeploymentKVPrefix + "/monitoring5/topology/instances/Compute1/0/attributes/ip_address":       []byte("1.2.3.4"),
 		consulutil.DeploymentKVPrefix + "/monitoring5/topology/instances/Compute1/0/attributes/state":            []byte("started"),
+
+		consulutil.DeploymentKVPrefix + "/monitoring6/topology/nodes/Compute1/metadata/monitoring_heal_policy":    []byte("reinstall"),
+		consulutil.DeploymentKVPrefix + "/monitoring6/topology/nodes/Compute1/metadata/monitoring_heal_threshold": []byte("5"),
+		consulutil.DeploymentKVPrefix + "/monitoring6/topology/nodes/Compute2/metadata/monitoring_heal_policy":    []byte("restart"),
+		consulutil.DeploymentKVPrefix + "/monitoring6/topology/nodes/Compute3/metadata/monitoring_heal_policy":    []byte("reboot"),
+		consulutil.DeploymentKVPrefix + "/monitoring6/topology/nodes/Compute4/metadata/monitoring_heal_policy":    []byte("restart"),
+		consulutil.DeploymentKVPrefix + "/monitoring6/topology/nodes/Compute4/metadata/monitoring_heal_threshold": []byte("0"),
+
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/types/tosca.nodes.Root/name":                             []byte("tosca.nodes.Root"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/types/tosca.nodes.Compute/derived_from":                  []byte("tosca.nodes.Root"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute1/type":                                     []byte("tosca.nodes.Compute"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute1/metadata/monitoring_time_interval":        []byte("5"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute1/metadata/monitoring_check_type":           []byte("http"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute1/metadata/monitoring_http_scheme":          []byte("https"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute1/metadata/monitoring_http_path":            []byte("health"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute1/metadata/monitoring_http_expected_status": []byte("200, 3xx"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute1/metadata/monitoring_http_body_regex":      []byte("UP"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute1/metadata/monitoring_http_tls_skip_verify": []byte("true"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute2/type":                                     []byte("tosca.nodes.Compute"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute2/metadata/monitoring_time_interval":        []byte("5"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute2/metadata/monitoring_check_type":           []byte("command"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute2/metadata/monitoring_port":                 []byte("2222"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute2/metadata/monitoring_command":              []byte("systemctl is-active myapp"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute3/type":                                     []byte("tosca.nodes.Compute"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute3/metadata/monitoring_time_interval":        []byte("5"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute3/metadata/monitoring_check_type":           []byte("command"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute4/type":                                     []byte("tosca.nodes.Compute"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute4/metadata/monitoring_time_interval":        []byte("5"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute4/metadata/monitoring_check_type":           []byte("http"),
+		consulutil.DeploymentKVPrefix + "/monitoring7/topology/nodes/Compute4/metadata/monitoring_http_expected_status": []byte("20x"),
 	})
 
 	t.Run("groupMonitoring", func(t *testing.T) {
@@ -87,5 +117,14 @@ func TestRunConsulMonitoringPackageTests(t *testing.T) {
 		t.Run("testAddAndRemoveCheck", func(t *testing.T) {
 			testAddAndRemoveCheck(t, client)
 		})
+		t.Run("testIsMonitoringRequiredWithCheckTypes", func(t *testing.T) {
+			testIsMonitoringRequiredWithCheckTypes(t, client)
+		})
+		t.Run("testCheckReportsHistoryAndPause", func(t *testing.T) {
+			testCheckReportsHistoryAndPause(t, client)
+		})
+		t.Run("testGetHealPolicy", func(t *testing.T) {
+			testGetHealPolicy(t, client)
+		})
 	})
 }
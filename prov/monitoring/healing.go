@@ -0,0 +1,279 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/helper/collections"
+	"github.com/ystia/yorc/tasks"
+	"github.com/ystia/yorc/tasks/collector"
+)
+
+// defaultHealThreshold is the number of consecutive critical checks results triggering a heal when not set in the node metadata
+const defaultHealThreshold = 3
+
+// healTaskCheckInterval is the period used to check if a heal workflow task is done
+var healTaskCheckInterval = 5 * time.Second
+
+// healWorkflows returns the ordered list of workflows run to heal an instance for a given policy
+func healWorkflows(policy HealPolicy) []string {
+	switch policy {
+	case HealPolicyRestart:
+		return []string{"stop", "start"}
+	case HealPolicyReinstall:
+		return []string{"uninstall", "install"}
+	}
+	return nil
+}
+
+// getHealPolicy returns the heal policy and the number of consecutive critical checks results triggering it
+// defined in the metadata of a given node
+func (mgr *monitoringMgr) getHealPolicy(deploymentID, nodeName string) (HealPolicy, int, error) {
+	found, val, err := deployments.GetNodeMetadata(mgr.cc.KV(), deploymentID, nodeName, "monitoring_heal_policy")
+	if err != nil || !found {
+		return HealPolicyNone, 0, err
+	}
+	policy, err := ParseHealPolicy(strings.TrimSpace(val))
+	if err != nil {
+		return HealPolicyNone, 0, errors.Wrapf(err, "invalid monitoring_heal_policy metadata for node %q", nodeName)
+	}
+
+	threshold := defaultHealThreshold
+	found, val, err = deployments.GetNodeMetadata(mgr.cc.KV(), deploymentID, nodeName, "monitoring_heal_threshold")
+	if err != nil {
+		return HealPolicyNone, 0, err
+	}
+	if found {
+		threshold, err = strconv.Atoi(strings.TrimSpace(val))
+		if err != nil || threshold < 1 {
+			return HealPolicyNone, 0, errors.Errorf("invalid monitoring_heal_threshold metadata %q for node %q: expecting a positive integer", val, nodeName)
+		}
+	}
+	return policy, threshold, nil
+}
+
+// reserveHeal checks if a heal is allowed for a given check according to the rate limit and to the
+// maximum number of concurrent heals of its deployment.
+//
+// If it is allowed, the heal is accounted until releaseHeal is called. Otherwise the reason is returned.
+func (mgr *monitoringMgr) reserveHeal(c *Check) (bool, string) {
+	minInterval := mgr.cfg.Monitoring.HealMinInterval
+	if minInterval <= 0 {
+		minInterval = config.DefaultMonitoringHealMinInterval
+	}
+	maxConcurrency := mgr.cfg.Monitoring.HealMaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = config.DefaultMonitoringHealMaxConcurrency
+	}
+
+	mgr.healsLock.Lock()
+	defer mgr.healsLock.Unlock()
+	if mgr.healingChecks[c.ID] {
+		return false, "a heal is already in progress for this instance"
+	}
+	if last, ok := mgr.lastHeals[c.ID]; ok && time.Since(last) < minInterval {
+		return false, fmt.Sprintf("last heal of this instance was launched less than %s ago (at %s)", minInterval, last.Format(time.RFC3339))
+	}
+	if mgr.deploymentsHeals[c.Report.DeploymentID] >= maxConcurrency {
+		return false, fmt.Sprintf("the maximum number of concurrent heals (%d) is reached for this deployment", maxConcurrency)
+	}
+	mgr.healingChecks[c.ID] = true
+	mgr.lastHeals[c.ID] = time.Now()
+	mgr.deploymentsHeals[c.Report.DeploymentID]++
+	return true, ""
+}
+
+func (mgr *monitoringMgr) releaseHeal(c *Check) {
+	mgr.healsLock.Lock()
+	defer mgr.healsLock.Unlock()
+	delete(mgr.healingChecks, c.ID)
+	mgr.deploymentsHeals[c.Report.DeploymentID]--
+	if mgr.deploymentsHeals[c.Report.DeploymentID] <= 0 {
+		delete(mgr.deploymentsHeals, c.Report.DeploymentID)
+	}
+}
+
+// handleCriticalResult counts consecutive critical results of a check and triggers
+// the heal policy of the node when its threshold is reached
+func (c *Check) handleCriticalResult() {
+	if c.Report.Status != CheckStatusCRITICAL {
+		// Status not updated as the check is being removed
+		return
+	}
+	c.consecutiveCriticals++
+	if c.consecutiveCriticals == 1 {
+		// Beginning of a failure, (re)load the heal policy as the node metadata may have been updated
+		var err error
+		c.healPolicy, c.healThreshold, err = defaultMonManager.getHealPolicy(c.Report.DeploymentID, c.Report.NodeName)
+		if err != nil {
+			events.WithContextOptionalFields(c.ctx).NewLogEntry(events.LogLevelWARN, c.Report.DeploymentID).
+				Registerf("Automatic healing disabled for node (%s-%s): %v", c.Report.NodeName, c.Report.Instance, err)
+		}
+	}
+	if c.healPolicy == HealPolicyNone || c.consecutiveCriticals < c.healThreshold {
+		return
+	}
+	// Whatever happens, wait for a new series of critical results before trying again
+	c.consecutiveCriticals = 0
+
+	ok, reason := defaultMonManager.reserveHeal(c)
+	if !ok {
+		events.WithContextOptionalFields(c.ctx).NewLogEntry(events.LogLevelWARN, c.Report.DeploymentID).
+			Registerf("Automatic %s of node (%s-%s) skipped: %s", c.healPolicy, c.Report.NodeName, c.Report.Instance, reason)
+		return
+	}
+	go func() {
+		defer defaultMonManager.releaseHeal(c)
+		defaultMonManager.heal(c.ctx, c.Report, c.healPolicy, c.healThreshold)
+	}()
+}
+
+// heal runs sequentially the workflows of a given policy restricted to the failed instance and to the instances hosted on it
+func (mgr *monitoringMgr) heal(ctx context.Context, report CheckReport, policy HealPolicy, threshold int) {
+	kv := mgr.cc.KV()
+	logEvent := func(level events.LogLevel, format string, args ...interface{}) {
+		events.WithContextOptionalFields(ctx).NewLogEntry(level, report.DeploymentID).
+			Registerf("Automatic %s of node (%s-%s): %s", policy, report.NodeName, report.Instance, fmt.Sprintf(format, args...))
+	}
+
+	status, err := deployments.GetDeploymentStatus(kv, report.DeploymentID)
+	if err != nil {
+		logEvent(events.LogLevelERROR, "failed to retrieve deployment status: %v", err)
+		return
+	}
+	if status != deployments.DEPLOYED {
+		logEvent(events.LogLevelWARN, "skipped as deployment status is %s", status)
+		return
+	}
+	hasLivingTask, livingTaskID, _, err := tasks.TargetHasLivingTasks(kv, report.DeploymentID)
+	if err != nil {
+		logEvent(events.LogLevelERROR, "failed to check deployment tasks: %v", err)
+		return
+	}
+	if hasLivingTask {
+		logEvent(events.LogLevelWARN, "skipped as task %q is running on this deployment", livingTaskID)
+		return
+	}
+	workflows, err := deployments.GetWorkflows(kv, report.DeploymentID)
+	if err != nil {
+		logEvent(events.LogLevelERROR, "failed to retrieve deployment workflows: %v", err)
+		return
+	}
+	wfNames := healWorkflows(policy)
+	for _, wfName := range wfNames {
+		if !collections.ContainsString(workflows, wfName) {
+			logEvent(events.LogLevelERROR, "skipped as workflow %q is not defined for this deployment", wfName)
+			return
+		}
+	}
+	nodesInstances, err := mgr.getHealedInstances(report.DeploymentID, report.NodeName, report.Instance)
+	if err != nil {
+		logEvent(events.LogLevelERROR, "failed to retrieve instances to heal: %v", err)
+		return
+	}
+
+	logEvent(events.LogLevelINFO, "launched after %d consecutive critical checks results, running workflows %s", threshold, strings.Join(wfNames, ", "))
+	coll := collector.NewCollector(mgr.cc)
+	for _, wfName := range wfNames {
+		data := map[string]string{
+			"workflowName":    wfName,
+			"continueOnError": strconv.FormatBool(false),
+		}
+		for node, instances := range nodesInstances {
+			data["nodes/"+node] = strings.Join(instances, ",")
+		}
+		taskID, err := coll.RegisterTaskWithData(report.DeploymentID, tasks.TaskTypeCustomWorkflow, data)
+		if err != nil {
+			logEvent(events.LogLevelERROR, "failed to launch workflow %q: %v", wfName, err)
+			return
+		}
+		logEvent(events.LogLevelINFO, "workflow %q launched with task %q", wfName, taskID)
+		taskStatus, err := mgr.waitForTaskEnd(taskID)
+		if err != nil {
+			logEvent(events.LogLevelERROR, "failed to wait for the end of task %q: %v", taskID, err)
+			return
+		}
+		if taskStatus != tasks.TaskStatusDONE {
+			logEvent(events.LogLevelERROR, "workflow %q ended with status %s (task %q), aborting heal", wfName, taskStatus, taskID)
+			return
+		}
+	}
+	logEvent(events.LogLevelINFO, "successfully completed")
+}
+
+// getHealedInstances returns the instance of a given node and the instances of nodes hosted on it, indexed by node name
+func (mgr *monitoringMgr) getHealedInstances(deploymentID, nodeName, instance string) (map[string][]string, error) {
+	kv := mgr.cc.KV()
+	res := map[string][]string{nodeName: {instance}}
+	hostedNodes, err := deployments.GetNodesHostedOn(kv, deploymentID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	for _, hostedNode := range hostedNodes {
+		instances, err := deployments.GetNodeInstancesIds(kv, deploymentID, hostedNode)
+		if err != nil {
+			return nil, err
+		}
+		for _, hostedInstance := range instances {
+			// Go through the hosted on hierarchy up to the healed node
+			host, hostInstance := hostedNode, hostedInstance
+			for host != "" && host != nodeName {
+				host, hostInstance, err = deployments.GetHostedOnNodeInstance(kv, deploymentID, host, hostInstance)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if host == nodeName && hostInstance == instance {
+				res[hostedNode] = append(res[hostedNode], hostedInstance)
+			}
+		}
+		sort.Strings(res[hostedNode])
+	}
+	return res, nil
+}
+
+// waitForTaskEnd waits for a task to be done, failed or canceled and returns its final status
+//
+// It returns an error if monitoring is stopped on this server in between.
+func (mgr *monitoringMgr) waitForTaskEnd(taskID string) (tasks.TaskStatus, error) {
+	ticker := time.NewTicker(healTaskCheckInterval)
+	defer ticker.Stop()
+	for {
+		status, err := tasks.GetTaskStatus(mgr.cc.KV(), taskID)
+		if err != nil {
+			return status, err
+		}
+		switch status {
+		case tasks.TaskStatusDONE, tasks.TaskStatusFAILED, tasks.TaskStatusCANCELED:
+			return status, nil
+		}
+		select {
+		case <-mgr.chStopMonitoring:
+			return status, errors.New("monitoring has been stopped on this server")
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+)
+
+func TestHealWorkflows(t *testing.T) {
+	require.Equal(t, []string{"stop", "start"}, healWorkflows(HealPolicyRestart))
+	require.Equal(t, []string{"uninstall", "install"}, healWorkflows(HealPolicyReinstall))
+	require.Nil(t, healWorkflows(HealPolicyNone))
+}
+
+func TestReserveHeal(t *testing.T) {
+	mgr := &monitoringMgr{
+		cfg:              config.Configuration{Monitoring: config.Monitoring{HealMinInterval: time.Hour, HealMaxConcurrency: 2}},
+		healingChecks:    make(map[string]bool),
+		lastHeals:        make(map[string]time.Time),
+		deploymentsHeals: make(map[string]int),
+	}
+	c1 := NewCheck("dep1", "Compute", "0")
+	c2 := NewCheck("dep1", "Compute", "1")
+	c3 := NewCheck("dep1", "Compute", "2")
+	c4 := NewCheck("dep2", "Compute", "0")
+
+	ok, _ := mgr.reserveHeal(c1)
+	require.True(t, ok)
+	ok, reason := mgr.reserveHeal(c1)
+	require.False(t, ok)
+	require.Contains(t, reason, "already in progress")
+
+	ok, _ = mgr.reserveHeal(c2)
+	require.True(t, ok)
+	ok, reason = mgr.reserveHeal(c3)
+	require.False(t, ok, "concurrency cap should be reached for dep1")
+	require.Contains(t, reason, "maximum number of concurrent heals (2)")
+	ok, _ = mgr.reserveHeal(c4)
+	require.True(t, ok, "concurrency cap is per deployment")
+
+	mgr.releaseHeal(c2)
+	ok, _ = mgr.reserveHeal(c3)
+	require.True(t, ok)
+
+	// Rate limit
+	mgr.releaseHeal(c1)
+	ok, reason = mgr.reserveHeal(c1)
+	require.False(t, ok)
+	require.Contains(t, reason, "less than 1h0m0s ago")
+	mgr.lastHeals[c1.ID] = time.Now().Add(-2 * time.Hour)
+	ok, _ = mgr.reserveHeal(c1)
+	require.True(t, ok)
+}
+
+func testGetHealPolicy(t *testing.T, client *api.Client) {
+	mgr := &monitoringMgr{cc: client}
+	tests := []struct {
+		node          string
+		wantPolicy    HealPolicy
+		wantThreshold int
+		wantErr       bool
+	}{
+		{"Compute1", HealPolicyReinstall, 5, false},
+		{"Compute2", HealPolicyRestart, defaultHealThreshold, false},
+		{"Compute3", HealPolicyNone, 0, true},
+		{"Compute4", HealPolicyNone, 0, true},
+		{"Compute5", HealPolicyNone, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.node, func(t *testing.T) {
+			policy, threshold, err := mgr.getHealPolicy("monitoring6", tt.node)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantPolicy, policy)
+			require.Equal(t, tt.wantThreshold, threshold)
+		})
+	}
+}
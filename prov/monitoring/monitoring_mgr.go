@@ -16,8 +16,10 @@ package monitoring
 
 import (
 	"context"
-	"fmt"
+	"net"
+	"net/url"
 	"path"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -29,15 +31,20 @@ import (
 	"github.com/ystia/yorc/deployments"
 	"github.com/ystia/yorc/events"
 	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/helper/sshutil"
 	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/operations"
 	"github.com/ystia/yorc/tasks"
 	"github.com/ystia/yorc/tasks/workflow"
 	"github.com/ystia/yorc/tasks/workflow/builder"
 	"github.com/ystia/yorc/tosca"
+	"golang.org/x/crypto/ssh"
 )
 
 var defaultMonManager *monitoringMgr
 
+var httpStatusRegexp = regexp.MustCompile(`^[1-5]([0-9]{2}|xx)$`)
+
 func init() {
 	workflow.RegisterPreActivityHook(removeMonitoringHook)
 	workflow.RegisterPostActivityHook(addMonitoringHook)
@@ -52,16 +59,23 @@ type monitoringMgr struct {
 	checks           map[string]*Check
 	serviceKey       string
 	cfg              config.Configuration
+	healsLock        sync.Mutex
+	healingChecks    map[string]bool
+	lastHeals        map[string]time.Time
+	deploymentsHeals map[string]int
 }
 
 // Start allows to instantiate a default Monitoring Manager and to start monitoring checks
 func Start(cfg config.Configuration, cc *api.Client) {
 	defaultMonManager = &monitoringMgr{
-		cc:           cc,
-		chShutdown:   make(chan struct{}),
-		isMonitoring: false,
-		serviceKey:   path.Join(consulutil.YorcServicePrefix, "/monitoring/leader"),
-		cfg:          cfg,
+		cc:               cc,
+		chShutdown:       make(chan struct{}),
+		isMonitoring:     false,
+		serviceKey:       path.Join(consulutil.YorcServicePrefix, "/monitoring/leader"),
+		cfg:              cfg,
+		healingChecks:    make(map[string]bool),
+		lastHeals:        make(map[string]time.Time),
+		deploymentsHeals: make(map[string]int),
 	}
 
 	// Watch leader election for monitoring service
@@ -165,27 +179,15 @@ func (mgr *monitoringMgr) startMonitoring() {
 					continue
 				}
 
-				kvp, _, err = mgr.cc.KV().Get(path.Join(key, "interval"), nil)
+				kvps, _, err := mgr.cc.KV().List(key, nil)
 				if err != nil {
 					handleError(err)
 					continue
 				}
-				if kvp != nil && len(kvp.Value) > 0 {
-					d, err := time.ParseDuration(string(kvp.Value))
-					if err != nil {
-						handleError(err)
-						continue
-					}
-					check.TimeInterval = d
-				}
-				kvp, _, err = mgr.cc.KV().Get(path.Join(key, "address"), nil)
-				if err != nil {
+				if err = readCheckDefinition(check, kvps); err != nil {
 					handleError(err)
 					continue
 				}
-				if kvp != nil && len(kvp.Value) > 0 {
-					check.TCPAddress = string(kvp.Value)
-				}
 
 				reportPath := path.Join(consulutil.MonitoringKVPrefix, "reports", id)
 				kvp, _, err = mgr.cc.KV().Get(path.Join(reportPath, "status"), nil)
@@ -200,9 +202,27 @@ func (mgr *monitoringMgr) startMonitoring() {
 						continue
 					}
 				}
+				kvp, _, err = mgr.cc.KV().Get(path.Join(reportPath, "last_error"), nil)
+				if err != nil {
+					handleError(err)
+					continue
+				}
+				if kvp != nil {
+					check.Report.LastError = string(kvp.Value)
+				}
+
+				// Stop paused checks without unregistering them
+				runningCheck, is := mgr.checks[id]
+				if check.Paused {
+					if is {
+						log.Debugf("Check with id:%q has been paused", id)
+						runningCheck.Stop()
+						delete(mgr.checks, id)
+					}
+					continue
+				}
 
 				// Store the check if not already present and start it
-				_, is := mgr.checks[id]
 				if !is {
 					mgr.checks[check.ID] = check
 					check.Start()
@@ -222,7 +242,7 @@ func addMonitoringHook(ctx context.Context, cfg config.Configuration, taskID, de
 		activity.Type() == builder.ActivityTypeSetState && activity.Value() == tosca.NodeStateStarted.String():
 
 		// Check if monitoring is required
-		isMonitorReq, monitoringInterval, err := defaultMonManager.isMonitoringRequired(deploymentID, target)
+		isMonitorReq, checkDef, err := defaultMonManager.isMonitoringRequired(deploymentID, target)
 		if err != nil {
 			events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, deploymentID).
 				Registerf("Failed to check if monitoring is required for node name:%q due to: %v", target, err)
@@ -252,7 +272,7 @@ func addMonitoringHook(ctx context.Context, cfg config.Configuration, taskID, de
 				return
 			}
 
-			if err := defaultMonManager.registerCheck(deploymentID, target, instance, ipAddress.RawString(), 22, monitoringInterval); err != nil {
+			if err := defaultMonManager.registerCheck(deploymentID, target, instance, ipAddress.RawString(), checkDef); err != nil {
 				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, deploymentID).
 					Registerf("Failed to register check for node name:%q due to: %v", target, err)
 				return
@@ -297,45 +317,223 @@ func removeMonitoringHook(ctx context.Context, cfg config.Configuration, taskID,
 	}
 }
 
-func (mgr *monitoringMgr) isMonitoringRequired(deploymentID, nodeName string) (bool, time.Duration, error) {
+func (mgr *monitoringMgr) isMonitoringRequired(deploymentID, nodeName string) (bool, CheckDefinition, error) {
+	checkDef := CheckDefinition{}
 	// Check if the node is a compute
 	var isCompute bool
 	isCompute, err := deployments.IsNodeDerivedFrom(mgr.cc.KV(), deploymentID, nodeName, "tosca.nodes.Compute")
 	if err != nil {
-		return false, 0, err
+		return false, checkDef, err
 	}
 	if !isCompute {
-		return false, 0, nil
+		return false, checkDef, nil
 	}
 
 	// monitoring_time_interval must be set to positive value
 	found, val, err := deployments.GetNodeMetadata(mgr.cc.KV(), deploymentID, nodeName, "monitoring_time_interval")
 	if err != nil {
-		return false, 0, err
+		return false, checkDef, err
 	}
 	if !found {
-		return false, 0, nil
+		return false, checkDef, nil
 	}
 
 	var t int
 	if t, err = strconv.Atoi(val); err != nil {
-		return false, 0, err
+		return false, checkDef, err
+	}
+	if t <= 0 {
+		return false, checkDef, nil
+	}
+	checkDef.TimeInterval, err = time.ParseDuration(val + "s")
+	if err != nil {
+		return false, checkDef, err
+	}
+	err = mgr.readCheckDefinitionMetadata(deploymentID, nodeName, &checkDef)
+	return err == nil, checkDef, err
+}
+
+// readCheckDefinitionMetadata reads the check type and its parameters from the monitoring_* metadata of a node
+func (mgr *monitoringMgr) readCheckDefinitionMetadata(deploymentID, nodeName string, checkDef *CheckDefinition) error {
+	metadata := make(map[string]string)
+	for _, name := range []string{"check_type", "port", "http_scheme", "http_path", "http_expected_status", "http_body_regex", "http_tls_skip_verify", "command"} {
+		found, val, err := deployments.GetNodeMetadata(mgr.cc.KV(), deploymentID, nodeName, "monitoring_"+name)
+		if err != nil {
+			return err
+		}
+		if found {
+			metadata[name] = strings.TrimSpace(val)
+		}
+	}
+
+	var err error
+	if metadata["check_type"] != "" {
+		checkDef.Type, err = ParseCheckType(strings.ToLower(metadata["check_type"]))
+		if err != nil {
+			return errors.Wrapf(err, "invalid monitoring_check_type metadata for node %q", nodeName)
+		}
+	}
+	if metadata["port"] != "" {
+		checkDef.Port, err = strconv.Atoi(metadata["port"])
+		if err != nil || checkDef.Port <= 0 || checkDef.Port > 65535 {
+			return errors.Errorf("invalid monitoring_port metadata %q for node %q", metadata["port"], nodeName)
+		}
+	}
+
+	switch checkDef.Type {
+	case CheckTypeHttp:
+		checkDef.HTTPScheme = strings.ToLower(metadata["http_scheme"])
+		switch checkDef.HTTPScheme {
+		case "":
+			checkDef.HTTPScheme = "http"
+		case "http", "https":
+		default:
+			return errors.Errorf("invalid monitoring_http_scheme metadata %q for node %q: expecting http or https", metadata["http_scheme"], nodeName)
+		}
+		if checkDef.Port == 0 {
+			checkDef.Port = 80
+			if checkDef.HTTPScheme == "https" {
+				checkDef.Port = 443
+			}
+		}
+		checkDef.HTTPPath = metadata["http_path"]
+		if !strings.HasPrefix(checkDef.HTTPPath, "/") {
+			checkDef.HTTPPath = "/" + checkDef.HTTPPath
+		}
+		if metadata["http_expected_status"] != "" {
+			for _, status := range strings.Split(metadata["http_expected_status"], ",") {
+				status = strings.ToLower(strings.TrimSpace(status))
+				if !httpStatusRegexp.MatchString(status) {
+					return errors.Errorf("invalid monitoring_http_expected_status metadata %q for node %q: expecting a comma-separated list of status codes (ex: 200) or classes (ex: 2xx)", metadata["http_expected_status"], nodeName)
+				}
+				checkDef.ExpectedStatus = append(checkDef.ExpectedStatus, status)
+			}
+		}
+		if metadata["http_body_regex"] != "" {
+			if _, err = regexp.Compile(metadata["http_body_regex"]); err != nil {
+				return errors.Wrapf(err, "invalid monitoring_http_body_regex metadata for node %q", nodeName)
+			}
+			checkDef.BodyRegex = metadata["http_body_regex"]
+		}
+		if metadata["http_tls_skip_verify"] != "" {
+			checkDef.TLSSkipVerify, err = strconv.ParseBool(metadata["http_tls_skip_verify"])
+			if err != nil {
+				return errors.Wrapf(err, "invalid monitoring_http_tls_skip_verify metadata for node %q", nodeName)
+			}
+		}
+	case CheckTypeCommand:
+		if metadata["command"] == "" {
+			return errors.Errorf("missing monitoring_command metadata for node %q with a command check type", nodeName)
+		}
+		checkDef.Command = metadata["command"]
+		if checkDef.Port == 0 {
+			checkDef.Port = 22
+		}
+	default:
+		if checkDef.Port == 0 {
+			checkDef.Port = 22
+		}
 	}
-	if t > 0 {
-		duration, err := time.ParseDuration(val + "s")
+	return nil
+}
+
+// readCheckDefinition fills a check from its stored definition
+func readCheckDefinition(check *Check, kvps api.KVPairs) error {
+	var err error
+	for _, kvp := range kvps {
+		if len(kvp.Value) == 0 {
+			continue
+		}
+		value := string(kvp.Value)
+		switch path.Base(kvp.Key) {
+		case "type":
+			check.Type, err = ParseCheckType(value)
+		case "interval":
+			check.TimeInterval, err = time.ParseDuration(value)
+		case "address":
+			check.TCPAddress = value
+		case "url":
+			check.URL = value
+		case "expected_status":
+			check.ExpectedStatus = strings.Split(value, ",")
+		case "body_regex":
+			check.BodyRegex = value
+		case "tls_skip_verify":
+			check.TLSSkipVerify, err = strconv.ParseBool(value)
+		case "command":
+			check.Command = value
+		case "paused":
+			check.Paused, err = strconv.ParseBool(value)
+		}
 		if err != nil {
-			return false, 0, err
+			return errors.Wrapf(err, "invalid check definition key %q", kvp.Key)
 		}
-		return true, duration, nil
 	}
-	return false, 0, nil
+	check.Report.Type = check.Type
+	return nil
+}
+
+// getSSHClient returns an SSH client connecting to an instance using its endpoint credentials
+func (mgr *monitoringMgr) getSSHClient(deploymentID, nodeName, instance, address string, timeout time.Duration) (*sshutil.SSHClient, error) {
+	kv := mgr.cc.KV()
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid SSH address %q", address)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid SSH address %q", address)
+	}
+	sshConfig := &ssh.ClientConfig{
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         timeout,
+		// Use root as default user
+		User: "root",
+	}
+	user, err := deployments.GetInstanceCapabilityAttributeValue(kv, deploymentID, nodeName, instance, "endpoint", "credentials", "user")
+	if err != nil {
+		return nil, err
+	}
+	if user != nil && user.RawString() != "" {
+		sshConfig.User = user.RawString()
+	}
+	privateKey, err := deployments.GetInstanceCapabilityAttributeValue(kv, deploymentID, nodeName, instance, "endpoint", "credentials", "keys", "0")
+	if err != nil {
+		return nil, err
+	}
+	password, err := deployments.GetInstanceCapabilityAttributeValue(kv, deploymentID, nodeName, instance, "endpoint", "credentials", "token")
+	if err != nil {
+		return nil, err
+	}
+	hasPrivateKey := privateKey != nil && privateKey.RawString() != ""
+	hasPassword := password != nil && password.RawString() != ""
+	if hasPrivateKey || !hasPassword {
+		// Use the default private key if there is neither private key nor password
+		keyPath := "~/.ssh/yorc.pem"
+		if hasPrivateKey {
+			keyPath = privateKey.RawString()
+		}
+		keyAuth, err := sshutil.ReadPrivateKey(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		sshConfig.Auth = append(sshConfig.Auth, keyAuth)
+	}
+	if hasPassword {
+		sshConfig.Auth = append(sshConfig.Auth, ssh.Password(password.RawString()))
+	}
+	bastion, err := operations.GetInstanceBastionHost(kv, deploymentID, nodeName, instance)
+	if err != nil {
+		return nil, err
+	}
+	return &sshutil.SSHClient{Config: sshConfig, Host: host, Port: port, Bastion: bastion}, nil
 }
 
 // registerCheck allows to register a check
-func (mgr *monitoringMgr) registerCheck(deploymentID, nodeName, instance, ipAddress string, port int, interval time.Duration) error {
+func (mgr *monitoringMgr) registerCheck(deploymentID, nodeName, instance, ipAddress string, checkDef CheckDefinition) error {
 	id := buildID(deploymentID, nodeName, instance)
-	log.Debugf("Register check with id:%q, iPAddress:%q, port:%d, interval:%d", id, ipAddress, port, interval)
-	tcpAddr := fmt.Sprintf("%s:%d", ipAddress, port)
+	log.Debugf("Register %s check with id:%q, iPAddress:%q, port:%d, interval:%d", checkDef.Type, id, ipAddress, checkDef.Port, checkDef.TimeInterval)
+	tcpAddr := net.JoinHostPort(ipAddress, strconv.Itoa(checkDef.Port))
 
 	// Check is registered in a transaction to ensure to be read in its wholeness
 	checkPath := path.Join(consulutil.MonitoringKVPrefix, "checks", id)
@@ -354,13 +552,37 @@ func (mgr *monitoringMgr) registerCheck(deploymentID, nodeName, instance, ipAddr
 		&api.KVTxnOp{
 			Verb:  api.KVSet,
 			Key:   path.Join(checkPath, "interval"),
-			Value: []byte(interval.String()),
+			Value: []byte(checkDef.TimeInterval.String()),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(checkPath, "type"),
+			Value: []byte(checkDef.Type.String()),
 		},
 		&api.KVTxnOp{
 			Verb:  api.KVSet,
 			Key:   path.Join(checkReportPath, "status"),
 			Value: []byte(CheckStatusPASSING.String()),
 		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(checkReportPath, "type"),
+			Value: []byte(checkDef.Type.String()),
+		},
+	}
+	switch checkDef.Type {
+	case CheckTypeHttp:
+		checkURL := url.URL{Scheme: checkDef.HTTPScheme, Host: tcpAddr, Path: checkDef.HTTPPath}
+		checkOps = append(checkOps,
+			&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(checkPath, "url"), Value: []byte(checkURL.String())},
+			&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(checkPath, "expected_status"), Value: []byte(strings.Join(checkDef.ExpectedStatus, ","))},
+			&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(checkPath, "body_regex"), Value: []byte(checkDef.BodyRegex)},
+			&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(checkPath, "tls_skip_verify"), Value: []byte(strconv.FormatBool(checkDef.TLSSkipVerify))},
+		)
+	case CheckTypeCommand:
+		checkOps = append(checkOps,
+			&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(checkPath, "command"), Value: []byte(checkDef.Command)},
+		)
 	}
 
 	ok, response, _, err := mgr.cc.KV().Txn(checkOps, nil)
@@ -420,48 +642,8 @@ func (mgr *monitoringMgr) unregisterCheck(id string) error {
 	return nil
 }
 
-// CheckFilterFunc defines a filter function for CheckReport
-type CheckFilterFunc func(CheckReport) bool
-
 // listCheckReports can return a filtered checks reports list if defined filter function. Otherwise, it returns the full check reports.
 func (mgr *monitoringMgr) listCheckReports(f CheckFilterFunc) ([]CheckReport, error) {
 	log.Debugf("List check reports")
-	keys, _, err := mgr.cc.KV().Keys(path.Join(consulutil.MonitoringKVPrefix, "reports")+"/", "/", nil)
-	if err != nil {
-		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
-	}
-	checkReports := make([]CheckReport, 0)
-	for _, key := range keys {
-		id := path.Base(key)
-		check, err := NewCheckFromID(id)
-		if err != nil {
-			return nil, err
-		}
-
-		kvp, _, err := mgr.cc.KV().Get(path.Join(key, "status"), nil)
-		if err != nil {
-			return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
-		}
-		if kvp != nil && len(kvp.Value) > 0 {
-			check.Report.Status, err = ParseCheckStatus(string(kvp.Value))
-			if err != nil {
-				return nil, err
-			}
-		}
-		checkReports = append(checkReports, check.Report)
-	}
-	return filter(checkReports, f), nil
-}
-
-func filter(tab []CheckReport, f CheckFilterFunc) []CheckReport {
-	if f == nil {
-		return tab
-	}
-	res := make([]CheckReport, 0)
-	for _, v := range tab {
-		if f(v) {
-			res = append(res, v)
-		}
-	}
-	return res
+	return ListCheckReports(mgr.cc.KV(), f)
 }
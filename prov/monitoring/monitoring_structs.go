@@ -15,9 +15,18 @@
 package monitoring
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/sshutil"
 )
 
 //go:generate go-enum -f=monitoring_structs.go --lower
@@ -35,24 +44,124 @@ const (
 	CRITICAL
 )
 
+// CheckType x ENUM(
+// tcp,
+// http,
+// command
+// )
+type CheckType int
+
+// HealPolicy x ENUM(
+// none,
+// restart,
+// reinstall
+// )
+type HealPolicy int
+
+// CheckDefinition defines how instances of a node are checked
+type CheckDefinition struct {
+	Type         CheckType
+	TimeInterval time.Duration
+	// Port is the checked TCP port, the HTTP server port or the SSH port for command checks
+	Port int
+	// HTTPScheme is either http or https
+	HTTPScheme string
+	HTTPPath   string
+	// ExpectedStatus contains expected HTTP status codes (ex: 200) or classes (ex: 2xx), any 2xx status is expected if empty
+	ExpectedStatus []string
+	// BodyRegex is an optional regular expression the HTTP response body should match
+	BodyRegex     string
+	TLSSkipVerify bool
+	// Command is a command run over SSH, it should exit with a zero status
+	Command string
+}
+
 // Check represents a registered check
 type Check struct {
-	ID           string
-	TCPAddress   string
-	TimeInterval time.Duration
-	Report       CheckReport
+	ID   string
+	Type CheckType
+	// TCPAddress is the checked address for TCP checks or the SSH address for command checks
+	TCPAddress     string
+	URL            string
+	ExpectedStatus []string
+	BodyRegex      string
+	TLSSkipVerify  bool
+	Command        string
+	TimeInterval   time.Duration
+	Paused         bool
+	Report         CheckReport
 
 	stop     bool
 	stopLock sync.Mutex
 	chStop   chan struct{}
 	timeout  time.Duration
 	ctx      context.Context
+
+	consecutiveCriticals int
+	healPolicy           HealPolicy
+	healThreshold        int
+
+	// lastCheckStored is the last check date stored in Consul, Report.LastCheck is only kept in memory between two updates
+	lastCheckStored time.Time
+
+	httpClient *http.Client
+	bodyRegexp *regexp.Regexp
+	sshClient  *sshutil.SSHClient
 }
 
 // CheckReport represents a node check report including its status
 type CheckReport struct {
-	DeploymentID string
-	NodeName     string
-	Instance     string
-	Status       CheckStatus
+	DeploymentID string      `json:"deployment_id"`
+	NodeName     string      `json:"node"`
+	Instance     string      `json:"instance"`
+	Type         CheckType   `json:"type"`
+	Status       CheckStatus `json:"status"`
+	LastError    string      `json:"last_error,omitempty"`
+	LastCheck    time.Time   `json:"last_check"`
+	Paused       bool        `json:"paused"`
+}
+
+// CheckHistoryEntry represents a status change of a check
+type CheckHistoryEntry struct {
+	Date   time.Time   `json:"date"`
+	Status CheckStatus `json:"status"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// MarshalJSON is used to represent this enumeration as a string instead of an int
+func (cs CheckStatus) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(`"`)
+	buffer.WriteString(cs.String())
+	buffer.WriteString(`"`)
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON is used to read this enumeration from a string
+func (cs *CheckStatus) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return errors.Wrap(err, "failed to unmarshal CheckStatus as string")
+	}
+	*cs, err = ParseCheckStatus(strings.ToUpper(s))
+	return errors.Wrap(err, "failed to parse CheckStatus from JSON input")
+}
+
+// MarshalJSON is used to represent this enumeration as a string instead of an int
+func (ct CheckType) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(`"`)
+	buffer.WriteString(ct.String())
+	buffer.WriteString(`"`)
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON is used to read this enumeration from a string
+func (ct *CheckType) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return errors.Wrap(err, "failed to unmarshal CheckType as string")
+	}
+	*ct, err = ParseCheckType(strings.ToLower(s))
+	return errors.Wrap(err, "failed to parse CheckType from JSON input")
 }
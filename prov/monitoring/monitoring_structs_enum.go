@@ -58,3 +58,87 @@ func ParseCheckStatus(name string) (CheckStatus, error) {
 	}
 	return CheckStatus(0), fmt.Errorf("%s is not a valid CheckStatus", name)
 }
+
+const (
+	// HealPolicyNone is a HealPolicy of type None
+	HealPolicyNone HealPolicy = iota
+	// HealPolicyRestart is a HealPolicy of type Restart
+	HealPolicyRestart
+	// HealPolicyReinstall is a HealPolicy of type Reinstall
+	HealPolicyReinstall
+)
+
+const _HealPolicyName = "nonerestartreinstall"
+
+var _HealPolicyMap = map[HealPolicy]string{
+	0: _HealPolicyName[0:4],
+	1: _HealPolicyName[4:11],
+	2: _HealPolicyName[11:20],
+}
+
+// String implements the Stringer interface.
+func (x HealPolicy) String() string {
+	if str, ok := _HealPolicyMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("HealPolicy(%d)", x)
+}
+
+var _HealPolicyValue = map[string]HealPolicy{
+	_HealPolicyName[0:4]:                    0,
+	strings.ToLower(_HealPolicyName[0:4]):   0,
+	_HealPolicyName[4:11]:                   1,
+	strings.ToLower(_HealPolicyName[4:11]):  1,
+	_HealPolicyName[11:20]:                  2,
+	strings.ToLower(_HealPolicyName[11:20]): 2,
+}
+
+// ParseHealPolicy attempts to convert a string to a HealPolicy
+func ParseHealPolicy(name string) (HealPolicy, error) {
+	if x, ok := _HealPolicyValue[name]; ok {
+		return x, nil
+	}
+	return HealPolicy(0), fmt.Errorf("%s is not a valid HealPolicy", name)
+}
+
+const (
+	// CheckTypeTcp is a CheckType of type Tcp
+	CheckTypeTcp CheckType = iota
+	// CheckTypeHttp is a CheckType of type Http
+	CheckTypeHttp
+	// CheckTypeCommand is a CheckType of type Command
+	CheckTypeCommand
+)
+
+const _CheckTypeName = "tcphttpcommand"
+
+var _CheckTypeMap = map[CheckType]string{
+	0: _CheckTypeName[0:3],
+	1: _CheckTypeName[3:7],
+	2: _CheckTypeName[7:14],
+}
+
+// String implements the Stringer interface.
+func (x CheckType) String() string {
+	if str, ok := _CheckTypeMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("CheckType(%d)", x)
+}
+
+var _CheckTypeValue = map[string]CheckType{
+	_CheckTypeName[0:3]:                   0,
+	strings.ToLower(_CheckTypeName[0:3]):  0,
+	_CheckTypeName[3:7]:                   1,
+	strings.ToLower(_CheckTypeName[3:7]):  1,
+	_CheckTypeName[7:14]:                  2,
+	strings.ToLower(_CheckTypeName[7:14]): 2,
+}
+
+// ParseCheckType attempts to convert a string to a CheckType
+func ParseCheckType(name string) (CheckType, error) {
+	if x, ok := _CheckTypeValue[name]; ok {
+		return x, nil
+	}
+	return CheckType(0), fmt.Errorf("%s is not a valid CheckType", name)
+}
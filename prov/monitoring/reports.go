@@ -0,0 +1,215 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/helper/consulutil"
+)
+
+// maxCheckHistoryEntries is the number of status changes kept in the history of a check
+const maxCheckHistoryEntries = 20
+
+// CheckFilterFunc defines a filter function for CheckReport
+type CheckFilterFunc func(CheckReport) bool
+
+// ListCheckReports can return a filtered checks reports list if defined filter function. Otherwise, it returns the full check reports.
+func ListCheckReports(kv *api.KV, f CheckFilterFunc) ([]CheckReport, error) {
+	keys, _, err := kv.Keys(path.Join(consulutil.MonitoringKVPrefix, "reports")+"/", "/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	checkReports := make([]CheckReport, 0)
+	for _, key := range keys {
+		report, err := readCheckReport(kv, path.Base(key))
+		if err != nil {
+			return nil, err
+		}
+		if report != nil {
+			checkReports = append(checkReports, *report)
+		}
+	}
+	return filter(checkReports, f), nil
+}
+
+// GetCheckReport returns the report of the check of a given node instance
+//
+// If there is no such check then a nil report is returned.
+func GetCheckReport(kv *api.KV, deploymentID, nodeName, instance string) (*CheckReport, error) {
+	return readCheckReport(kv, buildID(deploymentID, nodeName, instance))
+}
+
+func readCheckReport(kv *api.KV, id string) (*CheckReport, error) {
+	check, err := NewCheckFromID(id)
+	if err != nil {
+		return nil, err
+	}
+	reportPath := path.Join(consulutil.MonitoringKVPrefix, "reports", id)
+	kvps, _, err := kv.List(reportPath+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	var hasStatus bool
+	for _, kvp := range kvps {
+		value := string(kvp.Value)
+		switch kvp.Key {
+		case path.Join(reportPath, "status"):
+			if value == "" {
+				continue
+			}
+			hasStatus = true
+			check.Report.Status, err = ParseCheckStatus(value)
+		case path.Join(reportPath, "type"):
+			if value == "" {
+				continue
+			}
+			check.Report.Type, err = ParseCheckType(value)
+		case path.Join(reportPath, "last_error"):
+			check.Report.LastError = value
+		case path.Join(reportPath, "last_check"):
+			if value == "" {
+				continue
+			}
+			check.Report.LastCheck, err = time.Parse(time.RFC3339Nano, value)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid report value for key %q", kvp.Key)
+		}
+	}
+	if !hasStatus {
+		return nil, nil
+	}
+
+	kvp, _, err := kv.Get(path.Join(consulutil.MonitoringKVPrefix, "checks", id, "paused"), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp != nil && len(kvp.Value) > 0 {
+		check.Report.Paused, err = strconv.ParseBool(string(kvp.Value))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid paused flag for check %q", id)
+		}
+	}
+	return &check.Report, nil
+}
+
+func filter(tab []CheckReport, f CheckFilterFunc) []CheckReport {
+	if f == nil {
+		return tab
+	}
+	res := make([]CheckReport, 0)
+	for _, v := range tab {
+		if f(v) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// ListCheckHistory returns the status changes of the check of a given node instance sorted from the oldest to the latest
+func ListCheckHistory(kv *api.KV, deploymentID, nodeName, instance string) ([]CheckHistoryEntry, error) {
+	id := buildID(deploymentID, nodeName, instance)
+	kvps, _, err := kv.List(path.Join(consulutil.MonitoringKVPrefix, "reports", id, "history")+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	history := make([]CheckHistoryEntry, 0, len(kvps))
+	for _, kvp := range kvps {
+		var entry CheckHistoryEntry
+		if err = json.Unmarshal(kvp.Value, &entry); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal history entry of check %q", id)
+		}
+		history = append(history, entry)
+	}
+	return history, nil
+}
+
+func storeCheckHistoryEntry(kv *api.KV, id string, entry CheckHistoryEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal history entry of check %q", id)
+	}
+	historyPrefix := path.Join(consulutil.MonitoringKVPrefix, "reports", id, "history")
+	// Zero-padded timestamps keep entries sorted by date
+	_, err = kv.Put(&api.KVPair{Key: path.Join(historyPrefix, fmt.Sprintf("%020d", entry.Date.UnixNano())), Value: b}, nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	keys, _, err := kv.Keys(historyPrefix+"/", "/", nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if len(keys) <= maxCheckHistoryEntries {
+		return nil
+	}
+	sort.Strings(keys)
+	for _, key := range keys[:len(keys)-maxCheckHistoryEntries] {
+		if _, err = kv.Delete(key, nil); err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+	}
+	return nil
+}
+
+// storeLastCheckDate stores the date of the last run of a check unless its report has been removed in between
+func storeLastCheckDate(kv *api.KV, id string, date time.Time) error {
+	reportPath := path.Join(consulutil.MonitoringKVPrefix, "reports", id)
+	ops := api.KVTxnOps{
+		// A get operation fails the transaction if the key does not exist
+		&api.KVTxnOp{
+			Verb: api.KVGet,
+			Key:  path.Join(reportPath, "status"),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(reportPath, "last_check"),
+			Value: []byte(date.Format(time.RFC3339Nano)),
+		},
+	}
+	_, _, _, err := kv.Txn(ops, nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+// SetCheckPaused pauses or resumes the check of a given node instance
+//
+// A paused check is not run anymore but it stays registered, this allows to put an instance under maintenance.
+func SetCheckPaused(kv *api.KV, deploymentID, nodeName, instance string, paused bool) error {
+	id := buildID(deploymentID, nodeName, instance)
+	err := consulutil.StoreConsulKeyAsString(path.Join(consulutil.MonitoringKVPrefix, "checks", id, "paused"), strconv.FormatBool(paused))
+	if err != nil {
+		return err
+	}
+	action := "resumed"
+	if paused {
+		action = "paused"
+	}
+	ctx := events.NewContext(context.Background(), events.LogOptionalFields{
+		events.InstanceID: instance,
+		events.NodeID:     nodeName,
+	})
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, deploymentID).Registerf("Monitoring Check %s for node (%s-%s)", action, nodeName, instance)
+	return nil
+}
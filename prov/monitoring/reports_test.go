@@ -0,0 +1,88 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReportJSON(t *testing.T) {
+	report := CheckReport{DeploymentID: "dep", NodeName: "Compute", Instance: "0", Type: CheckTypeCommand, Status: CheckStatusCRITICAL, LastError: "command failed"}
+	b, err := json.Marshal(report)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"type":"command"`)
+	require.Contains(t, string(b), `"status":"CRITICAL"`)
+
+	var res CheckReport
+	require.NoError(t, json.Unmarshal(b, &res))
+	require.Equal(t, report, res)
+
+	require.Error(t, json.Unmarshal([]byte(`{"status":"UNKNOWN"}`), &res))
+}
+
+func testCheckReportsHistoryAndPause(t *testing.T, client *api.Client) {
+	kv := client.KV()
+	dep := "monitoring8"
+
+	// History is pruned to keep the latest entries
+	id := buildID(dep, "Compute2", "0")
+	start := time.Now()
+	for i := 0; i < maxCheckHistoryEntries+5; i++ {
+		status := CheckStatusCRITICAL
+		if i%2 == 1 {
+			status = CheckStatusPASSING
+		}
+		err := storeCheckHistoryEntry(kv, id, CheckHistoryEntry{Date: start.Add(time.Duration(i) * time.Second), Status: status})
+		require.NoError(t, err)
+	}
+	history, err := ListCheckHistory(kv, dep, "Compute2", "0")
+	require.NoError(t, err)
+	require.Len(t, history, maxCheckHistoryEntries)
+	require.True(t, history[0].Date.Equal(start.Add(5*time.Second)), "oldest entries should have been pruned")
+	require.Equal(t, CheckStatusPASSING, history[0].Status)
+
+	// A history alone is not a check report
+	report, err := GetCheckReport(kv, dep, "Compute2", "0")
+	require.NoError(t, err)
+	require.Nil(t, report)
+
+	err = defaultMonManager.registerCheck(dep, "Compute1", "0", "1.2.3.4", CheckDefinition{Type: CheckTypeTcp, Port: 22, TimeInterval: time.Second})
+	require.NoError(t, err)
+	defer defaultMonManager.flagCheckForRemoval(dep, "Compute1", "0")
+
+	report, err = GetCheckReport(kv, dep, "Compute1", "0")
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Equal(t, CheckTypeTcp, report.Type)
+	require.False(t, report.Paused)
+
+	err = SetCheckPaused(kv, dep, "Compute1", "0", true)
+	require.NoError(t, err)
+	report, err = GetCheckReport(kv, dep, "Compute1", "0")
+	require.NoError(t, err)
+	require.True(t, report.Paused)
+
+	reports, err := ListCheckReports(kv, func(cr CheckReport) bool {
+		return cr.DeploymentID == dep
+	})
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	require.Equal(t, "Compute1", reports[0].NodeName)
+}
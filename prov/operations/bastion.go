@@ -0,0 +1,76 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/sshutil"
+)
+
+// GetInstanceBastionHost returns the bastion host defined in the credentials of the endpoint capability of a given instance.
+//
+// Returned bastion host is nil if no bastion is defined.
+func GetInstanceBastionHost(kv *api.KV, deploymentID, nodeName, instance string) (*sshutil.BastionHost, error) {
+	bastion, err := deployments.GetInstanceCapabilityAttributeValue(kv, deploymentID, nodeName, instance, "endpoint", "credentials", "bastion")
+	if err != nil || bastion == nil || bastion.RawString() == "" {
+		return nil, err
+	}
+	bastionMap, err := cast.ToStringMapE(bastion.Value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid bastion definition for instance %q of node %q", instance, nodeName)
+	}
+	return toBastionHost(bastionMap)
+}
+
+// toBastionHost converts a yorc.datatypes.BastionHost complex value into a sshutil.BastionHost
+func toBastionHost(bastionMap map[string]interface{}) (*sshutil.BastionHost, error) {
+	if len(bastionMap) == 0 {
+		return nil, nil
+	}
+	b := &sshutil.BastionHost{
+		Host:     cast.ToString(bastionMap["host"]),
+		User:     cast.ToString(bastionMap["user"]),
+		Password: cast.ToString(bastionMap["token"]),
+	}
+	if b.Host == "" {
+		return nil, errors.New(`"host" is required for a bastion definition`)
+	}
+	var err error
+	if p, ok := bastionMap["port"]; ok && cast.ToString(p) != "" {
+		b.Port, err = cast.ToIntE(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid port for bastion %q", b.Host)
+		}
+	}
+	keys := cast.ToStringSlice(bastionMap["keys"])
+	if len(keys) > 0 {
+		b.PrivateKey = keys[0]
+	}
+	if nested, ok := bastionMap["bastion"]; ok && nested != nil {
+		nestedMap, err := cast.ToStringMapE(nested)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid bastion definition for bastion %q", b.Host)
+		}
+		b.Bastion, err = toBastionHost(nestedMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
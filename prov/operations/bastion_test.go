@@ -0,0 +1,82 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/testutil"
+)
+
+func TestToBastionHost(t *testing.T) {
+	t.Parallel()
+	b, err := toBastionHost(nil)
+	require.NoError(t, err)
+	require.Nil(t, b)
+
+	b, err = toBastionHost(map[string]interface{}{
+		"host": "b2",
+		"port": "2222",
+		"user": "centos",
+		"keys": []interface{}{"~/.ssh/b2.pem"},
+		"bastion": map[string]interface{}{
+			"host":  "b1",
+			"token": "pass",
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	require.Equal(t, "b2", b.Host)
+	require.Equal(t, 2222, b.Port)
+	require.Equal(t, "centos", b.User)
+	require.Equal(t, "~/.ssh/b2.pem", b.PrivateKey)
+	require.NotNil(t, b.Bastion)
+	require.Equal(t, "b1", b.Bastion.Host)
+	require.Equal(t, "pass", b.Bastion.Password)
+	require.Nil(t, b.Bastion.Bastion)
+
+	_, err = toBastionHost(map[string]interface{}{"user": "centos"})
+	require.Error(t, err, "a bastion without host should be rejected")
+	_, err = toBastionHost(map[string]interface{}{"host": "b1", "port": "ssh"})
+	require.Error(t, err, "a bastion with an invalid port should be rejected")
+}
+
+func testGetInstanceBastionHost(t *testing.T, kv *api.KV) {
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := deployments.StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/bastion.yaml")
+	require.NoError(t, err, "Can't store deployment definition")
+
+	for _, nodeName := range []string{"ComputeNoCredentials", "ComputeNoBastion"} {
+		b, err := GetInstanceBastionHost(kv, deploymentID, nodeName, "0")
+		require.NoError(t, err, "unexpected error for node %q without bastion", nodeName)
+		require.Nil(t, b, "no bastion expected for node %q", nodeName)
+	}
+
+	b, err := GetInstanceBastionHost(kv, deploymentID, "ComputeBastion", "0")
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	require.Equal(t, "10.0.0.1", b.Host)
+	require.Equal(t, 2222, b.Port)
+	require.Equal(t, "~/.ssh/bastion.pem", b.PrivateKey)
+	require.NotNil(t, b.Bastion)
+	require.Equal(t, "bastion.example.com", b.Bastion.Host)
+	require.Equal(t, "cloud", b.Bastion.User)
+	require.Equal(t, "pass", b.Bastion.Password)
+}
@@ -38,6 +38,8 @@ type EnvInput struct {
 	Value        string
 	InstanceName string
 	IsSecret     bool
+	// Artifact is set when the input value is the resolved location of an artifact (get_artifact function)
+	Artifact *deployments.ArtifactReference
 }
 
 func (ei EnvInput) String() string {
@@ -119,7 +121,7 @@ func ResolveInputsWithInstances(kv *api.KV, deploymentID, nodeName, taskID strin
 				return nil, nil, err
 			}
 			for i, iv := range inputValues {
-				envInputs = append(envInputs, &EnvInput{Name: input, InstanceName: GetInstanceName(iv.NodeName, iv.InstanceName), Value: iv.Value, IsSecret: iv.IsSecret})
+				envInputs = append(envInputs, &EnvInput{Name: input, InstanceName: GetInstanceName(iv.NodeName, iv.InstanceName), Value: iv.Value, IsSecret: iv.IsSecret, Artifact: iv.Artifact})
 				if i == 0 {
 					varInputsNames = append(varInputsNames, provutil.SanitizeForShell(input))
 				}
@@ -0,0 +1,65 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policies
+
+import (
+	"context"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+)
+
+// Supported values of the affinity property of placement policies
+const (
+	// AffinityColocate means that instances of targeted nodes should be placed on the same hosts when possible
+	AffinityColocate = "colocate"
+	// AffinityAntiColocate means that two instances of targeted nodes should never be placed on the same host
+	AffinityAntiColocate = "anti-colocate"
+)
+
+type placementHandler struct{}
+
+func (h *placementHandler) CheckPolicy(ctx context.Context, conf config.Configuration, deploymentID, policyName string) error {
+	cc, err := conf.GetConsulClient()
+	if err != nil {
+		return err
+	}
+	kv := cc.KV()
+	_, err = checkTargetsExist(kv, deploymentID, policyName)
+	if err != nil {
+		return err
+	}
+	_, err = GetPlacementAffinity(kv, deploymentID, policyName)
+	return err
+}
+
+// GetPlacementAffinity returns the affinity defined by a placement policy
+//
+// An empty string is returned if the policy does not define any affinity.
+func GetPlacementAffinity(kv *api.KV, deploymentID, policyName string) (string, error) {
+	value, err := deployments.GetPolicyPropertyValue(kv, deploymentID, policyName, "affinity")
+	if err != nil || value == nil {
+		return "", err
+	}
+	switch affinity := value.RawString(); affinity {
+	case "", AffinityColocate, AffinityAntiColocate:
+		return affinity, nil
+	default:
+		return "", errors.Errorf("unsupported affinity %q, supported values are %q and %q", affinity, AffinityColocate, AffinityAntiColocate)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policies provides the builtin handlers of TOSCA policies and allows to check
+// the policies of a deployment against the registered handlers.
+package policies
+
+import (
+	"context"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov"
+	"github.com/ystia/yorc/registry"
+)
+
+func init() {
+	reg := registry.GetRegistry()
+	reg.RegisterPolicyHandler([]string{"tosca.policies.Placement"}, &placementHandler{}, registry.BuiltinOrigin)
+	reg.RegisterPolicyHandler([]string{"tosca.policies.Scaling"}, &scalingHandler{}, registry.BuiltinOrigin)
+}
+
+// CheckDeploymentPolicies checks all policies of a deployment against their registered handlers
+//
+// The handler of a policy is the first one registered for its type or for the closest of its parent types.
+// Policies without any handler are ignored.
+func CheckDeploymentPolicies(ctx context.Context, cfg config.Configuration, kv *api.KV, deploymentID string) error {
+	policies, err := deployments.GetPolicies(kv, deploymentID)
+	if err != nil {
+		return err
+	}
+	for _, policy := range policies {
+		handler, err := getPolicyHandler(kv, deploymentID, policy)
+		if err != nil {
+			return err
+		}
+		if handler == nil {
+			log.Debugf("No handler registered for policy %q of deployment %q, ignoring it", policy, deploymentID)
+			continue
+		}
+		err = handler.CheckPolicy(ctx, cfg, deploymentID, policy)
+		if err != nil {
+			return errors.Wrapf(err, "policy %q is invalid", policy)
+		}
+	}
+	return nil
+}
+
+func getPolicyHandler(kv *api.KV, deploymentID, policyName string) (prov.PolicyHandler, error) {
+	policyType, err := deployments.GetPolicyType(kv, deploymentID, policyName)
+	if err != nil {
+		return nil, err
+	}
+	reg := registry.GetRegistry()
+	for policyType != "" {
+		handler, err := reg.GetPolicyHandler(policyType)
+		if err == nil {
+			return handler, nil
+		}
+		policyType, err = deployments.GetParentType(kv, deploymentID, policyType)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// checkTargetsExist checks that all targets of a policy are existing nodes
+func checkTargetsExist(kv *api.KV, deploymentID, policyName string) ([]string, error) {
+	targets, err := deployments.GetPolicyTargets(kv, deploymentID, policyName)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		exist, err := deployments.DoesNodeExist(kv, deploymentID, target)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			return nil, errors.Errorf("target node %q does not exist", target)
+		}
+	}
+	return targets, nil
+}
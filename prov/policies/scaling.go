@@ -0,0 +1,74 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policies
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+)
+
+type scalingHandler struct{}
+
+func (h *scalingHandler) CheckPolicy(ctx context.Context, conf config.Configuration, deploymentID, policyName string) error {
+	cc, err := conf.GetConsulClient()
+	if err != nil {
+		return err
+	}
+	kv := cc.KV()
+	targets, err := checkTargetsExist(kv, deploymentID, policyName)
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		scalable, err := deployments.HasScalableCapability(kv, deploymentID, target)
+		if err != nil {
+			return err
+		}
+		if !scalable {
+			return errors.Errorf("target node %q must be scalable", target)
+		}
+	}
+	min, err := getUintProperty(kv, deploymentID, policyName, "min_instances")
+	if err != nil {
+		return err
+	}
+	max, err := getUintProperty(kv, deploymentID, policyName, "max_instances")
+	if err != nil {
+		return err
+	}
+	if min >= 0 && max >= 0 && min > max {
+		return errors.Errorf("min_instances (%d) is greater than max_instances (%d)", min, max)
+	}
+	return nil
+}
+
+// getUintProperty returns the value of a policy property as a positive integer or -1 if not set
+func getUintProperty(kv *api.KV, deploymentID, policyName, propertyName string) (int64, error) {
+	value, err := deployments.GetPolicyPropertyValue(kv, deploymentID, policyName, propertyName)
+	if err != nil || value == nil || value.RawString() == "" {
+		return -1, err
+	}
+	v, err := strconv.ParseUint(value.RawString(), 10, 32)
+	if err != nil {
+		return -1, errors.Errorf("invalid value %q for property %q, a positive integer is expected", value.RawString(), propertyName)
+	}
+	return int64(v), nil
+}
@@ -122,3 +122,11 @@ type AsyncOperation struct {
 type ActionOperator interface {
 	ExecAction(ctx context.Context, conf config.Configuration, taskID, deploymentID string, action *Action) (deregister bool, err error)
 }
+
+// PolicyHandler is the interface for enforcing TOSCA policies
+//
+// CheckPolicy checks that the given policy of a deployment is valid and could be enforced.
+// It is called before the deployment (or its update) is accepted, an error means that the deployment is rejected.
+type PolicyHandler interface {
+	CheckPolicy(ctx context.Context, conf config.Configuration, deploymentID, policyName string) error
+}
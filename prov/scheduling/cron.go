@@ -0,0 +1,198 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduling
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// A CronSchedule is a parsed cron expression
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are used to implement the standard cron behavior: if both day of month and day of week
+	// are restricted then a day matches if any of them matches
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is accepted as an alias of sunday
+	cronDow = cronField{name: "day of week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCronExpression parses a standard cron expression.
+//
+// Expressions are composed of 5 space-separated fields: minute, hour, day of month, month and day of week.
+// Each field accepts '*', values, ranges (1-5), lists (1,3,5) and steps (*/15 or 0-30/10).
+// Months and days of week could also be given using their three first letters (JAN, MON).
+// The @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly descriptors are also supported.
+func ParseCronExpression(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if d, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("invalid cron expression %q: expecting 5 fields got %d", expr, len(fields))
+	}
+	cs := new(CronSchedule)
+	var err error
+	if cs.minute, _, err = parseCronField(fields[0], cronMinute); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if cs.hour, _, err = parseCronField(fields[1], cronHour); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if cs.dom, cs.domStar, err = parseCronField(fields[2], cronDom); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if cs.month, _, err = parseCronField(fields[3], cronMonth); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if cs.dow, cs.dowStar, err = parseCronField(fields[4], cronDow); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	// Sunday could be given as 0 or 7
+	if cs.dow&(1<<7) != 0 {
+		cs.dow |= 1
+	}
+	return cs, nil
+}
+
+// parseCronField returns a bit set of the values matched by a field and if the field starts with a '*'
+func parseCronField(field string, f cronField) (uint64, bool, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rangeAndStep := strings.SplitN(item, "/", 2)
+		var start, end uint
+		step := uint(1)
+		var err error
+		switch {
+		case rangeAndStep[0] == "*":
+			start, end = f.min, f.max
+		case strings.Contains(rangeAndStep[0], "-"):
+			bounds := strings.SplitN(rangeAndStep[0], "-", 2)
+			if start, err = parseCronValue(bounds[0], f); err != nil {
+				return 0, false, err
+			}
+			if end, err = parseCronValue(bounds[1], f); err != nil {
+				return 0, false, err
+			}
+		default:
+			if start, err = parseCronValue(rangeAndStep[0], f); err != nil {
+				return 0, false, err
+			}
+			end = start
+			if len(rangeAndStep) == 2 {
+				// a/step is equivalent to a-max/step
+				end = f.max
+			}
+		}
+		if len(rangeAndStep) == 2 {
+			s, err := strconv.ParseUint(rangeAndStep[1], 10, 8)
+			if err != nil || s == 0 {
+				return 0, false, errors.Errorf("invalid step %q for %s field", rangeAndStep[1], f.name)
+			}
+			step = uint(s)
+		}
+		if start > end {
+			return 0, false, errors.Errorf("invalid range %q for %s field", item, f.name)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, strings.HasPrefix(field, "*"), nil
+}
+
+func parseCronValue(value string, f cronField) (uint, error) {
+	if v, ok := f.names[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+	v, err := strconv.ParseUint(value, 10, 8)
+	if err != nil {
+		return 0, errors.Errorf("invalid value %q for %s field", value, f.name)
+	}
+	if uint(v) < f.min || uint(v) > f.max {
+		return 0, errors.Errorf("value %d out of range [%d-%d] for %s field", v, f.min, f.max, f.name)
+	}
+	return uint(v), nil
+}
+
+// Next returns the first activation time strictly after the given time, using the location of the given time.
+//
+// A zero time is returned if there is no activation time in the next five years (for instance for February 30th).
+func (cs *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if cs.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !cs.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if cs.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if cs.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (cs *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := cs.dom&(1<<uint(t.Day())) != 0
+	dowMatch := cs.dow&(1<<uint(t.Weekday())) != 0
+	if cs.domStar || cs.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduling
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	base := time.Date(2018, time.July, 13, 10, 25, 30, 0, time.UTC)
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"EveryMinute", "* * * * *", base, time.Date(2018, time.July, 13, 10, 26, 0, 0, time.UTC)},
+		{"EveryQuarter", "*/15 * * * *", base, time.Date(2018, time.July, 13, 10, 30, 0, 0, time.UTC)},
+		{"Daily", "@daily", base, time.Date(2018, time.July, 14, 0, 0, 0, 0, time.UTC)},
+		{"DailyAt2", "0 2 * * *", base, time.Date(2018, time.July, 14, 2, 0, 0, 0, time.UTC)},
+		{"SameMinuteExcluded", "25 10 * * *", base, time.Date(2018, time.July, 14, 10, 25, 0, 0, time.UTC)},
+		{"RangeAndList", "0 8-10,18 * * *", base, time.Date(2018, time.July, 13, 18, 0, 0, 0, time.UTC)},
+		{"WeekDays", "30 9 * * MON-FRI", base, time.Date(2018, time.July, 16, 9, 30, 0, 0, time.UTC)},
+		{"SundayAs7", "0 0 * * 7", base, time.Date(2018, time.July, 15, 0, 0, 0, 0, time.UTC)},
+		{"MonthName", "0 0 1 jan *", base, time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"DomOrDow", "0 0 20 * fri", base, time.Date(2018, time.July, 20, 0, 0, 0, 0, time.UTC)},
+		{"LeapDay", "0 0 29 2 *", base, time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"Never", "0 0 30 2 *", base, time.Time{}},
+		{"Timezone", "0 2 * * *", base.In(paris), time.Date(2018, time.July, 14, 2, 0, 0, 0, paris)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs, err := ParseCronExpression(tt.expr)
+			require.NoError(t, err)
+			got := cs.Next(tt.from)
+			assert.True(t, tt.want.Equal(got), "Next() = %v, want %v", got, tt.want)
+		})
+	}
+}
+
+func TestParseCronExpressionErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@every 5m"} {
+		_, err := ParseCronExpression(expr)
+		assert.Error(t, err, "expression %q", expr)
+	}
+}
+
+func TestScheduleJSON(t *testing.T) {
+	s := Schedule{ID: "s1", DeploymentID: "dep", WorkflowName: "backup", Cron: "@daily", OverlapPolicy: OverlapPolicyQueue}
+	b, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"overlap_policy":"queue"`)
+
+	var read Schedule
+	require.NoError(t, json.Unmarshal(b, &read))
+	assert.Equal(t, s, read)
+	require.NoError(t, read.Validate())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"overlap_policy":"unknown"}`), &read))
+	read.Timezone = "Not/AZone"
+	assert.Error(t, read.Validate())
+	read.Timezone = ""
+	read.WorkflowName = ""
+	assert.Error(t, read.Validate())
+}
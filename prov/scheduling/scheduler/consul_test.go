@@ -51,5 +51,8 @@ func TestRunConsulSchedulingPackageTests(t *testing.T) {
 		t.Run("testUnregisterAction", func(t *testing.T) {
 			testUnregisterAction(t, client)
 		})
+		t.Run("testScheduledWorkflowOverlapPolicies", func(t *testing.T) {
+			testScheduledWorkflowOverlapPolicies(t, client)
+		})
 	})
 }
@@ -0,0 +1,314 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/helper/metricsutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/scheduling"
+	"github.com/ystia/yorc/tasks"
+)
+
+// queuedRunCheckInterval is the interval at which the end of a previous run is checked for schedules with a queued run
+var queuedRunCheckInterval = 10 * time.Second
+
+type scheduledWorkflow struct {
+	scheduling.Schedule
+	kv           *api.KV
+	cron         *scheduling.CronSchedule
+	location     *time.Location
+	latestTaskID string
+	queuedRun    bool
+
+	stopScheduling     bool
+	stopSchedulingLock sync.Mutex
+	chStop             chan struct{}
+}
+
+func newScheduledWorkflow(kv *api.KV, s *scheduling.Schedule) (*scheduledWorkflow, error) {
+	sw := &scheduledWorkflow{Schedule: *s, kv: kv}
+	var err error
+	sw.cron, err = scheduling.ParseCronExpression(s.Cron)
+	if err != nil {
+		return nil, err
+	}
+	sw.location, err = s.Location()
+	if err != nil {
+		return nil, err
+	}
+	sw.latestTaskID, err = scheduling.GetScheduleLatestTaskID(kv, s.ID)
+	return sw, err
+}
+
+func (sw *scheduledWorkflow) start() {
+	sw.stopSchedulingLock.Lock()
+	defer sw.stopSchedulingLock.Unlock()
+
+	sw.chStop = make(chan struct{})
+	sw.stopScheduling = false
+	go sw.schedule()
+}
+
+func (sw *scheduledWorkflow) stop() {
+	sw.stopSchedulingLock.Lock()
+	defer sw.stopSchedulingLock.Unlock()
+
+	if !sw.stopScheduling {
+		sw.stopScheduling = true
+		close(sw.chStop)
+	}
+}
+
+func (sw *scheduledWorkflow) schedule() {
+	log.Debugf("Scheduling workflow %q of deployment %q with cron expression %q", sw.WorkflowName, sw.DeploymentID, sw.Cron)
+	next := sw.cron.Next(time.Now().In(sw.location))
+	if next.IsZero() {
+		log.Printf("[WARNING] Schedule %q of deployment %q will never be triggered with cron expression %q", sw.ID, sw.DeploymentID, sw.Cron)
+		return
+	}
+	timer := time.NewTimer(time.Until(next))
+	queueTicker := time.NewTicker(queuedRunCheckInterval)
+	defer queueTicker.Stop()
+	for {
+		select {
+		case <-sw.chStop:
+			log.Debugf("Stop scheduling workflow with schedule id:%s", sw.ID)
+			timer.Stop()
+			return
+		case <-timer.C:
+			err := sw.trigger()
+			if err != nil {
+				log.Printf("Failed to trigger schedule %q of deployment %q due to err:%+v", sw.ID, sw.DeploymentID, err)
+			}
+			next = sw.cron.Next(time.Now().In(sw.location))
+			if next.IsZero() {
+				return
+			}
+			timer.Reset(time.Until(next))
+		case <-queueTicker.C:
+			if !sw.queuedRun {
+				continue
+			}
+			living, err := sw.isLatestRunLiving()
+			if err != nil {
+				log.Printf("Failed to check previous run of schedule %q of deployment %q due to err:%+v", sw.ID, sw.DeploymentID, err)
+				continue
+			}
+			if !living {
+				sw.queuedRun = false
+				sw.run("queued run")
+			}
+		}
+	}
+}
+
+func (sw *scheduledWorkflow) isLatestRunLiving() (bool, error) {
+	if sw.latestTaskID == "" {
+		return false, nil
+	}
+	ok, err := tasks.TaskExists(sw.kv, sw.latestTaskID)
+	if err != nil || !ok {
+		return false, err
+	}
+	status, err := tasks.GetTaskStatus(sw.kv, sw.latestTaskID)
+	if err != nil {
+		return false, err
+	}
+	return status == tasks.TaskStatusINITIAL || status == tasks.TaskStatusRUNNING, nil
+}
+
+func (sw *scheduledWorkflow) trigger() error {
+	metrics.IncrCounter(metricsutil.CleanupMetricKey([]string{"scheduling", "schedules", sw.ID, "ticks"}), 1)
+	exists, err := deployments.DoesDeploymentExists(sw.kv, sw.DeploymentID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Printf("Deployment %q does not exist anymore, removing its schedule %q", sw.DeploymentID, sw.ID)
+		return scheduling.DeleteSchedule(sw.kv, sw.ID)
+	}
+
+	living, err := sw.isLatestRunLiving()
+	if err != nil {
+		return err
+	}
+	if !living {
+		sw.run("")
+		return nil
+	}
+
+	logger := events.WithContextOptionalFields(sw.logContext()).NewLogEntry(events.LogLevelINFO, sw.DeploymentID)
+	switch sw.OverlapPolicy {
+	case scheduling.OverlapPolicyQueue:
+		if sw.queuedRun {
+			msg := fmt.Sprintf("a run is already queued after the previous one (task ID: %q)", sw.latestTaskID)
+			logger.Registerf("Workflow %q scheduled run skipped: %s", sw.WorkflowName, msg)
+			return sw.storeRun(scheduling.ScheduleRunSkipped, "", msg)
+		}
+		sw.queuedRun = true
+		msg := fmt.Sprintf("waiting for the end of the previous run (task ID: %q)", sw.latestTaskID)
+		logger.Registerf("Workflow %q scheduled run queued: %s", sw.WorkflowName, msg)
+		return sw.storeRun(scheduling.ScheduleRunQueued, "", msg)
+	case scheduling.OverlapPolicyCancel:
+		previousTaskID := sw.latestTaskID
+		if err = tasks.CancelTask(sw.kv, previousTaskID); err != nil {
+			return err
+		}
+		sw.run(fmt.Sprintf("previous run (task ID: %q) canceled", previousTaskID))
+		return nil
+	default:
+		msg := fmt.Sprintf("the previous run (task ID: %q) is still in progress", sw.latestTaskID)
+		logger.Registerf("Workflow %q scheduled run skipped: %s", sw.WorkflowName, msg)
+		metrics.IncrCounter(metricsutil.CleanupMetricKey([]string{"scheduling", "schedules", sw.ID, "misses"}), 1)
+		return sw.storeRun(scheduling.ScheduleRunSkipped, "", msg)
+	}
+}
+
+// run starts the scheduled workflow and records the run into the schedule history
+func (sw *scheduledWorkflow) run(msg string) {
+	data := map[string]string{
+		"workflowName":    sw.WorkflowName,
+		"continueOnError": strconv.FormatBool(sw.ContinueOnError),
+		"scheduleID":      sw.ID,
+	}
+	taskID, err := defaultScheduler.collector.RegisterTaskWithData(sw.DeploymentID, tasks.TaskTypeCustomWorkflow, data)
+	if err != nil {
+		log.Printf("Failed to start workflow %q of deployment %q for schedule %q: %+v", sw.WorkflowName, sw.DeploymentID, sw.ID, err)
+		events.WithContextOptionalFields(sw.logContext()).NewLogEntry(events.LogLevelERROR, sw.DeploymentID).Registerf("Failed to start scheduled workflow %q: %v", sw.WorkflowName, err)
+		sw.logStoreRunError(sw.storeRun(scheduling.ScheduleRunFailed, "", err.Error()))
+		return
+	}
+	sw.latestTaskID = taskID
+	sw.logStoreRunError(scheduling.SetScheduleLatestTaskID(sw.kv, sw.ID, taskID))
+	log.Debugf("Scheduled workflow %q of deployment %q started with taskID:%q", sw.WorkflowName, sw.DeploymentID, taskID)
+	sw.logStoreRunError(sw.storeRun(scheduling.ScheduleRunStarted, taskID, msg))
+}
+
+func (sw *scheduledWorkflow) storeRun(status, taskID, msg string) error {
+	// Do not recreate the history of a schedule deleted in the meantime
+	s, err := scheduling.GetSchedule(sw.kv, sw.ID)
+	if err != nil || s == nil {
+		return err
+	}
+	return scheduling.StoreScheduleRun(sw.kv, sw.ID, &scheduling.ScheduleRun{Date: time.Now(), Status: status, TaskID: taskID, Message: msg})
+}
+
+func (sw *scheduledWorkflow) logStoreRunError(err error) {
+	if err != nil {
+		log.Printf("Failed to update history of schedule %q of deployment %q: %+v", sw.ID, sw.DeploymentID, err)
+	}
+}
+
+func (sw *scheduledWorkflow) logContext() context.Context {
+	return events.AddLogOptionalFields(context.Background(), events.LogOptionalFields{
+		events.WorkFlowID: sw.WorkflowName,
+	})
+}
+
+// needsRestart checks if a schedule definition changed in a way that requires to reschedule it
+func (sw *scheduledWorkflow) needsRestart(s *scheduling.Schedule) bool {
+	return sw.Cron != s.Cron || sw.Timezone != s.Timezone || sw.OverlapPolicy != s.OverlapPolicy ||
+		sw.WorkflowName != s.WorkflowName || sw.ContinueOnError != s.ContinueOnError
+}
+
+// watchSchedules polls for schedules definitions and starts or stops them accordingly
+func (sc *scheduler) watchSchedules() {
+	schedulesPrefix := path.Join(consulutil.SchedulingKVPrefix, "schedules")
+	var waitIndex uint64
+	for {
+		select {
+		case <-sc.chStopScheduling:
+			log.Debugf("Ending schedules watching has been requested: stop it now.")
+			return
+		case <-sc.chShutdown:
+			log.Debugf("Shutdown has been sent: stop scheduled workflows now.")
+			return
+		default:
+		}
+
+		q := &api.QueryOptions{WaitIndex: waitIndex}
+		keys, rMeta, err := sc.cc.KV().Keys(schedulesPrefix+"/", "/", q)
+		if err != nil {
+			handleError(err)
+			continue
+		}
+		if waitIndex == rMeta.LastIndex {
+			// long pool ended due to a timeout
+			// there is no new items go back to the pooling
+			continue
+		}
+		waitIndex = rMeta.LastIndex
+		sc.updateSchedules(keys)
+	}
+}
+
+func (sc *scheduler) updateSchedules(keys []string) {
+	sc.schedulesLock.Lock()
+	defer sc.schedulesLock.Unlock()
+	found := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		id := path.Base(key)
+		s, err := scheduling.GetSchedule(sc.cc.KV(), id)
+		if err != nil {
+			handleError(err)
+			found[id] = true
+			continue
+		}
+		if s == nil {
+			// Leftover of a deleted schedule
+			scheduling.DeleteSchedule(sc.cc.KV(), id)
+			continue
+		}
+		found[id] = true
+		sw, is := sc.schedules[id]
+		if is && (s.Paused || sw.needsRestart(s)) {
+			log.Debugf("stop schedule id:%q", id)
+			sw.stop()
+			delete(sc.schedules, id)
+			is = false
+		}
+		if !is && !s.Paused {
+			log.Debugf("start schedule id:%q", id)
+			sw, err = newScheduledWorkflow(sc.cc.KV(), s)
+			if err != nil {
+				handleError(errors.Wrapf(err, "failed to start schedule %q", id))
+				continue
+			}
+			sc.schedules[id] = sw
+			sw.start()
+		}
+	}
+	for id, sw := range sc.schedules {
+		if !found[id] {
+			log.Debugf("schedule id:%q has been removed", id)
+			sw.stop()
+			delete(sc.schedules, id)
+		}
+	}
+}
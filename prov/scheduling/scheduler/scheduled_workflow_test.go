@@ -0,0 +1,72 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"path"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/prov/scheduling"
+	"github.com/ystia/yorc/tasks"
+)
+
+func testScheduledWorkflowOverlapPolicies(t *testing.T, client *api.Client) {
+	t.Parallel()
+	kv := client.KV()
+	deploymentID := "dep-" + path.Base(t.Name())
+	_, err := kv.Put(&api.KVPair{Key: path.Join(consulutil.DeploymentKVPrefix, deploymentID, "status"), Value: []byte("DEPLOYED")}, nil)
+	require.NoError(t, err)
+	taskID := "task-" + path.Base(t.Name())
+	_, err = kv.Put(&api.KVPair{Key: path.Join(consulutil.TasksPrefix, taskID, "status"), Value: []byte(strconv.Itoa(int(tasks.TaskStatusRUNNING)))}, nil)
+	require.NoError(t, err)
+
+	for _, policy := range []scheduling.OverlapPolicy{scheduling.OverlapPolicySkip, scheduling.OverlapPolicyQueue} {
+		s := &scheduling.Schedule{ID: "sched-" + policy.String() + "-" + path.Base(t.Name()), DeploymentID: deploymentID, WorkflowName: "backup", Cron: "@daily", OverlapPolicy: policy, Paused: true}
+		require.NoError(t, scheduling.StoreSchedule(kv, s))
+		require.NoError(t, scheduling.SetScheduleLatestTaskID(kv, s.ID, taskID))
+
+		sw, err := newScheduledWorkflow(kv, s)
+		require.NoError(t, err)
+		require.Equal(t, taskID, sw.latestTaskID)
+		require.NoError(t, sw.trigger())
+		require.NoError(t, sw.trigger())
+
+		runs, err := scheduling.ListScheduleRuns(kv, s.ID)
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		if policy == scheduling.OverlapPolicyQueue {
+			require.Equal(t, scheduling.ScheduleRunQueued, runs[0].Status)
+			require.True(t, sw.queuedRun)
+		} else {
+			require.Equal(t, scheduling.ScheduleRunSkipped, runs[0].Status)
+		}
+		require.Equal(t, scheduling.ScheduleRunSkipped, runs[1].Status)
+	}
+
+	// Schedules of removed deployments are deleted on trigger
+	s := &scheduling.Schedule{ID: "sched-removed-" + path.Base(t.Name()), DeploymentID: "removed-" + deploymentID, WorkflowName: "backup", Cron: "@daily", Paused: true}
+	require.NoError(t, scheduling.StoreSchedule(kv, s))
+	sw, err := newScheduledWorkflow(kv, s)
+	require.NoError(t, err)
+	require.NoError(t, sw.trigger())
+	removed, err := scheduling.GetSchedule(kv, s.ID)
+	require.NoError(t, err)
+	require.Nil(t, removed)
+}
@@ -42,6 +42,8 @@ type scheduler struct {
 	isActiveLock     sync.Mutex
 	cfg              config.Configuration
 	actions          map[string]*scheduledAction
+	schedules        map[string]*scheduledWorkflow
+	schedulesLock    sync.Mutex
 }
 
 // unregisterAction allows to unregister a scheduled action
@@ -94,6 +96,10 @@ func (sc *scheduler) startScheduling() {
 	sc.isActiveLock.Unlock()
 	sc.chStopScheduling = make(chan struct{})
 	sc.actions = make(map[string]*scheduledAction)
+	sc.schedulesLock.Lock()
+	sc.schedules = make(map[string]*scheduledWorkflow)
+	sc.schedulesLock.Unlock()
+	go sc.watchSchedules()
 	var waitIndex uint64
 	go func() {
 		for {
@@ -172,6 +178,11 @@ func (sc *scheduler) stopScheduling() {
 		for _, action := range defaultScheduler.actions {
 			action.stop()
 		}
+		defaultScheduler.schedulesLock.Lock()
+		for _, sw := range defaultScheduler.schedules {
+			sw.stop()
+		}
+		defaultScheduler.schedulesLock.Unlock()
 	}
 }
 
@@ -0,0 +1,281 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduling
+
+//go:generate go-enum -f=schedules.go --lower
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/consulutil"
+)
+
+// OverlapPolicy x ENUM(
+// skip,
+// queue,
+// cancel
+// )
+type OverlapPolicy int
+
+// MarshalJSON is used to represent this enumeration as a string instead of an int
+func (op OverlapPolicy) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(`"`)
+	buffer.WriteString(op.String())
+	buffer.WriteString(`"`)
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON is used to read this enumeration from a string
+func (op *OverlapPolicy) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return errors.Wrap(err, "failed to unmarshal OverlapPolicy as string")
+	}
+	*op, err = ParseOverlapPolicy(strings.ToLower(s))
+	return errors.Wrap(err, "failed to parse OverlapPolicy from JSON input")
+}
+
+// A Schedule triggers the execution of a deployment workflow according to a cron expression.
+//
+// The OverlapPolicy defines what happens when the schedule is triggered while its previous run is still in progress:
+//   - skip: the new run is skipped
+//   - queue: the new run is started as soon as the previous one ends
+//   - cancel: the previous run is canceled and the new one is started
+type Schedule struct {
+	ID              string        `json:"id"`
+	DeploymentID    string        `json:"deployment_id"`
+	WorkflowName    string        `json:"workflow_name"`
+	Cron            string        `json:"cron"`
+	Timezone        string        `json:"timezone,omitempty"`
+	OverlapPolicy   OverlapPolicy `json:"overlap_policy"`
+	ContinueOnError bool          `json:"continue_on_error"`
+	Paused          bool          `json:"paused"`
+}
+
+// Validate checks that a schedule is well-formed
+func (s *Schedule) Validate() error {
+	if s.DeploymentID == "" {
+		return errors.New("missing schedule deployment id")
+	}
+	if s.WorkflowName == "" {
+		return errors.New("missing schedule workflow name")
+	}
+	if _, err := ParseCronExpression(s.Cron); err != nil {
+		return err
+	}
+	_, err := s.Location()
+	return err
+}
+
+// Location returns the time zone used to evaluate the schedule cron expression, UTC by default
+func (s *Schedule) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	return loc, errors.Wrapf(err, "invalid schedule timezone %q", s.Timezone)
+}
+
+// Next returns the first activation time of the schedule after the given time
+func (s *Schedule) Next(t time.Time) (time.Time, error) {
+	cs, err := ParseCronExpression(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := s.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cs.Next(t.In(loc)), nil
+}
+
+const (
+	// ScheduleRunStarted is the status of a schedule run that started a workflow
+	ScheduleRunStarted = "started"
+	// ScheduleRunSkipped is the status of a schedule run skipped as the previous one was still in progress
+	ScheduleRunSkipped = "skipped"
+	// ScheduleRunQueued is the status of a schedule run delayed until the end of the previous one
+	ScheduleRunQueued = "queued"
+	// ScheduleRunFailed is the status of a schedule run that failed to start a workflow
+	ScheduleRunFailed = "failed"
+)
+
+// maxScheduleRuns is the number of runs kept in a schedule history
+const maxScheduleRuns = 50
+
+// A ScheduleRun records a trigger of a schedule
+type ScheduleRun struct {
+	Date    time.Time `json:"date"`
+	Status  string    `json:"status"`
+	TaskID  string    `json:"task_id,omitempty"`
+	Message string    `json:"message,omitempty"`
+}
+
+var schedulesPrefix = path.Join(consulutil.SchedulingKVPrefix, "schedules")
+
+// StoreSchedule validates and stores (creates or replaces) a schedule
+func StoreSchedule(kv *api.KV, s *Schedule) error {
+	if s.ID == "" {
+		return errors.New("missing schedule id")
+	}
+	err := s.Validate()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal schedule %q", s.ID)
+	}
+	_, err = kv.Put(&api.KVPair{Key: path.Join(schedulesPrefix, s.ID, "definition"), Value: b}, nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+// GetSchedule returns a schedule or nil if it does not exist
+func GetSchedule(kv *api.KV, id string) (*Schedule, error) {
+	kvp, _, err := kv.Get(path.Join(schedulesPrefix, id, "definition"), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil {
+		return nil, nil
+	}
+	s := new(Schedule)
+	err = json.Unmarshal(kvp.Value, s)
+	return s, errors.Wrapf(err, "failed to unmarshal schedule %q", id)
+}
+
+// ListSchedules returns the schedules of a given deployment or all the schedules if deploymentID is empty
+func ListSchedules(kv *api.KV, deploymentID string) ([]*Schedule, error) {
+	keys, _, err := kv.Keys(schedulesPrefix+"/", "/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	schedules := make([]*Schedule, 0)
+	for _, key := range keys {
+		s, err := GetSchedule(kv, path.Base(key))
+		if err != nil {
+			return nil, err
+		}
+		if s != nil && (deploymentID == "" || s.DeploymentID == deploymentID) {
+			schedules = append(schedules, s)
+		}
+	}
+	return schedules, nil
+}
+
+// SetSchedulePaused pauses or resumes a schedule
+func SetSchedulePaused(kv *api.KV, id string, paused bool) error {
+	s, err := GetSchedule(kv, id)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return errors.Errorf("schedule %q not found", id)
+	}
+	s.Paused = paused
+	return StoreSchedule(kv, s)
+}
+
+// DeleteSchedule deletes a schedule and its runs history
+func DeleteSchedule(kv *api.KV, id string) error {
+	_, err := kv.DeleteTree(path.Join(schedulesPrefix, id)+"/", nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+// DeleteDeploymentSchedules deletes all the schedules of a given deployment
+func DeleteDeploymentSchedules(kv *api.KV, deploymentID string) error {
+	schedules, err := ListSchedules(kv, deploymentID)
+	if err != nil {
+		return err
+	}
+	for _, s := range schedules {
+		if err = DeleteSchedule(kv, s.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetScheduleLatestTaskID returns the id of the task started by the latest run of a schedule
+func GetScheduleLatestTaskID(kv *api.KV, id string) (string, error) {
+	kvp, _, err := kv.Get(path.Join(schedulesPrefix, id, "latestTaskID"), nil)
+	if err != nil {
+		return "", errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil {
+		return "", nil
+	}
+	return string(kvp.Value), nil
+}
+
+// SetScheduleLatestTaskID stores the id of the task started by the latest run of a schedule
+func SetScheduleLatestTaskID(kv *api.KV, id, taskID string) error {
+	return consulutil.StoreConsulKeyAsString(path.Join(schedulesPrefix, id, "latestTaskID"), taskID)
+}
+
+// StoreScheduleRun adds a run to a schedule history, only the latest runs are kept
+func StoreScheduleRun(kv *api.KV, id string, run *ScheduleRun) error {
+	b, err := json.Marshal(run)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal run of schedule %q", id)
+	}
+	historyPrefix := path.Join(schedulesPrefix, id, "history")
+	// Zero-padded timestamps keep runs sorted by date
+	_, err = kv.Put(&api.KVPair{Key: path.Join(historyPrefix, fmt.Sprintf("%020d", run.Date.UnixNano())), Value: b}, nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	keys, _, err := kv.Keys(historyPrefix+"/", "/", nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if len(keys) <= maxScheduleRuns {
+		return nil
+	}
+	sort.Strings(keys)
+	for _, key := range keys[:len(keys)-maxScheduleRuns] {
+		if _, err = kv.Delete(key, nil); err != nil {
+			return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+	}
+	return nil
+}
+
+// ListScheduleRuns returns the history of a schedule sorted from the oldest to the latest run
+func ListScheduleRuns(kv *api.KV, id string) ([]*ScheduleRun, error) {
+	kvps, _, err := kv.List(path.Join(schedulesPrefix, id, "history")+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	runs := make([]*ScheduleRun, 0, len(kvps))
+	for _, kvp := range kvps {
+		run := new(ScheduleRun)
+		if err = json.Unmarshal(kvp.Value, run); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal run of schedule %q", id)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
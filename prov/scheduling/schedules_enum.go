@@ -0,0 +1,65 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by go-enum
+// DO NOT EDIT!
+
+package scheduling
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// OverlapPolicySkip is a OverlapPolicy of type Skip
+	OverlapPolicySkip OverlapPolicy = iota
+	// OverlapPolicyQueue is a OverlapPolicy of type Queue
+	OverlapPolicyQueue
+	// OverlapPolicyCancel is a OverlapPolicy of type Cancel
+	OverlapPolicyCancel
+)
+
+const _OverlapPolicyName = "skipqueuecancel"
+
+var _OverlapPolicyMap = map[OverlapPolicy]string{
+	0: _OverlapPolicyName[0:4],
+	1: _OverlapPolicyName[4:9],
+	2: _OverlapPolicyName[9:15],
+}
+
+// String implements the Stringer interface.
+func (x OverlapPolicy) String() string {
+	if str, ok := _OverlapPolicyMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("OverlapPolicy(%d)", x)
+}
+
+var _OverlapPolicyValue = map[string]OverlapPolicy{
+	_OverlapPolicyName[0:4]:                   0,
+	strings.ToLower(_OverlapPolicyName[0:4]):  0,
+	_OverlapPolicyName[4:9]:                   1,
+	strings.ToLower(_OverlapPolicyName[4:9]):  1,
+	_OverlapPolicyName[9:15]:                  2,
+	strings.ToLower(_OverlapPolicyName[9:15]): 2,
+}
+
+// ParseOverlapPolicy attempts to convert a string to a OverlapPolicy
+func ParseOverlapPolicy(name string) (OverlapPolicy, error) {
+	if x, ok := _OverlapPolicyValue[name]; ok {
+		return x, nil
+	}
+	return OverlapPolicy(0), fmt.Errorf("%s is not a valid OverlapPolicy", name)
+}
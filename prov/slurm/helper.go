@@ -18,6 +18,7 @@ import (
 	"bufio"
 	"fmt"
 	"github.com/pkg/errors"
+	"github.com/spf13/cast"
 	"github.com/ystia/yorc/config"
 	"github.com/ystia/yorc/helper/sshutil"
 	"github.com/ystia/yorc/log"
@@ -71,13 +72,56 @@ func GetSSHClient(cfg config.Configuration) (*sshutil.SSHClient, error) {
 		return nil, err
 	}
 
+	bastion, err := getBastionHost(cfg.Infrastructures[infrastructureName].Get("bastion"))
+	if err != nil {
+		log.Printf("Unable to provide SSH client due to:%+v", err)
+		return nil, err
+	}
+
 	return &sshutil.SSHClient{
-		Config: SSHConfig,
-		Host:   cfg.Infrastructures[infrastructureName].GetString("url"),
-		Port:   port,
+		Config:  SSHConfig,
+		Host:    cfg.Infrastructures[infrastructureName].GetString("url"),
+		Port:    port,
+		Bastion: bastion,
 	}, nil
 }
 
+// getBastionHost returns the bastion host defined in the slurm configuration if any.
+//
+// A bastion could itself define a bastion used to reach it.
+func getBastionHost(bastionConfig interface{}) (*sshutil.BastionHost, error) {
+	if bastionConfig == nil {
+		return nil, nil
+	}
+	bastionMap, err := cast.ToStringMapE(bastionConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "slurm configuration bastion is not a valid map")
+	}
+	if len(bastionMap) == 0 {
+		return nil, nil
+	}
+	bastion := &sshutil.BastionHost{
+		Host:       cast.ToString(bastionMap["host"]),
+		User:       cast.ToString(bastionMap["user"]),
+		Password:   cast.ToString(bastionMap["password"]),
+		PrivateKey: cast.ToString(bastionMap["private_key"]),
+	}
+	if strings.TrimSpace(bastion.Host) == "" {
+		return nil, errors.New("slurm configuration bastion host is not set")
+	}
+	if bastion.Password == "" && bastion.PrivateKey == "" {
+		return nil, errors.Errorf("slurm configuration bastion %q missing authentication details, password or private_key should be set", bastion.Host)
+	}
+	if port, ok := bastionMap["port"]; ok {
+		bastion.Port, err = cast.ToIntE(port)
+		if err != nil {
+			return nil, errors.Wrapf(err, "slurm configuration bastion %q port is not a valid port", bastion.Host)
+		}
+	}
+	bastion.Bastion, err = getBastionHost(bastionMap["bastion"])
+	return bastion, err
+}
+
 // checkInfraConfig checks infrastructure mandatory configuration parameters
 func checkInfraConfig(cfg config.Configuration) error {
 	_, exist := cfg.Infrastructures[infrastructureName]
@@ -291,6 +291,27 @@ func TestPrivateKey(t *testing.T) {
 	assert.NoError(t, err, "Unexpected error parsing a configuration with password")
 	_, err = GetSSHClient(cfg)
 	assert.NoError(t, err, "Unexpected error getting a ssh client using a configuration with password")
+
+	// Slurm Configuration reached through chained bastions
+	cfg.Infrastructures["slurm"].Set("bastion", map[string]interface{}{
+		"host":     "10.0.0.1",
+		"port":     2222,
+		"password": "test",
+		"bastion": map[string]interface{}{
+			"host":        "bastion.example.com",
+			"user":        "cloud",
+			"private_key": privateKeyContent,
+		},
+	})
+	client, err := GetSSHClient(cfg)
+	require.NoError(t, err, "Unexpected error getting a ssh client using a configuration with bastions")
+	require.NotNil(t, client.Bastion)
+	assert.Equal(t, "cloud@bastion.example.com:22,root@10.0.0.1:2222", client.Bastion.String())
+
+	// A bastion without authentication details is invalid
+	cfg.Infrastructures["slurm"].Set("bastion", map[string]interface{}{"host": "10.0.0.1"})
+	_, err = GetSSHClient(cfg)
+	assert.Error(t, err, "Expected an error getting a ssh client using a bastion with no private key and no password defined")
 }
 
 func TestParseJobIDFromSbatchOut(t *testing.T) {
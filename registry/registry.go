@@ -87,6 +87,16 @@ type Registry interface {
 	GetActionOperator(actionType string) (prov.ActionOperator, error)
 	// ListActionOperators returns a map of actionTypes matches to prov.ActionOperator origin
 	ListActionOperators() []ActionTypeMatch
+
+	// RegisterPolicyHandler register a list of TOSCA policy types that should be checked by the given
+	// prov.PolicyHandler. Origin is the origin of the handler (builtin for builtin handlers or the plugin name in case of a plugin)
+	RegisterPolicyHandler(policyTypes []string, handler prov.PolicyHandler, origin string)
+	// Returns the first prov.PolicyHandler that matches the given policyType
+	//
+	// If the given policyType can't match any prov.PolicyHandler, an error is returned
+	GetPolicyHandler(policyType string) (prov.PolicyHandler, error)
+	// ListPolicyHandlers returns a map of policy types matches to prov.PolicyHandler origin
+	ListPolicyHandlers() []PolicyTypeMatch
 }
 
 var defaultReg Registry
@@ -121,6 +131,13 @@ type ActionTypeMatch struct {
 	Origin     string              `json:"origin"`
 }
 
+// PolicyTypeMatch represents a matching between a TOSCA policy type and a PolicyHandler from a given origin
+type PolicyTypeMatch struct {
+	PolicyType string             `json:"policy_type"`
+	Handler    prov.PolicyHandler `json:"-"`
+	Origin     string             `json:"origin"`
+}
+
 // Definition represents a TOSCA definition with its Name, Origin and Data content
 type Definition struct {
 	Name   string `json:"name"`
@@ -146,6 +163,7 @@ type defaultRegistry struct {
 	delegateMatches          []DelegateMatch
 	operationMatches         []OperationExecMatch
 	actionTypeMatches        []ActionTypeMatch
+	policyTypeMatches        []PolicyTypeMatch
 	definitions              []Definition
 	vaultClientBuilders      []VaultClientBuilder
 	infraUsageCollectors     []InfraUsageCollector
@@ -155,6 +173,7 @@ type defaultRegistry struct {
 	vaultsLock               sync.RWMutex
 	infraUsageCollectorsLock sync.RWMutex
 	actionOperatorsLock      sync.RWMutex
+	policyHandlersLock       sync.RWMutex
 }
 
 func (r *defaultRegistry) RegisterDelegates(matches []string, executor prov.DelegateExecutor, origin string) {
@@ -332,3 +351,35 @@ func (r *defaultRegistry) ListActionOperators() []ActionTypeMatch {
 	copy(result, r.actionTypeMatches)
 	return result
 }
+
+func (r *defaultRegistry) RegisterPolicyHandler(policyTypes []string, handler prov.PolicyHandler, origin string) {
+	r.policyHandlersLock.Lock()
+	defer r.policyHandlersLock.Unlock()
+	if len(policyTypes) > 0 {
+		newPolicyMatches := make([]PolicyTypeMatch, len(policyTypes))
+		for i := range policyTypes {
+			newPolicyMatches[i] = PolicyTypeMatch{PolicyType: policyTypes[i], Handler: handler, Origin: origin}
+		}
+		// Put them at the beginning
+		r.policyTypeMatches = append(newPolicyMatches, r.policyTypeMatches...)
+	}
+}
+
+func (r *defaultRegistry) GetPolicyHandler(policyType string) (prov.PolicyHandler, error) {
+	r.policyHandlersLock.RLock()
+	defer r.policyHandlersLock.RUnlock()
+	for _, m := range r.policyTypeMatches {
+		if policyType == m.PolicyType {
+			return m.Handler, nil
+		}
+	}
+	return nil, errors.Errorf("Unsupported policy type:%q for any registered policy handlers", policyType)
+}
+
+func (r *defaultRegistry) ListPolicyHandlers() []PolicyTypeMatch {
+	r.policyHandlersLock.RLock()
+	defer r.policyHandlersLock.RUnlock()
+	result := make([]PolicyTypeMatch, len(r.policyTypeMatches))
+	copy(result, r.policyTypeMatches)
+	return result
+}
@@ -0,0 +1,186 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/log"
+)
+
+const principalLookupKey contextKey = 2
+
+// principal is an authenticated REST API caller
+type principal struct {
+	Name   string
+	Role   Role
+	Method string
+}
+
+// An authenticator extracts a principal from the credentials carried by a request.
+//
+// It returns a nil principal and a nil error if the request does not carry credentials handled by this authenticator
+// and an error if credentials are handled but invalid.
+type authenticator interface {
+	authenticate(r *http.Request) (*principal, error)
+}
+
+func newAuthenticators(cfg config.HTTPAuth) ([]authenticator, error) {
+	authenticators := make([]authenticator, 0)
+	if len(cfg.Tokens) > 0 {
+		a := &staticTokenAuthenticator{tokens: make(map[string]*principal, len(cfg.Tokens))}
+		for _, t := range cfg.Tokens {
+			if t.Token == "" {
+				return nil, errors.Errorf("missing token value for API token %q", t.Name)
+			}
+			role, err := ParseRole(t.Role)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid role for API token %q", t.Name)
+			}
+			a.tokens[t.Token] = &principal{Name: t.Name, Role: role, Method: "token"}
+		}
+		authenticators = append(authenticators, a)
+	}
+	if cfg.JWT.KeySetFile != "" {
+		a, err := newJWTAuthenticator(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	if len(cfg.CertificatesRoles) > 0 {
+		a := &certificateAuthenticator{roles: make(map[string]Role, len(cfg.CertificatesRoles))}
+		for cn, r := range cfg.CertificatesRoles {
+			role, err := ParseRole(r)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid role for certificate common name %q", cn)
+			}
+			// Configuration keys are case insensitive
+			a.roles[strings.ToLower(cn)] = role
+		}
+		authenticators = append(authenticators, a)
+	}
+	if len(authenticators) == 0 {
+		return nil, errors.New("HTTP authentication enabled but neither API tokens, JWT key set nor certificates roles are configured")
+	}
+	return authenticators, nil
+}
+
+func getBearerToken(r *http.Request) string {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) > 7 && strings.EqualFold(authHeader[:7], "bearer ") {
+		return strings.TrimSpace(authHeader[7:])
+	}
+	return ""
+}
+
+type staticTokenAuthenticator struct {
+	tokens map[string]*principal
+}
+
+func (a *staticTokenAuthenticator) authenticate(r *http.Request) (*principal, error) {
+	token := getBearerToken(r)
+	if token == "" {
+		return nil, nil
+	}
+	for t, p := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// certificateAuthenticator relies on TLS client certificates already verified by the listener (see wrapListenerTLS)
+type certificateAuthenticator struct {
+	roles map[string]Role
+}
+
+func (a *certificateAuthenticator) authenticate(r *http.Request) (*principal, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, nil
+	}
+	cn := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	role, ok := a.roles[strings.ToLower(cn)]
+	if !ok {
+		return nil, errors.Errorf("no role granted to certificate %q", cn)
+	}
+	return &principal{Name: cn, Role: role, Method: "certificate"}, nil
+}
+
+func (s *Server) authenticate(r *http.Request) (*principal, error) {
+	for _, a := range s.authenticators {
+		p, err := a.authenticate(r)
+		if err != nil || p != nil {
+			return p, err
+		}
+	}
+	if getBearerToken(r) != "" {
+		return nil, errors.New("invalid bearer token")
+	}
+	return nil, errors.New("missing credentials")
+}
+
+// authorizationHandler returns a middleware that authenticates callers and checks that they hold the role
+// returned by requiredRole for the current request.
+//
+// This middleware is a no-op if authentication is disabled.
+func (s *Server) authorizationHandler(requiredRole func(r *http.Request) Role) func(http.Handler) http.Handler {
+	m := func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if !s.config.HTTPAuth.Enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+			p, err := s.authenticate(r)
+			if err != nil {
+				log.Debugf("[%s] %q authentication failed: %v", r.Method, r.URL.String(), err)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="yorc"`)
+				writeError(w, r, newUnauthorizedError("Authentication failed: "+err.Error()))
+				return
+			}
+			role := requiredRole(r)
+			if p.Role < role {
+				log.Debugf("[%s] %q denied to %s %q with role %q", r.Method, r.URL.String(), p.Method, p.Name, p.Role)
+				writeError(w, r, newForbiddenError(role))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalLookupKey, p)))
+		}
+		return http.HandlerFunc(fn)
+	}
+	return m
+}
+
+// requireRole returns a middleware checking that callers hold at least the given role
+func (s *Server) requireRole(role Role) func(http.Handler) http.Handler {
+	return s.authorizationHandler(func(r *http.Request) Role {
+		return role
+	})
+}
+
+// undeployRequiredRole requires the admin role to purge a deployment and the operator role to undeploy it
+func undeployRequiredRole(r *http.Request) Role {
+	if _, ok := r.URL.Query()["purge"]; ok {
+		return RoleAdmin
+	}
+	return RoleOperator
+}
@@ -0,0 +1,340 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // register SHA-256 hash function
+	_ "crypto/sha512" // register SHA-384 and SHA-512 hash functions
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+)
+
+const defaultJWTRoleClaim = "role"
+
+// jsonWebKey is a key of a JSON Web Key Set as defined by RFC 7517
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA public keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC public keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	// Symmetric keys
+	K string `json:"k,omitempty"`
+}
+
+type jwtKey struct {
+	kid string
+	alg string
+	key interface{}
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+}
+
+type jwtAuthenticator struct {
+	keys      []jwtKey
+	issuer    string
+	audience  string
+	roleClaim string
+	leeway    time.Duration
+}
+
+func newJWTAuthenticator(cfg config.HTTPAuthJWT) (*jwtAuthenticator, error) {
+	b, err := ioutil.ReadFile(cfg.KeySetFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read JWT key set file %q", cfg.KeySetFile)
+	}
+	keys, err := parseJWKS(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse JWT key set file %q", cfg.KeySetFile)
+	}
+	a := &jwtAuthenticator{
+		keys:      keys,
+		issuer:    cfg.Issuer,
+		audience:  cfg.Audience,
+		roleClaim: cfg.RoleClaim,
+		leeway:    cfg.Leeway,
+	}
+	if a.roleClaim == "" {
+		a.roleClaim = defaultJWTRoleClaim
+	}
+	return a, nil
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := decodeBase64URL(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func parseJWKS(b []byte) ([]jwtKey, error) {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	err := json.Unmarshal(b, &jwks)
+	if err != nil {
+		return nil, err
+	}
+	if len(jwks.Keys) == 0 {
+		return nil, errors.New("no keys defined")
+	}
+	keys := make([]jwtKey, 0, len(jwks.Keys))
+	for i, jwk := range jwks.Keys {
+		k := jwtKey{kid: jwk.Kid, alg: jwk.Alg}
+		switch jwk.Kty {
+		case "oct":
+			k.key, err = decodeBase64URL(jwk.K)
+		case "RSA":
+			pub := &rsa.PublicKey{}
+			var e *big.Int
+			pub.N, err = decodeBigInt(jwk.N)
+			if err == nil {
+				e, err = decodeBigInt(jwk.E)
+			}
+			if err == nil {
+				pub.E = int(e.Int64())
+				k.key = pub
+			}
+		case "EC":
+			pub := &ecdsa.PublicKey{}
+			switch jwk.Crv {
+			case "P-256":
+				pub.Curve = elliptic.P256()
+			case "P-384":
+				pub.Curve = elliptic.P384()
+			case "P-521":
+				pub.Curve = elliptic.P521()
+			default:
+				return nil, errors.Errorf("unsupported curve %q for key #%d", jwk.Crv, i)
+			}
+			pub.X, err = decodeBigInt(jwk.X)
+			if err == nil {
+				pub.Y, err = decodeBigInt(jwk.Y)
+			}
+			k.key = pub
+		default:
+			return nil, errors.Errorf("unsupported key type %q for key #%d", jwk.Kty, i)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key #%d", i)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func jwtHashFunction(alg string) (crypto.Hash, error) {
+	if len(alg) != 5 {
+		return 0, errors.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	switch alg[2:] {
+	case "256":
+		return crypto.SHA256, nil
+	case "384":
+		return crypto.SHA384, nil
+	case "512":
+		return crypto.SHA512, nil
+	}
+	return 0, errors.Errorf("unsupported JWT algorithm %q", alg)
+}
+
+func verifyJWTSignature(alg string, key interface{}, signingInput, signature []byte) error {
+	hash, err := jwtHashFunction(alg)
+	if err != nil {
+		return err
+	}
+	switch k := key.(type) {
+	case []byte:
+		if !strings.HasPrefix(alg, "HS") {
+			return errors.Errorf("algorithm %q not supported by a symmetric key", alg)
+		}
+		mac := hmac.New(hash.New, k)
+		mac.Write(signingInput)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return errors.Errorf("algorithm %q not supported by a RSA key", alg)
+		}
+		h := hash.New()
+		h.Write(signingInput)
+		return errors.Wrap(rsa.VerifyPKCS1v15(k, hash, h.Sum(nil), signature), "invalid signature")
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return errors.Errorf("algorithm %q not supported by an EC key", alg)
+		}
+		keySize := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*keySize {
+			return errors.New("invalid signature")
+		}
+		h := hash.New()
+		h.Write(signingInput)
+		r := new(big.Int).SetBytes(signature[:keySize])
+		s := new(big.Int).SetBytes(signature[keySize:])
+		if !ecdsa.Verify(k, h.Sum(nil), r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return errors.Errorf("unsupported key type %T", key)
+}
+
+func (a *jwtAuthenticator) authenticate(r *http.Request) (*principal, error) {
+	token := getBearerToken(r)
+	if strings.Count(token, ".") != 2 {
+		// Not a JWT
+		return nil, nil
+	}
+	claims, err := a.verify(token, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JWT")
+	}
+	role, err := a.role(claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JWT")
+	}
+	name, _ := claims["sub"].(string)
+	return &principal{Name: name, Role: role, Method: "jwt"}, nil
+}
+
+// verify checks the signature and the registered claims of a JWT and returns its claims
+func (a *jwtAuthenticator) verify(token string, now time.Time) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	b, err := decodeBase64URL(parts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed header")
+	}
+	var header jwtHeader
+	if err = json.Unmarshal(b, &header); err != nil {
+		return nil, errors.Wrap(err, "malformed header")
+	}
+	signature, err := decodeBase64URL(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed signature")
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, k := range a.keys {
+		if (header.Kid != "" && k.kid != "" && header.Kid != k.kid) || (k.alg != "" && k.alg != header.Alg) {
+			continue
+		}
+		if err = verifyJWTSignature(header.Alg, k.key, signingInput, signature); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		if err == nil {
+			err = errors.New("no matching key found")
+		}
+		return nil, err
+	}
+
+	b, err = decodeBase64URL(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed payload")
+	}
+	claims := make(map[string]interface{})
+	if err = json.Unmarshal(b, &claims); err != nil {
+		return nil, errors.Wrap(err, "malformed payload")
+	}
+	if exp, ok := claims["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0).Add(a.leeway)) {
+		return nil, errors.New("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(a.leeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, errors.New("token not valid yet")
+	}
+	if a.issuer != "" && claims["iss"] != a.issuer {
+		return nil, errors.Errorf("unexpected issuer %v", claims["iss"])
+	}
+	if a.audience != "" && !hasAudience(claims["aud"], a.audience) {
+		return nil, errors.Errorf("unexpected audience %v", claims["aud"])
+	}
+	return claims, nil
+}
+
+func hasAudience(aud interface{}, expected string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == expected
+	case []interface{}:
+		for _, a := range v {
+			if a == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// role returns the highest role listed in the role claim
+func (a *jwtAuthenticator) role(claims map[string]interface{}) (Role, error) {
+	var values []interface{}
+	switch v := claims[a.roleClaim].(type) {
+	case string:
+		values = []interface{}{v}
+	case []interface{}:
+		values = v
+	}
+	found := false
+	var role Role
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		r, err := ParseRole(s)
+		if err != nil {
+			continue
+		}
+		if !found || r > role {
+			role = r
+			found = true
+		}
+	}
+	if !found {
+		return role, errors.Errorf("no valid role found in claim %q", a.roleClaim)
+	}
+	return role, nil
+}
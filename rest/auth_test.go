@@ -0,0 +1,212 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+)
+
+func encodeTestJWTPart(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newTestHS256JWT(t *testing.T, secret []byte, claims map[string]interface{}) string {
+	signingInput := encodeTestJWTPart(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeTestJWTPart(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newTestES256JWT(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signingInput := encodeTestJWTPart(t, map[string]string{"alg": "ES256", "typ": "JWT", "kid": kid}) + "." + encodeTestJWTPart(t, claims)
+	h := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, h[:])
+	require.NoError(t, err)
+	sig := make([]byte, 64)
+	rb, sb := r.Bytes(), s.Bytes()
+	copy(sig[32-len(rb):32], rb)
+	copy(sig[64-len(sb):], sb)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func writeTestJWKS(t *testing.T, dir string, secret []byte, ecKey *ecdsa.PrivateKey) string {
+	pad := func(b []byte) string {
+		p := make([]byte, 32)
+		copy(p[32-len(b):], b)
+		return base64.RawURLEncoding.EncodeToString(p)
+	}
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "oct", "kid": "hmac", "k": base64.RawURLEncoding.EncodeToString(secret)},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": pad(ecKey.X.Bytes()), "y": pad(ecKey.Y.Bytes())},
+		},
+	}
+	b, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	path := filepath.Join(dir, "jwks.json")
+	require.NoError(t, ioutil.WriteFile(path, b, 0600))
+	return path
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := ioutil.TempDir("", "yorc-jwt")
+	require.NoError(t, err)
+	secret := []byte("a very secret key")
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherECKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	a, err := newJWTAuthenticator(config.HTTPAuthJWT{
+		KeySetFile: writeTestJWKS(t, tmpDir, secret, ecKey),
+		Issuer:     "https://issuer.example.com",
+		Audience:   "yorc",
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	validClaims := func(role interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"sub":  "john",
+			"iss":  "https://issuer.example.com",
+			"aud":  []string{"other", "yorc"},
+			"exp":  now.Add(time.Hour).Unix(),
+			"role": role,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		wantErr  bool
+		wantRole Role
+	}{
+		{"HS256Viewer", newTestHS256JWT(t, secret, validClaims("viewer")), false, RoleViewer},
+		{"ES256Admin", newTestES256JWT(t, ecKey, "ec", validClaims("admin")), false, RoleAdmin},
+		{"HighestRole", newTestES256JWT(t, ecKey, "ec", validClaims([]string{"viewer", "operator", "unknown"})), false, RoleOperator},
+		{"WrongSecret", newTestHS256JWT(t, []byte("wrong"), validClaims("admin")), true, RoleViewer},
+		{"WrongECKey", newTestES256JWT(t, otherECKey, "ec", validClaims("admin")), true, RoleViewer},
+		{"NoRole", newTestHS256JWT(t, secret, validClaims("unknown")), true, RoleViewer},
+		{"Expired", newTestHS256JWT(t, secret, map[string]interface{}{"iss": "https://issuer.example.com", "aud": "yorc", "exp": now.Add(-time.Hour).Unix(), "role": "admin"}), true, RoleViewer},
+		{"NotBefore", newTestHS256JWT(t, secret, map[string]interface{}{"iss": "https://issuer.example.com", "aud": "yorc", "nbf": now.Add(time.Hour).Unix(), "role": "admin"}), true, RoleViewer},
+		{"WrongIssuer", newTestHS256JWT(t, secret, map[string]interface{}{"iss": "other", "aud": "yorc", "role": "admin"}), true, RoleViewer},
+		{"WrongAudience", newTestHS256JWT(t, secret, map[string]interface{}{"iss": "https://issuer.example.com", "aud": "other", "role": "admin"}), true, RoleViewer},
+		{"AlgNone", encodeTestJWTPart(t, map[string]string{"alg": "none"}) + "." + encodeTestJWTPart(t, validClaims("admin")) + ".", true, RoleViewer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/deployments", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			p, err := a.authenticate(req)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, p)
+			assert.Equal(t, tt.wantRole, p.Role)
+			assert.Equal(t, "john", p.Name)
+		})
+	}
+}
+
+func TestAuthorizationHandler(t *testing.T) {
+	t.Parallel()
+	authCfg := config.HTTPAuth{
+		Enabled: true,
+		Tokens: []config.HTTPAuthToken{
+			{Name: "ci", Token: "viewer-token", Role: "viewer"},
+			{Name: "ops", Token: "operator-token", Role: "operator"},
+			{Name: "root", Token: "admin-token", Role: "Admin"},
+		},
+	}
+	authenticators, err := newAuthenticators(authCfg)
+	require.NoError(t, err)
+	s := &Server{
+		router:         newRouter(),
+		config:         config.Configuration{HTTPAuth: authCfg},
+		authenticators: authenticators,
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+	s.router.Delete("/deployments/:id", s.authorizationHandler(undeployRequiredRole)(ok))
+	s.router.Put("/hosts_pool/:host", s.requireRole(RoleAdmin)(ok))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{"NoCredentials", "DELETE", "/deployments/dep", "", http.StatusUnauthorized},
+		{"InvalidToken", "DELETE", "/deployments/dep", "unknown", http.StatusUnauthorized},
+		{"ViewerUndeploy", "DELETE", "/deployments/dep", "viewer-token", http.StatusForbidden},
+		{"OperatorUndeploy", "DELETE", "/deployments/dep", "operator-token", http.StatusAccepted},
+		{"OperatorPurge", "DELETE", "/deployments/dep?purge", "operator-token", http.StatusForbidden},
+		{"AdminPurge", "DELETE", "/deployments/dep?purge", "admin-token", http.StatusAccepted},
+		{"OperatorHostsPool", "PUT", "/hosts_pool/host1", "operator-token", http.StatusForbidden},
+		{"AdminHostsPool", "PUT", "/hosts_pool/host1", "admin-token", http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tt.token))
+			}
+			w := httptest.NewRecorder()
+			s.router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus >= 400 {
+				var errs Errors
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errs))
+				require.Len(t, errs.Errors, 1)
+				assert.Equal(t, tt.wantStatus, errs.Errors[0].Status)
+			}
+		})
+	}
+}
+
+func TestNewAuthenticatorsErrors(t *testing.T) {
+	t.Parallel()
+	_, err := newAuthenticators(config.HTTPAuth{Enabled: true})
+	assert.Error(t, err, "expecting an error when no authentication method is configured")
+	_, err = newAuthenticators(config.HTTPAuth{Enabled: true, Tokens: []config.HTTPAuthToken{{Name: "t", Token: "x", Role: "superuser"}}})
+	assert.Error(t, err, "expecting an error on invalid role")
+	_, err = newAuthenticators(config.HTTPAuth{Enabled: true, CertificatesRoles: map[string]string{"cn": "root"}})
+	assert.Error(t, err, "expecting an error on invalid role")
+}
@@ -0,0 +1,145 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/monitoring"
+)
+
+func (s *Server) listDeploymentChecksHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+
+	dExits, err := deployments.DoesDeploymentExists(s.consulClient.KV(), id)
+	if err != nil {
+		log.Panicf("%v", err)
+	}
+	if !dExits {
+		writeError(w, r, errNotFound)
+		return
+	}
+	s.listChecks(w, r, id, true)
+}
+
+func (s *Server) listChecksHandler(w http.ResponseWriter, r *http.Request) {
+	s.listChecks(w, r, "", false)
+}
+
+// listChecks writes the checks reports of a given deployment or of all deployments if deploymentID is empty
+//
+// Reports may be filtered by status using the "status" query parameter.
+func (s *Server) listChecks(w http.ResponseWriter, r *http.Request, deploymentID string, withHistory bool) {
+	filters := make([]monitoring.CheckFilterFunc, 0)
+	if deploymentID != "" {
+		filters = append(filters, func(cr monitoring.CheckReport) bool {
+			return cr.DeploymentID == deploymentID
+		})
+	}
+	if statusParam := r.URL.Query().Get("status"); statusParam != "" {
+		status, err := monitoring.ParseCheckStatus(strings.ToUpper(statusParam))
+		if err != nil {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		filters = append(filters, func(cr monitoring.CheckReport) bool {
+			return cr.Status == status
+		})
+	}
+
+	kv := s.consulClient.KV()
+	reports, err := monitoring.ListCheckReports(kv, func(cr monitoring.CheckReport) bool {
+		for _, f := range filters {
+			if !f(cr) {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+	if len(reports) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].DeploymentID != reports[j].DeploymentID {
+			return reports[i].DeploymentID < reports[j].DeploymentID
+		}
+		if reports[i].NodeName != reports[j].NodeName {
+			return reports[i].NodeName < reports[j].NodeName
+		}
+		return reports[i].Instance < reports[j].Instance
+	})
+
+	collection := CheckReportsCollection{Checks: make([]CheckReport, len(reports))}
+	for i, report := range reports {
+		collection.Checks[i] = CheckReport{CheckReport: report, Links: []AtomLink{
+			newAtomLink(LinkRelDeployment, path.Join("/deployments", report.DeploymentID)),
+			newAtomLink(LinkRelInstance, path.Join("/deployments", report.DeploymentID, "nodes", report.NodeName, "instances", report.Instance)),
+		}}
+		if withHistory {
+			collection.Checks[i].History, err = monitoring.ListCheckHistory(kv, report.DeploymentID, report.NodeName, report.Instance)
+			if err != nil {
+				log.Panic(err)
+			}
+		}
+	}
+	encodeJSONResponse(w, r, collection)
+}
+
+func (s *Server) pauseCheckHandler(w http.ResponseWriter, r *http.Request) {
+	s.setCheckPaused(w, r, true)
+}
+
+func (s *Server) resumeCheckHandler(w http.ResponseWriter, r *http.Request) {
+	s.setCheckPaused(w, r, false)
+}
+
+func (s *Server) setCheckPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+	nodeName := params.ByName("nodeName")
+	instanceID := params.ByName("instanceId")
+
+	kv := s.consulClient.KV()
+	report, err := monitoring.GetCheckReport(kv, id, nodeName, instanceID)
+	if err != nil {
+		log.Panic(err)
+	}
+	if report == nil {
+		writeError(w, r, errNotFound)
+		return
+	}
+	if report.Paused != paused {
+		if err = monitoring.SetCheckPaused(kv, id, nodeName, instanceID, paused); err != nil {
+			log.Panic(err)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
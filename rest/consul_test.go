@@ -72,5 +72,8 @@ func TestRunConsulRestPackageTests(t *testing.T) {
 		t.Run("testSSLRest", func(t *testing.T) {
 			testSSLREST(t, client, srv)
 		})
+		t.Run("testStreamEvents", func(t *testing.T) {
+			testStreamEvents(t, client)
+		})
 	})
 }
@@ -0,0 +1,83 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/deployments/backup"
+	"github.com/ystia/yorc/log"
+)
+
+func (s *Server) exportDeploymentHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+
+	kv := s.consulClient.KV()
+	dExits, err := deployments.DoesDeploymentExists(kv, id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if !dExits || deployments.IsUpdateStagingID(id) {
+		writeError(w, r, errNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", id+".tar.gz"))
+	if err = backup.Export(ctx, kv, s.config.WorkingDirectory, id, w); err != nil {
+		// Headers are already sent, we can only log the error
+		log.Printf("Failed to export deployment %q: %+v", id, err)
+	}
+}
+
+// postDeploymentHandler handles POST requests on /deployments/:id as the router does not allow
+// to register the /deployments/import static route along with /deployments/:id/* routes
+func (s *Server) postDeploymentHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	params = r.Context().Value(paramsLookupKey).(httprouter.Params)
+	if params.ByName("id") != "import" {
+		writeError(w, r, errNotFound)
+		return
+	}
+	if r.Header.Get("Content-Type") != "application/gzip" {
+		writeError(w, r, newUnsupportedMediaTypeError("application/gzip"))
+		return
+	}
+	s.importDeploymentHandler(w, r)
+}
+
+func (s *Server) importDeploymentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := backup.Import(r.Context(), s.consulClient.KV(), s.config.WorkingDirectory, r.Body)
+	if err != nil {
+		if backup.IsDeploymentAlreadyExistsError(err) {
+			writeError(w, r, newConflictRequest(err.Error()))
+			return
+		}
+		if backup.IsInvalidArchiveError(err) {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		log.Panic(err)
+	}
+	w.Header().Set("Location", fmt.Sprintf("/deployments/%s", id))
+	w.WriteHeader(http.StatusCreated)
+}
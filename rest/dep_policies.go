@@ -0,0 +1,105 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/log"
+)
+
+func (s *Server) listPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+	kv := s.consulClient.KV()
+
+	dExits, err := deployments.DoesDeploymentExists(kv, id)
+	if err != nil {
+		log.Panicf("%v", err)
+	}
+	if !dExits {
+		writeError(w, r, errNotFound)
+		return
+	}
+
+	policies, err := deployments.GetPolicies(kv, id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if len(policies) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	links := make([]AtomLink, len(policies))
+	for i, policy := range policies {
+		links[i] = newAtomLink(LinkRelPolicy, path.Join("/deployments", id, "policies", policy))
+	}
+	encodeJSONResponse(w, r, PoliciesCollection{Policies: links})
+}
+
+func (s *Server) getPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+	policyName := params.ByName("policyName")
+	kv := s.consulClient.KV()
+
+	exist, err := deployments.DoesPolicyExist(kv, id, policyName)
+	if err != nil {
+		log.Panic(err)
+	}
+	if !exist {
+		writeError(w, r, errNotFound)
+		return
+	}
+
+	policy := Policy{Name: policyName, Properties: make(map[string]string)}
+	policy.Type, err = deployments.GetPolicyType(kv, id, policyName)
+	if err != nil {
+		log.Panic(err)
+	}
+	policy.Targets, err = deployments.GetPolicyTargets(kv, id, policyName)
+	if err != nil {
+		log.Panic(err)
+	}
+	props, err := deployments.GetTypeProperties(kv, id, policy.Type, true)
+	if err != nil {
+		log.Panic(err)
+	}
+	for _, prop := range props {
+		value, err := deployments.GetPolicyPropertyValue(kv, id, policyName, prop)
+		if err != nil {
+			log.Panic(err)
+		}
+		if value != nil {
+			policy.Properties[prop] = value.RawString()
+		}
+	}
+	policy.Links = []AtomLink{
+		newAtomLink(LinkRelSelf, r.URL.Path),
+		newAtomLink(LinkRelDeployment, path.Join("/deployments", id)),
+	}
+	for _, target := range policy.Targets {
+		policy.Links = append(policy.Links, newAtomLink(LinkRelNode, path.Join("/deployments", id, "nodes", target)))
+	}
+	encodeJSONResponse(w, r, policy)
+}
@@ -107,11 +107,21 @@ func (s *Server) scaleOut(id, nodeName string, instancesDelta uint32) (string, e
 	if err != nil {
 		return "", err
 	}
+	_, policyMax, found, err := deployments.GetScalingPoliciesBoundsForNode(kv, id, nodeName)
+	if err != nil {
+		return "", err
+	}
+	if found && policyMax < maxInstances {
+		maxInstances = policyMax
+	}
 	currentNbInstance, err := deployments.GetNbInstancesForNode(kv, id, nodeName)
 	if err != nil {
 		return "", err
 	}
 
+	if currentNbInstance >= maxInstances {
+		return "", newBadRequestMessage("Maximum number of instances reached")
+	}
 	if currentNbInstance+instancesDelta > maxInstances {
 		log.Debug("The delta is too high, the max instances number is chosen")
 		instancesDelta = maxInstances - currentNbInstance
@@ -141,11 +151,21 @@ func (s *Server) scaleIn(id, nodeName string, instancesDelta uint32) (string, er
 	if err != nil {
 		return "", err
 	}
+	policyMin, _, found, err := deployments.GetScalingPoliciesBoundsForNode(kv, id, nodeName)
+	if err != nil {
+		return "", err
+	}
+	if found && policyMin > minInstances {
+		minInstances = policyMin
+	}
 	currentNbInstance, err := deployments.GetNbInstancesForNode(kv, id, nodeName)
 	if err != nil {
 		return "", err
 	}
 
+	if currentNbInstance <= minInstances {
+		return "", newBadRequestMessage("Minimum number of instances reached")
+	}
 	if currentNbInstance-instancesDelta < minInstances {
 		log.Debug("The delta is too low, the min instances number is chosen")
 		instancesDelta = currentNbInstance - minInstances
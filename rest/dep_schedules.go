@@ -0,0 +1,195 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/satori/go.uuid"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/collections"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/scheduling"
+)
+
+func (s *Server) newScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+	kv := s.consulClient.KV()
+
+	dExits, err := deployments.DoesDeploymentExists(kv, id)
+	if err != nil {
+		log.Panicf("%v", err)
+	}
+	if !dExits {
+		writeError(w, r, errNotFound)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Panic(err)
+	}
+	var schedule scheduling.Schedule
+	err = json.Unmarshal(body, &schedule)
+	if err != nil {
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+	schedule.ID = fmt.Sprint(uuid.NewV4())
+	schedule.DeploymentID = id
+	if err = schedule.Validate(); err != nil {
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+	workflows, err := deployments.GetWorkflows(kv, id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if !collections.ContainsString(workflows, schedule.WorkflowName) {
+		writeError(w, r, newBadRequestMessage(fmt.Sprintf("workflow %q not found in deployment %q", schedule.WorkflowName, id)))
+		return
+	}
+	if err = scheduling.StoreSchedule(kv, &schedule); err != nil {
+		log.Panic(err)
+	}
+	w.Header().Set("Location", path.Join("/deployments", id, "schedules", schedule.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) listSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+	kv := s.consulClient.KV()
+
+	dExits, err := deployments.DoesDeploymentExists(kv, id)
+	if err != nil {
+		log.Panicf("%v", err)
+	}
+	if !dExits {
+		writeError(w, r, errNotFound)
+		return
+	}
+
+	schedules, err := scheduling.ListSchedules(kv, id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if len(schedules) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	collection := SchedulesCollection{Schedules: make([]AtomLink, len(schedules))}
+	for i, schedule := range schedules {
+		collection.Schedules[i] = newAtomLink(LinkRelSchedule, path.Join("/deployments", id, "schedules", schedule.ID))
+	}
+	encodeJSONResponse(w, r, collection)
+}
+
+// getDeploymentSchedule returns the schedule referenced by the request parameters or writes a not found error and returns nil
+func (s *Server) getDeploymentSchedule(w http.ResponseWriter, r *http.Request) *scheduling.Schedule {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+	scheduleID := params.ByName("scheduleId")
+
+	schedule, err := scheduling.GetSchedule(s.consulClient.KV(), scheduleID)
+	if err != nil {
+		log.Panic(err)
+	}
+	if schedule == nil || schedule.DeploymentID != id {
+		writeError(w, r, errNotFound)
+		return nil
+	}
+	return schedule
+}
+
+func (s *Server) getScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	schedule := s.getDeploymentSchedule(w, r)
+	if schedule == nil {
+		return
+	}
+	kv := s.consulClient.KV()
+	history, err := scheduling.ListScheduleRuns(kv, schedule.ID)
+	if err != nil {
+		log.Panic(err)
+	}
+	result := Schedule{Schedule: *schedule, History: history, Links: []AtomLink{
+		newAtomLink(LinkRelSelf, r.URL.Path),
+		newAtomLink(LinkRelDeployment, path.Join("/deployments", schedule.DeploymentID)),
+		newAtomLink(LinkRelWorkflow, path.Join("/deployments", schedule.DeploymentID, "workflows", schedule.WorkflowName)),
+	}}
+	if !schedule.Paused {
+		next, err := schedule.Next(time.Now())
+		if err != nil {
+			log.Panic(err)
+		}
+		if !next.IsZero() {
+			result.NextRun = &next
+		}
+	}
+	latestTaskID, err := scheduling.GetScheduleLatestTaskID(kv, schedule.ID)
+	if err != nil {
+		log.Panic(err)
+	}
+	if latestTaskID != "" {
+		result.Links = append(result.Links, newAtomLink(LinkRelTask, path.Join("/deployments", schedule.DeploymentID, "tasks", latestTaskID)))
+	}
+	encodeJSONResponse(w, r, result)
+}
+
+func (s *Server) pauseScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	s.setSchedulePaused(w, r, true)
+}
+
+func (s *Server) resumeScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	s.setSchedulePaused(w, r, false)
+}
+
+func (s *Server) setSchedulePaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	schedule := s.getDeploymentSchedule(w, r)
+	if schedule == nil {
+		return
+	}
+	if schedule.Paused != paused {
+		if err := scheduling.SetSchedulePaused(s.consulClient.KV(), schedule.ID, paused); err != nil {
+			log.Panic(err)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	schedule := s.getDeploymentSchedule(w, r)
+	if schedule == nil {
+		return
+	}
+	if err := scheduling.DeleteSchedule(s.consulClient.KV(), schedule.ID); err != nil {
+		log.Panic(err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
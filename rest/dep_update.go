@@ -0,0 +1,209 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/ziputil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/policies"
+	"github.com/ystia/yorc/tasks"
+)
+
+func (s *Server) uploadDeploymentUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+
+	kv := s.consulClient.KV()
+	dExits, err := deployments.DoesDeploymentExists(kv, id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if !dExits || deployments.IsUpdateStagingID(id) {
+		writeError(w, r, errNotFound)
+		return
+	}
+
+	// A new update replaces the pending one
+	s.cleanupPendingUpdate(id)
+	stagingID := deployments.UpdateStagingID(id)
+	log.Printf("Analyzing update of deployment %s\n", id)
+	yamlPath, err := extractCSAR(r.Body, filepath.Join(s.config.WorkingDirectory, "deployments", stagingID))
+	if err != nil {
+		s.cleanupPendingUpdate(id)
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+	if err = deployments.StoreDeploymentDefinition(ctx, kv, stagingID, yamlPath); err != nil {
+		log.Debugf("ERROR: %+v", err)
+		s.cleanupPendingUpdate(id)
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+	if err = policies.CheckDeploymentPolicies(ctx, s.config, kv, stagingID); err != nil {
+		s.cleanupPendingUpdate(id)
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/deployments/%s/update/preview", id))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) previewDeploymentUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+
+	kv := s.consulClient.KV()
+	pending, err := deployments.HasPendingUpdate(kv, id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if !pending {
+		writeError(w, r, errNotFound)
+		return
+	}
+	diff, err := deployments.GetPendingUpdateDiff(kv, id)
+	if err != nil {
+		log.Panic(err)
+	}
+	encodeJSONResponse(w, r, diff)
+}
+
+func (s *Server) applyDeploymentUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+
+	kv := s.consulClient.KV()
+	pending, err := deployments.HasPendingUpdate(kv, id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if !pending {
+		writeError(w, r, errNotFound)
+		return
+	}
+	status, err := deployments.GetDeploymentStatus(kv, id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if status != deployments.DEPLOYED && status != deployments.UPDATE_FAILED {
+		writeError(w, r, newBadRequestMessage(fmt.Sprintf("Deployment with status %q can't be updated", status.String())))
+		return
+	}
+	hasLivingTask, livingTaskID, livingTaskStatus, err := tasks.TargetHasLivingTasks(kv, id)
+	if err != nil {
+		log.Panic(err)
+	} else if hasLivingTask {
+		writeError(w, r, newBadRequestError(tasks.NewAnotherLivingTaskAlreadyExistsError(livingTaskID, id, livingTaskStatus)))
+		return
+	}
+	diff, err := deployments.GetPendingUpdateDiff(kv, id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if diff.IsEmpty() {
+		writeError(w, r, newBadRequestMessage("The update doesn't change the deployment topology"))
+		return
+	}
+
+	updateOperation := r.URL.Query().Get("operation")
+	if updateOperation == "" {
+		updateOperation = deployments.DefaultUpdateOperation
+	}
+	// An applied update is replayed when applied again, so this request could be retried if a following step fails
+	diff, err = deployments.ApplyPendingUpdate(ctx, kv, id, updateOperation)
+	if err != nil {
+		log.Panic(err)
+	}
+	// Make artifacts of the updated topology available
+	stagingPath := filepath.Join(s.config.WorkingDirectory, "deployments", deployments.UpdateStagingID(id))
+	_, err = ziputil.Unzip(filepath.Join(stagingPath, "deployment.zip"), filepath.Join(s.config.WorkingDirectory, "deployments", id, "overlay"))
+	if err != nil {
+		log.Panic(err)
+	}
+
+	data := map[string]string{
+		"workflowName":    deployments.UpdateWorkflowName,
+		"updateOperation": updateOperation,
+		"removedNodes":    deployments.FormatRemovedNodes(diff.RemovedNodes),
+	}
+	taskID, err := s.tasksCollector.RegisterTaskWithData(id, tasks.TaskTypeUpdate, data)
+	if err != nil {
+		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		log.Panic(err)
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/deployments/%s/tasks/%s", id, taskID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) deleteDeploymentUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+
+	pending, err := deployments.HasPendingUpdate(s.consulClient.KV(), id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if !pending {
+		writeError(w, r, errNotFound)
+		return
+	}
+	hasLivingTask, livingTaskID, _, err := tasks.TargetHasLivingTasks(s.consulClient.KV(), id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if hasLivingTask {
+		taskType, err := tasks.GetTaskType(s.consulClient.KV(), livingTaskID)
+		if err != nil {
+			log.Panic(err)
+		}
+		if taskType == tasks.TaskTypeUpdate {
+			writeError(w, r, newBadRequestMessage("The update is currently being applied"))
+			return
+		}
+	}
+	s.cleanupPendingUpdate(id)
+	w.WriteHeader(http.StatusOK)
+}
+
+// cleanupPendingUpdate removes everything stored for the pending update of a deployment
+func (s *Server) cleanupPendingUpdate(deploymentID string) {
+	if err := deployments.DeletePendingUpdate(s.consulClient.KV(), deploymentID); err != nil {
+		log.Printf("[WARN] Failed to cleanup pending update of deployment %q: %v", deploymentID, err)
+	}
+	stagingPath := filepath.Join(s.config.WorkingDirectory, "deployments", deployments.UpdateStagingID(deploymentID))
+	if err := os.RemoveAll(stagingPath); err != nil {
+		log.Printf("[WARN] Failed to remove files of pending update of deployment %q: %v", deploymentID, err)
+	}
+}
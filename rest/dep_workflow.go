@@ -27,6 +27,7 @@ import (
 	"github.com/ystia/yorc/helper/collections"
 	"github.com/ystia/yorc/log"
 	"github.com/ystia/yorc/tasks"
+	"github.com/ystia/yorc/tasks/workflow/builder"
 )
 
 func (s *Server) newWorkflowHandler(w http.ResponseWriter, r *http.Request) {
@@ -55,6 +56,15 @@ func (s *Server) newWorkflowHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if isDryRunRequest(r) {
+		plan, err := builder.BuildPlan(ctx, s.consulClient.KV(), s.config, deploymentID, workflowName)
+		if err != nil {
+			log.Panic(err)
+		}
+		encodeJSONResponse(w, r, plan)
+		return
+	}
+
 	data := make(map[string]string)
 	data["workflowName"] = workflowName
 	if _, ok := r.URL.Query()["continueOnError"]; ok {
@@ -22,6 +22,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"regexp"
@@ -34,7 +35,9 @@ import (
 	"github.com/ystia/yorc/deployments"
 	"github.com/ystia/yorc/helper/consulutil"
 	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/prov/policies"
 	"github.com/ystia/yorc/tasks"
+	"github.com/ystia/yorc/tasks/workflow/builder"
 )
 
 func extractFile(f *zip.File, path string) {
@@ -94,11 +97,71 @@ func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 	} else {
 		uid = fmt.Sprint(uuid.NewV4())
 	}
+	dryRun := isDryRunRequest(r)
+	if dryRun {
+		// Definition is stored under a temporary id as the deployment should not be visible
+		// it is removed as soon as the plan is computed
+		uid = deployments.DryRunID(fmt.Sprint(uuid.NewV4()))
+		defer s.deleteStoredDeployment(uid)
+	}
 	log.Printf("Analyzing deployment %s\n", uid)
 
+	uploadPath := filepath.Join(s.config.WorkingDirectory, "deployments", uid)
+	yamlPath, err := extractCSAR(r.Body, uploadPath)
+	if err != nil {
+		if dryRun {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		log.Panic(err)
+	}
+
+	if err := deployments.StoreDeploymentDefinition(r.Context(), s.consulClient.KV(), uid, yamlPath); err != nil {
+		log.Debugf("ERROR: %+v", err)
+		if dryRun {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		log.Panic(err)
+	}
+	if err := policies.CheckDeploymentPolicies(r.Context(), s.config, s.consulClient.KV(), uid); err != nil {
+		if !dryRun {
+			s.deleteStoredDeployment(uid)
+		}
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+	if dryRun {
+		plan, err := builder.BuildPlan(r.Context(), s.consulClient.KV(), s.config, uid, "install")
+		if err != nil {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		encodeJSONResponse(w, r, plan)
+		return
+	}
+	data := map[string]string{
+		"workflowName": "install",
+	}
+	taskID, err := s.tasksCollector.RegisterTaskWithData(uid, tasks.TaskTypeDeploy, data)
+	if err != nil {
+		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		log.Panic(err)
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/deployments/%s/tasks/%s", uid, taskID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// extractCSAR stores the given CSAR archive under uploadPath and extracts it into the overlay directory.
+//
+// It returns the path of the TOSCA definition at the root of the archive.
+func extractCSAR(body io.Reader, uploadPath string) (string, error) {
 	var err error
 	var file *os.File
-	uploadPath := filepath.Join(s.config.WorkingDirectory, "deployments", uid)
 	if err = os.MkdirAll(uploadPath, 0775); err != nil {
 		log.Panicf("%+v", err)
 	}
@@ -108,8 +171,9 @@ func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Panicf("%+v", err)
 	}
+	defer file.Close()
 
-	_, err = io.Copy(file, r.Body)
+	_, err = io.Copy(file, body)
 	if err != nil {
 		log.Panicf("%+v", err)
 	}
@@ -153,27 +217,39 @@ func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if len(yamlList) != 1 {
-		log.Panic("One and only one YAML (.yml or .yaml) file should be present at the root of deployment archive")
+		return "", errors.New("One and only one YAML (.yml or .yaml) file should be present at the root of deployment archive")
 	}
+	return yamlList[0], nil
+}
 
-	if err := deployments.StoreDeploymentDefinition(r.Context(), s.consulClient.KV(), uid, yamlList[0]); err != nil {
-		log.Debugf("ERROR: %+v", err)
-		log.Panic(err)
+// isDryRunRequest checks if the dryRun query parameter is set, a dry-run request computes an execution plan without executing it
+func isDryRunRequest(r *http.Request) bool {
+	values, ok := r.URL.Query()["dryRun"]
+	if !ok {
+		return false
 	}
-	data := map[string]string{
-		"workflowName": "install",
+	if len(values) == 0 || values[0] == "" {
+		return true
 	}
-	taskID, err := s.tasksCollector.RegisterTaskWithData(uid, tasks.TaskTypeDeploy, data)
-	if err != nil {
-		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
-			writeError(w, r, newBadRequestError(err))
-			return
+	dryRun, err := strconv.ParseBool(values[0])
+	return err == nil && dryRun
+}
+
+// deleteStoredDeployment removes everything stored for a deployment that should not be kept,
+// either because it was submitted in dry-run mode or because it was rejected by policies
+func (s *Server) deleteStoredDeployment(deploymentID string) {
+	kv := s.consulClient.KV()
+	for _, prefix := range []string{consulutil.DeploymentKVPrefix, consulutil.EventsPrefix, consulutil.LogsPrefix} {
+		// The trailing slash prevents from deleting data of other deployments having an ID starting with this one
+		_, err := kv.DeleteTree(path.Join(prefix, deploymentID)+"/", nil)
+		if err != nil {
+			log.Printf("[WARN] Failed to cleanup data of deployment %q: %v", deploymentID, err)
 		}
-		log.Panic(err)
 	}
-
-	w.Header().Set("Location", fmt.Sprintf("/deployments/%s/tasks/%s", uid, taskID))
-	w.WriteHeader(http.StatusCreated)
+	overlayPath := filepath.Join(s.config.WorkingDirectory, "deployments", deploymentID)
+	if err := os.RemoveAll(overlayPath); err != nil {
+		log.Printf("[WARN] Failed to remove files of deployment %q: %v", deploymentID, err)
+	}
 }
 
 func (s *Server) deleteDeploymentHandler(w http.ResponseWriter, r *http.Request) {
@@ -277,10 +353,14 @@ func (s *Server) listDeploymentsHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	depCol := DeploymentsCollection{Deployments: make([]Deployment, len(depPaths))}
+	depCol := DeploymentsCollection{Deployments: make([]Deployment, 0, len(depPaths))}
 	depPrefix := consulutil.DeploymentKVPrefix + "/"
-	for depIndex, depPath := range depPaths {
+	for _, depPath := range depPaths {
 		deploymentID := strings.TrimRight(strings.TrimPrefix(depPath, depPrefix), "/ ")
+		if deployments.IsUpdateStagingID(deploymentID) || deployments.IsDryRunID(deploymentID) {
+			// Pending updates and dry-run deployments are not actual deployments
+			continue
+		}
 		status, err := deployments.GetDeploymentStatus(kv, deploymentID)
 		if err != nil {
 			if deployments.IsDeploymentNotFoundError(err) {
@@ -290,11 +370,11 @@ func (s *Server) listDeploymentsHandler(w http.ResponseWriter, r *http.Request)
 				log.Panic(err)
 			}
 		}
-		depCol.Deployments[depIndex] = Deployment{
+		depCol.Deployments = append(depCol.Deployments, Deployment{
 			ID:     deploymentID,
 			Status: status.String(),
 			Links:  []AtomLink{newAtomLink(LinkRelDeployment, "/deployments/"+deploymentID)},
-		}
+		})
 	}
 	encodeJSONResponse(w, r, depCol)
 }
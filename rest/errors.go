@@ -17,6 +17,7 @@ package rest
 import (
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 // Errors is a collection of REST errors
@@ -77,3 +78,11 @@ func newBadRequestMessage(message string) *Error {
 func newConflictRequest(message string) *Error {
 	return &Error{"conflict", http.StatusConflict, "Conflict", message}
 }
+
+func newUnauthorizedError(message string) *Error {
+	return &Error{"unauthorized", http.StatusUnauthorized, "Unauthorized", message}
+}
+
+func newForbiddenError(required Role) *Error {
+	return &Error{"forbidden", http.StatusForbidden, "Forbidden", fmt.Sprintf("This operation requires the %q role.", strings.ToLower(required.String()))}
+}
@@ -0,0 +1,141 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/log"
+)
+
+const (
+	// SSEEventTypeEvent is the Server-Sent Events type of status change events
+	SSEEventTypeEvent string = "event"
+	// SSEEventTypeLog is the Server-Sent Events type of log entries
+	SSEEventTypeLog string = "log"
+)
+
+// sseKeepAliveInterval is the maximum duration of a Consul blocking query in a stream,
+// a comment is sent to the client at this interval when there is nothing new to send
+// in order to keep the connection open and detect closed connections.
+var sseKeepAliveInterval = 30 * time.Second
+
+type entriesFetcher func(kv *api.KV, deploymentID string, waitIndex uint64, timeout time.Duration) ([]json.RawMessage, uint64, error)
+
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	s.streamEntries(w, r, SSEEventTypeEvent, events.StatusEvents)
+}
+
+func (s *Server) streamLogs(w http.ResponseWriter, r *http.Request) {
+	s.streamEntries(w, r, SSEEventTypeLog, events.LogsEvents)
+}
+
+// getStreamStartIndex returns the Consul index from which entries should be streamed.
+//
+// The Last-Event-ID header sent by clients on reconnection takes precedence on the index query parameter.
+func getStreamStartIndex(r *http.Request) (uint64, error) {
+	idx := r.Header.Get("Last-Event-ID")
+	if idx == "" {
+		idx = r.URL.Query().Get("index")
+	}
+	if idx == "" {
+		return 1, nil
+	}
+	return strconv.ParseUint(idx, 10, 64)
+}
+
+// streamEntries pushes entries using the text/event-stream format as they are stored in Consul.
+//
+// The SSE id of an event is the Consul index of its batch, it is only set on the last event of a batch so
+// a client reconnecting in the middle of a batch will get duplicates rather than missing entries.
+func (s *Server) streamEntries(w http.ResponseWriter, r *http.Request, eventType string, fetch entriesFetcher) {
+	var params httprouter.Params
+	ctx := r.Context()
+	kv := s.consulClient.KV()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+	if id != "" {
+		if depExist, err := deployments.DoesDeploymentExists(kv, id); err != nil {
+			log.Panic(err)
+		} else if !depExist {
+			writeError(w, r, errNotFound)
+			return
+		}
+	}
+
+	waitIndex, err := getStreamStartIndex(r)
+	if err != nil {
+		writeError(w, r, newBadRequestParameter("index", err))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		log.Panic(errors.New("streaming is not supported by the response writer"))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	// Tells clients to wait 1s before reconnecting if the connection is lost
+	fmt.Fprint(w, "retry: 1000\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		entries, lastIdx, err := fetch(kv, id, waitIndex, sseKeepAliveInterval)
+		if err != nil {
+			log.Printf("Failed to retrieve %ss to stream: %v", eventType, err)
+			return
+		}
+		if len(entries) == 0 {
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		for i, entry := range entries {
+			if i == len(entries)-1 {
+				_, err = fmt.Fprintf(w, "id: %d\n", lastIdx)
+			}
+			if err == nil {
+				_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, entry)
+			}
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			log.Debugf("Stream of %ss closed: %v", eventType, err)
+			return
+		}
+		flusher.Flush()
+		if lastIdx > waitIndex {
+			waitIndex = lastIdx
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/tasks/collector"
+)
+
+func testStreamEvents(t *testing.T, client *api.Client) {
+	sseKeepAliveInterval = 500 * time.Millisecond
+	deploymentID := "testStreamEvents"
+	_, err := events.DeploymentStatusChange(client.KV(), deploymentID, "deployment_in_progress")
+	require.NoError(t, err)
+
+	httpSrv := &Server{
+		router:         newRouter(),
+		consulClient:   client,
+		tasksCollector: collector.NewCollector(client),
+		config:         config.Configuration{},
+	}
+	httpSrv.registerHandlers()
+	ts := httptest.NewServer(httpSrv.router)
+	defer ts.Close()
+
+	req, err := http.NewRequest("GET", ts.URL+"/events/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// Publish an event while streaming
+	_, err = events.DeploymentStatusChange(client.KV(), deploymentID, "deployed")
+	require.NoError(t, err)
+
+	var data []string
+	var lastID string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() && len(data) < 2 {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "data: ") {
+			data = append(data, line)
+		}
+		if strings.HasPrefix(line, "id: ") {
+			lastID = strings.TrimPrefix(line, "id: ")
+		}
+	}
+	require.Len(t, data, 2)
+	assert.Contains(t, data[0], "deployment_in_progress")
+	assert.Contains(t, data[1], "deployed")
+	assert.NotEmpty(t, lastID)
+}
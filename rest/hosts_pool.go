@@ -32,8 +32,9 @@ func (s *Server) deleteHostInPool(w http.ResponseWriter, r *http.Request) {
 	var params httprouter.Params
 	ctx := r.Context()
 	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	poolName := getPoolName(params)
 	hostname := params.ByName("host")
-	err := s.hostsPoolMgr.Remove(hostname)
+	err := s.hostsPoolMgr.Remove(poolName, hostname)
 	if err != nil {
 		if hostspool.IsHostNotFoundError(err) {
 			writeError(w, r, errNotFound)
@@ -52,6 +53,7 @@ func (s *Server) newHostInPool(w http.ResponseWriter, r *http.Request) {
 	var params httprouter.Params
 	ctx := r.Context()
 	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	poolName := getPoolName(params)
 	hostname := params.ByName("host")
 
 	body, err := ioutil.ReadAll(r.Body)
@@ -79,7 +81,7 @@ func (s *Server) newHostInPool(w http.ResponseWriter, r *http.Request) {
 		labels[entry.Name] = entry.Value
 	}
 
-	err = s.hostsPoolMgr.Add(hostname, *host.Connection, labels)
+	err = s.hostsPoolMgr.Add(poolName, hostname, *host.Connection, labels)
 	if err != nil {
 		if hostspool.IsHostAlreadyExistError(err) || hostspool.IsBadRequestError(err) {
 			writeError(w, r, newBadRequestError(err))
@@ -87,7 +89,7 @@ func (s *Server) newHostInPool(w http.ResponseWriter, r *http.Request) {
 		}
 		log.Panic(err)
 	}
-	w.Header().Set("Location", fmt.Sprintf("/hosts_pool/%s", hostname))
+	w.Header().Set("Location", fmt.Sprintf("/hosts_pools/%s/%s", poolName, hostname))
 	w.WriteHeader(http.StatusCreated)
 }
 
@@ -95,6 +97,7 @@ func (s *Server) updateHostInPool(w http.ResponseWriter, r *http.Request) {
 	var params httprouter.Params
 	ctx := r.Context()
 	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	poolName := getPoolName(params)
 	hostname := params.ByName("host")
 
 	body, err := ioutil.ReadAll(r.Body)
@@ -109,18 +112,12 @@ func (s *Server) updateHostInPool(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The status is updated last as re-enabling a host checks its connection
 	if host.Connection != nil {
-		err = s.hostsPoolMgr.UpdateConnection(hostname, *host.Connection)
+		err = s.hostsPoolMgr.UpdateConnection(poolName, hostname, *host.Connection)
 		if err != nil {
-			if hostspool.IsBadRequestError(err) {
-				writeError(w, r, newBadRequestError(err))
-				return
-			}
-			if hostspool.IsHostNotFoundError(err) {
-				writeError(w, r, errNotFound)
-				return
-			}
-			log.Panic(err)
+			writeHostUpdateError(w, r, err)
+			return
 		}
 	}
 	labelsAdd := make(map[string]string)
@@ -134,43 +131,53 @@ func (s *Server) updateHostInPool(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if len(labelsDelete) > 0 {
-		err = s.hostsPoolMgr.RemoveLabels(hostname, labelsDelete)
+		err = s.hostsPoolMgr.RemoveLabels(poolName, hostname, labelsDelete)
 		if err != nil {
-			if hostspool.IsBadRequestError(err) {
-				writeError(w, r, newBadRequestError(err))
-				return
-			}
-			if hostspool.IsHostNotFoundError(err) {
-				writeError(w, r, errNotFound)
-				return
-			}
-			log.Panic(err)
+			writeHostUpdateError(w, r, err)
+			return
 		}
 	}
 	if len(labelsAdd) > 0 {
-		err = s.hostsPoolMgr.AddLabels(hostname, labelsAdd)
+		err = s.hostsPoolMgr.AddLabels(poolName, hostname, labelsAdd)
 		if err != nil {
-			if hostspool.IsBadRequestError(err) {
-				writeError(w, r, newBadRequestError(err))
-				return
-			}
-			if hostspool.IsHostNotFoundError(err) {
-				writeError(w, r, errNotFound)
-				return
-			}
-			log.Panic(err)
+			writeHostUpdateError(w, r, err)
+			return
+		}
+	}
+	if host.Status != nil {
+		err = s.hostsPoolMgr.UpdateHostStatus(poolName, hostname, *host.Status, host.Reason)
+		if err != nil {
+			writeHostUpdateError(w, r, err)
+			return
 		}
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
+// writeHostUpdateError writes the response of a failed update of a host.
+//
+// A host whose connection fails after being updated results in a conflict error, the update is still applied.
+func writeHostUpdateError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case hostspool.IsBadRequestError(err):
+		writeError(w, r, newBadRequestError(err))
+	case hostspool.IsHostNotFoundError(err):
+		writeError(w, r, errNotFound)
+	case hostspool.IsHostConnectionError(err):
+		writeError(w, r, newConflictRequest(err.Error()))
+	default:
+		log.Panic(err)
+	}
+}
+
 func (s *Server) getHostInPool(w http.ResponseWriter, r *http.Request) {
 	var params httprouter.Params
 	ctx := r.Context()
 	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	poolName := getPoolName(params)
 	hostname := params.ByName("host")
 
-	host, err := s.hostsPoolMgr.GetHost(hostname)
+	host, err := s.hostsPoolMgr.GetHost(poolName, hostname)
 	if err != nil {
 		if hostspool.IsHostNotFoundError(err) {
 			writeError(w, r, errNotFound)
@@ -184,11 +191,32 @@ func (s *Server) getHostInPool(w http.ResponseWriter, r *http.Request) {
 	}
 
 	restHost := Host{Host: host, Links: make([]AtomLink, 1)}
-	restHost.Links[0] = newAtomLink(LinkRelSelf, fmt.Sprintf("/hosts_pool/%s", hostname))
+	restHost.Links[0] = newAtomLink(LinkRelSelf, fmt.Sprintf("/hosts_pools/%s/%s", poolName, hostname))
 	encodeJSONResponse(w, r, restHost)
 }
 
+func (s *Server) listHostsPools(w http.ResponseWriter, r *http.Request) {
+	pools, err := s.hostsPoolMgr.ListPools()
+	if err != nil {
+		log.Panic(err)
+	}
+	if len(pools) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	poolsCol := HostsPoolsCollection{Pools: make([]AtomLink, len(pools))}
+	for i, p := range pools {
+		poolsCol.Pools[i] = newAtomLink(LinkRelHostsPool, fmt.Sprintf("/hosts_pools/%s", p))
+	}
+	encodeJSONResponse(w, r, poolsCol)
+}
+
 func (s *Server) listHostsInPool(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	poolName := getPoolName(params)
+
 	filtersString := r.URL.Query()["filter"]
 	filters := make([]labelsutil.Filter, len(filtersString))
 	for i := range filtersString {
@@ -200,23 +228,35 @@ func (s *Server) listHostsInPool(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	hostsNames, warnings, checkpoint, err := s.hostsPoolMgr.List(filters...)
+	hostsNames, warnings, checkpoint, err := s.hostsPoolMgr.List(poolName, filters...)
+	if err != nil {
+		if hostspool.IsBadRequestError(err) {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		log.Panic(err)
+	}
+
+	pending, err := s.hostsPoolMgr.ListPendingAllocations(poolName)
 	if err != nil {
 		log.Panic(err)
 	}
 
-	if len(hostsNames) == 0 && len(warnings) == 0 {
+	if len(hostsNames) == 0 && len(warnings) == 0 && len(pending) == 0 {
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
 	hostsCol := HostsCollection{}
 	hostsCol.Checkpoint = checkpoint
+	if len(pending) > 0 {
+		hostsCol.PendingAllocations = pending
+	}
 	if len(hostsNames) > 0 {
 		hostsCol.Hosts = make([]AtomLink, len(hostsNames))
 	}
 	for i, h := range hostsNames {
-		hostsCol.Hosts[i] = newAtomLink(LinkRelHost, fmt.Sprintf("/hosts_pool/%s", h))
+		hostsCol.Hosts[i] = newAtomLink(LinkRelHost, fmt.Sprintf("/hosts_pools/%s/%s", poolName, h))
 	}
 	if len(warnings) > 0 {
 		hostsCol.Warnings = make([]string, len(warnings))
@@ -229,6 +269,11 @@ func (s *Server) listHostsInPool(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) applyHostsPool(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	poolName := getPoolName(params)
+
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		log.Panic(err)
@@ -265,7 +310,7 @@ func (s *Server) applyHostsPool(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	err = s.hostsPoolMgr.Apply(pool, hostsPoolCheckpoint)
+	err = s.hostsPoolMgr.Apply(poolName, pool, hostsPoolCheckpoint)
 	if err != nil {
 		if hostspool.IsHostAlreadyExistError(err) || hostspool.IsBadRequestError(err) {
 			writeError(w, r, newBadRequestError(err))
@@ -280,3 +325,13 @@ func (s *Server) applyHostsPool(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}
 }
+
+// getPoolName returns the hosts pool name of a request, deprecated routes
+// without pool name apply to the default hosts pool
+func getPoolName(params httprouter.Params) string {
+	poolName := params.ByName("pool")
+	if poolName == "" {
+		return hostspool.DefaultPoolName
+	}
+	return poolName
+}
@@ -33,6 +33,12 @@ func testHostsPoolHandlers(t *testing.T, client *api.Client, srv *testutil.TestS
 	t.Run("testListHostsInPool", func(t *testing.T) {
 		testListHostsInPool(t, client, srv)
 	})
+	t.Run("testListHostsPools", func(t *testing.T) {
+		testListHostsPools(t, client, srv)
+	})
+	t.Run("testDeprecatedHostsPoolRoutes", func(t *testing.T) {
+		testDeprecatedHostsPoolRoutes(t, client, srv)
+	})
 	t.Run("testListNoHostsInPool", func(t *testing.T) {
 		testListNoHostsInPool(t, client, srv)
 	})
@@ -63,12 +69,12 @@ func testListHostsInPool(t *testing.T, client *api.Client, srv *testutil.TestSer
 	log.SetDebug(true)
 
 	srv.PopulateKV(t, map[string][]byte{
-		consulutil.HostsPoolPrefix + "/host21/status": []byte("free"),
-		consulutil.HostsPoolPrefix + "/host22/status": []byte("free"),
-		consulutil.HostsPoolPrefix + "/host23/status": []byte("free"),
+		consulutil.HostsPoolPrefix + "/pool1/host21/status": []byte("free"),
+		consulutil.HostsPoolPrefix + "/pool1/host22/status": []byte("free"),
+		consulutil.HostsPoolPrefix + "/pool1/host23/status": []byte("free"),
 	})
 
-	req := httptest.NewRequest("GET", "/hosts_pool", nil)
+	req := httptest.NewRequest("GET", "/hosts_pools/pool1", nil)
 	req.Header.Add("Accept", "application/json")
 	resp := newTestHTTPRouter(client, req)
 	body, err := ioutil.ReadAll(resp.Body)
@@ -83,22 +89,80 @@ func testListHostsInPool(t *testing.T, client *api.Client, srv *testutil.TestSer
 	require.Nil(t, err, "unexpected error unmarshalling json body")
 	require.NotNil(t, collection, "unexpected nil hosts collection")
 	require.Equal(t, 3, len(collection.Hosts))
-	require.Equal(t, "/hosts_pool/host21", collection.Hosts[0].Href)
+	require.Equal(t, "/hosts_pools/pool1/host21", collection.Hosts[0].Href)
 	require.Equal(t, "host", collection.Hosts[0].Rel)
-	require.Equal(t, "/hosts_pool/host22", collection.Hosts[1].Href)
+	require.Equal(t, "/hosts_pools/pool1/host22", collection.Hosts[1].Href)
 	require.Equal(t, "host", collection.Hosts[1].Rel)
-	require.Equal(t, "/hosts_pool/host23", collection.Hosts[2].Href)
+	require.Equal(t, "/hosts_pools/pool1/host23", collection.Hosts[2].Href)
 	require.Equal(t, "host", collection.Hosts[2].Rel)
 
-	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/host21", nil)
-	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/host22", nil)
-	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/host23", nil)
+	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/pool1/host21", nil)
+	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/pool1/host22", nil)
+	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/pool1/host23", nil)
+}
+
+func testListHostsPools(t *testing.T, client *api.Client, srv *testutil.TestServer) {
+	srv.PopulateKV(t, map[string][]byte{
+		consulutil.HostsPoolPrefix + "/pool2/host31/status": []byte("free"),
+		consulutil.HostsPoolPrefix + "/pool3/host31/status": []byte("free"),
+	})
+
+	req := httptest.NewRequest("GET", "/hosts_pools", nil)
+	req.Header.Add("Accept", "application/json")
+	resp := newTestHTTPRouter(client, req)
+	body, err := ioutil.ReadAll(resp.Body)
+
+	require.Nil(t, err, "unexpected error reading body response")
+	require.Equal(t, http.StatusOK, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusOK)
+
+	var collection HostsPoolsCollection
+	err = json.Unmarshal(body, &collection)
+	require.Nil(t, err, "unexpected error unmarshalling json body")
+	require.Equal(t, 2, len(collection.Pools))
+	require.Equal(t, "/hosts_pools/pool2", collection.Pools[0].Href)
+	require.Equal(t, "hosts_pool", collection.Pools[0].Rel)
+	require.Equal(t, "/hosts_pools/pool3", collection.Pools[1].Href)
+
+	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/pool2", nil)
+	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/pool3", nil)
+}
+
+func testDeprecatedHostsPoolRoutes(t *testing.T, client *api.Client, srv *testutil.TestServer) {
+	srv.PopulateKV(t, map[string][]byte{
+		consulutil.HostsPoolPrefix + "/default/host41/status": []byte("free"),
+		consulutil.HostsPoolPrefix + "/pool4/host42/status":   []byte("free"),
+	})
+
+	// Routes without pool name apply to the default pool
+	req := httptest.NewRequest("GET", "/hosts_pool", nil)
+	req.Header.Add("Accept", "application/json")
+	resp := newTestHTTPRouter(client, req)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err, "unexpected error reading body response")
+	require.Equal(t, http.StatusOK, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusOK)
+
+	var collection HostsCollection
+	err = json.Unmarshal(body, &collection)
+	require.Nil(t, err, "unexpected error unmarshalling json body")
+	require.Equal(t, 1, len(collection.Hosts))
+	require.Equal(t, "/hosts_pools/default/host41", collection.Hosts[0].Href)
+
+	req = httptest.NewRequest("DELETE", "/hosts_pool/host41", nil)
+	resp = newTestHTTPRouter(client, req)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusOK)
+
+	req = httptest.NewRequest("DELETE", "/hosts_pool/host42", nil)
+	resp = newTestHTTPRouter(client, req)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "host of another pool should not be found using deprecated routes")
+
+	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/default", nil)
+	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/pool4", nil)
 }
 
 func testListNoHostsInPool(t *testing.T, client *api.Client, srv *testutil.TestServer) {
 	t.Parallel()
 
-	req := httptest.NewRequest("GET", "/hosts_pool", nil)
+	req := httptest.NewRequest("GET", "/hosts_pools/empty_pool", nil)
 	req.Header.Add("Accept", "application/json")
 	resp := newTestHTTPRouter(client, req)
 	_, err := ioutil.ReadAll(resp.Body)
@@ -112,7 +176,7 @@ func testListHostsInPoolWithBadFilter(t *testing.T, client *api.Client, srv *tes
 	t.Parallel()
 	log.SetDebug(true)
 
-	req := httptest.NewRequest("GET", "/hosts_pool", nil)
+	req := httptest.NewRequest("GET", "/hosts_pools/pool1", nil)
 	filters := []string{"bad++"}
 	q := req.URL.Query()
 	for i := range filters {
@@ -131,14 +195,14 @@ func testListHostsInPoolWithBadFilter(t *testing.T, client *api.Client, srv *tes
 func testDeleteHostInPool(t *testing.T, client *api.Client, srv *testutil.TestServer) {
 	t.Parallel()
 	srv.PopulateKV(t, map[string][]byte{
-		consulutil.HostsPoolPrefix + "/host13/status":                 []byte("free"),
-		consulutil.HostsPoolPrefix + "/host13/connection/host":        []byte("1.2.3.4"),
-		consulutil.HostsPoolPrefix + "/host13/connection/port":        []byte("22"),
-		consulutil.HostsPoolPrefix + "/host13/connection/private_key": []byte("test/cert1.pem"),
-		consulutil.HostsPoolPrefix + "/host13/connection/user":        []byte("user1"),
+		consulutil.HostsPoolPrefix + "/pool1/host13/status":                 []byte("free"),
+		consulutil.HostsPoolPrefix + "/pool1/host13/connection/host":        []byte("1.2.3.4"),
+		consulutil.HostsPoolPrefix + "/pool1/host13/connection/port":        []byte("22"),
+		consulutil.HostsPoolPrefix + "/pool1/host13/connection/private_key": []byte("test/cert1.pem"),
+		consulutil.HostsPoolPrefix + "/pool1/host13/connection/user":        []byte("user1"),
 	})
 
-	req := httptest.NewRequest("DELETE", "/hosts_pool/host13", nil)
+	req := httptest.NewRequest("DELETE", "/hosts_pools/pool1/host13", nil)
 	resp := newTestHTTPRouter(client, req)
 	_, err := ioutil.ReadAll(resp.Body)
 
@@ -150,7 +214,7 @@ func testDeleteHostInPool(t *testing.T, client *api.Client, srv *testutil.TestSe
 func testDeleteHostInPoolNotFound(t *testing.T, client *api.Client, srv *testutil.TestServer) {
 	t.Parallel()
 
-	req := httptest.NewRequest("DELETE", "/hosts_pool/hostNOTFOUND", nil)
+	req := httptest.NewRequest("DELETE", "/hosts_pools/pool1/hostNOTFOUND", nil)
 	resp := newTestHTTPRouter(client, req)
 	_, err := ioutil.ReadAll(resp.Body)
 
@@ -173,15 +237,15 @@ func testNewHostInPool(t *testing.T, client *api.Client, srv *testutil.TestServe
 
 	tmp, err := json.Marshal(hostRequest)
 	require.Nil(t, err, "unexpected error marshalling data to provide body request")
-	req := httptest.NewRequest("PUT", "/hosts_pool/host11", bytes.NewBuffer([]byte(string(tmp))))
+	req := httptest.NewRequest("PUT", "/hosts_pools/pool1/host11", bytes.NewBuffer([]byte(string(tmp))))
 	req.Header.Add("Content-Type", "application/json")
 	resp := newTestHTTPRouter(client, req)
 	_, err = ioutil.ReadAll(resp.Body)
 	require.NotNil(t, resp, "unexpected nil response")
 	require.Equal(t, http.StatusCreated, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusCreated)
-	require.Equal(t, []string{"/hosts_pool/host11"}, resp.Header["Location"])
+	require.Equal(t, []string{"/hosts_pools/pool1/host11"}, resp.Header["Location"])
 
-	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/host11", nil)
+	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/pool1/host11", nil)
 }
 
 func testNewHostInPoolWithoutConnectionInfo(t *testing.T, client *api.Client, srv *testutil.TestServer) {
@@ -192,7 +256,7 @@ func testNewHostInPoolWithoutConnectionInfo(t *testing.T, client *api.Client, sr
 
 	tmp, err := json.Marshal(hostRequest)
 	require.Nil(t, err, "unexpected error marshalling data to provide body request")
-	req := httptest.NewRequest("PUT", "/hosts_pool/host12", bytes.NewBuffer([]byte(string(tmp))))
+	req := httptest.NewRequest("PUT", "/hosts_pools/pool1/host12", bytes.NewBuffer([]byte(string(tmp))))
 	req.Header.Add("Content-Type", "application/json")
 	resp := newTestHTTPRouter(client, req)
 	_, err = ioutil.ReadAll(resp.Body)
@@ -213,7 +277,7 @@ func testNewHostInPoolWithoutPrivateKeyOrPassword(t *testing.T, client *api.Clie
 
 	tmp, err := json.Marshal(hostRequest)
 	require.Nil(t, err, "unexpected error marshalling data to provide body request")
-	req := httptest.NewRequest("PUT", "/hosts_pool/host11", bytes.NewBuffer([]byte(string(tmp))))
+	req := httptest.NewRequest("PUT", "/hosts_pools/pool1/host11", bytes.NewBuffer([]byte(string(tmp))))
 	req.Header.Add("Content-Type", "application/json")
 	resp := newTestHTTPRouter(client, req)
 	_, err = ioutil.ReadAll(resp.Body)
@@ -225,14 +289,14 @@ func testGetHostInPool(t *testing.T, client *api.Client, srv *testutil.TestServe
 	t.Parallel()
 
 	srv.PopulateKV(t, map[string][]byte{
-		consulutil.HostsPoolPrefix + "/host17/status":                 []byte("free"),
-		consulutil.HostsPoolPrefix + "/host17/connection/host":        []byte("1.2.3.4"),
-		consulutil.HostsPoolPrefix + "/host17/connection/port":        []byte("22"),
-		consulutil.HostsPoolPrefix + "/host17/connection/private_key": []byte("test/cert1.pem"),
-		consulutil.HostsPoolPrefix + "/host17/connection/user":        []byte("user1"),
+		consulutil.HostsPoolPrefix + "/pool1/host17/status":                 []byte("free"),
+		consulutil.HostsPoolPrefix + "/pool1/host17/connection/host":        []byte("1.2.3.4"),
+		consulutil.HostsPoolPrefix + "/pool1/host17/connection/port":        []byte("22"),
+		consulutil.HostsPoolPrefix + "/pool1/host17/connection/private_key": []byte("test/cert1.pem"),
+		consulutil.HostsPoolPrefix + "/pool1/host17/connection/user":        []byte("user1"),
 	})
 
-	req := httptest.NewRequest("GET", "/hosts_pool/host17", nil)
+	req := httptest.NewRequest("GET", "/hosts_pools/pool1/host17", nil)
 	req.Header.Add("Accept", "application/json")
 	resp := newTestHTTPRouter(client, req)
 	body, err := ioutil.ReadAll(resp.Body)
@@ -258,5 +322,5 @@ func testGetHostInPool(t *testing.T, client *api.Client, srv *testutil.TestServe
 
 	require.Equal(t, hostspool.HostStatusFree, host.Status, "unexpected not free host status")
 
-	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/host17", nil)
+	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/pool1/host17", nil)
 }
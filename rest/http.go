@@ -83,6 +83,7 @@ type Server struct {
 	tasksCollector *collector.Collector
 	config         config.Configuration
 	hostsPoolMgr   hostspool.Manager
+	authenticators []authenticator
 }
 
 // Shutdown stops the HTTP server
@@ -123,6 +124,15 @@ func NewServer(configuration config.Configuration, client *api.Client, shutdownC
 		hostsPoolMgr:   hostspool.NewManager(client),
 	}
 
+	if configuration.HTTPAuth.Enabled {
+		httpServer.authenticators, err = newAuthenticators(configuration.HTTPAuth)
+		if err != nil {
+			listener.Close()
+			return nil, errors.Wrap(err, "Failed to setup HTTP authentication")
+		}
+		log.Printf("HTTP authentication enabled")
+	}
+
 	httpServer.registerHandlers()
 	if sslEnabled {
 		log.Printf("Starting HTTPServer over TLS on address %s", listener.Addr())
@@ -142,58 +152,101 @@ func NewServer(configuration config.Configuration, client *api.Client, shutdownC
 
 func (s *Server) registerHandlers() {
 	commonHandlers := alice.New(telemetryHandler, loggingHandler, recoverHandler)
+	viewerHandlers := commonHandlers.Append(s.requireRole(RoleViewer))
+	operatorHandlers := commonHandlers.Append(s.requireRole(RoleOperator))
+	adminHandlers := commonHandlers.Append(s.requireRole(RoleAdmin))
 	s.router.Get("/health", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getHealthHandler))
-	s.router.Post("/deployments", commonHandlers.Append(contentTypeHandler("application/zip")).ThenFunc(s.newDeploymentHandler))
-	s.router.Put("/deployments/:id", commonHandlers.Append(contentTypeHandler("application/zip")).ThenFunc(s.newDeploymentHandler))
-	s.router.Delete("/deployments/:id", commonHandlers.ThenFunc(s.deleteDeploymentHandler))
-	s.router.Get("/deployments/:id", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getDeploymentHandler))
-	s.router.Get("/deployments", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listDeploymentsHandler))
-	s.router.Get("/deployments/:id/events", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.pollEvents))
-	s.router.Get("/events", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.pollEvents))
-	s.router.Head("/deployments/:id/events", commonHandlers.ThenFunc(s.headEventsIndex))
-	s.router.Head("/events", commonHandlers.ThenFunc(s.headEventsIndex))
-	s.router.Get("/deployments/:id/logs", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.pollLogs))
-	s.router.Get("/logs", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.pollLogs))
-	s.router.Head("/deployments/:id/logs", commonHandlers.ThenFunc(s.headLogsEventsIndex))
-	s.router.Head("/logs", commonHandlers.ThenFunc(s.headLogsEventsIndex))
-	s.router.Get("/deployments/:id/nodes/:nodeName", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getNodeHandler))
-	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getNodeInstanceHandler))
-	s.router.Get("/deployments/:id/outputs", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listOutputsHandler))
-	s.router.Get("/deployments/:id/outputs/:opt", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getOutputHandler))
-	s.router.Get("/deployments/:id/tasks/:taskId", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getTaskHandler))
-	s.router.Get("/deployments/:id/tasks/:taskId/steps", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getTaskStepsHandler))
-	s.router.Delete("/deployments/:id/tasks/:taskId", commonHandlers.ThenFunc(s.cancelTaskHandler))
-	s.router.Put("/deployments/:id/tasks/:taskId", commonHandlers.ThenFunc(s.resumeTaskHandler))
-	s.router.Put("/deployments/:id/tasks/:taskId/steps/:stepId", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.updateTaskStepStatusHandler))
-	s.router.Post("/deployments/:id/scale/:nodeName", commonHandlers.ThenFunc(s.scaleHandler))
-	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId/attributes", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getNodeInstanceAttributesListHandler))
-	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId/attributes/:attributeName", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getNodeInstanceAttributeHandler))
-	s.router.Post("/deployments/:id/custom", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.newCustomCommandHandler))
-	s.router.Post("/deployments/:id/workflows/:workflowName", commonHandlers.ThenFunc(s.newWorkflowHandler))
-	s.router.Get("/deployments/:id/workflows/:workflowName", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getWorkflowHandler))
-	s.router.Get("/deployments/:id/workflows", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listWorkflowsHandler))
-
-	s.router.Get("/registry/delegates", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listRegistryDelegatesHandler))
-	s.router.Get("/registry/implementations", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listRegistryImplementationsHandler))
-	s.router.Get("/registry/definitions", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listRegistryDefinitionsHandler))
-	s.router.Get("/registry/vaults", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listVaultsBuilderHandler))
-	s.router.Get("/registry/infra_usage_collectors", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listInfraHandler))
-
-	s.router.Post("/infra_usage/:infraName", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.postInfraUsageHandler))
-	s.router.Get("/infra_usage/:infraName/tasks/:taskId", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getTaskQueryHandler))
-	s.router.Delete("/infra_usage/:infraName/tasks/:taskId", commonHandlers.ThenFunc(s.deleteTaskQueryHandler))
-	s.router.Get("/infra_usage", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listTaskQueryHandler))
-
-	s.router.Put("/hosts_pool/:host", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.newHostInPool))
-	s.router.Patch("/hosts_pool/:host", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.updateHostInPool))
-	s.router.Delete("/hosts_pool/:host", commonHandlers.ThenFunc(s.deleteHostInPool))
-	s.router.Post("/hosts_pool", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.applyHostsPool))
-	s.router.Put("/hosts_pool", commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.applyHostsPool))
-	s.router.Get("/hosts_pool", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listHostsInPool))
-	s.router.Get("/hosts_pool/:host", commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getHostInPool))
+	s.router.Post("/deployments", operatorHandlers.Append(contentTypeHandler("application/zip")).ThenFunc(s.newDeploymentHandler))
+	s.router.Put("/deployments/:id", operatorHandlers.Append(contentTypeHandler("application/zip")).ThenFunc(s.newDeploymentHandler))
+	s.router.Post("/deployments/:id", operatorHandlers.ThenFunc(s.postDeploymentHandler))
+	s.router.Delete("/deployments/:id", commonHandlers.Append(s.authorizationHandler(undeployRequiredRole)).ThenFunc(s.deleteDeploymentHandler))
+	s.router.Put("/deployments/:id/update", operatorHandlers.Append(contentTypeHandler("application/zip")).ThenFunc(s.uploadDeploymentUpdateHandler))
+	s.router.Post("/deployments/:id/update", operatorHandlers.ThenFunc(s.applyDeploymentUpdateHandler))
+	s.router.Delete("/deployments/:id/update", operatorHandlers.ThenFunc(s.deleteDeploymentUpdateHandler))
+	s.router.Get("/deployments/:id/update/preview", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.previewDeploymentUpdateHandler))
+	s.router.Get("/deployments/:id", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getDeploymentHandler))
+	s.router.Get("/deployments/:id/export", adminHandlers.Append(acceptHandler("application/gzip")).ThenFunc(s.exportDeploymentHandler))
+	s.router.Get("/deployments", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listDeploymentsHandler))
+	s.router.Get("/deployments/:id/events", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.pollEvents))
+	s.router.Get("/events", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.pollEvents))
+	s.router.Head("/deployments/:id/events", viewerHandlers.ThenFunc(s.headEventsIndex))
+	s.router.Head("/events", viewerHandlers.ThenFunc(s.headEventsIndex))
+	s.router.Get("/deployments/:id/logs", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.pollLogs))
+	s.router.Get("/logs", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.pollLogs))
+	s.router.Head("/deployments/:id/logs", viewerHandlers.ThenFunc(s.headLogsEventsIndex))
+	s.router.Head("/logs", viewerHandlers.ThenFunc(s.headLogsEventsIndex))
+	s.router.Get("/deployments/:id/events/stream", viewerHandlers.Append(acceptHandler("text/event-stream")).ThenFunc(s.streamEvents))
+	s.router.Get("/events/stream", viewerHandlers.Append(acceptHandler("text/event-stream")).ThenFunc(s.streamEvents))
+	s.router.Get("/deployments/:id/logs/stream", viewerHandlers.Append(acceptHandler("text/event-stream")).ThenFunc(s.streamLogs))
+	s.router.Get("/logs/stream", viewerHandlers.Append(acceptHandler("text/event-stream")).ThenFunc(s.streamLogs))
+	s.router.Get("/deployments/:id/nodes/:nodeName", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getNodeHandler))
+	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getNodeInstanceHandler))
+	s.router.Get("/deployments/:id/outputs", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listOutputsHandler))
+	s.router.Get("/deployments/:id/outputs/:opt", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getOutputHandler))
+	s.router.Get("/deployments/:id/policies", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listPoliciesHandler))
+	s.router.Get("/deployments/:id/policies/:policyName", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getPolicyHandler))
+	s.router.Get("/deployments/:id/tasks/:taskId", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getTaskHandler))
+	s.router.Get("/deployments/:id/tasks/:taskId/steps", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getTaskStepsHandler))
+	s.router.Delete("/deployments/:id/tasks/:taskId", operatorHandlers.ThenFunc(s.cancelTaskHandler))
+	s.router.Put("/deployments/:id/tasks/:taskId", operatorHandlers.ThenFunc(s.resumeTaskHandler))
+	s.router.Put("/deployments/:id/tasks/:taskId/steps/:stepId", operatorHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.updateTaskStepStatusHandler))
+	s.router.Post("/deployments/:id/scale/:nodeName", operatorHandlers.ThenFunc(s.scaleHandler))
+	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId/attributes", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getNodeInstanceAttributesListHandler))
+	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId/attributes/:attributeName", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getNodeInstanceAttributeHandler))
+	s.router.Post("/deployments/:id/custom", operatorHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.newCustomCommandHandler))
+	s.router.Post("/deployments/:id/workflows/:workflowName", operatorHandlers.ThenFunc(s.newWorkflowHandler))
+	s.router.Get("/deployments/:id/workflows/:workflowName", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getWorkflowHandler))
+	s.router.Get("/deployments/:id/workflows", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listWorkflowsHandler))
+	s.router.Post("/deployments/:id/schedules", operatorHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.newScheduleHandler))
+	s.router.Get("/deployments/:id/schedules", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listSchedulesHandler))
+	s.router.Get("/deployments/:id/schedules/:scheduleId", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getScheduleHandler))
+	s.router.Put("/deployments/:id/schedules/:scheduleId/pause", operatorHandlers.ThenFunc(s.pauseScheduleHandler))
+	s.router.Put("/deployments/:id/schedules/:scheduleId/resume", operatorHandlers.ThenFunc(s.resumeScheduleHandler))
+	s.router.Delete("/deployments/:id/schedules/:scheduleId", operatorHandlers.ThenFunc(s.deleteScheduleHandler))
+	s.router.Get("/deployments/:id/checks", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listDeploymentChecksHandler))
+	s.router.Put("/deployments/:id/checks/:nodeName/:instanceId/pause", operatorHandlers.ThenFunc(s.pauseCheckHandler))
+	s.router.Put("/deployments/:id/checks/:nodeName/:instanceId/resume", operatorHandlers.ThenFunc(s.resumeCheckHandler))
+	s.router.Get("/checks", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listChecksHandler))
+
+	s.router.Get("/registry/delegates", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listRegistryDelegatesHandler))
+	s.router.Get("/registry/implementations", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listRegistryImplementationsHandler))
+	s.router.Get("/registry/definitions", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listRegistryDefinitionsHandler))
+	s.router.Get("/registry/vaults", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listVaultsBuilderHandler))
+	s.router.Get("/registry/infra_usage_collectors", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listInfraHandler))
+
+	s.router.Post("/infra_usage/:infraName", operatorHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.postInfraUsageHandler))
+	s.router.Get("/infra_usage/:infraName/tasks/:taskId", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getTaskQueryHandler))
+	s.router.Delete("/infra_usage/:infraName/tasks/:taskId", operatorHandlers.ThenFunc(s.deleteTaskQueryHandler))
+	s.router.Get("/infra_usage", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listTaskQueryHandler))
+
+	s.router.Put("/hosts_pools/:pool/:host", adminHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.newHostInPool))
+	s.router.Patch("/hosts_pools/:pool/:host", adminHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.updateHostInPool))
+	s.router.Delete("/hosts_pools/:pool/:host", adminHandlers.ThenFunc(s.deleteHostInPool))
+	s.router.Post("/hosts_pools/:pool", adminHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.applyHostsPool))
+	s.router.Put("/hosts_pools/:pool", adminHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.applyHostsPool))
+	s.router.Get("/hosts_pools", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listHostsPools))
+	s.router.Get("/hosts_pools/:pool", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listHostsInPool))
+	s.router.Get("/hosts_pools/:pool/:host", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getHostInPool))
+
+	// Deprecated routes kept for backward compatibility, they apply to the default hosts pool
+	s.router.Put("/hosts_pool/:host", adminHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.newHostInPool))
+	s.router.Patch("/hosts_pool/:host", adminHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.updateHostInPool))
+	s.router.Delete("/hosts_pool/:host", adminHandlers.ThenFunc(s.deleteHostInPool))
+	s.router.Post("/hosts_pool", adminHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.applyHostsPool))
+	s.router.Put("/hosts_pool", adminHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.applyHostsPool))
+	s.router.Get("/hosts_pool", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listHostsInPool))
+	s.router.Get("/hosts_pool/:host", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getHostInPool))
+
+	s.router.Post("/webhooks", adminHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.newWebhookHandler))
+	s.router.Put("/webhooks/:id", adminHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.newWebhookHandler))
+	s.router.Get("/webhooks", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listWebhooksHandler))
+	s.router.Get("/webhooks/:id", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getWebhookHandler))
+	s.router.Delete("/webhooks/:id", adminHandlers.ThenFunc(s.deleteWebhookHandler))
+	s.router.Get("/webhooks/:id/dead_letters", viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listWebhookDeadLettersHandler))
+	s.router.Delete("/webhooks/:id/dead_letters/:deliveryId", adminHandlers.ThenFunc(s.deleteWebhookDeadLetterHandler))
 
 	if s.config.Telemetry.PrometheusEndpoint {
-		s.router.Get("/metrics", commonHandlers.Then(promhttp.Handler()))
+		s.router.Get("/metrics", viewerHandlers.Then(promhttp.Handler()))
 	}
 }
 
@@ -92,6 +92,13 @@ func (w *statusRecorderResponseWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+// Flush implements http.Flusher to allow streaming responses
+func (w *statusRecorderResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func telemetryHandler(next http.Handler) http.Handler {
 
 	fn := func(w http.ResponseWriter, r *http.Request) {
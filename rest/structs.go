@@ -19,8 +19,13 @@ package rest
 import (
 	"bytes"
 	"encoding/json"
+	"time"
 
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events/webhooks"
 	"github.com/ystia/yorc/prov/hostspool"
+	"github.com/ystia/yorc/prov/monitoring"
+	"github.com/ystia/yorc/prov/scheduling"
 	"github.com/ystia/yorc/registry"
 	"github.com/ystia/yorc/tosca"
 )
@@ -44,6 +49,16 @@ const (
 	LinkRelWorkflow string = "workflow"
 	// LinkRelHost defines the AtomLink Rel attribute for relationships of the "host" (for hostspool)
 	LinkRelHost string = "host"
+	// LinkRelHostsPool defines the AtomLink Rel attribute for relationships of the "hosts_pool" (for hostspool)
+	LinkRelHostsPool string = "hosts_pool"
+	// LinkRelPolicy defines the AtomLink Rel attribute for relationships of the "policy"
+	LinkRelPolicy string = "policy"
+	// LinkRelWebhook defines the AtomLink Rel attribute for relationships of the "webhook"
+	LinkRelWebhook string = "webhook"
+	// LinkRelDeadLetters defines the AtomLink Rel attribute for relationships of the "dead_letters" (for webhooks)
+	LinkRelDeadLetters string = "dead_letters"
+	// LinkRelSchedule defines the AtomLink Rel attribute for relationships of the "schedule"
+	LinkRelSchedule string = "schedule"
 )
 
 const (
@@ -53,7 +68,7 @@ const (
 
 const (
 	// YorcDeploymentIDPattern is the allowed pattern for Yorc deployments IDs
-	YorcDeploymentIDPattern string = "^[-_0-9a-zA-Z]+$"
+	YorcDeploymentIDPattern string = deployments.IDPattern
 
 	// Disable this for now as it doesn't have a concrete impact for now
 	// YorcDeploymentIDMaxLength is the maximum allowed length for Yorc deployments IDs
@@ -178,6 +193,55 @@ type WorkflowsCollection struct {
 	Workflows []AtomLink `json:"workflows"`
 }
 
+// PoliciesCollection is a collection of policies links
+//
+// Links are all of type LinkRelPolicy.
+type PoliciesCollection struct {
+	Policies []AtomLink `json:"policies"`
+}
+
+// Policy is the representation of a TOSCA policy
+//
+// Policy's links are of type LinkRelSelf, LinkRelDeployment and LinkRelNode for its targets.
+type Policy struct {
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	Targets    []string          `json:"targets,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Links      []AtomLink        `json:"links"`
+}
+
+// SchedulesCollection is a collection of workflows schedules links
+//
+// Links are all of type LinkRelSchedule.
+type SchedulesCollection struct {
+	Schedules []AtomLink `json:"schedules"`
+}
+
+// Schedule is the representation of a workflow schedule along with its runs history
+//
+// Schedule's links are of type LinkRelSelf, LinkRelDeployment, LinkRelWorkflow and LinkRelTask for its latest run.
+type Schedule struct {
+	scheduling.Schedule
+	NextRun *time.Time                `json:"next_run,omitempty"`
+	History []*scheduling.ScheduleRun `json:"history"`
+	Links   []AtomLink                `json:"links"`
+}
+
+// CheckReportsCollection is a collection of monitoring checks reports
+type CheckReportsCollection struct {
+	Checks []CheckReport `json:"checks"`
+}
+
+// CheckReport is the representation of a monitoring check report of a node instance along with its status changes history
+//
+// CheckReport's links are of type LinkRelDeployment and LinkRelInstance.
+type CheckReport struct {
+	monitoring.CheckReport
+	History []monitoring.CheckHistoryEntry `json:"history,omitempty"`
+	Links   []AtomLink                     `json:"links"`
+}
+
 // Workflow is a workflow representation.
 type Workflow struct {
 	Name string `json:"name"`
@@ -210,6 +274,17 @@ func (o *MapEntryOperation) UnmarshalJSON(b []byte) error {
 	return err
 }
 
+// Role is an enumeration of the authorization levels granted to REST API callers.
+//
+// Roles are ordered, a caller holding a given role is allowed to perform any operation
+// requiring a lower role.
+// ENUM(
+// Viewer,
+// Operator,
+// Admin
+// )
+type Role int
+
 // A MapEntry allows to manipulate a Map collection by performing operations (Op) on entries.
 // It is inspired (with many simplifications) by the JSON Patch RFC https://tools.ietf.org/html/rfc6902
 type MapEntry struct {
@@ -237,15 +312,26 @@ type HostsPoolRequest struct {
 type HostRequest struct {
 	Connection *hostspool.Connection `json:"connection,omitempty"`
 	Labels     []MapEntry            `json:"labels,omitempty"`
+	// Status allows to put a host in maintenance, to drain it or to re-enable it (using the free status)
+	Status *hostspool.HostStatus `json:"status,omitempty"`
+	Reason string                `json:"reason,omitempty"`
 }
 
 // HostsCollection is a collection of hosts registered in the host pool links
 //
 // Links are all of type LinkRelHost.
 type HostsCollection struct {
-	Checkpoint uint64     `json:"checkpoint,omitempty"`
-	Hosts      []AtomLink `json:"hosts"`
-	Warnings   []string   `json:"warnings,omitempty"`
+	Checkpoint         uint64                        `json:"checkpoint,omitempty"`
+	Hosts              []AtomLink                    `json:"hosts"`
+	Warnings           []string                      `json:"warnings,omitempty"`
+	PendingAllocations []hostspool.PendingAllocation `json:"pending_allocations,omitempty"`
+}
+
+// HostsPoolsCollection is a collection of hosts pools links
+//
+// Links are all of type LinkRelHostsPool.
+type HostsPoolsCollection struct {
+	Pools []AtomLink `json:"pools"`
 }
 
 // Host is a host in the host pool representation
@@ -280,3 +366,23 @@ type RegistryVaultsCollection struct {
 type RegistryInfraUsageCollectorsCollection struct {
 	InfraUsageCollectors []registry.InfraUsageCollector `json:"infrastructure_usage_collectors"`
 }
+
+// WebhooksCollection is a collection of webhooks subscriptions links
+//
+// Links are all of type LinkRelWebhook.
+type WebhooksCollection struct {
+	Webhooks []AtomLink `json:"webhooks"`
+}
+
+// Webhook is the representation of a webhook subscription, its secret is never returned
+//
+// Links are of type LinkRelSelf and LinkRelDeadLetters.
+type Webhook struct {
+	webhooks.Subscription
+	Links []AtomLink `json:"links"`
+}
+
+// DeadLettersCollection is a collection of events that could not be delivered to a webhook
+type DeadLettersCollection struct {
+	DeadLetters []*webhooks.DeadLetter `json:"dead_letters"`
+}
@@ -58,3 +58,45 @@ func ParseMapEntryOperation(name string) (MapEntryOperation, error) {
 	}
 	return MapEntryOperation(0), fmt.Errorf("%s is not a valid MapEntryOperation", name)
 }
+
+const (
+	// RoleViewer is a Role of type Viewer
+	RoleViewer Role = iota
+	// RoleOperator is a Role of type Operator
+	RoleOperator
+	// RoleAdmin is a Role of type Admin
+	RoleAdmin
+)
+
+const _RoleName = "ViewerOperatorAdmin"
+
+var _RoleMap = map[Role]string{
+	0: _RoleName[0:6],
+	1: _RoleName[6:14],
+	2: _RoleName[14:19],
+}
+
+// String implements the Stringer interface.
+func (x Role) String() string {
+	if str, ok := _RoleMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("Role(%d)", x)
+}
+
+var _RoleValue = map[string]Role{
+	_RoleName[0:6]:                    0,
+	strings.ToLower(_RoleName[0:6]):   0,
+	_RoleName[6:14]:                   1,
+	strings.ToLower(_RoleName[6:14]):  1,
+	_RoleName[14:19]:                  2,
+	strings.ToLower(_RoleName[14:19]): 2,
+}
+
+// ParseRole attempts to convert a string to a Role
+func ParseRole(name string) (Role, error) {
+	if x, ok := _RoleValue[name]; ok {
+		return x, nil
+	}
+	return Role(0), fmt.Errorf("%s is not a valid Role", name)
+}
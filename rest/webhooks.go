@@ -0,0 +1,170 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/satori/go.uuid"
+
+	"github.com/ystia/yorc/events/webhooks"
+	"github.com/ystia/yorc/log"
+)
+
+func (s *Server) newWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+	status := http.StatusOK
+	if id == "" {
+		id = fmt.Sprint(uuid.NewV4())
+		status = http.StatusCreated
+	} else if ok, _ := regexp.MatchString(YorcDeploymentIDPattern, id); !ok {
+		writeError(w, r, newBadRequestMessage(fmt.Sprintf("Webhook ID should respect the following format: %q", YorcDeploymentIDPattern)))
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Panic(err)
+	}
+	var subscription webhooks.Subscription
+	err = json.Unmarshal(body, &subscription)
+	if err != nil {
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+	subscription.ID = id
+	if err = subscription.Validate(); err != nil {
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+	if status == http.StatusOK {
+		existing, err := webhooks.GetSubscription(s.consulClient.KV(), id)
+		if err != nil {
+			log.Panic(err)
+		}
+		if existing == nil {
+			status = http.StatusCreated
+		} else if subscription.Secret == "" {
+			// The secret is never returned to clients, keep it if not provided
+			subscription.Secret = existing.Secret
+		}
+	}
+	err = webhooks.StoreSubscription(s.consulClient.KV(), &subscription)
+	if err != nil {
+		log.Panic(err)
+	}
+	w.Header().Set("Location", fmt.Sprintf("/webhooks/%s", id))
+	w.WriteHeader(status)
+}
+
+func (s *Server) getWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+
+	subscription, err := webhooks.GetSubscription(s.consulClient.KV(), id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if subscription == nil {
+		writeError(w, r, errNotFound)
+		return
+	}
+	subscription.Secret = ""
+	webhook := Webhook{Subscription: *subscription, Links: []AtomLink{
+		newAtomLink(LinkRelSelf, fmt.Sprintf("/webhooks/%s", id)),
+		newAtomLink(LinkRelDeadLetters, fmt.Sprintf("/webhooks/%s/dead_letters", id)),
+	}}
+	encodeJSONResponse(w, r, webhook)
+}
+
+func (s *Server) listWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := webhooks.ListSubscriptions(s.consulClient.KV())
+	if err != nil {
+		log.Panic(err)
+	}
+	if len(subscriptions) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	collection := WebhooksCollection{Webhooks: make([]AtomLink, len(subscriptions))}
+	for i, subscription := range subscriptions {
+		collection.Webhooks[i] = newAtomLink(LinkRelWebhook, fmt.Sprintf("/webhooks/%s", subscription.ID))
+	}
+	encodeJSONResponse(w, r, collection)
+}
+
+func (s *Server) deleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+
+	subscription, err := webhooks.GetSubscription(s.consulClient.KV(), id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if subscription == nil {
+		writeError(w, r, errNotFound)
+		return
+	}
+	if err = webhooks.DeleteSubscription(s.consulClient.KV(), id); err != nil {
+		log.Panic(err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) listWebhookDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+
+	subscription, err := webhooks.GetSubscription(s.consulClient.KV(), id)
+	if err != nil {
+		log.Panic(err)
+	}
+	if subscription == nil {
+		writeError(w, r, errNotFound)
+		return
+	}
+	deadLetters, err := webhooks.ListDeadLetters(s.consulClient.KV(), id)
+	if err != nil {
+		log.Panic(err)
+	}
+	encodeJSONResponse(w, r, DeadLettersCollection{DeadLetters: deadLetters})
+}
+
+func (s *Server) deleteWebhookDeadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+	deliveryID := params.ByName("deliveryId")
+
+	if err := webhooks.DeleteDeadLetter(s.consulClient.KV(), id, deliveryID); err != nil {
+		log.Panic(err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -15,6 +15,7 @@
 package server
 
 import (
+	"path"
 	"testing"
 
 	"github.com/blang/semver"
@@ -35,6 +36,9 @@ func TestConsulServerPackage(t *testing.T) {
 		t.Run("testSetupVersion", func(t *testing.T) {
 			testSetupVersion(t, client)
 		})
+		t.Run("testUpgradeHostsPoolTo110", func(t *testing.T) {
+			testUpgradeHostsPoolTo110(t, client)
+		})
 	})
 }
 
@@ -89,3 +93,34 @@ func testSetupVersion(t *testing.T, client *api.Client) {
 	checkSchemaVersion(t, kv, v.String())
 
 }
+
+func testUpgradeHostsPoolTo110(t *testing.T, client *api.Client) {
+	kv := client.KV()
+	hosts := map[string]string{
+		"host1/status":                      "allocated",
+		"host1/connection/host":             "10.0.0.1",
+		"host1/labels/os.type":              "linux",
+		"host1/allocations/alloc1/instance": "0",
+		"default/status":                    "free",
+		"connection/status":                 "free",
+	}
+	for k, v := range hosts {
+		_, err := kv.Put(&api.KVPair{Key: path.Join(consulutil.HostsPoolPrefix, k), Value: []byte(v)}, nil)
+		require.NoError(t, err)
+	}
+
+	setSchemaVersion(t, kv, "1.0.0")
+	err := setupConsulDBSchema(client)
+	require.NoError(t, err)
+	checkCurrentSchemaVersion(t, kv)
+
+	kvps, _, err := kv.List(consulutil.HostsPoolPrefix+"/", nil)
+	require.NoError(t, err)
+	assert.Len(t, kvps, len(hosts))
+	for k, v := range hosts {
+		kvp, _, err := kv.Get(path.Join(consulutil.HostsPoolPrefix, "default", k), nil)
+		require.NoError(t, err)
+		require.NotNil(t, kvp, "missing key %q", k)
+		assert.Equal(t, v, string(kvp.Value))
+	}
+}
@@ -30,10 +30,17 @@ import (
 	_ "github.com/ystia/yorc/prov/slurm"
 	// Registering hosts pool delegate executor in the registry
 	_ "github.com/ystia/yorc/prov/hostspool"
+	// Registering docker delegate executor in the registry
+	_ "github.com/ystia/yorc/prov/docker"
 	// Registering builtin Tosca definition files
 	_ "github.com/ystia/yorc/tosca"
 	// Registering builtin HashiCorp Vault Client Builder
 	_ "github.com/ystia/yorc/vault/hashivault"
+	// Registering builtin encrypted file, environment variables, Kubernetes and composite secrets backends
+	_ "github.com/ystia/yorc/vault/composite"
+	_ "github.com/ystia/yorc/vault/env"
+	_ "github.com/ystia/yorc/vault/filestore"
+	_ "github.com/ystia/yorc/vault/kubernetes"
 	// Registering builtin activity hooks
 	_ "github.com/ystia/yorc/prov/validation"
 )
@@ -200,6 +207,42 @@ func (pm *pluginManager) loadPlugins(cfg config.Configuration) error {
 			log.Debugf("%+v", err)
 		}
 
+		// Request the action operator plugin
+		raw, err = rpcClient.Dispense(plugin.ActionPluginName)
+		if err == nil {
+			actionOperator := raw.(plugin.ActionOperator)
+			actionTypes, err := actionOperator.GetActionTypes()
+			if err != nil {
+				log.Printf("[Warning] Failed to retrieve action operator supported action types for plugin %q.", pluginID)
+				log.Debugf("%+v", err)
+			}
+			if len(actionTypes) > 0 {
+				log.Debugf("Registering supported action types %v into registry for plugin %q", actionTypes, pluginID)
+				reg.RegisterActionOperator(actionTypes, actionOperator, pluginID)
+			}
+		} else {
+			log.Printf("[Warning] Can't retrieve action operator from plugin %q: %v. This is likely due to a outdated plugin.", pluginID, err)
+			log.Debugf("%+v", err)
+		}
+
+		// Request the policy handler plugin
+		raw, err = rpcClient.Dispense(plugin.PolicyHandlerPluginName)
+		if err == nil {
+			policyHandler := raw.(plugin.PolicyHandler)
+			policyTypes, err := policyHandler.GetPolicyTypes()
+			if err != nil {
+				log.Printf("[Warning] Failed to retrieve policy handler supported policy types for plugin %q.", pluginID)
+				log.Debugf("%+v", err)
+			}
+			if len(policyTypes) > 0 {
+				log.Debugf("Registering supported policy types %v into registry for plugin %q", policyTypes, pluginID)
+				reg.RegisterPolicyHandler(policyTypes, policyHandler, pluginID)
+			}
+		} else {
+			log.Printf("[Warning] Can't retrieve policy handler from plugin %q: %v. This is likely due to a outdated plugin.", pluginID, err)
+			log.Debugf("%+v", err)
+		}
+
 		pm.pluginClients = append(pm.pluginClients, client)
 
 		log.Printf("Plugin %q successfully loaded", pluginID)
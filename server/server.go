@@ -28,6 +28,8 @@ import (
 
 	"github.com/ystia/yorc/config"
 	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/events/webhooks"
 	"github.com/ystia/yorc/helper/consulutil"
 	"github.com/ystia/yorc/log"
 	"github.com/ystia/yorc/prov/monitoring"
@@ -42,6 +44,11 @@ func RunServer(configuration config.Configuration, shutdownCh chan struct{}) err
 		return err
 	}
 
+	err = events.SetSecretsPatterns(configuration.LogsSecretsPatterns)
+	if err != nil {
+		return err
+	}
+
 	vaultClient, err := buildVaultClient(configuration)
 	if err != nil {
 		return err
@@ -103,6 +110,10 @@ func RunServer(configuration config.Configuration, shutdownCh chan struct{}) err
 	scheduler.Start(configuration, client)
 	defer scheduler.Stop()
 
+	// Start webhooks events dispatcher
+	webhooks.Start(configuration, client)
+	defer webhooks.Stop()
+
 WAIT:
 	signalCh := make(chan os.Signal, 4)
 	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
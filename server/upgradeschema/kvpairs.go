@@ -0,0 +1,60 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgradeschema
+
+import (
+	"github.com/blang/semver"
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/consulutil"
+)
+
+// kvPairsUpgradeToMap references functions allowing to upgrade exported Consul KV pairs
+// to a given schema version
+var kvPairsUpgradeToMap = map[string]func(api.KVPairs) error{
+	"1.0.0": eventsKVPairsChange,
+}
+
+// UpgradeKVPairs upgrades in place a set of Consul KV pairs exported using the given
+// schema version to the schema version understood by this version of Yorc
+func UpgradeKVPairs(fromVersion string, kvps api.KVPairs) error {
+	vFrom, err := semver.Make(fromVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse schema version %q", fromVersion)
+	}
+	vCurrent, err := semver.Make(consulutil.YorcSchemaVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse current version of consul db schema")
+	}
+	if vFrom.GT(vCurrent) {
+		return errors.Errorf("this version of Yorc is too old compared to the schema version (%s), an upgrade is needed.", vFrom)
+	}
+
+	versions := make([]semver.Version, 0, len(kvPairsUpgradeToMap))
+	for v := range kvPairsUpgradeToMap {
+		versions = append(versions, semver.MustParse(v))
+	}
+	semver.Sort(versions)
+	for _, vUp := range versions {
+		if vUp.GT(vFrom) && vUp.LTE(vCurrent) {
+			err = kvPairsUpgradeToMap[vUp.String()](kvps)
+			if err != nil {
+				return errors.Wrapf(err, "failed to upgrade KV pairs to schema version %q", vUp)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgradeschema
+
+import (
+	"encoding/json"
+	"path"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/events"
+	"github.com/ystia/yorc/helper/consulutil"
+)
+
+func TestUpgradeKVPairs(t *testing.T) {
+	eventKey := path.Join(consulutil.EventsPrefix, "dep", "2018-06-01T10:00:00Z")
+	otherKey := path.Join(consulutil.DeploymentKVPrefix, "dep", "status")
+	kvps := api.KVPairs{
+		{Key: eventKey, Value: []byte("Compute\nstarted\n0"), Flags: 0},
+		{Key: otherKey, Value: []byte("DEPLOYED")},
+	}
+	require.NoError(t, UpgradeKVPairs("0.0.0", kvps))
+
+	event := make(map[string]string)
+	require.NoError(t, json.Unmarshal(kvps[0].Value, &event))
+	assert.Equal(t, eventKey, kvps[0].Key)
+	assert.Equal(t, "dep", event["deploymentId"])
+	assert.Equal(t, events.StatusChangeTypeInstance.String(), event["type"])
+	assert.Equal(t, "Compute", event["nodeId"])
+	assert.Equal(t, "started", event["status"])
+	assert.Equal(t, "0", event["instanceId"])
+	assert.Equal(t, "DEPLOYED", string(kvps[1].Value))
+
+	// Already up to date
+	kvps = api.KVPairs{{Key: eventKey, Value: []byte(`{"type":"anEvent"}`)}}
+	require.NoError(t, UpgradeKVPairs(consulutil.YorcSchemaVersion, kvps))
+	assert.Equal(t, `{"type":"anEvent"}`, string(kvps[0].Value))
+
+	assert.Error(t, UpgradeKVPairs("99.0.0", kvps))
+	assert.Error(t, UpgradeKVPairs("not a version", kvps))
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgradeschema
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+)
+
+// defaultHostsPoolName is the name of the hosts pool in which hosts registered before the introduction of named pools are moved
+const defaultHostsPoolName = "default"
+
+// UpgradeTo110 allows to upgrade Consul schema from schema version 1.0.0 to 1.1.0
+func UpgradeTo110(kv *api.KV, leaderch <-chan struct{}) error {
+	log.Print("Preparing upgrade database schema to 1.1.0 schema version")
+	return hostsPoolChange(kv, leaderch)
+}
+
+func hostsPoolChange(kv *api.KV, leaderch <-chan struct{}) error {
+	// Hosts are now stored in named pools
+	// Existing hosts are moved to the default pool
+	log.Print("Update hosts pool to move hosts into the default pool")
+	hostsKeys, _, err := kv.Keys(consulutil.HostsPoolPrefix+"/", "/", nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to upgrade consul database schema to 1.1.0")
+	}
+	// A host named as the default pool is moved first so that its keys could not be
+	// mixed up with the keys of other moved hosts
+	sort.SliceStable(hostsKeys, func(i, j int) bool {
+		return path.Base(hostsKeys[i]) == defaultHostsPoolName && path.Base(hostsKeys[j]) != defaultHostsPoolName
+	})
+	for _, hostKey := range hostsKeys {
+		select {
+		case <-leaderch:
+			return errors.New("failed to upgrade consul database schema to 1.1.0: lock lost")
+		default:
+		}
+		hostPrefix := strings.TrimSuffix(hostKey, "/")
+		hostname := path.Base(hostPrefix)
+		kvps, _, err := kv.List(hostPrefix+"/", nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to upgrade consul database schema to 1.1.0")
+		}
+		for _, kvp := range kvps {
+			newKey := path.Join(consulutil.HostsPoolPrefix, defaultHostsPoolName, hostname, strings.TrimPrefix(kvp.Key, hostPrefix+"/"))
+			_, err = kv.Put(&api.KVPair{Key: newKey, Value: kvp.Value, Flags: kvp.Flags}, nil)
+			if err != nil {
+				return errors.Wrapf(err, "failed to upgrade consul database schema to 1.1.0")
+			}
+			// Keys are deleted one by one as a host may be named as the default pool
+			_, err = kv.Delete(kvp.Key, nil)
+			if err != nil {
+				return errors.Wrapf(err, "failed to upgrade consul database schema to 1.1.0")
+			}
+		}
+	}
+	return nil
+}
@@ -37,6 +37,44 @@ func eventsChange(kv *api.KV, leaderch <-chan struct{}) error {
 	// To store with the new format (JSON)
 	log.Print("Update events format change")
 	eventsPrefix := path.Join(consulutil.EventsPrefix)
+
+	kvps, qm, err := kv.List(eventsPrefix, nil)
+	if err != nil || qm == nil {
+		return errors.Wrapf(err, "failed to upgrade consul database schema from 100")
+	}
+	for _, kvp := range kvps {
+		key, b, err := convertPre31Event(eventsPrefix, kvp)
+		if err != nil {
+			return err
+		}
+		err = consulutil.StoreConsulKey(key, b)
+		if err != nil {
+			return errors.Wrapf(err, "failed to upgrade consul database schema from 100")
+		}
+	}
+	return nil
+}
+
+// eventsKVPairsChange converts in place exported pre-3.1 events to the new JSON format
+func eventsKVPairsChange(kvps api.KVPairs) error {
+	eventsPrefix := path.Join(consulutil.EventsPrefix)
+	for _, kvp := range kvps {
+		if !strings.HasPrefix(kvp.Key, eventsPrefix+"/") {
+			continue
+		}
+		key, b, err := convertPre31Event(eventsPrefix, kvp)
+		if err != nil {
+			return err
+		}
+		kvp.Key = key
+		kvp.Value = b
+		kvp.Flags = 0
+	}
+	return nil
+}
+
+// convertPre31Event returns the key and the JSON value of a given event stored using the pre-3.1 format
+func convertPre31Event(eventsPrefix string, kvp *api.KVPair) (string, []byte, error) {
 	type StatusUpdateType uint64
 	const (
 		// InstanceStatusChangeType is the StatusUpdate type for an instance state change event
@@ -67,60 +105,53 @@ func eventsChange(kv *api.KV, leaderch <-chan struct{}) error {
 		return ""
 	}
 
-	kvps, qm, err := kv.List(eventsPrefix, nil)
-	if err != nil || qm == nil {
-		return errors.Wrapf(err, "failed to upgrade consul database schema from 100")
+	event := make(map[string]string)
+	depIDAndTimestamp := strings.Split(strings.TrimPrefix(kvp.Key, eventsPrefix+"/"), "/")
+	if len(depIDAndTimestamp) < 2 {
+		return "", nil, errors.Errorf("failed to upgrade consul database schema from 100: unexpected event key %q", kvp.Key)
 	}
-	for _, kvp := range kvps {
-		event := make(map[string]string)
-		depIDAndTimestamp := strings.Split(strings.TrimPrefix(kvp.Key, eventsPrefix+"/"), "/")
-		deploymentID := depIDAndTimestamp[0]
-		eventTimestamp := depIDAndTimestamp[1]
+	deploymentID := depIDAndTimestamp[0]
+	eventTimestamp := depIDAndTimestamp[1]
 
-		values := strings.Split(string(kvp.Value), "\n")
-		eventType := StatusUpdateType(kvp.Flags)
-		switch eventType {
-		case InstanceStatusChangeType:
-			if len(values) != 3 {
-				return errors.Errorf("failed to upgrade consul database schema from 100: unexpected event value %q for event %q", string(kvp.Value), kvp.Key)
-			}
-			event["deploymentId"] = deploymentID
-			event["timestamp"] = eventTimestamp
-			event["type"] = stringStatusUpdate(eventType)
-			event["nodeId"] = values[0]
-			event["status"] = values[1]
-			event["instanceId"] = values[2]
-		case DeploymentStatusChangeType:
-			if len(values) != 1 {
-				return errors.Errorf("failed to upgrade consul database schema from 100: unexpected event value %q for event %q", string(kvp.Value), kvp.Key)
-			}
-			event["deploymentId"] = deploymentID
-			event["timestamp"] = eventTimestamp
-			event["type"] = stringStatusUpdate(eventType)
-			event["status"] = values[0]
-		case CustomCommandStatusChangeType, ScalingStatusChangeType, WorkflowStatusChangeType:
-			if len(values) != 2 {
-				return errors.Errorf("failed to upgrade consul database schema from 100: unexpected event value %q for event %q", string(kvp.Value), kvp.Key)
-			}
-			event["deploymentId"] = deploymentID
-			event["timestamp"] = eventTimestamp
-			event["type"] = stringStatusUpdate(eventType)
-			event["status"] = values[1]
-			event["alienExecutionId"] = values[0]
-		default:
-			return errors.Errorf("failed to upgrade consul database schema from 100: unsupported event type %d for event %q", kvp.Flags, kvp.Key)
+	values := strings.Split(string(kvp.Value), "\n")
+	eventType := StatusUpdateType(kvp.Flags)
+	switch eventType {
+	case InstanceStatusChangeType:
+		if len(values) != 3 {
+			return "", nil, errors.Errorf("failed to upgrade consul database schema from 100: unexpected event value %q for event %q", string(kvp.Value), kvp.Key)
 		}
-
-		// Save new format value
-		log.Debugf("Convert event format with event:%+v", event)
-		b, err := json.Marshal(event)
-		if err != nil {
-			log.Printf("failed to upgrade consul database schema from 100:  failed to marshal event [%+v]: due to error:%+v", event, err)
+		event["deploymentId"] = deploymentID
+		event["timestamp"] = eventTimestamp
+		event["type"] = stringStatusUpdate(eventType)
+		event["nodeId"] = values[0]
+		event["status"] = values[1]
+		event["instanceId"] = values[2]
+	case DeploymentStatusChangeType:
+		if len(values) != 1 {
+			return "", nil, errors.Errorf("failed to upgrade consul database schema from 100: unexpected event value %q for event %q", string(kvp.Value), kvp.Key)
 		}
-		err = consulutil.StoreConsulKey(path.Join(eventsPrefix, deploymentID, event["timestamp"]), b)
-		if err != nil {
-			return errors.Wrapf(err, "failed to upgrade consul database schema from 100")
+		event["deploymentId"] = deploymentID
+		event["timestamp"] = eventTimestamp
+		event["type"] = stringStatusUpdate(eventType)
+		event["status"] = values[0]
+	case CustomCommandStatusChangeType, ScalingStatusChangeType, WorkflowStatusChangeType:
+		if len(values) != 2 {
+			return "", nil, errors.Errorf("failed to upgrade consul database schema from 100: unexpected event value %q for event %q", string(kvp.Value), kvp.Key)
 		}
+		event["deploymentId"] = deploymentID
+		event["timestamp"] = eventTimestamp
+		event["type"] = stringStatusUpdate(eventType)
+		event["status"] = values[1]
+		event["alienExecutionId"] = values[0]
+	default:
+		return "", nil, errors.Errorf("failed to upgrade consul database schema from 100: unsupported event type %d for event %q", kvp.Flags, kvp.Key)
 	}
-	return nil
+
+	// Save new format value
+	log.Debugf("Convert event format with event:%+v", event)
+	b, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("failed to upgrade consul database schema from 100:  failed to marshal event [%+v]: due to error:%+v", event, err)
+	}
+	return path.Join(eventsPrefix, deploymentID, event["timestamp"]), b, nil
 }
@@ -25,7 +25,8 @@ package tasks
 // CustomCommand,
 // CustomWorkflow,
 // Query,
-// Action
+// Action,
+// Update
 // )
 type TaskType int
 
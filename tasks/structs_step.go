@@ -27,6 +27,10 @@ type TaskStepStatus int
 
 // TaskStep represents a step related to a workflow
 type TaskStep struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	StatusReason string `json:"status_reason,omitempty"`
 }
+
+// TaskStepStatusReasonTimeout is the status reason of a step in error due to an activity timeout
+const TaskStepStatusReasonTimeout = "timeout"
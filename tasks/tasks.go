@@ -59,7 +59,7 @@ func IsAnotherLivingTaskAlreadyExistsError(err error) (bool, string) {
 
 // IsWorkflowTask returns true if the task type is related to workflow
 func IsWorkflowTask(taskType TaskType) bool {
-	return taskType == TaskTypeDeploy || taskType == TaskTypeUnDeploy || taskType == TaskTypePurge || taskType == TaskTypeScaleIn || taskType == TaskTypeScaleOut || taskType == TaskTypeCustomWorkflow
+	return taskType == TaskTypeDeploy || taskType == TaskTypeUnDeploy || taskType == TaskTypePurge || taskType == TaskTypeScaleIn || taskType == TaskTypeScaleOut || taskType == TaskTypeCustomWorkflow || taskType == TaskTypeUpdate
 }
 
 type taskDataNotFound struct {
@@ -143,7 +143,7 @@ func GetTaskType(kv *api.KV, taskID string) (TaskType, error) {
 	if err != nil {
 		return TaskTypeDeploy, errors.Wrapf(err, "Invalid task type:")
 	}
-	if typeInt < 0 || typeInt > int(TaskTypeAction) {
+	if typeInt < 0 || typeInt > int(TaskTypeUpdate) {
 		return TaskTypeDeploy, errors.Errorf("Invalid type for task with id %q: %q", taskID, string(kvp.Value))
 	}
 	return TaskType(typeInt), nil
@@ -233,7 +233,7 @@ func TargetHasLivingTasks(kv *api.KV, targetID string) (bool, string, string, er
 			}
 
 			switch tType {
-			case TaskTypeDeploy, TaskTypeUnDeploy, TaskTypePurge, TaskTypeScaleIn, TaskTypeScaleOut:
+			case TaskTypeDeploy, TaskTypeUnDeploy, TaskTypePurge, TaskTypeScaleIn, TaskTypeScaleOut, TaskTypeUpdate:
 				if tStatus == TaskStatusINITIAL || tStatus == TaskStatusRUNNING {
 					return true, taskID, tStatus.String(), nil
 				}
@@ -344,7 +344,7 @@ func EmitTaskEventWithContextualLogs(ctx context.Context, kv *api.KV, deployment
 	switch taskType {
 	case TaskTypeCustomCommand:
 		return events.PublishAndLogCustomCommandStatusChange(ctx, kv, deploymentID, taskID, strings.ToLower(status))
-	case TaskTypeCustomWorkflow, TaskTypeDeploy, TaskTypeUnDeploy:
+	case TaskTypeCustomWorkflow, TaskTypeDeploy, TaskTypeUnDeploy, TaskTypeUpdate:
 		return events.PublishAndLogWorkflowStatusChange(ctx, kv, deploymentID, taskID, workflowName, strings.ToLower(status))
 	case TaskTypeScaleIn, TaskTypeScaleOut:
 		return events.PublishAndLogScalingStatusChange(ctx, kv, deploymentID, taskID, strings.ToLower(status))
@@ -361,12 +361,38 @@ func GetTaskRelatedSteps(kv *api.KV, taskID string) ([]TaskStep, error) {
 		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
 
+	reasons, _, err := kv.List(path.Join(consulutil.TasksPrefix, taskID, ".stepsStatusReasons"), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	reasonsMap := make(map[string]string, len(reasons))
+	for _, kvp := range reasons {
+		reasonsMap[path.Base(kvp.Key)] = string(kvp.Value)
+	}
+
 	for _, kvp := range kvps {
-		steps = append(steps, TaskStep{Name: path.Base(kvp.Key), Status: string(kvp.Value)})
+		step := TaskStep{Name: path.Base(kvp.Key), Status: string(kvp.Value)}
+		// Reasons are only relevant for steps in error
+		if strings.EqualFold(step.Status, TaskStepStatusERROR.String()) {
+			step.StatusReason = reasonsMap[step.Name]
+		}
+		steps = append(steps, step)
 	}
 	return steps, nil
 }
 
+// SetTaskStepStatusReason stores the reason of the current status of a step
+//
+// An empty reason removes any previously stored reason.
+func SetTaskStepStatusReason(kv *api.KV, taskID, stepName, reason string) error {
+	key := path.Join(consulutil.TasksPrefix, taskID, ".stepsStatusReasons", stepName)
+	if reason == "" {
+		_, err := kv.Delete(key, nil)
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	return consulutil.StoreConsulKeyAsString(key, reason)
+}
+
 // GetTaskStepStatus returns the step status of the related step name
 func GetTaskStepStatus(kv *api.KV, taskID, stepName string) (TaskStepStatus, error) {
 	kvp, _, err := kv.Get(path.Join(consulutil.WorkflowsPrefix, taskID, stepName), nil)
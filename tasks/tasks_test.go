@@ -72,6 +72,11 @@ func populateKV(t *testing.T, srv *testutil.TestServer) {
 		consulutil.WorkflowsPrefix + "/t8/step3":  []byte("status3"),
 		consulutil.WorkflowsPrefix + "/t10/step1": []byte("error"),
 		consulutil.WorkflowsPrefix + "/t11/step1": []byte("status1"),
+		consulutil.WorkflowsPrefix + "/t12/step1": []byte("error"),
+		consulutil.WorkflowsPrefix + "/t12/step2": []byte("done"),
+
+		consulutil.TasksPrefix + "/t12/.stepsStatusReasons/step1": []byte("timeout"),
+		consulutil.TasksPrefix + "/t12/.stepsStatusReasons/step2": []byte("timeout"),
 
 		consulutil.TasksPrefix + "/t12/status":    []byte("3"),
 		consulutil.TasksPrefix + "/t12/type":      []byte("5"),
@@ -482,6 +487,7 @@ func testGetTaskRelatedWFSteps(t *testing.T, kv *api.KV) {
 		{"TaskWith3Steps", args{kv, "t8"}, []TaskStep{{Name: "step1", Status: "status1"}, {Name: "step2", Status: "status2"}, {Name: "step3", Status: "status3"}}, false},
 		{"TaskWithoutStep", args{kv, "t9"}, []TaskStep{}, false},
 		{"TaskDoesntExist", args{kv, "fake"}, []TaskStep{}, false},
+		{"TaskWithStepsReasons", args{kv, "t12"}, []TaskStep{{Name: "step1", Status: "error", StatusReason: TaskStepStatusReasonTimeout}, {Name: "step2", Status: "done"}}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -103,6 +103,7 @@ func buildStepFromWFStep(kv *api.KV, deploymentID, wfName, stepName string, wfSt
 		TargetRelationship: wfStep.TargetRelationShip,
 		Target:             wfStep.Target,
 		Activities:         make([]Activity, 0, len(wfStep.Activities)),
+		Retry:              wfStep.Retry,
 	}
 	var targetIsMandatory bool
 	for _, wfActivity := range wfStep.Activities {
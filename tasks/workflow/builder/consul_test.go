@@ -36,5 +36,8 @@ func TestRunConsulWorkflowPackageTests(t *testing.T) {
 		t.Run("testBuildWorkFlow", func(t *testing.T) {
 			testBuildWorkFlow(t, srv, kv)
 		})
+		t.Run("testBuildPlan", func(t *testing.T) {
+			testBuildPlan(t, kv)
+		})
 	})
 }
@@ -0,0 +1,349 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/collections"
+	"github.com/ystia/yorc/prov/operations"
+	"github.com/ystia/yorc/registry"
+)
+
+// infrastructuresWithOptionalConfig are infrastructures that work without a configuration in the Yorc server configuration
+var infrastructuresWithOptionalConfig = []string{"docker", "hostspool", "kubernetes"}
+
+// A Plan describes what would be executed by a workflow without executing it
+type Plan struct {
+	WorkflowName string      `json:"workflow_name"`
+	Steps        []*PlanStep `json:"steps"`
+	// UnresolvedFunctions reports TOSCA functions (inputs, properties) that can't be resolved
+	UnresolvedFunctions []PlanIssue `json:"unresolved_functions,omitempty"`
+	// MissingImplementations reports operations or delegates that can't be executed by any executor
+	MissingImplementations []PlanIssue `json:"missing_implementations,omitempty"`
+	// MissingInfrastructureConfigs reports infrastructures used by the workflow that are not configured on the Yorc server
+	MissingInfrastructureConfigs []string `json:"missing_infrastructure_configs,omitempty"`
+}
+
+// HasIssues returns true if the plan reports an issue that would prevent the workflow to be executed successfully
+func (p *Plan) HasIssues() bool {
+	return len(p.UnresolvedFunctions) > 0 || len(p.MissingImplementations) > 0 || len(p.MissingInfrastructureConfigs) > 0
+}
+
+// A PlanStep describes a workflow step as it would be executed
+//
+// Steps are ordered by Level, steps of a same level may be executed concurrently.
+type PlanStep struct {
+	Name            string          `json:"name"`
+	Level           int             `json:"level"`
+	Target          string          `json:"target,omitempty"`
+	OperationHost   string          `json:"operation_host,omitempty"`
+	Async           bool            `json:"async,omitempty"`
+	IsOnFailurePath bool            `json:"on_failure_path,omitempty"`
+	IsOnCancelPath  bool            `json:"on_cancel_path,omitempty"`
+	Activities      []*PlanActivity `json:"activities"`
+	Next            []string        `json:"next,omitempty"`
+	OnFailure       []string        `json:"on_failure,omitempty"`
+	OnCancel        []string        `json:"on_cancel,omitempty"`
+}
+
+// A PlanActivity describes a workflow step activity and the executor that would run it
+type PlanActivity struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	// ImplementationArtifact is the implementation artifact of a call-operation activity
+	ImplementationArtifact string `json:"implementation_artifact,omitempty"`
+	// ExecutorOrigin is the origin (builtin or plugin name) of the executor that would run this activity
+	ExecutorOrigin string `json:"executor_origin,omitempty"`
+	// Skipped is true for operations that are not implemented and would be bypassed
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// A PlanIssue describes a problem detected while planning a workflow
+type PlanIssue struct {
+	Step    string `json:"step,omitempty"`
+	Node    string `json:"node,omitempty"`
+	Message string `json:"message"`
+}
+
+// BuildPlan computes the execution plan of a workflow for a given deployment.
+//
+// Nothing is executed and nothing is stored, executors are resolved using the registry
+// and unresolved functions, missing implementations and missing infrastructures configurations are reported.
+func BuildPlan(ctx context.Context, kv *api.KV, cfg config.Configuration, deploymentID, workflowName string) (*Plan, error) {
+	steps, err := BuildWorkFlow(kv, deploymentID, workflowName)
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{WorkflowName: workflowName, Steps: make([]*PlanStep, 0, len(steps))}
+
+	err = plan.checkInputs(kv, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := computeStepsLevels(steps)
+	infrastructures := make([]string, 0)
+	targets := make([]string, 0)
+	for _, s := range steps {
+		ps := &PlanStep{
+			Name:            s.Name,
+			Level:           levels[s.Name],
+			Target:          s.Target,
+			OperationHost:   s.OperationHost,
+			Async:           s.Async,
+			IsOnFailurePath: s.IsOnFailurePath,
+			IsOnCancelPath:  s.IsOnCancelPath,
+			Activities:      make([]*PlanActivity, 0, len(s.Activities)),
+			Next:            stepsNames(s.Next),
+			OnFailure:       stepsNames(s.OnFailure),
+			OnCancel:        stepsNames(s.OnCancel),
+		}
+		for _, a := range s.Activities {
+			pa, err := plan.resolveActivity(ctx, kv, deploymentID, s, a)
+			if err != nil {
+				return nil, err
+			}
+			ps.Activities = append(ps.Activities, pa)
+			if a.Type() == ActivityTypeDelegate || (a.Type() == ActivityTypeCallOperation && !pa.Skipped) {
+				infra, err := GetNodeInfrastructure(kv, deploymentID, s.Target)
+				if err != nil {
+					return nil, err
+				}
+				if infra != "" && !collections.ContainsString(infrastructures, infra) {
+					infrastructures = append(infrastructures, infra)
+				}
+			}
+		}
+		if s.Target != "" && !collections.ContainsString(targets, s.Target) {
+			targets = append(targets, s.Target)
+		}
+		plan.Steps = append(plan.Steps, ps)
+	}
+	sort.Slice(plan.Steps, func(i, j int) bool {
+		if plan.Steps[i].Level != plan.Steps[j].Level {
+			return plan.Steps[i].Level < plan.Steps[j].Level
+		}
+		return plan.Steps[i].Name < plan.Steps[j].Name
+	})
+
+	sort.Strings(targets)
+	for _, target := range targets {
+		err = plan.checkNodeProperties(kv, deploymentID, target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(infrastructures)
+	for _, infra := range infrastructures {
+		if _, ok := cfg.Infrastructures[infra]; !ok && !collections.ContainsString(infrastructuresWithOptionalConfig, infra) {
+			plan.MissingInfrastructureConfigs = append(plan.MissingInfrastructureConfigs, infra)
+		}
+	}
+	return plan, nil
+}
+
+func (p *Plan) resolveActivity(ctx context.Context, kv *api.KV, deploymentID string, s *Step, a Activity) (*PlanActivity, error) {
+	pa := &PlanActivity{Type: a.Type().String(), Value: a.Value()}
+	switch a.Type() {
+	case ActivityTypeDelegate:
+		nodeType, err := deployments.GetNodeType(kv, deploymentID, s.Target)
+		if err != nil {
+			return nil, err
+		}
+		origin, err := getDelegateExecutorOrigin(nodeType)
+		if err != nil {
+			return nil, err
+		}
+		if origin == "" {
+			p.MissingImplementations = append(p.MissingImplementations, PlanIssue{Step: s.Name, Node: s.Target,
+				Message: fmt.Sprintf("no delegate executor found for node type %q", nodeType)})
+		}
+		pa.ExecutorOrigin = origin
+	case ActivityTypeCallOperation:
+		op, err := operations.GetOperation(ctx, kv, deploymentID, s.Target, a.Value(), s.TargetRelationship, s.OperationHost)
+		if err != nil {
+			if deployments.IsOperationNotImplemented(err) {
+				// Operation not implemented it will be skipped at execution time
+				pa.Skipped = true
+				return pa, nil
+			}
+			p.MissingImplementations = append(p.MissingImplementations, PlanIssue{Step: s.Name, Node: s.Target,
+				Message: fmt.Sprintf("failed to resolve operation %q: %v", a.Value(), err)})
+			return pa, nil
+		}
+		pa.ImplementationArtifact = op.ImplementationArtifact
+		origin, err := getOperationExecutorOrigin(kv, deploymentID, op.ImplementationArtifact)
+		if err != nil {
+			return nil, err
+		}
+		if origin == "" {
+			p.MissingImplementations = append(p.MissingImplementations, PlanIssue{Step: s.Name, Node: s.Target,
+				Message: fmt.Sprintf("no operation executor found for implementation artifact %q of operation %q", op.ImplementationArtifact, a.Value())})
+		}
+		pa.ExecutorOrigin = origin
+	}
+	return pa, nil
+}
+
+func (p *Plan) checkInputs(kv *api.KV, deploymentID string) error {
+	inputs, err := deployments.GetTopologyInputsNames(kv, deploymentID)
+	if err != nil {
+		return err
+	}
+	sort.Strings(inputs)
+	for _, input := range inputs {
+		value, err := deployments.GetInputValue(kv, deploymentID, input)
+		if err != nil {
+			p.UnresolvedFunctions = append(p.UnresolvedFunctions, PlanIssue{Message: fmt.Sprintf("failed to resolve input %q: %v", input, err)})
+			continue
+		}
+		required, err := deployments.IsTopologyInputRequired(kv, deploymentID, input)
+		if err != nil {
+			return err
+		}
+		if value == "" && required {
+			p.UnresolvedFunctions = append(p.UnresolvedFunctions, PlanIssue{Message: fmt.Sprintf("required input %q has no value nor default", input)})
+		}
+	}
+	return nil
+}
+
+func (p *Plan) checkNodeProperties(kv *api.KV, deploymentID, nodeName string) error {
+	nodeType, err := deployments.GetNodeType(kv, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	props, err := deployments.GetTypeProperties(kv, deploymentID, nodeType, true)
+	if err != nil {
+		return err
+	}
+	sort.Strings(props)
+	for _, prop := range props {
+		_, err = deployments.GetNodePropertyValue(kv, deploymentID, nodeName, prop)
+		if err != nil {
+			p.UnresolvedFunctions = append(p.UnresolvedFunctions, PlanIssue{Node: nodeName,
+				Message: fmt.Sprintf("failed to resolve property %q: %v", prop, err)})
+		}
+	}
+	return nil
+}
+
+// GetNodeInfrastructure returns the infrastructure of a node based on its type hierarchy
+// (types named yorc.nodes.<infrastructure>.<type>) or an empty string if the node is not related to an infrastructure
+func GetNodeInfrastructure(kv *api.KV, deploymentID, nodeName string) (string, error) {
+	nodeType, err := deployments.GetNodeType(kv, deploymentID, nodeName)
+	if err != nil {
+		return "", err
+	}
+	for nodeType != "" {
+		parts := strings.Split(nodeType, ".")
+		if len(parts) > 3 && parts[0] == "yorc" && parts[1] == "nodes" {
+			return parts[2], nil
+		}
+		nodeType, err = deployments.GetParentType(kv, deploymentID, nodeType)
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// getDelegateExecutorOrigin returns the origin of the delegate executor registered for a node type
+// or an empty string if there is none. It follows the registry matching rules.
+func getDelegateExecutorOrigin(nodeType string) (string, error) {
+	for _, m := range registry.GetRegistry().ListDelegateExecutors() {
+		ok, err := regexp.MatchString(m.Match, nodeType)
+		if err != nil {
+			return "", errors.Wrapf(err, "Failed to match delegate executor from nodeType %q", nodeType)
+		}
+		if ok {
+			return m.Origin, nil
+		}
+	}
+	return "", nil
+}
+
+// getOperationExecutorOrigin returns the origin of the operation executor registered for an implementation artifact
+// or an empty string if there is none. Like at execution time executors registered for parent artifact types are also considered.
+func getOperationExecutorOrigin(kv *api.KV, deploymentID, artifact string) (string, error) {
+	for artifact != "" {
+		for _, m := range registry.GetRegistry().ListOperationExecutors() {
+			if m.Artifact == artifact {
+				return m.Origin, nil
+			}
+		}
+		var err error
+		artifact, err = deployments.GetParentType(kv, deploymentID, artifact)
+		if err != nil {
+			if deployments.IsTypeMissingError(err) {
+				return "", nil
+			}
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// computeStepsLevels returns for each step the length of the longest path from an initial step
+func computeStepsLevels(steps map[string]*Step) map[string]int {
+	levels := make(map[string]int, len(steps))
+	var level func(s *Step, visiting map[string]bool) int
+	level = func(s *Step, visiting map[string]bool) int {
+		if l, ok := levels[s.Name]; ok {
+			return l
+		}
+		if visiting[s.Name] {
+			// Should not happen as workflows are acyclic, do not loop forever anyway
+			return 0
+		}
+		visiting[s.Name] = true
+		l := 0
+		for _, prev := range s.Previous {
+			if pl := level(prev, visiting) + 1; pl > l {
+				l = pl
+			}
+		}
+		delete(visiting, s.Name)
+		levels[s.Name] = l
+		return l
+	}
+	for _, s := range steps {
+		level(s, make(map[string]bool))
+	}
+	return levels
+}
+
+func stepsNames(steps []*Step) []string {
+	if len(steps) == 0 {
+		return nil
+	}
+	names := make([]string, len(steps))
+	for i, s := range steps {
+		names[i] = s.Name
+	}
+	sort.Strings(names)
+	return names
+}
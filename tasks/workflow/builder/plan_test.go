@@ -0,0 +1,108 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/prov"
+	"github.com/ystia/yorc/registry"
+)
+
+type mockPlanExecutor struct{}
+
+func (m *mockPlanExecutor) ExecDelegate(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName, delegateOperation string) error {
+	return nil
+}
+
+func (m *mockPlanExecutor) ExecAsyncOperation(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName string, operation prov.Operation, stepName string) (*prov.Action, time.Duration, error) {
+	return nil, 0, nil
+}
+
+func (m *mockPlanExecutor) ExecOperation(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName string, operation prov.Operation) error {
+	return nil
+}
+
+func testBuildPlan(t *testing.T, kv *api.KV) {
+	deploymentID := strings.Replace(t.Name(), "/", "_", -1)
+	err := deployments.StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/plan.yaml")
+	require.NoError(t, err)
+
+	mock := &mockPlanExecutor{}
+	registry.GetRegistry().RegisterDelegates([]string{`yorc\.nodes\.planinfra\..*`}, mock, "plan-tests")
+	registry.GetRegistry().RegisterOperationExecutor([]string{"ystia.yorc.tests.artifacts.Implementation.PlanCustom"}, mock, "plan-tests")
+
+	plan, err := BuildPlan(context.Background(), kv, config.Configuration{}, deploymentID, "install")
+	require.NoError(t, err)
+	require.NotNil(t, plan)
+	assert.Equal(t, "install", plan.WorkflowName)
+	assert.True(t, plan.HasIssues())
+
+	// Steps are ordered by level
+	require.Len(t, plan.Steps, 5)
+	expectedOrder := []string{"Compute_install", "PlanNode_create", "PlanNode_configure", "PlanNode_start", "PlanNode_stop"}
+	for i, name := range expectedOrder {
+		assert.Equal(t, name, plan.Steps[i].Name)
+		assert.Equal(t, i, plan.Steps[i].Level)
+	}
+	assert.Equal(t, []string{"PlanNode_create"}, plan.Steps[0].Next)
+
+	// Delegate resolved by node type
+	require.Len(t, plan.Steps[0].Activities, 1)
+	assert.Equal(t, "delegate", plan.Steps[0].Activities[0].Type)
+	assert.Equal(t, "plan-tests", plan.Steps[0].Activities[0].ExecutorOrigin)
+
+	// Operation resolved by implementation artifact
+	require.Len(t, plan.Steps[1].Activities, 2)
+	assert.Equal(t, "set-state", plan.Steps[1].Activities[0].Type)
+	assert.Equal(t, "call-operation", plan.Steps[1].Activities[1].Type)
+	assert.Equal(t, "ystia.yorc.tests.artifacts.Implementation.PlanCustom", plan.Steps[1].Activities[1].ImplementationArtifact)
+	assert.Equal(t, "plan-tests", plan.Steps[1].Activities[1].ExecutorOrigin)
+
+	// No executor for this artifact
+	assert.Equal(t, "", plan.Steps[2].Activities[0].ExecutorOrigin)
+	require.Len(t, plan.MissingImplementations, 1)
+	assert.Equal(t, "PlanNode_configure", plan.MissingImplementations[0].Step)
+	assert.Equal(t, "PlanNode", plan.MissingImplementations[0].Node)
+
+	// Executor resolved using the parent artifact type
+	assert.Equal(t, "plan-tests", plan.Steps[3].Activities[0].ExecutorOrigin)
+
+	// Not implemented operation is skipped
+	assert.True(t, plan.Steps[4].Activities[0].Skipped)
+
+	require.Len(t, plan.UnresolvedFunctions, 1)
+	assert.Contains(t, plan.UnresolvedFunctions[0].Message, "required_input")
+
+	assert.Equal(t, []string{"planinfra"}, plan.MissingInfrastructureConfigs)
+
+	// Infrastructure configured
+	cfg := config.Configuration{Infrastructures: map[string]config.DynamicMap{"planinfra": config.DynamicMap{}}}
+	plan, err = BuildPlan(context.Background(), kv, cfg, deploymentID, "install")
+	require.NoError(t, err)
+	assert.Len(t, plan.MissingInfrastructureConfigs, 0)
+
+	_, err = BuildPlan(context.Background(), kv, cfg, deploymentID, "doesnotexist")
+	require.Error(t, err)
+}
@@ -29,5 +29,14 @@ func TestRunConsulWorkflowPackageTests(t *testing.T) {
 		t.Run("testRunStep", func(t *testing.T) {
 			testRunStep(t, srv, client)
 		})
+		t.Run("testRunStepWithRetries", func(t *testing.T) {
+			testRunStepWithRetries(t, srv, client)
+		})
+		t.Run("testRunStepWithTimeout", func(t *testing.T) {
+			testRunStepWithTimeout(t, srv, client)
+		})
+		t.Run("testConsulSlotAcquirer", func(t *testing.T) {
+			testConsulSlotAcquirer(t, client)
+		})
 	})
 }
@@ -27,16 +27,24 @@ import (
 
 	"github.com/ystia/yorc/config"
 	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/helper/metricsutil"
 	"github.com/ystia/yorc/log"
 	"github.com/ystia/yorc/tasks"
 )
 
 const executionLockPrefix = ".processingLock-"
 
+// throttledExecutionsRetryInterval is the maximum delay before trying again to dispatch executions
+// that were postponed due to concurrency limits
+var throttledExecutionsRetryInterval = 5 * time.Second
+
 // Dispatcher concern is polling executions task and dispatch them across available workers
 // It has to acquire a lock on the execution task as other distributed dispatchers can try to do the same
 // If it gets the lock, it instantiates an execution task and push it to workers pool
 // If it receives a message from shutdown channel, it has to spread the shutdown to workers
+//
+// Pending executions are dispatched by decreasing task type priority and executions exceeding
+// the per deployment or per infrastructure concurrency limits are postponed.
 type Dispatcher struct {
 	client     *api.Client
 	shutdownCh chan struct{}
@@ -44,12 +52,15 @@ type Dispatcher struct {
 	maxWorkers int
 	cfg        config.Configuration
 	wg         *sync.WaitGroup
+	priorities map[tasks.TaskType]int
+	limiter    *executionsLimiter
 }
 
 // NewDispatcher create a new Dispatcher with a given number of workers
 func NewDispatcher(cfg config.Configuration, shutdownCh chan struct{}, client *api.Client, wg *sync.WaitGroup) *Dispatcher {
 	pool := make(chan chan *taskExecution, cfg.WorkersNumber)
-	dispatcher := &Dispatcher{WorkerPool: pool, client: client, shutdownCh: shutdownCh, maxWorkers: cfg.WorkersNumber, cfg: cfg, wg: wg}
+	dispatcher := &Dispatcher{WorkerPool: pool, client: client, shutdownCh: shutdownCh, maxWorkers: cfg.WorkersNumber, cfg: cfg, wg: wg,
+		priorities: getTaskTypesPriorities(cfg.TasksDispatcher), limiter: newExecutionsLimiter(cfg.TasksDispatcher, newConsulSlotAcquirer(client))}
 	dispatcher.emitTasksMetrics()
 	return dispatcher
 }
@@ -86,10 +97,12 @@ func (d *Dispatcher) emitTasksMetrics() {
 		defer d.wg.Done()
 		kv := d.client.KV()
 		lastWarn := time.Now().Add(-6 * time.Minute)
+		var runningGauges map[string][]string
 		for {
 			select {
 			case <-time.After(time.Second):
 				metrics.SetGauge([]string{"workers", "free"}, float32(len(d.WorkerPool)))
+				runningGauges = d.emitRunningExecutionsMetrics(runningGauges)
 				nb, maxWait, err := getNbAndMaxTasksWaitTimeMs(kv)
 				if err != nil {
 					now := time.Now()
@@ -109,6 +122,31 @@ func (d *Dispatcher) emitTasksMetrics() {
 	}()
 }
 
+// emitRunningExecutionsMetrics sets gauges of executions running per deployment and per infrastructure.
+// Gauges previously reported that have no more running executions are reset to 0.
+// It returns the reported gauges for the next call.
+func (d *Dispatcher) emitRunningExecutionsMetrics(previous map[string][]string) map[string][]string {
+	byDeployment, byInfrastructure := d.limiter.runningExecutions()
+	reported := make(map[string][]string, len(byDeployment)+len(byInfrastructure))
+	report := func(kind, name string, nb int) {
+		key := metricsutil.CleanupMetricKey([]string{"executions", "running", kind, name})
+		metrics.SetGauge(key, float32(nb))
+		reported[kind+"/"+name] = key
+	}
+	for deploymentID, nb := range byDeployment {
+		report("deployments", deploymentID, nb)
+	}
+	for infra, nb := range byInfrastructure {
+		report("infrastructures", infra, nb)
+	}
+	for id, key := range previous {
+		if _, ok := reported[id]; !ok {
+			metrics.SetGauge(key, 0)
+		}
+	}
+	return reported
+}
+
 func getExecutionKeyValue(kv *api.KV, execID, execKey string) (string, error) {
 	execPath := path.Join(consulutil.ExecutionsTaskPrefix, execID)
 	kvPairContent, _, err := kv.Get(path.Join(execPath, execKey), nil)
@@ -146,6 +184,8 @@ func (d *Dispatcher) Run() {
 		log.Panicf("Can't connect to Consul %+v... Aborting", err)
 	}
 
+	var throttled bool
+	cache := make(pendingExecutionsCache)
 	for {
 		select {
 		case <-d.shutdownCh:
@@ -154,6 +194,10 @@ func (d *Dispatcher) Run() {
 		default:
 		}
 		q := &api.QueryOptions{WaitIndex: waitIndex}
+		if throttled {
+			// Do not wait for a change to retry postponed executions
+			q.WaitTime = throttledExecutionsRetryInterval
+		}
 		log.Debugf("Long polling Task Executions")
 		execKeys, rMeta, err := kv.Keys(consulutil.ExecutionsTaskPrefix+"/", "/", q)
 		if err != nil {
@@ -162,100 +206,154 @@ func (d *Dispatcher) Run() {
 			log.Debugf("%+v", err)
 			continue
 		}
-		if waitIndex == rMeta.LastIndex {
+		if waitIndex == rMeta.LastIndex && !throttled {
 			// long pool ended due to a timeout
 			// there is no new items go back to the pooling
 			continue
 		}
 		waitIndex = rMeta.LastIndex
 		log.Debugf("Got response new wait index is %d", waitIndex)
+		throttled = false
+		lockedExecs := getLockedExecutions(kv, execKeys)
+		pendingExecs := make([]pendingExecution, 0, len(execKeys))
+		pendingIDs := make(map[string]bool, len(execKeys))
 		for _, execKey := range execKeys {
 			execID := path.Base(execKey)
-			// Ignore locks
-			if strings.HasPrefix(execID, executionLockPrefix) {
-				continue
-			}
-
-			log.Debugf("Try to acquire processing lock for task execution %s", execKey)
-			opts := &api.LockOptions{
-				Key:          path.Join(consulutil.ExecutionsTaskPrefix, executionLockPrefix+execID),
-				Value:        []byte(nodeName),
-				LockTryOnce:  true,
-				LockWaitTime: 10 * time.Millisecond,
-			}
-			lock, err := d.client.LockOpts(opts)
-			if err != nil {
-				log.Printf("Can't create processing lock for key %s: %+v", execKey, err)
+			// Ignore locks and executions already processed by this server or another one
+			if strings.HasPrefix(execID, executionLockPrefix) || lockedExecs[execID] {
 				continue
 			}
-			leaderChan, err := lock.Lock(nil)
-			if err != nil {
-				log.Printf("Can't create acquire lock for key %s: %+v", execKey, err)
-				continue
-			}
-			if leaderChan == nil {
-				log.Debugf("Another instance got the lock for key %s", execKey)
-				continue
-			}
-
-			log.Debugf("Got processing lock for Task Execution %s", execKey)
-
-			taskID, err := getExecutionKeyValue(kv, execID, "taskID")
-			if err != nil {
-				log.Debugf("Ignore execution with ID:%q due to error:%v", execID, err)
-				d.deleteExecutionTree(execID)
-				lock.Unlock()
-				lock.Destroy()
-				continue
-			}
-			// Check TaskExecution status
-			status, err := tasks.GetTaskStatus(kv, taskID)
-			if err != nil {
-				log.Debugf("Seems the task with id:%q is no longer relevant due to error:%s so related execution will be removed", taskID, err)
-				d.deleteExecutionTree(execID)
-				lock.Unlock()
-				lock.Destroy()
-				continue
-			}
-			if status != tasks.TaskStatusINITIAL && status != tasks.TaskStatusRUNNING {
-				log.Debugf("Skipping Task Execution with status %q", status)
-				// Delete useless execution
-				d.deleteExecutionTree(execID)
-				lock.Unlock()
-				lock.Destroy()
+			pendingIDs[execID] = true
+			pendingExecs = append(pendingExecs, cache.get(kv, execID, d.priorities, d.limiter.hasInfrastructureLimits()))
+		}
+		cache.retain(pendingIDs)
+		sortPendingExecutions(pendingExecs)
+		for _, pe := range pendingExecs {
+			if ok, reason := d.limiter.tryAcquire(pe.id, pe.targetID, pe.infrastructure); !ok {
+				log.Debugf("Postpone Task Execution %q: %s", pe.id, reason)
+				metrics.IncrCounter([]string{"executions", "throttled"}, 1)
+				throttled = true
 				continue
 			}
-
-			t, err := buildTaskExecution(d.client, execID)
-			if err != nil {
-				log.Print(err)
-				log.Debugf("%+v", err)
-				lock.Unlock()
-				lock.Destroy()
-				continue
+			dispatched, shutdown := d.dispatchExecution(kv, nodeName, pe)
+			if !dispatched {
+				d.limiter.release(pe.id)
 			}
-			log.Printf("Processing Task Execution %q linked to deployment %q", execID, t.targetID)
-			t.lock = lock
-			log.Debugf("New Task Execution created %+v: pushing it to workers channel", t)
-			// try to obtain a worker TaskExecution channel until timeout
-			select {
-			case taskChannel := <-d.WorkerPool:
-				taskChannel <- t
-			case <-leaderChan:
-				// lock lost
-				continue
-			case <-d.shutdownCh:
-				lock.Unlock()
-				lock.Destroy()
+			if shutdown {
 				log.Printf("Dispatcher received shutdown signal. Exiting...")
 				return
-			case <-time.After(2 * time.Second):
-				log.Debugf("Release the lock for execID:%q to let another yorc instance worker take the execution", execID)
-				lock.Unlock()
-				lock.Destroy()
-				time.Sleep(100 * time.Millisecond)
-				break
 			}
 		}
 	}
 }
+
+// getLockedExecutions returns the IDs of executions which processing lock is currently held
+func getLockedExecutions(kv *api.KV, execKeys []string) map[string]bool {
+	lockedExecs := make(map[string]bool)
+	for _, execKey := range execKeys {
+		lockName := path.Base(execKey)
+		if !strings.HasPrefix(lockName, executionLockPrefix) {
+			continue
+		}
+		kvp, _, err := kv.Get(execKey, nil)
+		if err != nil {
+			log.Debugf("Failed to check processing lock %q: %v", execKey, err)
+			continue
+		}
+		if kvp != nil && kvp.Session != "" {
+			lockedExecs[strings.TrimPrefix(lockName, executionLockPrefix)] = true
+		}
+	}
+	return lockedExecs
+}
+
+// dispatchExecution acquires the processing lock of a task execution and pushes it to a worker.
+//
+// It returns true if the execution was handed to a worker and true as second value if a shutdown was requested.
+func (d *Dispatcher) dispatchExecution(kv *api.KV, nodeName string, pe pendingExecution) (bool, bool) {
+	execID := pe.id
+	execKey := path.Join(consulutil.ExecutionsTaskPrefix, execID)
+	log.Debugf("Try to acquire processing lock for task execution %s", execKey)
+	opts := &api.LockOptions{
+		Key:          path.Join(consulutil.ExecutionsTaskPrefix, executionLockPrefix+execID),
+		Value:        []byte(nodeName),
+		LockTryOnce:  true,
+		LockWaitTime: 10 * time.Millisecond,
+	}
+	lock, err := d.client.LockOpts(opts)
+	if err != nil {
+		log.Printf("Can't create processing lock for key %s: %+v", execKey, err)
+		return false, false
+	}
+	leaderChan, err := lock.Lock(nil)
+	if err != nil {
+		log.Printf("Can't create acquire lock for key %s: %+v", execKey, err)
+		return false, false
+	}
+	if leaderChan == nil {
+		log.Debugf("Another instance got the lock for key %s", execKey)
+		return false, false
+	}
+
+	log.Debugf("Got processing lock for Task Execution %s", execKey)
+
+	taskID, err := getExecutionKeyValue(kv, execID, "taskID")
+	if err != nil {
+		log.Debugf("Ignore execution with ID:%q due to error:%v", execID, err)
+		d.deleteExecutionTree(execID)
+		lock.Unlock()
+		lock.Destroy()
+		return false, false
+	}
+	// Check TaskExecution status
+	status, err := tasks.GetTaskStatus(kv, taskID)
+	if err != nil {
+		log.Debugf("Seems the task with id:%q is no longer relevant due to error:%s so related execution will be removed", taskID, err)
+		d.deleteExecutionTree(execID)
+		lock.Unlock()
+		lock.Destroy()
+		return false, false
+	}
+	if status != tasks.TaskStatusINITIAL && status != tasks.TaskStatusRUNNING {
+		log.Debugf("Skipping Task Execution with status %q", status)
+		// Delete useless execution
+		d.deleteExecutionTree(execID)
+		lock.Unlock()
+		lock.Destroy()
+		return false, false
+	}
+
+	t, err := buildTaskExecution(d.client, execID)
+	if err != nil {
+		log.Print(err)
+		log.Debugf("%+v", err)
+		lock.Unlock()
+		lock.Destroy()
+		return false, false
+	}
+	log.Printf("Processing Task Execution %q linked to deployment %q", execID, t.targetID)
+	t.lock = lock
+	t.releaseSlot = func() {
+		d.limiter.release(pe.id)
+	}
+	log.Debugf("New Task Execution created %+v: pushing it to workers channel", t)
+	// try to obtain a worker TaskExecution channel until timeout
+	select {
+	case taskChannel := <-d.WorkerPool:
+		taskChannel <- t
+		return true, false
+	case <-leaderChan:
+		// lock lost
+		return false, false
+	case <-d.shutdownCh:
+		lock.Unlock()
+		lock.Destroy()
+		return false, true
+	case <-time.After(2 * time.Second):
+		log.Debugf("Release the lock for execID:%q to let another yorc instance worker take the execution", execID)
+		lock.Unlock()
+		lock.Destroy()
+		time.Sleep(100 * time.Millisecond)
+		return false, false
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/tasks"
+	"github.com/ystia/yorc/tasks/workflow/builder"
+)
+
+// defaultTaskTypesPriorities are the priorities of task types used when not overridden by the configuration.
+// Short-lived interactive tasks are dispatched ahead of workflows.
+var defaultTaskTypesPriorities = map[tasks.TaskType]int{
+	tasks.TaskTypeQuery:          20,
+	tasks.TaskTypeAction:         20,
+	tasks.TaskTypeCustomCommand:  20,
+	tasks.TaskTypeCustomWorkflow: 10,
+	tasks.TaskTypeScaleOut:       10,
+	tasks.TaskTypeScaleIn:        10,
+}
+
+// getTaskTypesPriorities merges the default task types priorities with those defined in the configuration
+func getTaskTypesPriorities(cfg config.TasksDispatcher) map[tasks.TaskType]int {
+	priorities := make(map[tasks.TaskType]int, len(defaultTaskTypesPriorities))
+	for tt, p := range defaultTaskTypesPriorities {
+		priorities[tt] = p
+	}
+	for name, p := range cfg.TaskTypesPriorities {
+		found := false
+		for tt := tasks.TaskTypeDeploy; tt <= tasks.TaskTypeUpdate; tt++ {
+			if strings.EqualFold(tt.String(), name) {
+				priorities[tt] = p
+				found = true
+				break
+			}
+		}
+		if !found {
+			log.Printf("[WARNING] Ignoring priority defined for unknown task type %q in tasks dispatcher configuration", name)
+		}
+	}
+	return priorities
+}
+
+// pendingExecution is a task execution waiting to be dispatched
+type pendingExecution struct {
+	id             string
+	targetID       string
+	infrastructure string
+	priority       int
+	creationDate   time.Time
+}
+
+// sortPendingExecutions sorts executions by decreasing priority then by creation date
+func sortPendingExecutions(execs []pendingExecution) {
+	sort.SliceStable(execs, func(i, j int) bool {
+		if execs[i].priority != execs[j].priority {
+			return execs[i].priority > execs[j].priority
+		}
+		return execs[i].creationDate.Before(execs[j].creationDate)
+	})
+}
+
+// getPendingExecution reads the information needed to schedule a task execution.
+//
+// The infrastructure of the execution is resolved only if withInfrastructure is true.
+// Missing information are ignored as executions that can't be read are dealt with by the dispatcher,
+// the returned boolean is false in this case.
+func getPendingExecution(kv *api.KV, execID string, priorities map[tasks.TaskType]int, withInfrastructure bool) (pendingExecution, bool) {
+	pe := pendingExecution{id: execID, creationDate: time.Now()}
+	// The creation date may not be stored yet
+	if cd, err := getExecutionKeyValue(kv, execID, "creationDate"); err == nil {
+		if d, err := time.Parse(time.RFC3339Nano, cd); err == nil {
+			pe.creationDate = d
+		}
+	}
+	taskID, err := getExecutionKeyValue(kv, execID, "taskID")
+	if err != nil {
+		return pe, false
+	}
+	pe.targetID, err = tasks.GetTaskTarget(kv, taskID)
+	if err != nil {
+		return pe, false
+	}
+	taskType, err := tasks.GetTaskType(kv, taskID)
+	if err != nil {
+		return pe, false
+	}
+	pe.priority = priorities[taskType]
+	if withInfrastructure && tasks.IsWorkflowTask(taskType) {
+		step, err := getExecutionKeyValue(kv, execID, "step")
+		if err != nil {
+			return pe, false
+		}
+		pe.infrastructure, err = getStepInfrastructure(kv, taskID, pe.targetID, step, taskType)
+		if err != nil {
+			log.Debugf("Failed to resolve infrastructure of task execution %q: %+v", execID, err)
+			return pe, false
+		}
+	}
+	return pe, true
+}
+
+// pendingExecutionsCache keeps the scheduling information of pending executions between dispatcher loops
+// as they do not change while an execution is pending
+type pendingExecutionsCache map[string]pendingExecution
+
+// get returns the scheduling information of an execution, reading it only if it is not cached yet
+func (c pendingExecutionsCache) get(kv *api.KV, execID string, priorities map[tasks.TaskType]int, withInfrastructure bool) pendingExecution {
+	if pe, ok := c[execID]; ok {
+		return pe
+	}
+	pe, complete := getPendingExecution(kv, execID, priorities, withInfrastructure)
+	// Incomplete information are read again next time
+	if complete {
+		c[execID] = pe
+	}
+	return pe
+}
+
+// retain removes from the cache the executions that are not in the given ones
+func (c pendingExecutionsCache) retain(execIDs map[string]bool) {
+	for execID := range c {
+		if !execIDs[execID] {
+			delete(c, execID)
+		}
+	}
+}
+
+// executionsLimitsPrefix is the Consul prefix of the semaphores enforcing executions concurrency limits
+var executionsLimitsPrefix = path.Join(consulutil.YorcManagementPrefix, "executions_limits")
+
+// executionsLimitsSessionTTL is the TTL of the Consul sessions holding executions slots,
+// slots held by a Yorc server that stopped are freed once it expires
+const executionsLimitsSessionTTL = 15 * time.Second
+
+// A slotAcquirer reserves a slot among the given limit of slots shared by all Yorc servers under the given name.
+//
+// It returns the function freeing the slot or nil if no slot is available.
+type slotAcquirer func(name string, limit int) (func(), error)
+
+// newConsulSlotAcquirer returns a slotAcquirer using Consul semaphores
+func newConsulSlotAcquirer(cc *api.Client) slotAcquirer {
+	return func(name string, limit int) (func(), error) {
+		// Contender entries are deleted with the session, this prevents them to accumulate when
+		// the semaphore could not be acquired
+		sessionID, _, err := cc.Session().Create(&api.SessionEntry{
+			Name:     "executions limit " + name,
+			TTL:      executionsLimitsSessionTTL.String(),
+			Behavior: api.SessionBehaviorDelete,
+			// Consul Issue : LockDelay = 0 is not allowed by API. https://github.com/hashicorp/consul/issues/1077
+			LockDelay: 1 * time.Nanosecond,
+		}, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+		doneCh := make(chan struct{})
+		// Destroys the session when doneCh is closed
+		go cc.Session().RenewPeriodic(executionsLimitsSessionTTL.String(), sessionID, nil, doneCh)
+		semaphore, err := cc.SemaphoreOpts(&api.SemaphoreOptions{
+			Prefix:            path.Join(executionsLimitsPrefix, name),
+			Limit:             limit,
+			Session:           sessionID,
+			SemaphoreTryOnce:  true,
+			SemaphoreWaitTime: 10 * time.Millisecond,
+		})
+		if err != nil {
+			close(doneCh)
+			return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+		lostCh, err := semaphore.Acquire(nil)
+		if err != nil || lostCh == nil {
+			close(doneCh)
+			return nil, errors.Wrapf(err, "failed to acquire executions slot for %s", name)
+		}
+		return func() {
+			if err := semaphore.Release(); err != nil {
+				log.Printf("Failed to release executions slot for %s: %v", name, err)
+			}
+			close(doneCh)
+			// Removes the semaphore if this was its last holder
+			if err := semaphore.Destroy(); err != nil && err != api.ErrSemaphoreInUse {
+				log.Debugf("Failed to cleanup executions semaphore for %s: %v", name, err)
+			}
+		}, nil
+	}
+}
+
+// executionSlot holds the concurrency slots reserved for a running execution
+type executionSlot struct {
+	deploymentID   string
+	infrastructure string
+	release        []func()
+}
+
+// executionsLimiter enforces per deployment and per infrastructure concurrency limits
+// across all Yorc servers.
+//
+// It also keeps track of task executions running on this Yorc server for metrics purpose.
+type executionsLimiter struct {
+	lock                 sync.Mutex
+	acquireSlot          slotAcquirer
+	maxPerDeployment     int
+	maxPerInfrastructure map[string]int
+	slots                map[string]executionSlot
+	byDeployment         map[string]int
+	byInfrastructure     map[string]int
+}
+
+func newExecutionsLimiter(cfg config.TasksDispatcher, acquireSlot slotAcquirer) *executionsLimiter {
+	l := &executionsLimiter{
+		acquireSlot:          acquireSlot,
+		maxPerDeployment:     cfg.MaxConcurrentExecutionsPerDeployment,
+		maxPerInfrastructure: make(map[string]int, len(cfg.MaxConcurrentExecutionsPerInfrastructure)),
+		slots:                make(map[string]executionSlot),
+		byDeployment:         make(map[string]int),
+		byInfrastructure:     make(map[string]int),
+	}
+	for infra, max := range cfg.MaxConcurrentExecutionsPerInfrastructure {
+		l.maxPerInfrastructure[strings.ToLower(infra)] = max
+	}
+	return l
+}
+
+// hasInfrastructureLimits returns true if a concurrency limit is defined for at least one infrastructure
+func (l *executionsLimiter) hasInfrastructureLimits() bool {
+	for _, max := range l.maxPerInfrastructure {
+		if max > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// tryAcquire reserves a slot for the given execution of the given deployment on the given infrastructure
+// (which may be empty if the execution is not related to an infrastructure).
+//
+// It returns false and a reason if a concurrency limit is reached.
+func (l *executionsLimiter) tryAcquire(execID, deploymentID, infrastructure string) (bool, string) {
+	infrastructure = strings.ToLower(infrastructure)
+	slot := executionSlot{deploymentID: deploymentID, infrastructure: infrastructure}
+	releaseAll := func() {
+		for _, release := range slot.release {
+			release()
+		}
+	}
+	limits := []struct {
+		kind, name string
+		max        int
+	}{
+		{"deployment", deploymentID, l.maxPerDeployment},
+		{"infrastructure", infrastructure, l.maxPerInfrastructure[infrastructure]},
+	}
+	for _, limit := range limits {
+		if limit.name == "" || limit.max <= 0 {
+			continue
+		}
+		release, err := l.acquireSlot(path.Join(limit.kind+"s", limit.name), limit.max)
+		if err != nil {
+			log.Printf("[WARNING] Failed to check concurrent executions limit for %s %s: %v", limit.kind, limit.name, err)
+		}
+		if release == nil {
+			releaseAll()
+			return false, "maximum number of concurrent executions reached for " + limit.kind + " " + limit.name
+		}
+		slot.release = append(slot.release, release)
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.slots[execID] = slot
+	l.byDeployment[deploymentID]++
+	if infrastructure != "" {
+		l.byInfrastructure[infrastructure]++
+	}
+	return true, ""
+}
+
+// release frees the slot previously reserved for an execution using tryAcquire
+func (l *executionsLimiter) release(execID string) {
+	l.lock.Lock()
+	slot, ok := l.slots[execID]
+	if !ok {
+		l.lock.Unlock()
+		return
+	}
+	delete(l.slots, execID)
+	if l.byDeployment[slot.deploymentID] <= 1 {
+		delete(l.byDeployment, slot.deploymentID)
+	} else {
+		l.byDeployment[slot.deploymentID]--
+	}
+	if slot.infrastructure != "" {
+		if l.byInfrastructure[slot.infrastructure] <= 1 {
+			delete(l.byInfrastructure, slot.infrastructure)
+		} else {
+			l.byInfrastructure[slot.infrastructure]--
+		}
+	}
+	l.lock.Unlock()
+	for _, release := range slot.release {
+		release()
+	}
+}
+
+// runningExecutions returns a snapshot of the number of executions running on this Yorc server per deployment and per infrastructure
+func (l *executionsLimiter) runningExecutions() (map[string]int, map[string]int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	byDeployment := make(map[string]int, len(l.byDeployment))
+	for k, v := range l.byDeployment {
+		byDeployment[k] = v
+	}
+	byInfrastructure := make(map[string]int, len(l.byInfrastructure))
+	for k, v := range l.byInfrastructure {
+		byInfrastructure[k] = v
+	}
+	return byDeployment, byInfrastructure
+}
+
+// getWorkflowNameForTaskType returns the name of the workflow run by a workflow task
+func getWorkflowNameForTaskType(kv *api.KV, taskID string, taskType tasks.TaskType) (string, error) {
+	switch taskType {
+	case tasks.TaskTypeDeploy, tasks.TaskTypeScaleOut:
+		return "install", nil
+	case tasks.TaskTypeUnDeploy, tasks.TaskTypePurge, tasks.TaskTypeScaleIn:
+		return "uninstall", nil
+	case tasks.TaskTypeUpdate:
+		return deployments.UpdateWorkflowName, nil
+	default:
+		return tasks.GetTaskData(kv, taskID, "workflowName")
+	}
+}
+
+// getStepInfrastructure returns the infrastructure on which a workflow step operates.
+//
+// This is the infrastructure of the step target node, if any.
+func getStepInfrastructure(kv *api.KV, taskID, deploymentID, step string, taskType tasks.TaskType) (string, error) {
+	wfName, err := getWorkflowNameForTaskType(kv, taskID, taskType)
+	if err != nil {
+		return "", err
+	}
+	wf, err := deployments.ReadWorkflow(kv, deploymentID, wfName)
+	if err != nil {
+		return "", err
+	}
+	s, ok := wf.Steps[step]
+	if !ok {
+		return "", errors.Errorf("unknown step %q in workflow %q of deployment %q", step, wfName, deploymentID)
+	}
+	if s == nil || s.Target == "" {
+		return "", nil
+	}
+	return builder.GetNodeInfrastructure(kv, deploymentID, s.Target)
+}
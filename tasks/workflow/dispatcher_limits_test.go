@@ -0,0 +1,178 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/tasks"
+)
+
+func TestGetTaskTypesPriorities(t *testing.T) {
+	t.Parallel()
+	priorities := getTaskTypesPriorities(config.TasksDispatcher{})
+	assert.True(t, priorities[tasks.TaskTypeQuery] > priorities[tasks.TaskTypeDeploy])
+	assert.True(t, priorities[tasks.TaskTypeCustomCommand] > priorities[tasks.TaskTypeDeploy])
+	assert.Equal(t, 0, priorities[tasks.TaskTypeDeploy])
+
+	// Viper lower cases keys
+	priorities = getTaskTypesPriorities(config.TasksDispatcher{TaskTypesPriorities: map[string]int{"deploy": 50, "CustomCommand": 5, "unknown": 100}})
+	assert.Equal(t, 50, priorities[tasks.TaskTypeDeploy])
+	assert.Equal(t, 5, priorities[tasks.TaskTypeCustomCommand])
+	assert.Equal(t, defaultTaskTypesPriorities[tasks.TaskTypeQuery], priorities[tasks.TaskTypeQuery])
+	// Defaults are not altered
+	assert.Equal(t, 20, defaultTaskTypesPriorities[tasks.TaskTypeCustomCommand])
+}
+
+func TestSortPendingExecutions(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	execs := []pendingExecution{
+		{id: "deploy-old", priority: 0, creationDate: now.Add(-time.Minute)},
+		{id: "query-new", priority: 20, creationDate: now},
+		{id: "deploy-new", priority: 0, creationDate: now},
+		{id: "query-old", priority: 20, creationDate: now.Add(-time.Second)},
+		{id: "wf", priority: 10, creationDate: now.Add(-time.Hour)},
+	}
+	sortPendingExecutions(execs)
+	ids := make([]string, len(execs))
+	for i := range execs {
+		ids[i] = execs[i].id
+	}
+	assert.Equal(t, []string{"query-old", "query-new", "wf", "deploy-old", "deploy-new"}, ids)
+}
+
+func TestPendingExecutionsCache(t *testing.T) {
+	t.Parallel()
+	cache := pendingExecutionsCache{
+		"e1": {id: "e1", targetID: "dep1", priority: 10},
+		"e2": {id: "e2", targetID: "dep2"},
+	}
+	// Cached executions are not read again
+	pe := cache.get(nil, "e1", nil, true)
+	assert.Equal(t, "dep1", pe.targetID)
+	assert.Equal(t, 10, pe.priority)
+
+	cache.retain(map[string]bool{"e2": true, "e3": true})
+	assert.Len(t, cache, 1)
+	assert.Contains(t, cache, "e2")
+}
+
+// newTestSlotAcquirer returns an in-memory slotAcquirer
+func newTestSlotAcquirer() slotAcquirer {
+	var lock sync.Mutex
+	holders := make(map[string]int)
+	return func(name string, limit int) (func(), error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if holders[name] >= limit {
+			return nil, nil
+		}
+		holders[name]++
+		return func() {
+			lock.Lock()
+			defer lock.Unlock()
+			holders[name]--
+		}, nil
+	}
+}
+
+func TestExecutionsLimiter(t *testing.T) {
+	t.Parallel()
+	l := newExecutionsLimiter(config.TasksDispatcher{
+		MaxConcurrentExecutionsPerDeployment:     2,
+		MaxConcurrentExecutionsPerInfrastructure: map[string]int{"OpenStack": 1, "aws": 0},
+	}, newTestSlotAcquirer())
+	assert.True(t, l.hasInfrastructureLimits())
+
+	ok, _ := l.tryAcquire("e1", "dep1", "")
+	assert.True(t, ok)
+	ok, _ = l.tryAcquire("e2", "dep1", "openstack")
+	assert.True(t, ok)
+	// Deployment limit reached
+	ok, reason := l.tryAcquire("e3", "dep1", "")
+	assert.False(t, ok)
+	assert.Contains(t, reason, "dep1")
+	// Infrastructure limit reached
+	ok, reason = l.tryAcquire("e4", "dep2", "OPENSTACK")
+	assert.False(t, ok)
+	assert.Contains(t, reason, "openstack")
+	// No limit for this infrastructure
+	ok, _ = l.tryAcquire("e5", "dep2", "aws")
+	assert.True(t, ok)
+	ok, _ = l.tryAcquire("e6", "dep2", "aws")
+	assert.True(t, ok)
+
+	byDeployment, byInfrastructure := l.runningExecutions()
+	assert.Equal(t, map[string]int{"dep1": 2, "dep2": 2}, byDeployment)
+	assert.Equal(t, map[string]int{"openstack": 1, "aws": 2}, byInfrastructure)
+
+	l.release("e2")
+	ok, _ = l.tryAcquire("e7", "dep2", "openstack")
+	assert.False(t, ok, "dep2 reached its own limit")
+	// The infrastructure slot is freed when the deployment limit is reached
+	ok, _ = l.tryAcquire("e8", "dep3", "openstack")
+	assert.True(t, ok)
+
+	l.release("e1")
+	l.release("e5")
+	l.release("e6")
+	l.release("e8")
+	// Releasing twice has no effect
+	l.release("e8")
+	byDeployment, byInfrastructure = l.runningExecutions()
+	assert.Len(t, byDeployment, 0)
+	assert.Len(t, byInfrastructure, 0)
+
+	unlimited := newExecutionsLimiter(config.TasksDispatcher{}, newTestSlotAcquirer())
+	assert.False(t, unlimited.hasInfrastructureLimits())
+	for i := 0; i < 100; i++ {
+		ok, _ = unlimited.tryAcquire(strconv.Itoa(i), "dep", "openstack")
+		assert.True(t, ok)
+	}
+}
+
+func testConsulSlotAcquirer(t *testing.T, cc *api.Client) {
+	acquireSlot := newConsulSlotAcquirer(cc)
+	release1, err := acquireSlot("deployments/testConsulSlotAcquirer", 2)
+	require.NoError(t, err)
+	require.NotNil(t, release1)
+	release2, err := acquireSlot("deployments/testConsulSlotAcquirer", 2)
+	require.NoError(t, err)
+	require.NotNil(t, release2)
+	release, err := acquireSlot("deployments/testConsulSlotAcquirer", 2)
+	require.NoError(t, err)
+	require.Nil(t, release, "limit should be reached")
+
+	release1()
+	release3, err := acquireSlot("deployments/testConsulSlotAcquirer", 2)
+	require.NoError(t, err)
+	require.NotNil(t, release3)
+	release2()
+	release3()
+
+	kvp, _, err := cc.KV().Get(path.Join(executionsLimitsPrefix, "deployments/testConsulSlotAcquirer", api.DefaultSemaphoreKey), nil)
+	require.NoError(t, err)
+	require.Nil(t, kvp, "semaphore should be removed once released")
+}
@@ -0,0 +1,243 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/tasks/workflow/builder"
+	"github.com/ystia/yorc/tosca"
+)
+
+const (
+	retryBackoffFixed       = "fixed"
+	retryBackoffExponential = "exponential"
+
+	retryOnAll       = "all"
+	retryOnAny       = "any"
+	retryOnDelegate  = "delegate"
+	retryOnOperation = "operation"
+	retryOnTimeout   = "timeout"
+
+	retryMetadataPrefix = "yorc.retry."
+)
+
+// retryPolicy is the resolved retry policy applied to the activities of a workflow step
+type retryPolicy struct {
+	maxAttempts  int
+	backoff      string
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	retryOn      []string
+}
+
+func newRetryPolicyFromConfig(cfg config.RetryPolicy) retryPolicy {
+	p := retryPolicy{
+		maxAttempts:  cfg.MaxAttempts,
+		backoff:      strings.ToLower(cfg.Backoff),
+		initialDelay: cfg.InitialDelay,
+		maxDelay:     cfg.MaxDelay,
+		retryOn:      cfg.RetryOn,
+	}
+	if p.maxAttempts <= 0 {
+		p.maxAttempts = config.DefaultWfStepRetryMaxAttempts
+	}
+	if p.backoff == "" {
+		p.backoff = config.DefaultWfStepRetryBackoff
+	}
+	if p.initialDelay <= 0 {
+		p.initialDelay = config.DefaultWfStepRetryInitialDelay
+	}
+	if p.maxDelay <= 0 {
+		p.maxDelay = config.DefaultWfStepRetryMaxDelay
+	}
+	if len(p.retryOn) == 0 {
+		p.retryOn = []string{retryOnAll}
+	}
+	return p
+}
+
+// merge overrides the policy with values defined in the given TOSCA retry policy
+func (p *retryPolicy) merge(rp *tosca.RetryPolicy) error {
+	if rp == nil {
+		return nil
+	}
+	if rp.MaxAttempts > 0 {
+		p.maxAttempts = rp.MaxAttempts
+	}
+	if rp.Backoff != "" {
+		p.backoff = strings.ToLower(rp.Backoff)
+	}
+	var err error
+	if rp.InitialDelay != "" {
+		p.initialDelay, err = time.ParseDuration(rp.InitialDelay)
+		if err != nil {
+			return errors.Wrapf(err, "invalid retry initial_delay %q", rp.InitialDelay)
+		}
+	}
+	if rp.MaxDelay != "" {
+		p.maxDelay, err = time.ParseDuration(rp.MaxDelay)
+		if err != nil {
+			return errors.Wrapf(err, "invalid retry max_delay %q", rp.MaxDelay)
+		}
+	}
+	if len(rp.RetryOn) > 0 {
+		p.retryOn = make([]string, 0, len(rp.RetryOn))
+		for _, r := range rp.RetryOn {
+			p.retryOn = append(p.retryOn, strings.ToLower(strings.TrimSpace(r)))
+		}
+	}
+	return p.validate()
+}
+
+func (p *retryPolicy) validate() error {
+	if p.backoff != retryBackoffFixed && p.backoff != retryBackoffExponential {
+		return errors.Errorf("unsupported retry backoff %q, expecting one of %q or %q", p.backoff, retryBackoffFixed, retryBackoffExponential)
+	}
+	for _, r := range p.retryOn {
+		switch r {
+		case retryOnAll, retryOnAny, retryOnDelegate, retryOnOperation, retryOnTimeout:
+		default:
+			return errors.Errorf("unsupported retry_on error class %q", r)
+		}
+	}
+	return nil
+}
+
+// delay returns the delay to wait after the given (1-based) failed attempt
+func (p retryPolicy) delay(attempt int) time.Duration {
+	d := p.initialDelay
+	if p.backoff == retryBackoffExponential {
+		for i := 1; i < attempt && d < p.maxDelay; i++ {
+			d *= 2
+		}
+	}
+	if p.maxDelay > 0 && d > p.maxDelay {
+		d = p.maxDelay
+	}
+	return d
+}
+
+// shouldRetry checks if an error returned by the given activity matches one of the error classes of the policy
+func (p retryPolicy) shouldRetry(activity builder.Activity, err error) bool {
+	if err == nil {
+		return false
+	}
+	activityType := activity.Type()
+	if activityType != builder.ActivityTypeDelegate && activityType != builder.ActivityTypeCallOperation {
+		return false
+	}
+	for _, r := range p.retryOn {
+		switch r {
+		case retryOnAll, retryOnAny:
+			return true
+		case retryOnDelegate:
+			if activityType == builder.ActivityTypeDelegate {
+				return true
+			}
+		case retryOnOperation:
+			if activityType == builder.ActivityTypeCallOperation {
+				return true
+			}
+		case retryOnTimeout:
+			if isTimeoutError(err) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isTimeoutError(err error) bool {
+	cause := errors.Cause(err)
+	if cause == context.DeadlineExceeded {
+		return true
+	}
+	t, ok := cause.(interface{ Timeout() bool })
+	return ok && t.Timeout()
+}
+
+// resolveRetryPolicy computes the retry policy of a step
+//
+// The global configuration is overridden by metadata defined on the step's target node template
+// or its types hierarchy (node template metadata taking precedence), which are in turn overridden
+// by the retry policy defined on the step itself.
+func resolveRetryPolicy(kv *api.KV, cfg config.Configuration, deploymentID, nodeName string, stepPolicy *tosca.RetryPolicy) (retryPolicy, error) {
+	p := newRetryPolicyFromConfig(cfg.WfStepRetry)
+	if err := p.validate(); err != nil {
+		return p, errors.Wrap(err, "invalid retry policy in configuration")
+	}
+	if nodeName != "" {
+		nodePolicy, err := getNodeRetryPolicy(kv, deploymentID, nodeName)
+		if err != nil {
+			return p, err
+		}
+		if err = p.merge(nodePolicy); err != nil {
+			return p, errors.Wrapf(err, "invalid retry policy in metadata of node %q", nodeName)
+		}
+	}
+	if err := p.merge(stepPolicy); err != nil {
+		return p, errors.Wrap(err, "invalid retry policy in workflow step")
+	}
+	return p, nil
+}
+
+// getNodeRetryPolicy reads the yorc.retry.* metadata of a node template, falling back to its types hierarchy
+func getNodeRetryPolicy(kv *api.KV, deploymentID, nodeName string) (*tosca.RetryPolicy, error) {
+	nodeType, err := deployments.GetNodeType(kv, deploymentID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	getMetadata := func(key string) (bool, string, error) {
+		found, value, err := deployments.GetNodeMetadata(kv, deploymentID, nodeName, retryMetadataPrefix+key)
+		if err != nil || found {
+			return found, value, err
+		}
+		return deployments.GetTypeMetadata(kv, deploymentID, nodeType, retryMetadataPrefix+key, true)
+	}
+	rp := &tosca.RetryPolicy{}
+	found, value, err := getMetadata("max_attempts")
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		rp.MaxAttempts, err = strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %smax_attempts metadata value %q", retryMetadataPrefix, value)
+		}
+	}
+	for key, dest := range map[string]*string{"backoff": &rp.Backoff, "initial_delay": &rp.InitialDelay, "max_delay": &rp.MaxDelay} {
+		_, *dest, err = getMetadata(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	found, value, err = getMetadata("retry_on")
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		rp.RetryOn = strings.Split(value, ",")
+	}
+	return rp, nil
+}
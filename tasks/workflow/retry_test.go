@@ -0,0 +1,214 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/registry"
+	"github.com/ystia/yorc/tasks"
+	"github.com/ystia/yorc/tasks/workflow/builder"
+	"github.com/ystia/yorc/tosca"
+)
+
+type mockActivity struct {
+	activityType builder.ActivityType
+}
+
+func (m mockActivity) Type() builder.ActivityType {
+	return m.activityType
+}
+
+func (m mockActivity) Value() string {
+	return "mock"
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string {
+	return "timeout"
+}
+
+func (timeoutError) Timeout() bool {
+	return true
+}
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	t.Parallel()
+	p := newRetryPolicyFromConfig(config.RetryPolicy{})
+	assert.Equal(t, config.DefaultWfStepRetryMaxAttempts, p.maxAttempts)
+	assert.Equal(t, config.DefaultWfStepRetryBackoff, p.backoff)
+	assert.Equal(t, config.DefaultWfStepRetryInitialDelay, p.initialDelay)
+	assert.Equal(t, config.DefaultWfStepRetryMaxDelay, p.maxDelay)
+	assert.Equal(t, []string{retryOnAll}, p.retryOn)
+	assert.NoError(t, p.validate())
+}
+
+func TestRetryPolicyMerge(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		rp      *tosca.RetryPolicy
+		want    retryPolicy
+		wantErr bool
+	}{
+		{"NilPolicy", nil, retryPolicy{3, "fixed", time.Second, time.Minute, []string{"all"}}, false},
+		{"MaxAttemptsOnly", &tosca.RetryPolicy{MaxAttempts: 5}, retryPolicy{5, "fixed", time.Second, time.Minute, []string{"all"}}, false},
+		{"AllFields", &tosca.RetryPolicy{MaxAttempts: 2, Backoff: "Exponential", InitialDelay: "5s", MaxDelay: "30s", RetryOn: []string{"Timeout", " delegate"}},
+			retryPolicy{2, "exponential", 5 * time.Second, 30 * time.Second, []string{"timeout", "delegate"}}, false},
+		{"WrongBackoff", &tosca.RetryPolicy{Backoff: "linear"}, retryPolicy{}, true},
+		{"WrongDelay", &tosca.RetryPolicy{InitialDelay: "5 seconds"}, retryPolicy{}, true},
+		{"WrongErrorClass", &tosca.RetryPolicy{RetryOn: []string{"network"}}, retryPolicy{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := retryPolicy{3, "fixed", time.Second, time.Minute, []string{"all"}}
+			err := p.merge(tt.rp)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, p)
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	t.Parallel()
+	fixed := retryPolicy{backoff: retryBackoffFixed, initialDelay: 10 * time.Second, maxDelay: time.Minute}
+	assert.Equal(t, 10*time.Second, fixed.delay(1))
+	assert.Equal(t, 10*time.Second, fixed.delay(5))
+
+	exp := retryPolicy{backoff: retryBackoffExponential, initialDelay: 10 * time.Second, maxDelay: time.Minute}
+	assert.Equal(t, 10*time.Second, exp.delay(1))
+	assert.Equal(t, 20*time.Second, exp.delay(2))
+	assert.Equal(t, 40*time.Second, exp.delay(3))
+	assert.Equal(t, time.Minute, exp.delay(4))
+	assert.Equal(t, time.Minute, exp.delay(100))
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	t.Parallel()
+	delegate := mockActivity{builder.ActivityTypeDelegate}
+	callOp := mockActivity{builder.ActivityTypeCallOperation}
+	setState := mockActivity{builder.ActivityTypeSetState}
+	genericErr := errors.New("failure")
+	tests := []struct {
+		name     string
+		retryOn  []string
+		activity builder.Activity
+		err      error
+		want     bool
+	}{
+		{"NoError", []string{"all"}, delegate, nil, false},
+		{"AllDelegate", []string{"all"}, delegate, genericErr, true},
+		{"AnyCallOp", []string{"any"}, callOp, genericErr, true},
+		{"SetStateNeverRetried", []string{"all"}, setState, genericErr, false},
+		{"DelegateOnly", []string{"delegate"}, callOp, genericErr, false},
+		{"OperationOnly", []string{"operation"}, callOp, genericErr, true},
+		{"TimeoutGenericError", []string{"timeout"}, delegate, genericErr, false},
+		{"TimeoutDeadlineExceeded", []string{"timeout"}, delegate, errors.Wrap(context.DeadlineExceeded, "op failed"), true},
+		{"TimeoutErrorInterface", []string{"timeout"}, callOp, errors.WithStack(timeoutError{}), true},
+		{"SeveralClasses", []string{"timeout", "delegate"}, delegate, genericErr, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := retryPolicy{retryOn: tt.retryOn}
+			assert.Equal(t, tt.want, p.shouldRetry(tt.activity, tt.err))
+		})
+	}
+}
+
+type failingDelegateExecutor struct {
+	failures int
+	calls    int
+}
+
+func (m *failingDelegateExecutor) ExecDelegate(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName, delegateOperation string) error {
+	m.calls++
+	if m.calls <= m.failures {
+		return errors.New("Failed required for mock")
+	}
+	return nil
+}
+
+func testRunStepWithRetries(t *testing.T, srv1 *testutil.TestServer, cc *api.Client) {
+	kv := cc.KV()
+	deploymentID := strings.Replace(t.Name(), "/", "_", -1)
+	err := deployments.StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/workflow.yaml")
+	require.Nil(t, err)
+
+	executor := &failingDelegateExecutor{}
+	registry.GetRegistry().RegisterDelegates([]string{"ystia.yorc.tests.nodes.WFCompute"}, executor, "tests")
+
+	tests := []struct {
+		name      string
+		failures  int
+		cfgPolicy config.RetryPolicy
+		retry     *tosca.RetryPolicy
+		wantCalls int
+		wantErr   bool
+	}{
+		{"NoRetryByDefault", 1, config.RetryPolicy{}, nil, 1, true},
+		{"GlobalPolicy", 2, config.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}, nil, 3, false},
+		{"StepPolicyOverridesGlobal", 3, config.RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond}, &tosca.RetryPolicy{MaxAttempts: 4}, 4, false},
+		{"AttemptsExhausted", 5, config.RetryPolicy{InitialDelay: time.Millisecond}, &tosca.RetryPolicy{MaxAttempts: 3, Backoff: "exponential"}, 3, true},
+		{"ErrorClassNotMatching", 2, config.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, RetryOn: []string{"operation"}}, nil, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearActivityHooks()
+			executor.calls = 0
+			executor.failures = tt.failures
+
+			wfSteps, err := builder.BuildWorkFlow(kv, deploymentID, "install")
+			require.Nil(t, err)
+			bs := wfSteps["Compute_install"]
+			require.NotNil(t, bs)
+			bs.Next = nil
+			bs.Retry = tt.retry
+
+			te := &taskExecution{id: "taskExecutionID", taskID: "taskID", targetID: deploymentID}
+			s := wrapBuilderStep(bs, cc, te)
+			srv1.SetKV(t, path.Join(consulutil.WorkflowsPrefix, s.t.taskID, "Compute_install"), []byte("initial"))
+			err = s.run(context.Background(), config.Configuration{WfStepRetry: tt.cfgPolicy}, kv, deploymentID, false, "install", &worker{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("step.run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantCalls, executor.calls)
+			status, err := tasks.GetTaskStepStatus(kv, s.t.taskID, "Compute_install")
+			require.NoError(t, err)
+			if tt.wantErr {
+				assert.Equal(t, tasks.TaskStepStatusERROR, status)
+			} else {
+				assert.Equal(t, tasks.TaskStepStatusDONE, status)
+			}
+		})
+	}
+}
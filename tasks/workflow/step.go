@@ -42,8 +42,10 @@ import (
 // step represents the workflow step
 type step struct {
 	*builder.Step
-	cc *api.Client
-	t  *taskExecution
+	cc          *api.Client
+	t           *taskExecution
+	retryPolicy *retryPolicy
+	attempt     int
 }
 
 func wrapBuilderStep(s *builder.Step, cc *api.Client, t *taskExecution) *step {
@@ -95,7 +97,7 @@ func isSourceOperationOnTarget(s *step) bool {
 // isRunnable Checks if a Step should be run or bypassed
 //
 // It first checks if the Step is not already done in this workflow instance
-// And for ScaleOut and ScaleDown it checks if the node or the target node in case of an operation running on the target node is part of the operation
+// And for ScaleOut, ScaleDown and custom workflows restricted to some nodes it checks if the node or the target node in case of an operation running on the target node is part of the operation
 func (s *step) isRunnable() (bool, error) {
 	kv := s.cc.KV()
 	kvp, _, err := kv.Get(path.Join(consulutil.WorkflowsPrefix, s.t.taskID, s.Name), nil)
@@ -122,7 +124,16 @@ func (s *step) isRunnable() (bool, error) {
 		}
 	}
 
-	if s.t.taskType == tasks.TaskTypeScaleOut || s.t.taskType == tasks.TaskTypeScaleIn {
+	isRestricted := s.t.taskType == tasks.TaskTypeScaleOut || s.t.taskType == tasks.TaskTypeScaleIn
+	if s.t.taskType == tasks.TaskTypeCustomWorkflow {
+		// Custom workflows may be restricted to some nodes instances (eg. automatic healing)
+		nodes, err := tasks.GetTaskRelatedNodes(kv, s.t.taskID)
+		if err != nil {
+			return false, err
+		}
+		isRestricted = len(nodes) > 0
+	}
+	if isRestricted {
 		// If not a relationship check the actual node
 		if s.TargetRelationship == "" {
 			return tasks.IsTaskRelatedNode(kv, s.t.taskID, s.Target)
@@ -207,15 +218,24 @@ func (s *step) run(ctx context.Context, cfg config.Configuration, kv *api.KV, de
 					hook(ctx, cfg, s.t.taskID, deploymentID, s.Target, activity)
 				}
 			}()
-			err := s.runActivity(ctx, kv, cfg, deploymentID, workflowName, bypassErrors, w, activity)
+			err := s.runActivityWithRetries(ctx, kv, cfg, deploymentID, workflowName, bypassErrors, w, activity)
 			if err != nil {
 				setNodeStatus(ctx, kv, s.t.taskID, deploymentID, s.Target, tosca.NodeStateError.String())
 				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, deploymentID).Registerf("TaskStep %q: error details: %+v", s.Name, err)
 				// Set step in error but continue if needed
 				s.setStatus(tasks.TaskStepStatusERROR)
+				var reason string
+				if isTimeoutError(err) {
+					reason = tasks.TaskStepStatusReasonTimeout
+					events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, deploymentID).Registerf("TaskStep %q: %v", s.Name, err)
+				}
+				err2 := tasks.SetTaskStepStatusReason(kv, s.t.taskID, s.Name, reason)
+				if err2 != nil {
+					log.Printf("Deployment id: %q, Task id: %q, Failed to set status reason for step %q: %+v", deploymentID, s.t.taskID, s.Name, err2)
+				}
 				if !bypassErrors {
 					tasks.NotifyErrorOnTask(s.t.taskID)
-					err2 := s.registerOnCancelOrFailureSteps(ctx, workflowName, s.OnFailure)
+					err2 = s.registerOnCancelOrFailureSteps(ctx, workflowName, s.OnFailure)
 					if err2 != nil {
 						events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, deploymentID).Registerf("failed to register on failure steps: %v", err2)
 					}
@@ -236,6 +256,40 @@ func (s *step) run(ctx context.Context, cfg config.Configuration, kv *api.KV, de
 	return nil
 }
 
+// runActivityWithRetries runs an activity and retries it according to the step retry policy
+//
+// Only delegate and call-operation activities may be retried.
+func (s *step) runActivityWithRetries(ctx context.Context, kv *api.KV, cfg config.Configuration, deploymentID, workflowName string, bypassErrors bool, w *worker, activity builder.Activity) error {
+	if activity.Type() != builder.ActivityTypeDelegate && activity.Type() != builder.ActivityTypeCallOperation {
+		return s.runActivity(ctx, kv, cfg, deploymentID, workflowName, bypassErrors, w, activity)
+	}
+	if s.retryPolicy == nil {
+		policy, err := resolveRetryPolicy(kv, cfg, deploymentID, s.Target, s.Retry)
+		if err != nil {
+			return err
+		}
+		s.retryPolicy = &policy
+	}
+	policy := s.retryPolicy
+	for attempt := 1; ; attempt++ {
+		if policy.maxAttempts > 1 {
+			s.attempt = attempt
+			ctx = events.AddLogOptionalFields(ctx, events.LogOptionalFields{events.Attempt: attempt})
+		}
+		err := s.runActivity(ctx, kv, cfg, deploymentID, workflowName, bypassErrors, w, activity)
+		if err == nil || attempt >= policy.maxAttempts || ctx.Err() != nil || !policy.shouldRetry(activity, err) {
+			return err
+		}
+		delay := policy.delay(attempt)
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, deploymentID).Registerf("TaskStep %q: attempt %d/%d failed: %v, retrying in %s", s.Name, attempt, policy.maxAttempts, err, delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
 func (s *step) runActivity(wfCtx context.Context, kv *api.KV, cfg config.Configuration, deploymentID, workflowName string, bypassErrors bool, w *worker, activity builder.Activity) error {
 	// Get activity related instances
 	instances, err := tasks.GetInstances(kv, s.t.taskID, deploymentID, s.Target)
@@ -243,7 +297,7 @@ func (s *step) runActivity(wfCtx context.Context, kv *api.KV, cfg config.Configu
 		return err
 	}
 
-	eventInfo := &events.WorkflowStepInfo{WorkflowName: workflowName, NodeName: s.Target, StepName: s.Name}
+	eventInfo := &events.WorkflowStepInfo{WorkflowName: workflowName, NodeName: s.Target, StepName: s.Name, Attempt: s.attempt}
 	switch activity.Type() {
 	case builder.ActivityTypeDelegate:
 		nodeType, err := deployments.GetNodeType(kv, deploymentID, s.Target)
@@ -263,7 +317,11 @@ func (s *step) runActivity(wfCtx context.Context, kv *api.KV, cfg config.Configu
 		}
 		err = func() error {
 			defer metrics.MeasureSince(metricsutil.CleanupMetricKey([]string{"executor", "delegate", deploymentID, nodeType, delegateOp}), time.Now())
-			return provisioner.ExecDelegate(wfCtx, cfg, s.t.taskID, deploymentID, s.Target, delegateOp)
+			timeout := cfg.WfStepTimeouts.Delegate
+			execCtx, cancel := withActivityTimeout(wfCtx, timeout)
+			defer cancel()
+			err := provisioner.ExecDelegate(execCtx, cfg, s.t.taskID, deploymentID, s.Target, delegateOp)
+			return checkActivityTimeout(wfCtx, execCtx, "delegate."+delegateOp, timeout, err)
 		}()
 
 		if err != nil {
@@ -340,7 +398,18 @@ func (s *step) runActivity(wfCtx context.Context, kv *api.KV, cfg config.Configu
 		} else {
 			err = func() error {
 				defer metrics.MeasureSince(metricsutil.CleanupMetricKey([]string{"executor", "operation", deploymentID, nodeType, op.Name}), time.Now())
-				return exec.ExecOperation(wfCtx, cfg, s.t.taskID, deploymentID, s.Target, op)
+				timeout := cfg.WfStepTimeouts.CallOperation
+				implTimeout, err := deployments.GetOperationImplementationTimeout(kv, deploymentID, op.ImplementedInNodeTemplate, op.ImplementedInType, op.Name)
+				if err != nil {
+					return err
+				}
+				if implTimeout > 0 {
+					timeout = implTimeout
+				}
+				execCtx, cancel := withActivityTimeout(wfCtx, timeout)
+				defer cancel()
+				err = exec.ExecOperation(execCtx, cfg, s.t.taskID, deploymentID, s.Target, op)
+				return checkActivityTimeout(wfCtx, execCtx, op.Name, timeout, err)
 			}()
 		}
 		if err != nil {
@@ -40,6 +40,8 @@ type taskExecution struct {
 	step         string
 	// finalFunction is function a function called at the end of the taskExecution if no other taskExecution are running
 	finalFunction func() error
+	// releaseSlot frees the dispatcher concurrency slot used by the taskExecution
+	releaseSlot func()
 }
 
 func (t *taskExecution) releaseLock() {
@@ -0,0 +1,59 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// activityTimeoutError is returned when a workflow step activity exceeds its timeout
+type activityTimeoutError struct {
+	activity string
+	timeout  time.Duration
+	cause    error
+}
+
+func (e activityTimeoutError) Error() string {
+	return fmt.Sprintf("activity %q timed out after %s: %v", e.activity, e.timeout, e.cause)
+}
+
+// Timeout allows to identify timeout errors
+func (e activityTimeoutError) Timeout() bool {
+	return true
+}
+
+// withActivityTimeout returns a context with a deadline set to the given timeout, a zero timeout means no deadline
+func withActivityTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// checkActivityTimeout turns an error returned by an activity into a timeout error if the activity
+// context deadline was exceeded while its parent context is still alive (ie. not canceled)
+func checkActivityTimeout(parentCtx, activityCtx context.Context, activity string, timeout time.Duration, err error) error {
+	if err == nil || timeout <= 0 {
+		return err
+	}
+	if activityCtx.Err() == context.DeadlineExceeded && parentCtx.Err() == nil {
+		return errors.WithStack(activityTimeoutError{activity: activity, timeout: timeout, cause: err})
+	}
+	return err
+}
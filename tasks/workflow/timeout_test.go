@@ -0,0 +1,103 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/deployments"
+	"github.com/ystia/yorc/helper/consulutil"
+	"github.com/ystia/yorc/registry"
+	"github.com/ystia/yorc/tasks"
+	"github.com/ystia/yorc/tasks/workflow/builder"
+)
+
+func TestCheckActivityTimeout(t *testing.T) {
+	t.Parallel()
+	genericErr := errors.New("failure")
+
+	// No timeout configured
+	ctx, cancel := withActivityTimeout(context.Background(), 0)
+	defer cancel()
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline)
+	assert.Equal(t, genericErr, checkActivityTimeout(context.Background(), ctx, "op", 0, genericErr))
+
+	// Deadline exceeded
+	ctx, cancel = withActivityTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	err := checkActivityTimeout(context.Background(), ctx, "standard.create", time.Millisecond, genericErr)
+	require.Error(t, err)
+	assert.True(t, isTimeoutError(err))
+	assert.Contains(t, err.Error(), "standard.create")
+	assert.Nil(t, checkActivityTimeout(context.Background(), ctx, "standard.create", time.Millisecond, nil))
+
+	// Parent context canceled is not a timeout
+	parentCtx, parentCancel := context.WithCancel(context.Background())
+	ctx, cancel = withActivityTimeout(parentCtx, time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	parentCancel()
+	assert.Equal(t, genericErr, checkActivityTimeout(parentCtx, ctx, "op", time.Millisecond, genericErr))
+}
+
+type blockingDelegateExecutor struct{}
+
+func (m *blockingDelegateExecutor) ExecDelegate(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName, delegateOperation string) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func testRunStepWithTimeout(t *testing.T, srv1 *testutil.TestServer, cc *api.Client) {
+	kv := cc.KV()
+	deploymentID := strings.Replace(t.Name(), "/", "_", -1)
+	err := deployments.StoreDeploymentDefinition(context.Background(), kv, deploymentID, "testdata/workflow.yaml")
+	require.Nil(t, err)
+
+	registry.GetRegistry().RegisterDelegates([]string{"ystia.yorc.tests.nodes.WFCompute"}, &blockingDelegateExecutor{}, "tests")
+	clearActivityHooks()
+
+	wfSteps, err := builder.BuildWorkFlow(kv, deploymentID, "install")
+	require.Nil(t, err)
+	bs := wfSteps["Compute_install"]
+	require.NotNil(t, bs)
+	bs.Next = nil
+
+	te := &taskExecution{id: "taskExecutionID", taskID: "taskWithTimeout", targetID: deploymentID}
+	s := wrapBuilderStep(bs, cc, te)
+	srv1.SetKV(t, path.Join(consulutil.WorkflowsPrefix, s.t.taskID, "Compute_install"), []byte("initial"))
+	cfg := config.Configuration{WfStepTimeouts: config.WfStepTimeouts{Delegate: 50 * time.Millisecond}}
+	err = s.run(context.Background(), cfg, kv, deploymentID, false, "install", &worker{})
+	require.Error(t, err)
+	assert.True(t, isTimeoutError(err))
+
+	steps, err := tasks.GetTaskRelatedSteps(kv, s.t.taskID)
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	assert.Equal(t, tasks.TaskStepStatusERROR.String(), steps[0].Status)
+	assert.Equal(t, tasks.TaskStepStatusReasonTimeout, steps[0].StatusReason)
+}
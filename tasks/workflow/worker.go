@@ -162,6 +162,10 @@ func (w *worker) handleExecution(t *taskExecution) {
 	err := t.notifyStart()
 	if err != nil {
 		log.Printf("%+v", err)
+		// Free the concurrency slot as the deferred release below is not installed yet
+		if t.releaseSlot != nil {
+			t.releaseSlot()
+		}
 		return
 	}
 	defer func() {
@@ -170,6 +174,10 @@ func (w *worker) handleExecution(t *taskExecution) {
 		if err != nil {
 			log.Printf("%+v", err)
 		}
+		// Free the concurrency slot before deleting the execution as it triggers the dispatch of postponed executions
+		if t.releaseSlot != nil {
+			t.releaseSlot()
+		}
 		t.delete()
 		if err != nil {
 			log.Printf("%+v", err)
@@ -216,6 +224,8 @@ func (w *worker) handleExecution(t *taskExecution) {
 		err = w.runScaleIn(ctx, t)
 	case tasks.TaskTypeCustomWorkflow:
 		err = w.runCustomWorkflow(ctx, t, wfName)
+	case tasks.TaskTypeUpdate:
+		err = w.runUpdate(ctx, t)
 
 	case tasks.TaskTypeAction:
 		err = w.runAction(ctx, t)
@@ -630,11 +640,27 @@ func (w *worker) runPurge(ctx context.Context, t *taskExecution) error {
 	if err != nil {
 		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
+	// Delete workflows schedules of the deployment
+	err = scheduling.DeleteDeploymentSchedules(kv, t.targetID)
+	if err != nil {
+		return err
+	}
 	overlayPath := filepath.Join(w.cfg.WorkingDirectory, "deployments", t.targetID)
 	err = os.RemoveAll(overlayPath)
 	if err != nil {
 		return errors.Wrapf(err, "failed to remove deployments artifacts stored on disk: %q", overlayPath)
 	}
+	events.UnregisterDeploymentSecrets(t.targetID)
+	// Remove a potential pending update
+	err = deployments.DeletePendingUpdate(kv, t.targetID)
+	if err != nil {
+		return err
+	}
+	updatePath := filepath.Join(w.cfg.WorkingDirectory, "deployments", deployments.UpdateStagingID(t.targetID))
+	err = os.RemoveAll(updatePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove deployment update artifacts stored on disk: %q", updatePath)
+	}
 	// Now cleanup: mark it as done so nobody will try to run it, clear the processing lock and finally delete the TaskExecution.
 	checkAndSetTaskStatus(t.cc.KV(), t.taskID, tasks.TaskStatusDONE)
 	err = tasks.DeleteTask(kv, t.taskID)
@@ -671,6 +697,39 @@ func (w *worker) runScaleIn(ctx context.Context, t *taskExecution) error {
 	return w.runWorkflowStep(ctx, t, "uninstall", true)
 }
 
+func (w *worker) runUpdate(ctx context.Context, t *taskExecution) error {
+	err := deployments.SetDeploymentStatus(ctx, w.consulClient.KV(), t.targetID, deployments.UPDATE_IN_PROGRESS)
+	if err != nil {
+		return err
+	}
+	t.finalFunction = func() error {
+		kv := t.cc.KV()
+		taskStatus, err := updateTaskStatusAccordingToWorkflowStatus(ctx, kv, t.targetID, t.taskID, deployments.UpdateWorkflowName)
+		if err != nil {
+			return err
+		}
+		if taskStatus != tasks.TaskStatusDONE {
+			return deployments.SetDeploymentStatus(ctx, kv, t.targetID, deployments.UPDATE_FAILED)
+		}
+		// Removed nodes definitions are not needed anymore
+		removedNodes, err := tasks.GetTaskData(kv, t.taskID, "removedNodes")
+		if err != nil && !tasks.IsTaskDataNotFoundError(err) {
+			return err
+		}
+		err = deployments.CleanupAppliedUpdate(kv, t.targetID, deployments.ParseRemovedNodes(removedNodes))
+		if err != nil {
+			return err
+		}
+		overlayPath := filepath.Join(w.cfg.WorkingDirectory, "deployments", deployments.UpdateStagingID(t.targetID))
+		err = os.RemoveAll(overlayPath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to remove deployment update artifacts stored on disk: %q", overlayPath)
+		}
+		return deployments.SetDeploymentStatus(ctx, kv, t.targetID, deployments.DEPLOYED)
+	}
+	return w.runWorkflowStep(ctx, t, deployments.UpdateWorkflowName, false)
+}
+
 func (w *worker) runCustomWorkflow(ctx context.Context, t *taskExecution, wfName string) error {
 	kv := w.consulClient.KV()
 	if wfName == "" {
@@ -55,3 +55,46 @@ topology_template:
 	require.Equal(t, false, *input.Required)
 	require.Equal(t, `http://10.197.132.16/sla`, input.Value.GetLiteral())
 }
+
+func TestTopologyTemplate_Policies(t *testing.T) {
+	data := `
+name: topo test
+policy_types:
+  my.policies.Placement:
+    derived_from: tosca.policies.Placement
+    targets: [ tosca.nodes.Compute ]
+    properties:
+      affinity:
+        type: string
+topology_template:
+  policies:
+    - anti_affinity:
+        type: my.policies.Placement
+        description: Spread computes
+        properties:
+          affinity: anti-colocate
+        targets: [ Compute1, Compute2 ]
+    - scaling:
+        type: tosca.policies.Scaling
+        targets: [ Compute1 ]
+`
+	topo := Topology{}
+
+	err := yaml.Unmarshal([]byte(data), &topo)
+	require.Nil(t, err)
+	require.Contains(t, topo.PolicyTypes, "my.policies.Placement")
+	policyType := topo.PolicyTypes["my.policies.Placement"]
+	require.Equal(t, "tosca.policies.Placement", policyType.DerivedFrom)
+	require.Equal(t, []string{"tosca.nodes.Compute"}, policyType.Targets)
+	require.Contains(t, policyType.Properties, "affinity")
+
+	require.Len(t, topo.TopologyTemplate.Policies, 2)
+	require.Contains(t, topo.TopologyTemplate.Policies[0], "anti_affinity")
+	policy := topo.TopologyTemplate.Policies[0]["anti_affinity"]
+	require.Equal(t, "my.policies.Placement", policy.Type)
+	require.Equal(t, "Spread computes", policy.Description)
+	require.Equal(t, []string{"Compute1", "Compute2"}, policy.Targets)
+	require.Equal(t, "anti-colocate", policy.Properties["affinity"].GetLiteral())
+	require.Contains(t, topo.TopologyTemplate.Policies[1], "scaling")
+	require.Equal(t, []string{"Compute1"}, topo.TopologyTemplate.Policies[1]["scaling"].Targets)
+}
@@ -40,6 +40,10 @@ func TestFunctionParsing(t *testing.T) {
 		{"TestConcatFunction", inputs{yml: "concat: [get_property: [SELF, ip_address], get_attribute: [SELF, port]]"}, false},
 		{"TestGetInputFunction", inputs{yml: "get_input: ip_address"}, false},
 		{"TestConcatFunctionQuoting", inputs{yml: `concat: ["http://", get_property: [SELF, ip_address], get_attribute: [SELF, port], "\"ff\""]`}, false},
+		{"TestTokenFunction", inputs{yml: `token: [get_attribute: [SELF, url], ":", 1]`}, false},
+		{"TestGetNodesOfTypeFunction", inputs{yml: "get_nodes_of_type: tosca.nodes.Compute"}, false},
+		{"TestGetArtifactFunction", inputs{yml: "get_artifact: [SELF, my_artifact]"}, false},
+		{"TestGetArtifactFunctionWithLocation", inputs{yml: "get_artifact: [SELF, my_artifact, /tmp/my_artifact.tgz, true]"}, false},
 	}
 
 	for _, tt := range tests {
@@ -89,6 +93,7 @@ func TestFunction_GetFunctionsByOperator(t *testing.T) {
 		{"1stLevel", generateFunctionFromYaml(t, `{get_property: [SELF, port]}`), args{GetPropertyOperator}, []*Function{generateFunctionFromYaml(t, `{get_property: [SELF, port]}`)}},
 		{"nestedLevel", generateFunctionFromYaml(t, `{concat: [get_property: [SELF, port]]}`), args{GetPropertyOperator}, []*Function{generateFunctionFromYaml(t, `{get_property: [SELF, port]}`)}},
 		{"severalNestedLevel", generateFunctionFromYaml(t, `{concat: [get_property: [SELF, port], concat: [get_input: "i", get_property: [SELF, test]]]}`), args{GetPropertyOperator}, []*Function{generateFunctionFromYaml(t, `{get_property: [SELF, port]}`), generateFunctionFromYaml(t, `{get_property: [SELF, test]}`)}},
+		{"nestedGetArtifact", generateFunctionFromYaml(t, `{concat: ["file://", get_artifact: [SELF, my_art]]}`), args{GetArtifactOperator}, []*Function{generateFunctionFromYaml(t, `{get_artifact: [SELF, my_art]}`)}},
 		{"notFound", generateFunctionFromYaml(t, `{concat: [get_property: [SELF, port], concat: [get_input: "i", get_property: [SELF, test]]]}`), args{GetAttributeOperator}, []*Function{}},
 	}
 	for _, tt := range tests {
@@ -98,6 +98,17 @@ type ArtifactType struct {
 	Properties map[string]PropertyDefinition `yaml:"properties,omitempty"`
 }
 
+// An PolicyType is the representation of a TOSCA Policy Type
+//
+// See http://docs.oasis-open.org/tosca/TOSCA-Simple-Profile-YAML/v1.2/TOSCA-Simple-Profile-YAML-v1.2.html#DEFN_ENTITY_POLICY_TYPE
+// for more details
+type PolicyType struct {
+	Type       `yaml:",inline"`
+	Properties map[string]PropertyDefinition `yaml:"properties,omitempty"`
+	Targets    []string                      `yaml:"targets,omitempty,flow"`
+	// Triggers not enforced in Yorc so we don't parse them
+}
+
 // An DataType is the representation of a TOSCA Data Type
 //
 // See http://docs.oasis-open.org/tosca/TOSCA-Simple-Profile-YAML/v1.2/TOSCA-Simple-Profile-YAML-v1.2.html#DEFN_ENTITY_DATA_TYPE
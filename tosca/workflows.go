@@ -34,7 +34,19 @@ type Step struct {
 	OperationHost      string     `yaml:"operation_host,omitempty" json:"operation_host,omitempty"`
 
 	// Non standard
-	OnCancel []string `yaml:"on_cancel,omitempty" json:"on_cancel,omitempty"`
+	OnCancel []string     `yaml:"on_cancel,omitempty" json:"on_cancel,omitempty"`
+	Retry    *RetryPolicy `yaml:"retry,omitempty" json:"retry,omitempty"`
+}
+
+// A RetryPolicy defines how a failing workflow step activity should be retried before the step is considered in error
+//
+// This is a non standard extension. Durations are expressed using the Go duration format (ie: 10s, 1m30s).
+type RetryPolicy struct {
+	MaxAttempts  int      `yaml:"max_attempts,omitempty" json:"max_attempts,omitempty"`
+	Backoff      string   `yaml:"backoff,omitempty" json:"backoff,omitempty"`
+	InitialDelay string   `yaml:"initial_delay,omitempty" json:"initial_delay,omitempty"`
+	MaxDelay     string   `yaml:"max_delay,omitempty" json:"max_delay,omitempty"`
+	RetryOn      []string `yaml:"retry_on,omitempty" json:"retry_on,omitempty"`
 }
 
 // An Activity is the representation of a TOSCA Workflow Step Activity
@@ -0,0 +1,114 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package composite
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/registry"
+	"github.com/ystia/yorc/vault"
+)
+
+// ID is the vault type of the composite client
+const ID = "composite"
+
+type clientBuilder struct {
+}
+
+// BuildClient builds a client for each configured backend.
+//
+// Backends are defined in the "backends" list option, each backend being a map having the same options than
+// a standalone vault configuration plus a "prefix" option and a "strip_prefix" option.
+func (b *clientBuilder) BuildClient(cfg config.Configuration) (vault.Client, error) {
+	log.Debug("Setting up composite secrets store")
+	backendsConf, err := cast.ToSliceE(cfg.Vault.Get("backends"))
+	if err != nil || len(backendsConf) == 0 {
+		return nil, errors.New("the \"backends\" option of the composite vault should be a non-empty list")
+	}
+	cc := &compositeClient{backends: make([]backend, 0, len(backendsConf))}
+	for i, bc := range backendsConf {
+		bConf, err := cast.ToStringMapE(bc)
+		if err != nil {
+			cc.Shutdown()
+			return nil, errors.Errorf("backend #%d of the composite vault should be a map", i)
+		}
+		backendCfg := cfg
+		backendCfg.Vault = config.DynamicMap(bConf)
+		backendType := backendCfg.Vault.GetString("type")
+		if backendType == "" || backendType == ID {
+			cc.Shutdown()
+			return nil, errors.Errorf("backend #%d of the composite vault has an invalid type %q", i, backendType)
+		}
+		builder, err := registry.GetRegistry().GetVaultClientBuilder(backendType)
+		if err != nil {
+			cc.Shutdown()
+			return nil, errors.Wrapf(err, "backend #%d of the composite vault", i)
+		}
+		client, err := builder.BuildClient(backendCfg)
+		if err != nil {
+			cc.Shutdown()
+			return nil, errors.Wrapf(err, "failed to build backend #%d of the composite vault", i)
+		}
+		cc.backends = append(cc.backends, backend{
+			prefix:      backendCfg.Vault.GetString("prefix"),
+			stripPrefix: backendCfg.Vault.GetBool("strip_prefix"),
+			client:      client,
+		})
+	}
+	return cc, nil
+}
+
+type backend struct {
+	prefix      string
+	stripPrefix bool
+	client      vault.Client
+}
+
+type compositeClient struct {
+	backends []backend
+}
+
+// GetSecret delegates the retrieval of the secret to the first backend which prefix matches the secret id.
+//
+// A backend without prefix matches any secret id. Options are given as is to the selected backend.
+func (cc *compositeClient) GetSecret(id string, options ...string) (vault.Secret, error) {
+	for _, b := range cc.backends {
+		if !strings.HasPrefix(id, b.prefix) {
+			continue
+		}
+		backendID := id
+		if b.stripPrefix {
+			backendID = strings.TrimPrefix(id, b.prefix)
+		}
+		return b.client.GetSecret(backendID, options...)
+	}
+	return nil, errors.Errorf("no secrets backend matches secret %q", id)
+}
+
+func (cc *compositeClient) Shutdown() error {
+	var err error
+	for _, b := range cc.backends {
+		if e := b.client.Shutdown(); e != nil {
+			log.Printf("failed to shutdown secrets backend with prefix %q: %v", b.prefix, e)
+			err = e
+		}
+	}
+	return err
+}
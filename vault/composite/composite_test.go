@@ -0,0 +1,139 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package composite
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/registry"
+	"github.com/ystia/yorc/vault"
+)
+
+type mockBuilder struct {
+}
+
+func (b *mockBuilder) BuildClient(cfg config.Configuration) (vault.Client, error) {
+	if cfg.Vault.GetBool("fail") {
+		return nil, errors.New("failed")
+	}
+	return &mockClient{name: cfg.Vault.GetString("name")}, nil
+}
+
+type mockClient struct {
+	name     string
+	shutdown bool
+}
+
+func (c *mockClient) GetSecret(id string, options ...string) (vault.Secret, error) {
+	return &mockSecret{value: c.name + ":" + id + ":" + vault.ParseOptions(options...)["data"]}, nil
+}
+
+func (c *mockClient) Shutdown() error {
+	c.shutdown = true
+	return nil
+}
+
+type mockSecret struct {
+	value string
+}
+
+func (s *mockSecret) String() string {
+	return s.value
+}
+
+func (s *mockSecret) Raw() interface{} {
+	return s.value
+}
+
+func TestCompositeGetSecret(t *testing.T) {
+	registry.GetRegistry().RegisterVaultClientBuilder("composite-test-mock", &mockBuilder{}, "test")
+
+	cfg := config.Configuration{Vault: config.DynamicMap{
+		"type": ID,
+		"backends": []interface{}{
+			map[string]interface{}{"type": "composite-test-mock", "name": "k8s", "prefix": "k8s:", "strip_prefix": true},
+			map[interface{}]interface{}{"type": "composite-test-mock", "name": "vault", "prefix": "/secrets/"},
+			map[string]interface{}{"type": "composite-test-mock", "name": "default"},
+		},
+	}}
+	client, err := (&clientBuilder{}).BuildClient(cfg)
+	require.NoError(t, err)
+
+	tests := []struct {
+		id      string
+		options []string
+		want    string
+	}{
+		{"k8s:db-creds", []string{"data=password"}, "k8s:db-creds:password"},
+		{"/secrets/app/token", nil, "vault:/secrets/app/token:"},
+		{"other", nil, "default:other:"},
+	}
+	for _, tt := range tests {
+		s, err := client.GetSecret(tt.id, tt.options...)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, s.String())
+	}
+
+	require.NoError(t, client.Shutdown())
+	for _, b := range client.(*compositeClient).backends {
+		require.True(t, b.client.(*mockClient).shutdown)
+	}
+}
+
+func TestCompositeGetSecretNoMatchingBackend(t *testing.T) {
+	registry.GetRegistry().RegisterVaultClientBuilder("composite-test-mock", &mockBuilder{}, "test")
+
+	cfg := config.Configuration{Vault: config.DynamicMap{
+		"type": ID,
+		"backends": []interface{}{
+			map[string]interface{}{"type": "composite-test-mock", "prefix": "/secrets/"},
+		},
+	}}
+	client, err := (&clientBuilder{}).BuildClient(cfg)
+	require.NoError(t, err)
+	_, err = client.GetSecret("/other/secret")
+	require.Error(t, err)
+}
+
+func TestCompositeBuildClientErrors(t *testing.T) {
+	registry.GetRegistry().RegisterVaultClientBuilder("composite-test-mock", &mockBuilder{}, "test")
+
+	tests := []struct {
+		name     string
+		backends interface{}
+	}{
+		{"NoBackends", nil},
+		{"NotAList", "backend"},
+		{"NotAMap", []interface{}{"backend"}},
+		{"MissingType", []interface{}{map[string]interface{}{"prefix": "/secrets/"}}},
+		{"NestedComposite", []interface{}{map[string]interface{}{"type": ID}}},
+		{"UnknownType", []interface{}{map[string]interface{}{"type": "composite-test-unknown"}}},
+		{"BuildFailure", []interface{}{
+			map[string]interface{}{"type": "composite-test-mock"},
+			map[string]interface{}{"type": "composite-test-mock", "fail": true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Configuration{Vault: config.DynamicMap{"type": ID, "backends": tt.backends}}
+			_, err := (&clientBuilder{}).BuildClient(cfg)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/vault"
+)
+
+type clientBuilder struct {
+}
+
+func (b *clientBuilder) BuildClient(cfg config.Configuration) (vault.Client, error) {
+	log.Debug("Setting up environment variables secrets store")
+	prefix := cfg.Vault.GetString("variable_prefix")
+	if prefix == "" {
+		// Without prefix any variable of the Yorc server, including its own credentials, could be read
+		return nil, errors.New("the \"variable_prefix\" option is required for the environment variables secrets store")
+	}
+	return &envClient{prefix: prefix}, nil
+}
+
+type envClient struct {
+	prefix string
+}
+
+// VariableName returns the name of the environment variable holding the secret with the given id
+//
+// Leading slashes of the id are ignored, letters are upper-cased and any character other than
+// a letter, a digit or an underscore is replaced by an underscore. The result is prefixed by the
+// given prefix.
+func VariableName(prefix, id string) string {
+	id = strings.TrimLeft(id, "/")
+	return prefix + strings.Map(func(r rune) rune {
+		if r == '_' || (r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, id)
+}
+
+// GetSecret returns the value of the environment variable corresponding to the given secret id.
+//
+// Supported options are:
+//   - "env=<variable_name>" to use a given variable name instead of the one computed from the id,
+//     it should start with the configured prefix,
+//   - "data=<key>" to select a key of a secret stored as a JSON object.
+func (ec *envClient) GetSecret(id string, options ...string) (vault.Secret, error) {
+	opts := vault.ParseOptions(options...)
+	name, ok := opts["env"]
+	if !ok {
+		name = VariableName(ec.prefix, id)
+	} else if !strings.HasPrefix(name, ec.prefix) {
+		return nil, errors.Errorf("secret %q can't be read from environment variable %q, variables names should start with %q", id, name, ec.prefix)
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, errors.Errorf("secret %q not found, environment variable %q is not set", id, name)
+	}
+	d, ok := opts["data"]
+	if !ok {
+		return &envSecret{value: value}, nil
+	}
+	m := make(map[string]interface{})
+	err := json.Unmarshal([]byte(value), &m)
+	if err != nil {
+		return nil, errors.Wrapf(err, "secret %q is not a JSON object, can't get its %q data", id, d)
+	}
+	data, ok := m[d]
+	if !ok {
+		return nil, errors.Errorf("secret %q has no %q data", id, d)
+	}
+	return &envSecret{value: data}, nil
+}
+
+func (ec *envClient) Shutdown() error {
+	return nil
+}
+
+type envSecret struct {
+	value interface{}
+}
+
+func (es *envSecret) String() string {
+	return fmt.Sprint(es.value)
+}
+
+func (es *envSecret) Raw() interface{} {
+	return es.value
+}
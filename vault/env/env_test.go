@@ -0,0 +1,64 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+)
+
+func TestVariableName(t *testing.T) {
+	require.Equal(t, "SECRETS_DB_PASSWORD", VariableName("", "/secrets/db/password"))
+	require.Equal(t, "YORC_SECRET_MY_APP_TOKEN", VariableName("YORC_SECRET_", "my-app.token"))
+}
+
+func TestEnvGetSecret(t *testing.T) {
+	os.Setenv("YORC_TEST_SECRET_DB_PASSWORD", "s3cr3t")
+	defer os.Unsetenv("YORC_TEST_SECRET_DB_PASSWORD")
+	os.Setenv("YORC_TEST_SECRET_APP", `{"user": "admin", "password": "adminpass"}`)
+	defer os.Unsetenv("YORC_TEST_SECRET_APP")
+
+	client, err := (&clientBuilder{}).BuildClient(config.Configuration{Vault: config.DynamicMap{"type": "env", "variable_prefix": "YORC_TEST_SECRET_"}})
+	require.NoError(t, err)
+
+	s, err := client.GetSecret("/db/password")
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", s.String())
+
+	s, err = client.GetSecret("app", "data=user")
+	require.NoError(t, err)
+	require.Equal(t, "admin", s.String())
+
+	s, err = client.GetSecret("whatever", "env=YORC_TEST_SECRET_DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", s.String())
+
+	_, err = client.GetSecret("whatever", "env=HOME")
+	require.Error(t, err, "variables without prefix should not be read")
+
+	_, err = (&clientBuilder{}).BuildClient(config.Configuration{Vault: config.DynamicMap{"type": "env"}})
+	require.Error(t, err, "prefix should be required")
+
+	_, err = client.GetSecret("app", "data=missing")
+	require.Error(t, err)
+	_, err = client.GetSecret("/db/password", "data=user")
+	require.Error(t, err)
+	_, err = client.GetSecret("missing")
+	require.Error(t, err)
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/vault"
+)
+
+// KeySize is the size in bytes of the keys used to encrypt secrets files (AES-256)
+const KeySize = 32
+
+type clientBuilder struct {
+}
+
+func (b *clientBuilder) BuildClient(cfg config.Configuration) (vault.Client, error) {
+	log.Debug("Setting up encrypted file secrets store")
+	filePath := cfg.Vault.GetString("file")
+	if filePath == "" {
+		return nil, errors.New("the \"file\" option is required for the encrypted file secrets store")
+	}
+	key, err := readKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read secrets file %q", filePath)
+	}
+	plaintext, err := Decrypt(key, data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt secrets file %q", filePath)
+	}
+	secrets := make(map[string]interface{})
+	err = yaml.Unmarshal(plaintext, &secrets)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse secrets file %q", filePath)
+	}
+	return &fileClient{secrets: secrets}, nil
+}
+
+// readKey reads the base64-encoded key either directly from the configuration or from a file
+func readKey(cfg config.Configuration) ([]byte, error) {
+	encodedKey := cfg.Vault.GetString("key")
+	if encodedKey == "" {
+		keyFile := cfg.Vault.GetString("key_file")
+		if keyFile == "" {
+			return nil, errors.New("either the \"key\" or the \"key_file\" option is required for the encrypted file secrets store")
+		}
+		b, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read secrets file key %q", keyFile)
+		}
+		encodedKey = string(b)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "secrets file key is not a valid base64 encoded string")
+	}
+	if len(key) != KeySize {
+		return nil, errors.Errorf("secrets file key should be %d bytes long, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// GenerateKey generates a random key suitable to encrypt a secrets file
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	_, err := io.ReadFull(rand.Reader, key)
+	return key, errors.Wrap(err, "failed to generate secrets file key")
+}
+
+// Encrypt encrypts a secrets file content using AES-256-GCM.
+//
+// The random nonce is prepended to the returned sealed data. This format is specific to Yorc, it is
+// not compatible with age or NaCl secretbox which are not available in our vendored dependencies.
+func Encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt decrypts a secrets file content encrypted with Encrypt
+func Decrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("encrypted data is too short")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	return plaintext, errors.Wrap(err, "failed to decrypt data, the key may be wrong or the data corrupted")
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid key")
+	}
+	gcm, err := cipher.NewGCM(block)
+	return gcm, errors.Wrap(err, "failed to setup AES-GCM cipher")
+}
+
+type fileClient struct {
+	secrets map[string]interface{}
+}
+
+// GetSecret returns the secret stored under the given id in the secrets file.
+//
+// The "data=<key>" option allows to select a given key of a secret defined as a map.
+func (fc *fileClient) GetSecret(id string, options ...string) (vault.Secret, error) {
+	value, ok := fc.secrets[id]
+	if !ok {
+		return nil, errors.Errorf("secret %q not found", id)
+	}
+	opts := vault.ParseOptions(options...)
+	if d, ok := opts["data"]; ok {
+		m, err := cast.ToStringMapE(value)
+		if err != nil {
+			return nil, errors.Errorf("secret %q is not a map, can't get its %q data", id, d)
+		}
+		value, ok = m[d]
+		if !ok {
+			return nil, errors.Errorf("secret %q has no %q data", id, d)
+		}
+	}
+	return &fileSecret{value: value}, nil
+}
+
+func (fc *fileClient) Shutdown() error {
+	return nil
+}
+
+type fileSecret struct {
+	value interface{}
+}
+
+func (fs *fileSecret) String() string {
+	return fmt.Sprint(fs.value)
+}
+
+func (fs *fileSecret) Raw() interface{} {
+	return fs.value
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestore
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/config"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	data, err := Encrypt(key, []byte("secret: value"))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "value")
+
+	plaintext, err := Decrypt(key, data)
+	require.NoError(t, err)
+	require.Equal(t, "secret: value", string(plaintext))
+
+	otherKey, err := GenerateKey()
+	require.NoError(t, err)
+	_, err = Decrypt(otherKey, data)
+	require.Error(t, err)
+	_, err = Decrypt(key, data[:4])
+	require.Error(t, err)
+	_, err = Encrypt(key[:10], []byte("secret: value"))
+	require.Error(t, err)
+}
+
+func TestFileStoreGetSecret(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "yorc-filestore-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	data, err := Encrypt(key, []byte(`
+/secrets/db/password: s3cr3t
+/secrets/app:
+  user: admin
+  password: adminpass
+`))
+	require.NoError(t, err)
+	secretsFile := filepath.Join(tmpDir, "secrets.enc")
+	require.NoError(t, ioutil.WriteFile(secretsFile, data, 0600))
+	keyFile := filepath.Join(tmpDir, "key")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600))
+
+	cfg := config.Configuration{Vault: config.DynamicMap{"type": "file", "file": secretsFile, "key_file": keyFile}}
+	client, err := (&clientBuilder{}).BuildClient(cfg)
+	require.NoError(t, err)
+	defer client.Shutdown()
+
+	s, err := client.GetSecret("/secrets/db/password")
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", s.String())
+
+	s, err = client.GetSecret("/secrets/app", "data=password")
+	require.NoError(t, err)
+	require.Equal(t, "adminpass", s.String())
+
+	_, err = client.GetSecret("/secrets/app", "data=missing")
+	require.Error(t, err)
+	_, err = client.GetSecret("/secrets/db/password", "data=password")
+	require.Error(t, err)
+	_, err = client.GetSecret("/secrets/missing")
+	require.Error(t, err)
+
+	cfg.Vault = config.DynamicMap{"type": "file", "file": secretsFile, "key": base64.StdEncoding.EncodeToString(key[:16])}
+	_, err = (&clientBuilder{}).BuildClient(cfg)
+	require.Error(t, err)
+	cfg.Vault = config.DynamicMap{"type": "file", "file": secretsFile}
+	_, err = (&clientBuilder{}).BuildClient(cfg)
+	require.Error(t, err)
+}
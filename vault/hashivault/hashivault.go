@@ -16,7 +16,6 @@ package hashivault
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/pkg/errors"
@@ -137,15 +136,7 @@ type vaultClient struct {
 
 func (vc *vaultClient) GetSecret(id string, options ...string) (vault.Secret, error) {
 	// log.Debugf("Getting secret: %q", id)
-	opts := make(map[string]string)
-	for _, o := range options {
-		optsList := strings.SplitN(o, "=", 2)
-		if len(optsList) == 2 {
-			opts[optsList[0]] = optsList[1]
-		} else {
-			opts[o] = ""
-		}
-	}
+	opts := vault.ParseOptions(options...)
 	s, err := vc.vClient.Logical().Read(id)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to read secret %q", id)
@@ -0,0 +1,152 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/ystia/yorc/config"
+	"github.com/ystia/yorc/helper/stringutil"
+	"github.com/ystia/yorc/log"
+	"github.com/ystia/yorc/vault"
+)
+
+const defaultNamespace = "default"
+
+type clientBuilder struct {
+}
+
+func (b *clientBuilder) BuildClient(cfg config.Configuration) (vault.Client, error) {
+	log.Debug("Setting up Kubernetes secrets store")
+	var conf *rest.Config
+	var err error
+	masterURL := cfg.Vault.GetString("master_url")
+	kubeConfigPathOrContent := cfg.Vault.GetString("kubeconfig")
+	if kubeConfigPathOrContent == "" && masterURL == "" {
+		log.Debugf("No Kubernetes cluster specified in vault configuration, attempting to authenticate inside the cluster")
+		conf, err = rest.InClusterConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build kubernetes InClusterConfig")
+		}
+	} else {
+		var kubeConfigPath string
+		if kubeConfigPathOrContent != "" {
+			var wasPath bool
+			if kubeConfigPath, wasPath, err = stringutil.GetFilePath(kubeConfigPathOrContent); err != nil {
+				return nil, errors.Wrap(err, "failed to get Kubernetes config file")
+			}
+			if !wasPath {
+				defer os.Remove(kubeConfigPath)
+			}
+		}
+		conf, err = clientcmd.BuildConfigFromFlags(masterURL, kubeConfigPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build kubernetes config")
+		}
+		if kubeConfigPath == "" {
+			conf.TLSClientConfig.Insecure = cfg.Vault.GetBool("insecure")
+			conf.TLSClientConfig.CAFile = cfg.Vault.GetString("ca_file")
+			conf.TLSClientConfig.CertFile = cfg.Vault.GetString("cert_file")
+			conf.TLSClientConfig.KeyFile = cfg.Vault.GetString("key_file")
+		}
+	}
+	clientset, err := kubernetes.NewForConfig(conf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes clientset from config")
+	}
+	return &k8sClient{clientset: clientset, namespace: cfg.Vault.GetStringOrDefault("namespace", defaultNamespace)}, nil
+}
+
+type k8sClient struct {
+	clientset kubernetes.Interface
+	namespace string
+}
+
+// GetSecret reads a Kubernetes Secret.
+//
+// The id is either the name of the secret or <namespace>/<name>.
+// Supported options are:
+//   - "namespace=<namespace>" to read the secret from a given namespace, it should
+//     match the namespace of the id if any,
+//   - "data=<key>" to select a key of the secret data.
+//
+// Without data option, if the secret has a single key its value is returned.
+func (kc *k8sClient) GetSecret(id string, options ...string) (vault.Secret, error) {
+	opts := vault.ParseOptions(options...)
+	namespace, name := kc.namespace, strings.TrimLeft(id, "/")
+	idNamespace := ""
+	if i := strings.Index(name, "/"); i > 0 {
+		idNamespace, name = name[:i], name[i+1:]
+		namespace = idNamespace
+	}
+	if ns, ok := opts["namespace"]; ok && ns != "" {
+		if idNamespace != "" && idNamespace != ns {
+			return nil, errors.Errorf("secret id %q namespace conflicts with namespace option %q", id, ns)
+		}
+		namespace = ns
+	}
+	if name == "" || strings.Contains(name, "/") {
+		return nil, errors.Errorf("invalid secret id %q, expecting either <name> or <namespace>/<name>", id)
+	}
+	s, err := kc.clientset.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read secret %q in namespace %q", name, namespace)
+	}
+	secret := &k8sSecret{Secret: s}
+	if d, ok := opts["data"]; ok {
+		if _, ok = s.Data[d]; !ok {
+			return nil, errors.Errorf("secret %q in namespace %q has no %q data", name, namespace, d)
+		}
+		secret.key = d
+	} else if len(s.Data) == 1 {
+		for k := range s.Data {
+			secret.key = k
+		}
+	}
+	return secret, nil
+}
+
+func (kc *k8sClient) Shutdown() error {
+	return nil
+}
+
+type k8sSecret struct {
+	*v1.Secret
+	key string
+}
+
+func (ks *k8sSecret) String() string {
+	if ks.key != "" {
+		return string(ks.Data[ks.key])
+	}
+	data := make(map[string]string, len(ks.Data))
+	for k, v := range ks.Data {
+		data[k] = string(v)
+	}
+	return fmt.Sprint(data)
+}
+
+func (ks *k8sSecret) Raw() interface{} {
+	return ks.Secret
+}
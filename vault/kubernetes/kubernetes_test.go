@@ -0,0 +1,111 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// The following types implement only the parts of the client-go clientset used to read secrets
+
+type mockClientset struct {
+	kubernetes.Interface
+	secrets map[string]map[string]*v1.Secret
+}
+
+func (m *mockClientset) CoreV1() corev1.CoreV1Interface {
+	return &mockCoreV1{secrets: m.secrets}
+}
+
+type mockCoreV1 struct {
+	corev1.CoreV1Interface
+	secrets map[string]map[string]*v1.Secret
+}
+
+func (m *mockCoreV1) Secrets(namespace string) corev1.SecretInterface {
+	return &mockSecrets{namespace: namespace, secrets: m.secrets[namespace]}
+}
+
+type mockSecrets struct {
+	corev1.SecretInterface
+	namespace string
+	secrets   map[string]*v1.Secret
+}
+
+func (m *mockSecrets) Get(name string, options metav1.GetOptions) (*v1.Secret, error) {
+	s, ok := m.secrets[name]
+	if !ok {
+		return nil, errors.Errorf("secrets %q not found in namespace %q", name, m.namespace)
+	}
+	return s, nil
+}
+
+func newMockClient() *k8sClient {
+	return &k8sClient{
+		namespace: defaultNamespace,
+		clientset: &mockClientset{secrets: map[string]map[string]*v1.Secret{
+			"default": {
+				"db": {Data: map[string][]byte{"password": []byte("s3cr3t")}},
+			},
+			"apps": {
+				"app": {Data: map[string][]byte{"user": []byte("admin"), "password": []byte("adminpass")}},
+			},
+		}},
+	}
+}
+
+func TestK8sGetSecret(t *testing.T) {
+	t.Parallel()
+	client := newMockClient()
+
+	s, err := client.GetSecret("db")
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", s.String(), "single key value should be returned")
+
+	s, err = client.GetSecret("/default/db", "data=password")
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", s.String())
+
+	s, err = client.GetSecret("apps/app", "data=user")
+	require.NoError(t, err)
+	require.Equal(t, "admin", s.String())
+
+	s, err = client.GetSecret("app", "namespace=apps")
+	require.NoError(t, err)
+	require.Equal(t, "map[password:adminpass user:admin]", s.String())
+	require.IsType(t, &v1.Secret{}, s.Raw())
+
+	s, err = client.GetSecret("apps/app", "namespace=apps", "data=password")
+	require.NoError(t, err)
+	require.Equal(t, "adminpass", s.String())
+
+	_, err = client.GetSecret("apps/app", "namespace=default")
+	require.Error(t, err, "conflicting namespaces should be rejected")
+	_, err = client.GetSecret("apps/app/password")
+	require.Error(t, err)
+	_, err = client.GetSecret("apps/")
+	require.Error(t, err)
+	_, err = client.GetSecret("app")
+	require.Error(t, err, "secret should not be found in the default namespace")
+	_, err = client.GetSecret("apps/app", "data=missing")
+	require.Error(t, err)
+}
@@ -16,6 +16,7 @@ package vault
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ystia/yorc/config"
 )
@@ -52,3 +53,19 @@ type ClientBuilder interface {
 	// BuildClient builds a Vault client based on Yorc configuration
 	BuildClient(cfg config.Configuration) (Client, error)
 }
+
+// ParseOptions parses secrets options given as "key=value" strings.
+//
+// Options without a value are returned with an empty value.
+func ParseOptions(options ...string) map[string]string {
+	opts := make(map[string]string, len(options))
+	for _, o := range options {
+		optsList := strings.SplitN(o, "=", 2)
+		if len(optsList) == 2 {
+			opts[optsList[0]] = optsList[1]
+		} else {
+			opts[o] = ""
+		}
+	}
+	return opts
+}
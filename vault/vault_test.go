@@ -0,0 +1,39 @@
+// Copyright 2018 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []string
+		want    map[string]string
+	}{
+		{"NoOptions", nil, map[string]string{}},
+		{"KeyValue", []string{"data=password"}, map[string]string{"data": "password"}},
+		{"ValueWithEqual", []string{"data=a=b", "ns"}, map[string]string{"data": "a=b", "ns": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseOptions(tt.options...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}